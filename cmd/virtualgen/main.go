@@ -0,0 +1,661 @@
+// Command virtualgen generates the boilerplate of virtual objects.
+//
+// It reads the XxxObject structs annotated with a goforms:virtual directive
+// and emits the Xxx, XxxSpi and XxxInterface interfaces, the NewXxxObject constructor,
+// the XxxObj accessor and optionally the NewXxx builder, following forms.PanelObject.
+// Declarations already present in the package are not generated again.
+//
+// Usage:
+//
+//	//go:generate go run github.com/zzl/goforms/cmd/virtualgen
+//
+//	//goforms:virtual builder=WindowBg,Border
+//	type PanelObject struct {
+//		ContainerControlObject
+//		super *ContainerControlObject
+//
+//		WindowBg bool
+//		Border   bool
+//	}
+//
+//	//goforms:interface
+//	func (this *PanelObject) GetBorder() bool { ... }
+//
+// Directive options:
+//
+//	base=Name      the interface extended by Xxx, defaults to the base object name without Object
+//	spi=Name       the interface extended by XxxSpi, defaults to the nearest Spi interface
+//	               up the chain of base objects, e.g. ControlSpi for ContainerControlObject
+//	builder[=F,..] generate the NewXxx builder, copying the listed fields
+//
+// Methods annotated with goforms:interface are added to Xxx,
+// those annotated with goforms:spi are added to XxxSpi.
+//
+// With -check, nothing is written; the command fails if the output file is not up to date.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const formsPath = "github.com/zzl/goforms/forms"
+
+const directive = "//goforms:virtual"
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of object type names; default all annotated types")
+	output    = flag.String("output", "", "output file name; default <file>_gen.go, where file is $GOFILE or the package name")
+	check     = flag.Bool("check", false, "fail if the output file is not up to date instead of writing it")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("virtualgen: ")
+	flag.Parse()
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	outputPath := *output
+	if outputPath == "" {
+		base := os.Getenv("GOFILE")
+		if base == "" {
+			base = "virtual"
+		}
+		outputPath = strings.TrimSuffix(base, ".go") + "_gen.go"
+	}
+	if !filepath.IsAbs(outputPath) {
+		outputPath = filepath.Join(dir, outputPath)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+	src, err := Generate(dir, os.Getenv("GOFILE"), filepath.Base(outputPath), types)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *check {
+		existing, _ := os.ReadFile(outputPath)
+		if !bytes.Equal(existing, src) {
+			log.Fatalf("%s is not up to date, run go generate", outputPath)
+		}
+		return
+	}
+	err = os.WriteFile(outputPath, src, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Generate parses the package in dir, ignoring the output file, and returns
+// the generated source for the given types. If no types are given, the annotated
+// types of the source file are used, or those of the package if sourceName is empty.
+func Generate(dir string, sourceName string, outputName string, typeNames []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		name := info.Name()
+		return name != outputName && !strings.HasSuffix(name, "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+	var pkg *ast.Package
+	for _, it := range pkgs {
+		pkg = it
+	}
+	g := &generator{fset: fset, dir: dir, pkg: pkg, declared: make(map[string]bool)}
+	g.scan()
+	return g.generate(sourceName, typeNames)
+}
+
+type method struct {
+	Name      string
+	Signature string
+}
+
+type field struct {
+	Name string
+	Type string
+}
+
+type object struct {
+	Name          string //XxxObject
+	Short         string //Xxx
+	Var           string //local variable name in the builder
+	Base          string
+	BaseSpi       string
+	Builder       bool
+	BuilderFields []field
+	Methods       []method
+	SpiMethods    []method
+
+	GenInterface     bool
+	GenSpi           bool
+	GenFull          bool
+	GenConstructor   bool
+	GenAccessor      bool
+	GenBuilder       bool
+	GenBuilderCreate bool
+}
+
+// decls are the struct and interface types of a package, to resolve base objects.
+type decls struct {
+	structs    map[string]*ast.StructType
+	interfaces map[string]bool
+}
+
+func newDecls() *decls {
+	return &decls{structs: make(map[string]*ast.StructType), interfaces: make(map[string]bool)}
+}
+
+func (this *decls) add(spec *ast.TypeSpec) {
+	switch t := spec.Type.(type) {
+	case *ast.StructType:
+		this.structs[spec.Name.Name] = t
+	case *ast.InterfaceType:
+		this.interfaces[spec.Name.Name] = true
+	}
+}
+
+type generator struct {
+	fset *token.FileSet
+	dir  string
+	pkg  *ast.Package
+
+	qualifier string //prefix of the forms identifiers
+	internal  bool   //whether generating for the forms package itself
+	dotImport bool
+
+	structs  map[string]*ast.TypeSpec
+	docs     map[string]*ast.CommentGroup
+	files    map[string]string //struct name to base file name
+	methods  map[string][]*ast.FuncDecl
+	declared map[string]bool //top level names and Type.Method names
+
+	local      *decls //including the Spi interfaces to be generated
+	formsDecls *decls //parsed when needed
+}
+
+func (this *generator) scan() {
+	this.structs = make(map[string]*ast.TypeSpec)
+	this.docs = make(map[string]*ast.CommentGroup)
+	this.files = make(map[string]string)
+	this.methods = make(map[string][]*ast.FuncDecl)
+	this.local = newDecls()
+
+	importsForms := false
+	fileNames := make([]string, 0, len(this.pkg.Files))
+	for fileName := range this.pkg.Files {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+	for _, fileName := range fileNames {
+		file := this.pkg.Files[fileName]
+		for _, spec := range file.Imports {
+			if strings.Trim(spec.Path.Value, `"`) != formsPath {
+				continue
+			}
+			importsForms = true
+			if spec.Name != nil && spec.Name.Name == "." {
+				this.dotImport = true
+			} else if spec.Name != nil {
+				this.qualifier = spec.Name.Name + "."
+			}
+		}
+		for _, decl := range file.Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						this.declared[spec.Name.Name] = true
+						this.local.add(spec)
+						if _, ok := spec.Type.(*ast.StructType); ok {
+							this.structs[spec.Name.Name] = spec
+							this.files[spec.Name.Name] = filepath.Base(fileName)
+							doc := spec.Doc
+							if doc == nil && len(decl.Specs) == 1 {
+								doc = decl.Doc
+							}
+							this.docs[spec.Name.Name] = doc
+						}
+					case *ast.ValueSpec:
+						for _, name := range spec.Names {
+							this.declared[name.Name] = true
+						}
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil {
+					this.declared[decl.Name.Name] = true
+					continue
+				}
+				recv := receiverTypeName(decl.Recv.List[0].Type)
+				this.declared[recv+"."+decl.Name.Name] = true
+				this.methods[recv] = append(this.methods[recv], decl)
+			}
+		}
+	}
+	this.internal = this.pkg.Name == "forms" && !importsForms
+	if !this.internal && !this.dotImport && this.qualifier == "" {
+		this.qualifier = "forms."
+	}
+	for name, doc := range this.docs {
+		if _, ok := directiveOf(doc, directive); ok {
+			this.local.interfaces[strings.TrimSuffix(name, "Object")+"Spi"] = true
+		}
+	}
+}
+
+// declsOf returns the declarations of the package of a base object type,
+// which is qualified by the forms package or local, or dot imported from forms.
+func (this *generator) declsOf(qualifier string, name string) (*decls, error) {
+	if qualifier == "" {
+		if _, ok := this.local.structs[name]; ok || !this.dotImport {
+			return this.local, nil
+		}
+	} else if qualifier != this.qualifier {
+		return nil, fmt.Errorf("base %s%s is not in the forms package", qualifier, name)
+	}
+	if this.internal {
+		return this.local, nil
+	}
+	if this.formsDecls == nil {
+		p, err := build.Import(formsPath, this.dir, build.FindOnly)
+		if err != nil {
+			return nil, err
+		}
+		pkgs, err := parser.ParseDir(this.fset, p.Dir, func(info os.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go")
+		}, 0)
+		if err != nil {
+			return nil, err
+		}
+		this.formsDecls = newDecls()
+		for _, pkg := range pkgs {
+			for _, file := range pkg.Files {
+				for _, decl := range file.Decls {
+					if decl, ok := decl.(*ast.GenDecl); ok {
+						for _, spec := range decl.Specs {
+							if spec, ok := spec.(*ast.TypeSpec); ok {
+								this.formsDecls.add(spec)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+	return this.formsDecls, nil
+}
+
+// resolveSpi returns the Spi interface to be extended by objects with a base object,
+// that of the base or, if it has none, that of its own base and so on up the chain,
+// e.g. ControlSpi for ContainerControlObject.
+func (this *generator) resolveSpi(baseType string) (string, error) {
+	for typ := baseType; strings.HasSuffix(typ, "Object"); {
+		qualifier, name := splitQualified(typ)
+		d, err := this.declsOf(qualifier, name)
+		if err != nil {
+			return "", err
+		}
+		spi := strings.TrimSuffix(name, "Object") + "Spi"
+		if d.interfaces[spi] {
+			return qualifier + spi, nil
+		}
+		st, ok := d.structs[name]
+		if !ok || len(st.Fields.List) == 0 || len(st.Fields.List[0].Names) != 0 {
+			break
+		}
+		//the base of the base, in the same package
+		typ = qualify(qualifier, this.exprString(st.Fields.List[0].Type))
+	}
+	return "", fmt.Errorf("no Spi interface found for base %s, set the spi option", baseType)
+}
+
+// splitQualified splits a type name into its package qualifier, with the dot, and name.
+func splitQualified(typ string) (string, string) {
+	if n := strings.LastIndex(typ, "."); n >= 0 {
+		return typ[:n+1], typ[n+1:]
+	}
+	return "", typ
+}
+
+// qualify qualifies a type name unless it is qualified already.
+func qualify(qualifier string, typ string) string {
+	if strings.Contains(typ, ".") {
+		return typ
+	}
+	return qualifier + typ
+}
+
+func receiverTypeName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func directiveOf(doc *ast.CommentGroup, name string) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	for _, comment := range doc.List {
+		text := comment.Text
+		if text == name {
+			return "", true
+		}
+		if strings.HasPrefix(text, name+" ") {
+			return strings.TrimSpace(text[len(name):]), true
+		}
+	}
+	return "", false
+}
+
+func (this *generator) generate(sourceName string, typeNames []string) ([]byte, error) {
+	if len(typeNames) == 0 {
+		for name := range this.structs {
+			if sourceName != "" && this.files[name] != sourceName {
+				continue
+			}
+			if _, ok := directiveOf(this.docs[name], directive); ok {
+				typeNames = append(typeNames, name)
+			}
+		}
+		sort.Strings(typeNames)
+	}
+	if len(typeNames) == 0 {
+		return nil, errors.New("no annotated types found")
+	}
+	var objects []*object
+	for _, name := range typeNames {
+		obj, err := this.parseObject(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, obj)
+	}
+
+	imports := map[string]string{}
+	usesForms := false
+	for _, obj := range objects {
+		usesForms = usesForms || obj.GenInterface || obj.GenSpi ||
+			obj.GenBuilder || obj.GenBuilderCreate
+		if obj.GenConstructor {
+			imports["github.com/zzl/goforms/framework/virtual"] = ""
+		}
+		if obj.GenBuilderCreate {
+			imports["github.com/zzl/goforms/framework/utils"] = ""
+		}
+	}
+	if !usesForms || this.internal {
+		//
+	} else if this.dotImport {
+		imports[formsPath] = "."
+	} else {
+		imports[formsPath] = ""
+	}
+
+	var buf bytes.Buffer
+	err := fileTemplate.Execute(&buf, map[string]any{
+		"Package":  this.pkg.Name,
+		"Imports":  sortedImports(imports),
+		"Objects":  objects,
+		"Q":        this.qualifier,
+		"Internal": this.internal,
+	})
+	if err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, buf.Bytes())
+	}
+	return src, nil
+}
+
+func sortedImports(imports map[string]string) []string {
+	var lines []string
+	for path, name := range imports {
+		if name != "" {
+			lines = append(lines, name+` "`+path+`"`)
+		} else {
+			lines = append(lines, `"`+path+`"`)
+		}
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return strings.TrimPrefix(lines[i], `. `) < strings.TrimPrefix(lines[j], `. `)
+	})
+	return lines
+}
+
+func (this *generator) parseObject(name string) (*object, error) {
+	spec, ok := this.structs[name]
+	if !ok {
+		return nil, fmt.Errorf("struct type %s not found", name)
+	}
+	if !strings.HasSuffix(name, "Object") || name == "Object" {
+		return nil, fmt.Errorf("%s: name must be of the form XxxObject", name)
+	}
+	options, _ := directiveOf(this.docs[name], directive)
+
+	st := spec.Type.(*ast.StructType)
+	if len(st.Fields.List) == 0 || len(st.Fields.List[0].Names) != 0 {
+		return nil, fmt.Errorf("%s: the base object must be the first field", name)
+	}
+	baseType := this.exprString(st.Fields.List[0].Type)
+	if !strings.HasSuffix(baseType, "Object") {
+		return nil, fmt.Errorf("%s: base %s is not a virtual object type", name, baseType)
+	}
+	hasSuper := false
+	for _, f := range st.Fields.List[1:] {
+		if len(f.Names) == 1 && f.Names[0].Name == "super" {
+			if this.exprString(f.Type) != "*"+baseType {
+				return nil, fmt.Errorf("%s: super must be of type *%s", name, baseType)
+			}
+			hasSuper = true
+		}
+	}
+	if !hasSuper {
+		return nil, fmt.Errorf("%s: missing field super *%s", name, baseType)
+	}
+
+	short := strings.TrimSuffix(name, "Object")
+	baseQualifier, _ := splitQualified(baseType)
+	obj := &object{
+		Name:  name,
+		Short: short,
+		Var:   strings.ToLower(short[:1]) + short[1:],
+		Base:  strings.TrimSuffix(baseType, "Object"),
+	}
+	for _, option := range strings.Fields(options) {
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "base":
+			obj.Base = qualify(baseQualifier, value)
+		case "spi":
+			obj.BaseSpi = qualify(baseQualifier, value)
+		case "builder":
+			obj.Builder = true
+			if value == "" {
+				continue
+			}
+			for _, fieldName := range strings.Split(value, ",") {
+				f, err := this.findField(st, fieldName)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %v", name, err)
+				}
+				obj.BuilderFields = append(obj.BuilderFields, f)
+			}
+		default:
+			return nil, fmt.Errorf("%s: unknown directive option %q", name, key)
+		}
+	}
+
+	if obj.BaseSpi == "" {
+		spi, err := this.resolveSpi(baseType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", name, err)
+		}
+		obj.BaseSpi = spi
+	}
+
+	for _, fn := range this.methods[name] {
+		if _, ok := directiveOf(fn.Doc, "//goforms:interface"); ok {
+			obj.Methods = append(obj.Methods, this.methodOf(fn))
+		}
+		if _, ok := directiveOf(fn.Doc, "//goforms:spi"); ok {
+			obj.SpiMethods = append(obj.SpiMethods, this.methodOf(fn))
+		}
+	}
+
+	obj.GenInterface = !this.declared[short]
+	obj.GenSpi = !this.declared[short+"Spi"]
+	obj.GenFull = !this.declared[short+"Interface"]
+	obj.GenConstructor = !this.declared["New"+name]
+	obj.GenAccessor = !this.declared[name+"."+short+"Obj"]
+	obj.GenBuilder = obj.Builder && !this.declared["New"+short]
+	obj.GenBuilderCreate = obj.Builder && !this.declared["New"+short+".Create"]
+	return obj, nil
+}
+
+func (this *generator) findField(st *ast.StructType, name string) (field, error) {
+	for _, f := range st.Fields.List {
+		for _, ident := range f.Names {
+			if ident.Name == name {
+				if !ident.IsExported() {
+					return field{}, fmt.Errorf("builder field %s is not exported", name)
+				}
+				return field{Name: name, Type: this.exprString(f.Type)}, nil
+			}
+		}
+	}
+	return field{}, fmt.Errorf("builder field %s not found", name)
+}
+
+func (this *generator) methodOf(fn *ast.FuncDecl) method {
+	sig := this.exprString(fn.Type)
+	return method{Name: fn.Name.Name, Signature: strings.TrimPrefix(sig, "func")}
+}
+
+func (this *generator) exprString(node ast.Node) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, this.fset, node)
+	return buf.String()
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by virtualgen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+{{- $q := .Q}}{{$internal := .Internal}}
+{{range .Objects}}{{$obj := .}}
+{{- if .GenInterface}}
+type {{.Short}} interface {
+	{{.Base}}
+{{range .Methods}}
+	{{.Name}}{{.Signature}}
+{{- end}}
+
+	{{.Short}}Obj() *{{.Name}}
+}
+{{end}}
+{{- if .GenSpi}}
+type {{.Short}}Spi interface {
+	{{.BaseSpi}}
+{{- range .SpiMethods}}
+	{{.Name}}{{.Signature}}
+{{- end}}
+}
+{{end}}
+{{- if .GenFull}}
+type {{.Short}}Interface interface {
+	{{.Short}}
+	{{.Short}}Spi
+}
+{{end}}
+{{- if .GenBuilder}}
+type New{{.Short}} struct {
+	Parent {{$q}}Container
+	Name   string
+	Pos    {{$q}}Point
+	Size   {{$q}}Size
+{{- range .BuilderFields}}
+	{{.Name}} {{.Type}}
+{{- end}}
+}
+{{end}}
+{{- if .GenBuilderCreate}}
+func (me New{{.Short}}) Create(extraOpts ...*{{$q}}WindowOptions) {{.Short}} {
+	{{.Var}} := New{{.Name}}()
+{{- if $internal}}
+	{{.Var}}.name = me.Name
+{{- else}}
+	{{.Var}}.SetName(me.Name)
+{{- end}}
+{{- range .BuilderFields}}
+	{{$obj.Var}}.{{.Name}} = me.{{.Name}}
+{{- end}}
+
+	opts := utils.OptionalArg(extraOpts)
+	opts.Left = me.Pos.X
+	opts.Top = me.Pos.Y
+
+{{- if $internal}}
+
+	opts.ParentHandle = resolveParentHandle(me.Parent)
+	err := {{.Var}}.Create(*opts)
+	assertNoErr(err)
+
+	configControlSize({{.Var}}, me.Size)
+{{- else}}
+
+	opts.ParentHandle = {{$q}}ResolveParentHandle(me.Parent)
+	err := {{.Var}}.Create(*opts)
+	{{$q}}ReportError(err)
+
+	{{$q}}ConfigControlSize({{.Var}}, me.Size)
+{{- end}}
+	return {{.Var}}
+}
+{{end}}
+{{- if .GenConstructor}}
+func New{{.Name}}() *{{.Name}} {
+	return virtual.New[{{.Name}}]()
+}
+{{end}}
+{{- if .GenAccessor}}
+func (this *{{.Name}}) {{.Short}}Obj() *{{.Name}} {
+	return this
+}
+{{end}}
+{{- end}}`))
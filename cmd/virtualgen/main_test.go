@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestGenerate generates the code of the packages in testdata,
+// and compares it with their virtual_gen.go.golden file.
+func TestGenerate(t *testing.T) {
+	dirs, err := filepath.Glob(filepath.Join("testdata", "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, dir := range dirs {
+		t.Run(filepath.Base(dir), func(t *testing.T) {
+			src, err := Generate(dir, "", "virtual_gen.go", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			golden := filepath.Join(dir, "virtual_gen.go.golden")
+			if *update {
+				if err := os.WriteFile(golden, src, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(src, want) {
+				t.Errorf("generated code differs from %s:\n%s", golden, src)
+			}
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+	}{
+		{"no base", "type FooObject struct {\n\tName string\n}"},
+		{"no super", "type FooObject struct {\n\tforms.ControlObject\n}"},
+		{"unresolved spi", "type FooObject struct {\n\tBarObject\n\tsuper *BarObject\n}\n\ntype BarObject struct{}"},
+		{"unknown option", "//goforms:virtual color=red\ntype FooObject struct {\n\tforms.ControlObject\n\tsuper *forms.ControlObject\n}"},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		src := "package foo\n\nimport \"github.com/zzl/goforms/forms\"\n\nvar _ forms.Control\n\n"
+		if !bytes.Contains([]byte(test.src), []byte("goforms:virtual")) {
+			src += "//goforms:virtual\n"
+		}
+		src += test.src + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "foo.go"), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Generate(dir, "", "virtual_gen.go", []string{"FooObject"}); err == nil {
+			t.Errorf("%s: no error", test.name)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package views
+
+import "github.com/zzl/goforms/forms"
+
+//goforms:virtual
+type ViewObject struct {
+	forms.ControlObject
+	super *forms.ControlObject
+}
+
+//goforms:spi
+func (this *ViewObject) Render() {
+}
+
+//goforms:virtual
+type ListViewObject struct {
+	ViewObject
+	super *ViewObject
+}
+
+//goforms:virtual spi=forms.WindowSpi
+type RawViewObject struct {
+	forms.WindowObject
+	super *forms.WindowObject
+}
@@ -0,0 +1,23 @@
+package widgets
+
+import (
+	. "github.com/zzl/goforms/forms"
+)
+
+//goforms:virtual
+type BadgeObject struct {
+	LabelObject
+	super *LabelObject
+
+	Count int
+}
+
+//goforms:interface
+func (this *BadgeObject) SetCount(count int) {
+	this.Count = count
+}
+
+//goforms:spi
+func (this *BadgeObject) FormatCount(count int) string {
+	return ""
+}
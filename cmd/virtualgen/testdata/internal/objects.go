@@ -0,0 +1,22 @@
+package forms
+
+type Control interface {
+	GetName() string
+}
+
+type ControlSpi interface {
+	OnCreated()
+}
+
+type ControlObject struct {
+	name string
+}
+
+type ContainerControl interface {
+	Control
+}
+
+type ContainerControlObject struct {
+	ControlObject
+	super *ControlObject
+}
@@ -0,0 +1,7 @@
+package forms
+
+//goforms:virtual builder
+type PanelObject struct {
+	ContainerControlObject
+	super *ContainerControlObject
+}
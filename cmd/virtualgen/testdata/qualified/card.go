@@ -0,0 +1,18 @@
+package widgets
+
+import "github.com/zzl/goforms/forms"
+
+// CardObject is a panel-like container with a caption.
+//
+//goforms:virtual builder=Caption
+type CardObject struct {
+	forms.ContainerControlObject
+	super *forms.ContainerControlObject
+
+	Caption string
+}
+
+//goforms:interface
+func (this *CardObject) GetCaption() string {
+	return this.Caption
+}
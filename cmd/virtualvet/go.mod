@@ -0,0 +1,15 @@
+// The analyzer is a module of its own, so that the library, on go 1.21, does not
+// depend on x/tools. It requires a recent Go instead: x/tools loads packages from
+// the export data written by the go command running it, a format that changes with
+// Go releases. Older x/tools such as v0.24.0, the last supporting go 1.21, fail on
+// current toolchains with "package without types" errors.
+module github.com/zzl/goforms/cmd/virtualvet
+
+go 1.25.0
+
+require golang.org/x/tools v0.45.0
+
+require (
+	golang.org/x/mod v0.36.0 // indirect
+	golang.org/x/sync v0.20.0 // indirect
+)
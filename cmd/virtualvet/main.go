@@ -0,0 +1,18 @@
+// Command virtualvet runs the virtualcheck analyzer.
+//
+// It can be run directly, or as a vet tool:
+//
+//	go install github.com/zzl/goforms/cmd/virtualvet
+//	go vet -vettool=$(which virtualvet) ./...
+//
+// Use -fix to apply the suggested fixes.
+package main
+
+import (
+	"github.com/zzl/goforms/cmd/virtualvet/virtualcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(virtualcheck.Analyzer)
+}
@@ -0,0 +1,108 @@
+package a
+
+import "github.com/zzl/goforms/framework/virtual"
+
+type Window interface {
+	virtual.Virtual
+	Create() error
+	Init()
+	Dispose()
+}
+
+type WindowSpi interface {
+	OnCreate()
+	OnSize(width, height int)
+}
+
+type WindowInterface interface {
+	Window
+	WindowSpi
+}
+
+type WindowObject struct {
+	virtual.VirtualObject[WindowInterface]
+}
+
+func (this *WindowObject) Init()                    {}
+func (this *WindowObject) Dispose()                 {}
+func (this *WindowObject) OnCreate()                {}
+func (this *WindowObject) OnSize(width, height int) {}
+
+func (this *WindowObject) Create() error {
+	this.RealObject.OnCreate()
+	this.OnCreate() // want `OnCreate is overridable \(declared in WindowSpi\); call this.RealObject.OnCreate so that overrides of derived objects take effect`
+	return nil
+}
+
+func (this *WindowObject) CreateIn(parent *WindowObject) {
+	register(this) // want `CreateIn passes this instead of this.RealObject, bypassing the overrides of derived objects`
+}
+
+func register(window any) {}
+
+type ButtonObject struct {
+	WindowObject
+	super *WindowObject
+}
+
+// Init calls super, Dispose and Create don't.
+func (this *ButtonObject) Init() {
+	this.super.Init()
+}
+
+func (this *ButtonObject) Dispose() { // want `Dispose does not call this.super.Dispose\(\)`
+}
+
+func (this *ButtonObject) Create() error { // want `ButtonObject.Create does not call this.super.Create`
+	return nil
+}
+
+type LabelObject struct { // want `LabelObject has no super field; add super \*WindowObject`
+	WindowObject
+}
+
+type EditObject struct {
+	WindowObject
+	super *ButtonObject // want `super field of EditObject should be of type \*WindowObject`
+}
+
+func (this *EditObject) Init() { // want `Init does not call this.super.Init\(\)`
+}
+
+type PanelObject struct {
+	Name         string
+	WindowObject // want `base object WindowObject of PanelObject should be the first field`
+	super        *WindowObject
+}
+
+type GroupObject struct {
+	WindowObject
+	super        *WindowObject
+	ButtonObject // want `GroupObject embeds ButtonObject besides its base WindowObject; only the first embedded Virtual field is set up as base`
+}
+
+type Slider interface {
+	Window
+}
+
+type SliderSpi interface {
+	WindowSpi
+	OnScroll()
+}
+
+type SliderObject struct { // want `SliderObject does not implement SliderSpi \(missing method OnScroll\)`
+	WindowObject
+	super *WindowObject
+}
+
+func NewSliderObject() *SliderObject {
+	return virtual.New[SliderObject]()
+}
+
+func NewButtons() []*ButtonObject {
+	return []*ButtonObject{
+		&ButtonObject{}, // want `virtual object ButtonObject constructed without virtual.New; its super fields and RealObject are not set up`
+		virtual.New(&ButtonObject{}),
+		virtual.New[ButtonObject](),
+	}
+}
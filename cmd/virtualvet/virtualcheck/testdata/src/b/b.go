@@ -0,0 +1,42 @@
+package main
+
+import "github.com/zzl/goforms/framework/virtual"
+
+type Animal interface {
+	virtual.Virtual
+	Greet()
+}
+
+type AnimalSpi interface {
+	Say(something string)
+}
+
+type AnimalInterface interface {
+	Animal
+	AnimalSpi
+}
+
+type AnimalObject struct {
+	virtual.VirtualObject[AnimalInterface]
+}
+
+func (this *AnimalObject) Greet() {
+	this.Say("Hello!") // want `Say is overridable`
+}
+
+func (this *AnimalObject) Say(something string) {}
+
+type CatObject struct {
+	AnimalObject
+	super *AnimalObject
+}
+
+// Greet calls Say directly, as no object can derive from CatObject.
+func (this *CatObject) Greet() {
+	this.super.Greet()
+	this.Say("Meow!")
+}
+
+func main() {
+	virtual.New[CatObject]().Greet()
+}
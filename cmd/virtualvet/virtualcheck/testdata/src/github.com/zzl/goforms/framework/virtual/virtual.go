@@ -0,0 +1,27 @@
+// Package virtual is a stub of the virtual package for the analyzer tests.
+package virtual
+
+type Virtual interface {
+	SetRealObject(object any)
+	GetRealObject() any
+}
+
+type VirtualObject[T any] struct {
+	RealObject T
+}
+
+func (this *VirtualObject[T]) SetRealObject(realObject any) {
+	this.RealObject = realObject.(T)
+}
+
+func (this *VirtualObject[T]) GetRealObject() any {
+	return this.RealObject
+}
+
+func New[T any](preConstructed ...*T) *T {
+	var obj T
+	return &obj
+}
+
+func Realize(virtualObject any) {
+}
@@ -0,0 +1,609 @@
+// Package virtualcheck defines an Analyzer that checks the use of the virtual object pattern.
+//
+// The mistakes it reports otherwise only show up at runtime:
+//
+//   - a derived virtual object without the super field,
+//     or with a super field not pointing to the base object;
+//   - a base object that is not the first embedded Virtual field,
+//     or another Virtual type embedded besides the base;
+//   - an Init or Dispose override not calling this.super.Init or this.super.Dispose,
+//     and a Create override not calling this.super.Create;
+//   - a CreateIn method passing the receiver itself instead of this.RealObject;
+//   - a call of an Spi method through the receiver instead of this.RealObject,
+//     which bypasses the overrides of derived objects, unless the receiver type
+//     can not be derived as an unexported or main package type not embedded;
+//   - an XxxObject constructed with virtual.New not implementing the XxxSpi interface;
+//   - a virtual object constructed with &XxxObject{} instead of virtual.New.
+//
+// Suggested fixes are provided where the fix is unambiguous.
+package virtualcheck
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const virtualPath = "github.com/zzl/goforms/framework/virtual"
+
+const doc = `check the use of the virtual object pattern
+
+Reports derived virtual objects missing the super field, base objects that are not
+the first Virtual field, Init/Dispose/Create overrides not calling super,
+Spi methods called without going through RealObject, XxxObject types
+not implementing XxxSpi, and virtual objects constructed without virtual.New.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "virtualcheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+type checker struct {
+	pass *analysis.Pass
+
+	virtualPkg    *types.Package
+	virtualIface  *types.Interface
+	virtualObject types.Object //the VirtualObject generic type
+
+	spiMethods map[*types.Func]string //methods of Spi interfaces to the interface names
+	structs    map[*types.TypeName]*virtualStruct
+}
+
+type virtualStruct struct {
+	name  *types.TypeName
+	spec  *ast.TypeSpec
+	st    *ast.StructType
+	base  *ast.Field //the embedded base object, nil if none
+	root  bool       //whether the base is a VirtualObject
+	super *ast.Field
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	virtualPkg := findPackage(pass.Pkg, virtualPath, map[*types.Package]bool{})
+	if virtualPkg == nil {
+		return nil, nil
+	}
+	virtualIface, ok := virtualPkg.Scope().Lookup("Virtual").Type().Underlying().(*types.Interface)
+	if !ok {
+		return nil, nil
+	}
+	c := &checker{
+		pass:          pass,
+		virtualPkg:    virtualPkg,
+		virtualIface:  virtualIface,
+		virtualObject: virtualPkg.Scope().Lookup("VirtualObject"),
+		spiMethods:    make(map[*types.Func]string),
+		structs:       make(map[*types.TypeName]*virtualStruct),
+	}
+	c.collectSpiMethods(pass.Pkg, map[*types.Package]bool{})
+
+	insp := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	insp.Preorder([]ast.Node{(*ast.TypeSpec)(nil)}, func(node ast.Node) {
+		c.checkStruct(node.(*ast.TypeSpec))
+	})
+	insp.Preorder([]ast.Node{(*ast.FuncDecl)(nil)}, func(node ast.Node) {
+		c.checkMethod(node.(*ast.FuncDecl))
+	})
+	if pass.Pkg.Path() != virtualPath {
+		insp.WithStack([]ast.Node{(*ast.CompositeLit)(nil)}, func(node ast.Node, push bool, stack []ast.Node) bool {
+			if push {
+				c.checkLiteral(node.(*ast.CompositeLit), stack)
+			}
+			return true
+		})
+	}
+	c.checkSpis()
+	return nil, nil
+}
+
+func findPackage(pkg *types.Package, path string, seen map[*types.Package]bool) *types.Package {
+	if pkg.Path() == path {
+		return pkg
+	}
+	if seen[pkg] {
+		return nil
+	}
+	seen[pkg] = true
+	for _, imported := range pkg.Imports() {
+		if found := findPackage(imported, path, seen); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// collectSpiMethods records the explicit methods of the interfaces named XxxSpi
+// in the package and its dependencies.
+func (this *checker) collectSpiMethods(pkg *types.Package, seen map[*types.Package]bool) {
+	if seen[pkg] {
+		return
+	}
+	seen[pkg] = true
+	scope := pkg.Scope()
+	for _, name := range scope.Names() {
+		if !strings.HasSuffix(name, "Spi") {
+			continue
+		}
+		typeName, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		iface, ok := typeName.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		for n := 0; n < iface.NumExplicitMethods(); n++ {
+			this.spiMethods[iface.ExplicitMethod(n)] = name
+		}
+	}
+	for _, imported := range pkg.Imports() {
+		this.collectSpiMethods(imported, seen)
+	}
+}
+
+// isVirtual tells whether t is a struct implementing Virtual through a pointer,
+// or embedding such a struct; the latter may fail to implement Virtual
+// because of an ambiguous selector when several Virtual types are embedded.
+func (this *checker) isVirtual(t types.Type) bool {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok {
+		return false
+	}
+	if types.Implements(types.NewPointer(t), this.virtualIface) {
+		return true
+	}
+	for n := 0; n < st.NumFields(); n++ {
+		f := st.Field(n)
+		if f.Embedded() && types.Implements(types.NewPointer(f.Type()), this.virtualIface) {
+			return true
+		}
+	}
+	return false
+}
+
+func (this *checker) isVirtualObject(t types.Type) bool {
+	named, ok := t.(*types.Named)
+	return ok && named.Origin().Obj() == this.virtualObject
+}
+
+func (this *checker) checkStruct(spec *ast.TypeSpec) {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok || spec.TypeParams != nil {
+		return
+	}
+	typeName, ok := this.pass.TypesInfo.Defs[spec.Name].(*types.TypeName)
+	if !ok || !this.isVirtual(typeName.Type()) || this.isVirtualObject(typeName.Type()) {
+		return
+	}
+	vs := &virtualStruct{name: typeName, spec: spec, st: st}
+	this.structs[typeName] = vs
+
+	for n, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			t := this.pass.TypesInfo.TypeOf(f.Type)
+			if t == nil || !this.isVirtual(t) {
+				continue
+			}
+			if vs.base == nil {
+				vs.base = f
+				vs.root = this.isVirtualObject(t)
+				if n != 0 {
+					this.pass.Reportf(f.Pos(), "base object %s of %s should be the first field",
+						this.exprString(f.Type), spec.Name.Name)
+				}
+			} else {
+				this.pass.Reportf(f.Pos(), "%s embeds %s besides its base %s; "+
+					"only the first embedded Virtual field is set up as base",
+					spec.Name.Name, this.exprString(f.Type), this.exprString(vs.base.Type))
+			}
+		} else {
+			for _, name := range f.Names {
+				if name.Name == "super" {
+					vs.super = f
+				}
+			}
+		}
+	}
+	if vs.base == nil || vs.root {
+		return
+	}
+	if vs.super == nil {
+		this.reportMissingSuper(vs)
+		return
+	}
+	baseText := this.exprString(vs.base.Type)
+	baseType := this.pass.TypesInfo.TypeOf(vs.base.Type)
+	superType := this.pass.TypesInfo.TypeOf(vs.super.Type)
+	if superType == nil || !types.Identical(superType, types.NewPointer(baseType)) {
+		this.pass.Report(analysis.Diagnostic{
+			Pos: vs.super.Pos(),
+			Message: fmt.Sprintf("super field of %s should be of type *%s",
+				spec.Name.Name, baseText),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Change the type of the super field",
+				TextEdits: []analysis.TextEdit{{
+					Pos:     vs.super.Type.Pos(),
+					End:     vs.super.Type.End(),
+					NewText: []byte("*" + baseText),
+				}},
+			}},
+		})
+	}
+}
+
+// receiverStruct returns the virtual struct and the receiver name of a method.
+func (this *checker) receiverStruct(fn *ast.FuncDecl) (*types.TypeName, string) {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Body == nil {
+		return nil, ""
+	}
+	recv := fn.Recv.List[0]
+	if len(recv.Names) != 1 || recv.Names[0].Name == "_" {
+		return nil, ""
+	}
+	ptr, ok := this.pass.TypesInfo.TypeOf(recv.Type).(*types.Pointer)
+	if !ok {
+		return nil, ""
+	}
+	named, ok := ptr.Elem().(*types.Named)
+	if !ok || named.TypeArgs() != nil || !this.isVirtual(named) || this.isVirtualObject(named) {
+		return nil, ""
+	}
+	return named.Obj(), recv.Names[0].Name
+}
+
+func (this *checker) checkMethod(fn *ast.FuncDecl) {
+	typeName, recvName := this.receiverStruct(fn)
+	if typeName == nil {
+		return
+	}
+	vs := this.structs[typeName]
+	name := fn.Name.Name
+	switch name {
+	case "Init", "Dispose", "Create":
+		if vs == nil || vs.base == nil || vs.root || !this.baseHasMethod(typeName, name) {
+			break
+		}
+		if vs.super == nil {
+			//reported with the struct
+		} else if callsSuper(fn.Body, recvName, name) {
+			//ok
+		} else if name == "Create" {
+			this.pass.Reportf(fn.Name.Pos(), "%s.Create does not call %s.super.Create",
+				typeName.Name(), recvName)
+		} else if fn.Type.Params.NumFields() == 0 {
+			this.reportMissingSuperCall(fn, recvName)
+		}
+	case "CreateIn":
+		this.checkCreateIn(fn, recvName)
+	}
+	this.checkSpiCalls(fn, typeName, recvName)
+}
+
+// reportMissingSuper reports a derived virtual struct without a super field
+// to reach the base implementations.
+// Note that this.super would otherwise resolve to the super field of the base.
+func (this *checker) reportMissingSuper(vs *virtualStruct) {
+	baseText := this.exprString(vs.base.Type)
+	this.pass.Report(analysis.Diagnostic{
+		Pos: vs.spec.Name.Pos(),
+		Message: fmt.Sprintf("%s has no super field; add super *%s",
+			vs.spec.Name.Name, baseText),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message: "Add the super field",
+			TextEdits: []analysis.TextEdit{{
+				Pos:     vs.base.End(),
+				End:     vs.base.End(),
+				NewText: []byte("\n\tsuper *" + baseText),
+			}},
+		}},
+	})
+}
+
+// baseHasMethod tells whether the base object of the type has the method.
+func (this *checker) baseHasMethod(typeName *types.TypeName, method string) bool {
+	st := typeName.Type().Underlying().(*types.Struct)
+	for n := 0; n < st.NumFields(); n++ {
+		f := st.Field(n)
+		if !f.Embedded() || !this.isVirtual(f.Type()) {
+			continue
+		}
+		obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(f.Type()), false, typeName.Pkg(), method)
+		_, ok := obj.(*types.Func)
+		return ok
+	}
+	return false
+}
+
+// callsSuper tells whether the body contains a call of recv.super.method.
+func callsSuper(body *ast.BlockStmt, recvName string, method string) bool {
+	found := false
+	ast.Inspect(body, func(node ast.Node) bool {
+		if found {
+			return false
+		}
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || sel.Sel.Name != method {
+			return true
+		}
+		superSel, ok := sel.X.(*ast.SelectorExpr)
+		if !ok || superSel.Sel.Name != "super" {
+			return true
+		}
+		if ident, ok := superSel.X.(*ast.Ident); ok && ident.Name == recvName {
+			found = true
+		}
+		return true
+	})
+	return found
+}
+
+func (this *checker) reportMissingSuperCall(fn *ast.FuncDecl, recvName string) {
+	name := fn.Name.Name
+	call := recvName + ".super." + name + "()"
+	var edit analysis.TextEdit
+	if name == "Init" {
+		edit = analysis.TextEdit{Pos: fn.Body.Lbrace + 1, End: fn.Body.Lbrace + 1,
+			NewText: []byte("\n\t" + call)}
+	} else {
+		pos := fn.Body.Rbrace
+		if n := len(fn.Body.List); n != 0 {
+			if ret, ok := fn.Body.List[n-1].(*ast.ReturnStmt); ok {
+				pos = ret.Pos()
+				edit = analysis.TextEdit{Pos: pos, End: pos, NewText: []byte(call + "\n\t")}
+			}
+		}
+		if edit.NewText == nil {
+			edit = analysis.TextEdit{Pos: pos, End: pos, NewText: []byte("\t" + call + "\n")}
+		}
+	}
+	this.pass.Report(analysis.Diagnostic{
+		Pos:     fn.Name.Pos(),
+		Message: fmt.Sprintf("%s does not call %s", name, call),
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "Call " + call,
+			TextEdits: []analysis.TextEdit{edit},
+		}},
+	})
+}
+
+func (this *checker) checkCreateIn(fn *ast.FuncDecl, recvName string) {
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		for _, arg := range call.Args {
+			if ident, ok := arg.(*ast.Ident); ok && ident.Name == recvName {
+				this.pass.Report(analysis.Diagnostic{
+					Pos: arg.Pos(),
+					Message: fmt.Sprintf("CreateIn passes %s instead of %s.RealObject, "+
+						"bypassing the overrides of derived objects", recvName, recvName),
+					SuggestedFixes: []analysis.SuggestedFix{{
+						Message: "Pass " + recvName + ".RealObject",
+						TextEdits: []analysis.TextEdit{{
+							Pos: arg.End(), End: arg.End(), NewText: []byte(".RealObject"),
+						}},
+					}},
+				})
+			}
+		}
+		return true
+	})
+}
+
+// mayBeDerived tells whether other virtual objects may embed the type as their base:
+// an exported type outside a main package, or a type embedded in the package.
+func (this *checker) mayBeDerived(typeName *types.TypeName) bool {
+	if typeName.Exported() && this.pass.Pkg.Name() != "main" {
+		return true
+	}
+	for _, vs := range this.structs {
+		if vs.base != nil && types.Identical(this.pass.TypesInfo.TypeOf(vs.base.Type), typeName.Type()) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSpiCalls reports recv.M() calls where M is an Spi method of the real object,
+// unless the receiver type can not be derived, so that its real object is itself.
+func (this *checker) checkSpiCalls(fn *ast.FuncDecl, typeName *types.TypeName, recvName string) {
+	if !this.mayBeDerived(typeName) {
+		return
+	}
+	realObj, _, _ := types.LookupFieldOrMethod(typeName.Type(), false, typeName.Pkg(), "RealObject")
+	realVar, ok := realObj.(*types.Var)
+	if !ok {
+		return
+	}
+	ast.Inspect(fn.Body, func(node ast.Node) bool {
+		call, ok := node.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		sel, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		ident, ok := sel.X.(*ast.Ident)
+		if !ok || ident.Name != recvName || sel.Sel.Name == fn.Name.Name {
+			return true
+		}
+		method, _, _ := types.LookupFieldOrMethod(realVar.Type(), false, typeName.Pkg(), sel.Sel.Name)
+		fun, ok := method.(*types.Func)
+		if !ok {
+			return true
+		}
+		spiName, ok := this.spiMethods[fun]
+		if !ok {
+			return true
+		}
+		this.pass.Report(analysis.Diagnostic{
+			Pos: sel.Pos(),
+			Message: fmt.Sprintf("%s is overridable (declared in %s); call %s.RealObject.%s "+
+				"so that overrides of derived objects take effect",
+				sel.Sel.Name, spiName, recvName, sel.Sel.Name),
+			SuggestedFixes: []analysis.SuggestedFix{{
+				Message: "Call through RealObject",
+				TextEdits: []analysis.TextEdit{{
+					Pos: ident.End(), End: ident.End(), NewText: []byte(".RealObject"),
+				}},
+			}},
+		})
+		return true
+	})
+}
+
+// constructedTypes returns the types instantiated with virtual.New in the package.
+func (this *checker) constructedTypes() map[*types.TypeName]bool {
+	constructed := make(map[*types.TypeName]bool)
+	for ident, instance := range this.pass.TypesInfo.Instances {
+		fun, ok := this.pass.TypesInfo.Uses[ident].(*types.Func)
+		if !ok || fun.Pkg() != this.virtualPkg || fun.Name() != "New" || instance.TypeArgs.Len() != 1 {
+			continue
+		}
+		if named, ok := instance.TypeArgs.At(0).(*types.Named); ok {
+			constructed[named.Obj()] = true
+		}
+	}
+	return constructed
+}
+
+// checkSpis reports XxxObject types constructed in their package
+// but not implementing its XxxSpi interface.
+// Abstract objects, never constructed themselves, are not checked.
+func (this *checker) checkSpis() {
+	scope := this.pass.Pkg.Scope()
+	constructed := this.constructedTypes()
+	for typeName, vs := range this.structs {
+		if !constructed[typeName] {
+			continue
+		}
+		name := strings.TrimSuffix(typeName.Name(), "Object")
+		if name == typeName.Name() {
+			continue
+		}
+		spi, ok := scope.Lookup(name + "Spi").(*types.TypeName)
+		if !ok {
+			continue
+		}
+		iface, ok := spi.Type().Underlying().(*types.Interface)
+		if !ok {
+			continue
+		}
+		ptr := types.NewPointer(typeName.Type())
+		if missing, _ := types.MissingMethod(ptr, iface, true); missing != nil {
+			this.pass.Reportf(vs.spec.Name.Pos(), "%s does not implement %s (missing method %s)",
+				typeName.Name(), spi.Name(), missing.Name())
+		}
+	}
+}
+
+func (this *checker) checkLiteral(lit *ast.CompositeLit, stack []ast.Node) {
+	t := this.pass.TypesInfo.TypeOf(lit)
+	if t == nil || !this.isVirtual(t) || this.isVirtualObject(t) {
+		return
+	}
+	typeText := this.exprString(lit.Type)
+	var addr *ast.UnaryExpr
+	if len(stack) >= 2 {
+		if unary, ok := stack[len(stack)-2].(*ast.UnaryExpr); ok && unary.Op == token.AND {
+			addr = unary
+			if len(stack) >= 3 {
+				if call, ok := stack[len(stack)-3].(*ast.CallExpr); ok && this.isVirtualConstructor(call) {
+					return //virtual.New(&XxxObject{...}) is fine
+				}
+			}
+		}
+	}
+	diag := analysis.Diagnostic{
+		Pos: lit.Pos(),
+		Message: fmt.Sprintf("virtual object %s constructed without virtual.New; "+
+			"its super fields and RealObject are not set up", typeText),
+	}
+	if addr != nil && lit.Type != nil {
+		if virtualName, ok := this.virtualImportName(lit.Pos()); ok {
+			var newText string
+			if len(lit.Elts) == 0 {
+				newText = virtualName + "New[" + typeText + "]()"
+			} else {
+				newText = virtualName + "New(" + this.exprString(addr) + ")"
+			}
+			diag.SuggestedFixes = []analysis.SuggestedFix{{
+				Message: "Use virtual.New",
+				TextEdits: []analysis.TextEdit{{
+					Pos: addr.Pos(), End: addr.End(), NewText: []byte(newText),
+				}},
+			}}
+		}
+	}
+	this.pass.Report(diag)
+}
+
+func (this *checker) isVirtualConstructor(call *ast.CallExpr) bool {
+	fun := call.Fun
+	if index, ok := fun.(*ast.IndexExpr); ok {
+		fun = index.X
+	}
+	var ident *ast.Ident
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return false
+	}
+	obj, ok := this.pass.TypesInfo.Uses[ident].(*types.Func)
+	if !ok || obj.Pkg() != this.virtualPkg {
+		return false
+	}
+	return obj.Name() == "New" || obj.Name() == "Realize"
+}
+
+// virtualImportName returns the qualifier of the virtual package in the file
+// containing pos, such as "virtual.", and whether the package is imported.
+func (this *checker) virtualImportName(pos token.Pos) (string, bool) {
+	for _, file := range this.pass.Files {
+		if file.Pos() > pos || pos > file.End() {
+			continue
+		}
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			if path != virtualPath {
+				continue
+			}
+			if spec.Name == nil {
+				return "virtual.", true
+			}
+			if spec.Name.Name == "." {
+				return "", true
+			}
+			if spec.Name.Name != "_" {
+				return spec.Name.Name + ".", true
+			}
+		}
+	}
+	return "", false
+}
+
+func (this *checker) exprString(node ast.Node) string {
+	var buf bytes.Buffer
+	_ = printer.Fprint(&buf, this.pass.Fset, node)
+	return buf.String()
+}
@@ -0,0 +1,13 @@
+package virtualcheck_test
+
+import (
+	"testing"
+
+	"github.com/zzl/goforms/cmd/virtualvet/virtualcheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.Run(t, analysistest.TestData(), virtualcheck.Analyzer, "b")
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), virtualcheck.Analyzer, "a")
+}
@@ -1,14 +1,8 @@
 package drawing
 
-import (
-	"github.com/zzl/go-gdiplus/gdip"
-	"log"
-)
+import "github.com/zzl/go-gdiplus/gdip"
 
 func init() {
-	status := gdip.GdiplusStartup()
-	if status != gdip.Ok {
-		log.Fatalln("gdip startup failed")
-	}
-
+	//reported rather than fatal, the drawing calls then fail with their own errors
+	checkStatus(gdip.GdiplusStartup())
 }
@@ -4,7 +4,10 @@ import (
 	"errors"
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing/svg"
 	"github.com/zzl/goforms/framework/scope"
+	"image"
+	"image/color"
 	"log"
 	"runtime"
 	"syscall"
@@ -71,21 +74,49 @@ func NewBitmapFromHIcon(s *Scope, hIcon win32.HICON) *Bitmap {
 func NewBitmapFromHBitmap(s *Scope, hBitmap win32.HBITMAP) *Bitmap {
 	var pBitmap *gdip.Bitmap
 	status := gdip.CreateBitmapFromHBITMAP(hBitmap, 0, &pBitmap)
-	ensureOk(status)
+	checkStatus(status)
 	return newBitmap(s, pBitmap)
 }
 
 func NewBitmap(s *Scope, width int32, height int32, format gdip.PixelFormat, scan0 *byte) *Bitmap {
 	var pBitmap *gdip.Bitmap
 	status := gdip.CreateBitmapFromScan0(width, height, 0, format, nil, &pBitmap)
-	ensureOk(status)
+	checkStatus(status)
 	return newBitmap(s, pBitmap)
 }
 
+// NewBitmapFromGoImage creates a 32bpp premultiplied ARGB bitmap with the pixels of a Go image.
+func NewBitmapFromGoImage(s *Scope, img image.Image) *Bitmap {
+	bounds := img.Bounds()
+	width, height := int32(bounds.Dx()), int32(bounds.Dy())
+	bitmap := NewBitmap(s, width, height, gdip.PixelFormat32bppPARGB, nil)
+	if width == 0 || height == 0 {
+		return bitmap
+	}
+	data := bitmap.LockBits(Rect{0, 0, width, height},
+		gdip.ImageLockModeWrite, gdip.PixelFormat32bppPARGB)
+	scan0 := *(*unsafe.Pointer)(unsafe.Pointer(&data.Scan0))
+	for y := 0; y < int(height); y++ {
+		row := unsafe.Slice((*byte)(unsafe.Add(scan0, y*int(data.Stride))), width*4)
+		for x := 0; x < int(width); x++ {
+			c := color.RGBAModel.Convert(img.At(bounds.Min.X+x, bounds.Min.Y+y)).(color.RGBA)
+			row[x*4], row[x*4+1], row[x*4+2], row[x*4+3] = c.B, c.G, c.R, c.A
+		}
+	}
+	bitmap.UnlockBits(data)
+	return bitmap
+}
+
+// NewBitmapFromSvg creates a bitmap of an SVG document rendered at a size,
+// e.g. that of icons at the current DPI.
+func NewBitmapFromSvg(s *Scope, doc *svg.Document, width, height int32) *Bitmap {
+	return NewBitmapFromGoImage(s, doc.Render(int(width), int(height)))
+}
+
 func NewBitmapFromGraphics(s *Scope, width, height int32, g *Graphics) *Bitmap {
 	var pBitmap *gdip.Bitmap
 	status := gdip.CreateBitmapFromGraphics(width, height, g.p, &pBitmap)
-	ensureOk(status)
+	checkStatus(status)
 	return newBitmap(s, pBitmap)
 }
 
@@ -98,7 +129,8 @@ func NewBitmapFromImageWithSize(s *Scope, image *Image, width, height int32) *Bi
 	bitmap := NewBitmap(s, width, height, gdip.PixelFormat32bppARGB, nil)
 	g, err := NewGraphicsFromImage(nil, bitmap.AsImage())
 	if err != nil {
-		log.Fatalln(err)
+		ReportError(err)
+		return bitmap
 	}
 	defer g.Dispose()
 	g.Clear(Color{})
@@ -247,14 +279,18 @@ func hiconToBitmap(s *Scope, hIcon win32.HICON) *Bitmap {
 	var pBitmap *gdip.Bitmap
 	status := gdip.CreateBitmapFromScan0(width, height, 0, format, nil, &pBitmap)
 	if status != gdip.Ok {
-		log.Fatal(status)
+		ReportError(GdipError(status))
+		return nil
 	}
+	bitmap := newBitmap(s, pBitmap)
 
 	var pGraphics *gdip.Graphics
 	status = gdip.GetImageGraphicsContext(&pBitmap.Image, &pGraphics)
 	if status != gdip.Ok {
-		log.Fatal(status)
+		ReportError(GdipError(status))
+		return bitmap
 	}
+	defer gdip.DeleteGraphics(pGraphics)
 
 	gdip.GraphicsClear(pGraphics, 0x00FFFFFF)
 	var hdc win32.HDC
@@ -268,7 +304,7 @@ func hiconToBitmap(s *Scope, hIcon win32.HICON) *Bitmap {
 	}
 	gdip.ReleaseDC(pGraphics, hdc)
 
-	return newBitmap(s, pBitmap)
+	return bitmap
 }
 
 func HIconToHBitmap(hIcon win32.HICON) win32.HBITMAP {
@@ -367,7 +403,7 @@ func HBitmapToBitmap(hBitmap win32.HBITMAP) *Bitmap {
 		log.Println("wrong pixel format..")
 		return nil
 	}
-	pScan0 := unsafe.Pointer(bitmapData.Scan0)
+	pScan0 := *(*unsafe.Pointer)(unsafe.Pointer(&bitmapData.Scan0))
 	win32.CopyMemory(pScan0, bm.BmBits,
 		uint32(bm.BmWidthBytes*bm.BmHeight))
 
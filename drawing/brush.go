@@ -3,6 +3,7 @@ package drawing
 import (
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/leaks"
 	"runtime"
 	"unsafe"
 )
@@ -13,14 +14,16 @@ type Brush struct {
 
 func newBrush(s *Scope, p *gdip.Brush) *Brush {
 	brush := &Brush{p}
+	leaks.Track(brush)
 	if s != nil {
 		s.Add(brush)
 	}
-	runtime.SetFinalizer(brush, (*Brush).Dispose)
+	setFinalizer(brush)
 	return brush
 }
 
 func (this *Brush) Dispose() {
+	leaks.Disposed(this)
 	if this.p == nil {
 		return
 	}
@@ -29,9 +32,17 @@ func (this *Brush) Dispose() {
 	runtime.SetFinalizer(this, nil)
 }
 
+// handle returns the native object, reporting use after dispose.
+func (this *Brush) handle() *gdip.Brush {
+	if this.p == nil {
+		leaks.Used(this)
+	}
+	return this.p
+}
+
 func (this *Brush) Clone(s *Scope) *Brush {
 	var p2 *gdip.Brush
-	gdip.CloneBrush(this.p, &p2)
+	gdip.CloneBrush(this.handle(), &p2)
 	return newBrush(s, p2)
 }
 
@@ -42,6 +53,7 @@ type SolidBrush struct {
 
 func newSolidBrush(s *Scope, p *gdip.SolidFill) *SolidBrush {
 	brush := &SolidBrush{Brush: Brush{&p.Brush}}
+	leaks.Track(brush)
 	if s != nil {
 		s.Add(brush)
 	}
@@ -57,12 +69,12 @@ func NewSolidBrush(s *Scope, color Color) *SolidBrush {
 
 func (this *SolidBrush) Clone(s *Scope) *SolidBrush {
 	var p2 *gdip.Brush
-	gdip.CloneBrush(this.p, &p2)
+	gdip.CloneBrush(this.handle(), &p2)
 	return newSolidBrush(s, (*gdip.SolidFill)(unsafe.Pointer(p2)))
 }
 
 func (this *SolidBrush) P() *gdip.SolidFill {
-	return (*gdip.SolidFill)(unsafe.Pointer(this.p))
+	return (*gdip.SolidFill)(unsafe.Pointer(this.handle()))
 }
 
 func (this *SolidBrush) SetColor(color Color) {
@@ -94,6 +106,7 @@ type HatchBrush struct {
 
 func newHatchBrush(s *Scope, p *gdip.Hatch) *HatchBrush {
 	brush := &HatchBrush{Brush: Brush{&p.Brush}}
+	leaks.Track(brush)
 	if s != nil {
 		s.Add(brush)
 	}
@@ -109,12 +122,12 @@ func NewHatchBrush(s *Scope, style gdip.HatchStyle, foreColor, backColor Color)
 
 func (this *HatchBrush) Clone(s *Scope) *HatchBrush {
 	var p2 *gdip.Brush
-	gdip.CloneBrush(this.p, &p2)
+	gdip.CloneBrush(this.handle(), &p2)
 	return newHatchBrush(s, (*gdip.Hatch)(unsafe.Pointer(p2)))
 }
 
 func (this *HatchBrush) P() *gdip.Hatch {
-	return (*gdip.Hatch)(unsafe.Pointer(this.p))
+	return (*gdip.Hatch)(unsafe.Pointer(this.handle()))
 }
 
 func (this *HatchBrush) GetHatchStyle() gdip.HatchStyle {
@@ -141,6 +154,7 @@ type LinearGradientBrush struct {
 
 func newLinearGradientBrush(s *Scope, p *gdip.LineGradient) *LinearGradientBrush {
 	brush := &LinearGradientBrush{Brush: Brush{&p.Brush}}
+	leaks.Track(brush)
 	if s != nil {
 		s.Add(brush)
 	}
@@ -168,7 +182,7 @@ func NewLinearGradientBrushF(s *Scope, rect RectF, color1, color2 Color, angle f
 }
 
 func (this *LinearGradientBrush) P() *gdip.LineGradient {
-	return (*gdip.LineGradient)(unsafe.Pointer(this.p))
+	return (*gdip.LineGradient)(unsafe.Pointer(this.handle()))
 }
 
 func (this *LinearGradientBrush) GetColors() (color1, color2 Color) {
@@ -292,7 +306,7 @@ func (this *LinearGradientBrush) GetTransform(s *Scope) *Matrix {
 }
 
 func (this *LinearGradientBrush) SetTransform(matrix *Matrix) {
-	status := gdip.SetLineTransform(this.P(), matrix.p)
+	status := gdip.SetLineTransform(this.P(), matrix.handle())
 	checkStatus(status)
 }
 
@@ -306,7 +320,7 @@ func (this *LinearGradientBrush) MultiplyTransform(matrix *Matrix, append bool)
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.MultiplyLineTransform(this.P(), matrix.p, order)
+	status := gdip.MultiplyLineTransform(this.P(), matrix.handle(), order)
 	checkStatus(status)
 }
 
@@ -343,6 +357,7 @@ type PathGradientBrush struct {
 
 func newPathGradientBrush(s *Scope, p *gdip.PathGradient) *PathGradientBrush {
 	brush := &PathGradientBrush{Brush: Brush{&p.Brush}}
+	leaks.Track(brush)
 	if s != nil {
 		s.Add(brush)
 	}
@@ -351,7 +366,7 @@ func newPathGradientBrush(s *Scope, p *gdip.PathGradient) *PathGradientBrush {
 
 func NewPathGradientBrush(s *Scope, path *Path) *PathGradientBrush {
 	var p *gdip.PathGradient
-	status := gdip.CreatePathGradientFromPath(path.p, &p)
+	status := gdip.CreatePathGradientFromPath(path.handle(), &p)
 	checkStatus(status)
 	return newPathGradientBrush(s, p)
 }
@@ -373,7 +388,7 @@ func NewPathGradientBrushFromPointsF(s *Scope, points []PointF,
 }
 
 func (this *PathGradientBrush) P() *gdip.PathGradient {
-	return (*gdip.PathGradient)(unsafe.Pointer(this.p))
+	return (*gdip.PathGradient)(unsafe.Pointer(this.handle()))
 }
 
 func (this *PathGradientBrush) GetCenterColor() Color {
@@ -420,7 +435,7 @@ func (this *PathGradientBrush) GetPath(s *Scope) *Path {
 }
 
 func (this *PathGradientBrush) SetPath(path *Path) {
-	status := gdip.SetPathGradientPath(this.P(), path.p)
+	status := gdip.SetPathGradientPath(this.P(), path.handle())
 	checkStatus(status)
 }
 
@@ -557,7 +572,7 @@ func (this *PathGradientBrush) GetTransform(s *Scope) *Matrix {
 }
 
 func (this *PathGradientBrush) SetTransform(matrix *Matrix) {
-	status := gdip.SetPathGradientTransform(this.P(), matrix.p)
+	status := gdip.SetPathGradientTransform(this.P(), matrix.handle())
 	checkStatus(status)
 }
 
@@ -571,7 +586,7 @@ func (this *PathGradientBrush) MultiplyTransform(matrix *Matrix, append bool) {
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.MultiplyPathGradientTransform(this.P(), matrix.p, order)
+	status := gdip.MultiplyPathGradientTransform(this.P(), matrix.handle(), order)
 	checkStatus(status)
 }
 
@@ -623,6 +638,7 @@ type TextureBrush struct {
 
 func newTextureBrush(s *Scope, p *gdip.Texture) *TextureBrush {
 	brush := &TextureBrush{Brush: Brush{&p.Brush}}
+	leaks.Track(brush)
 	if s != nil {
 		s.Add(brush)
 	}
@@ -673,13 +689,13 @@ func NewTextureBrushRectAttrF(s *Scope, image *Image, wrapMode gdip.WrapMode,
 
 func (this *TextureBrush) Clone(s *Scope) *TextureBrush {
 	var p2 *gdip.Brush
-	status := gdip.CloneBrush(this.p, &p2)
+	status := gdip.CloneBrush(this.handle(), &p2)
 	checkStatus(status)
 	return newTextureBrush(s, (*gdip.Texture)(unsafe.Pointer(p2)))
 }
 
 func (this *TextureBrush) P() *gdip.Texture {
-	return (*gdip.Texture)(unsafe.Pointer(this.p))
+	return (*gdip.Texture)(unsafe.Pointer(this.handle()))
 }
 
 func (this *TextureBrush) GetTransform(s *Scope) *Matrix {
@@ -690,7 +706,7 @@ func (this *TextureBrush) GetTransform(s *Scope) *Matrix {
 }
 
 func (this *TextureBrush) SetTransform(matrix *Matrix) {
-	status := gdip.SetTextureTransform(this.P(), matrix.p)
+	status := gdip.SetTextureTransform(this.P(), matrix.handle())
 	checkStatus(status)
 }
 
@@ -722,7 +738,7 @@ func (this *TextureBrush) MultiplyTransform(matrix *Matrix, append bool) {
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.MultiplyTextureTransform(this.P(), matrix.p, order)
+	status := gdip.MultiplyTextureTransform(this.P(), matrix.handle(), order)
 	checkStatus(status)
 }
 
@@ -3,6 +3,7 @@ package brushes
 import (
 	"github.com/zzl/goforms/drawing"
 	"github.com/zzl/goforms/drawing/colors"
+	"github.com/zzl/goforms/framework/leaks"
 )
 
 var (
@@ -31,140 +32,147 @@ var (
 
 func Black() *drawing.Brush {
 	if _black == nil {
-		_black = drawing.NewSolidBrush(nil, colors.Black).AsBrush()
+		_black = newCached(colors.Black)
 	}
 	return _black
 }
 
 func White() *drawing.Brush {
 	if _white == nil {
-		_white = drawing.NewSolidBrush(nil, colors.White).AsBrush()
+		_white = newCached(colors.White)
 	}
 	return _white
 }
 
 func Transparent() *drawing.Brush {
 	if _transparent == nil {
-		_transparent = drawing.NewSolidBrush(nil, colors.Transparent).AsBrush()
+		_transparent = newCached(colors.Transparent)
 	}
 	return _transparent
 }
 
 func Blue() *drawing.Brush {
 	if _blue == nil {
-		_blue = drawing.NewSolidBrush(nil, colors.Blue).AsBrush()
+		_blue = newCached(colors.Blue)
 	}
 	return _blue
 }
 
 func SkyBlue() *drawing.Brush {
 	if _skyBlue == nil {
-		_skyBlue = drawing.NewSolidBrush(nil, colors.SkyBlue).AsBrush()
+		_skyBlue = newCached(colors.SkyBlue)
 	}
 	return _skyBlue
 }
 
 func Cyan() *drawing.Brush {
 	if _cyan == nil {
-		_cyan = drawing.NewSolidBrush(nil, colors.Cyan).AsBrush()
+		_cyan = newCached(colors.Cyan)
 	}
 	return _cyan
 }
 
 func Fuchsia() *drawing.Brush {
 	if _fuchsia == nil {
-		_fuchsia = drawing.NewSolidBrush(nil, colors.Fuchsia).AsBrush()
+		_fuchsia = newCached(colors.Fuchsia)
 	}
 	return _fuchsia
 }
 
 func Gray() *drawing.Brush {
 	if _gray == nil {
-		_gray = drawing.NewSolidBrush(nil, colors.Gray).AsBrush()
+		_gray = newCached(colors.Gray)
 	}
 	return _gray
 }
 
 func Green() *drawing.Brush {
 	if _green == nil {
-		_green = drawing.NewSolidBrush(nil, colors.Green).AsBrush()
+		_green = newCached(colors.Green)
 	}
 	return _green
 }
 
 func Lime() *drawing.Brush {
 	if _lime == nil {
-		_lime = drawing.NewSolidBrush(nil, colors.Lime).AsBrush()
+		_lime = newCached(colors.Lime)
 	}
 	return _lime
 }
 
 func Magenta() *drawing.Brush {
 	if _magenta == nil {
-		_magenta = drawing.NewSolidBrush(nil, colors.Magenta).AsBrush()
+		_magenta = newCached(colors.Magenta)
 	}
 	return _magenta
 }
 
 func Orange() *drawing.Brush {
 	if _orange == nil {
-		_orange = drawing.NewSolidBrush(nil, colors.Orange).AsBrush()
+		_orange = newCached(colors.Orange)
 	}
 	return _orange
 }
 
 func Purple() *drawing.Brush {
 	if _purple == nil {
-		_purple = drawing.NewSolidBrush(nil, colors.Purple).AsBrush()
+		_purple = newCached(colors.Purple)
 	}
 	return _purple
 }
 
 func Violet() *drawing.Brush {
 	if _violet == nil {
-		_violet = drawing.NewSolidBrush(nil, colors.Violet).AsBrush()
+		_violet = newCached(colors.Violet)
 	}
 	return _violet
 }
 
 func Red() *drawing.Brush {
 	if _red == nil {
-		_red = drawing.NewSolidBrush(nil, colors.Red).AsBrush()
+		_red = newCached(colors.Red)
 	}
 	return _red
 }
 
 func Silver() *drawing.Brush {
 	if _silver == nil {
-		_silver = drawing.NewSolidBrush(nil, colors.Silver).AsBrush()
+		_silver = newCached(colors.Silver)
 	}
 	return _silver
 }
 
 func Yellow() *drawing.Brush {
 	if _yellow == nil {
-		_yellow = drawing.NewSolidBrush(nil, colors.Yellow).AsBrush()
+		_yellow = newCached(colors.Yellow)
 	}
 	return _yellow
 }
 
 func Window() *drawing.Brush {
 	if _window == nil {
-		_window = drawing.NewSolidBrush(nil, colors.Window).AsBrush()
+		_window = newCached(colors.Window)
 	}
 	return _window
 }
 
 func WindowText() *drawing.Brush {
 	if _windowText == nil {
-		_windowText = drawing.NewSolidBrush(nil, colors.WindowText).AsBrush()
+		_windowText = newCached(colors.WindowText)
 	}
 	return _windowText
 }
 
 func GrayText() *drawing.Brush {
 	if _grayText == nil {
-		_grayText = drawing.NewSolidBrush(nil, colors.GrayText).AsBrush()
+		_grayText = newCached(colors.GrayText)
 	}
 	return _grayText
 }
+
+// newCached creates a brush cached for the lifetime of the application.
+func newCached(color drawing.Color) *drawing.Brush {
+	brush := drawing.NewSolidBrush(nil, color).AsBrush()
+	leaks.Ignore(brush)
+	return brush
+}
@@ -0,0 +1,273 @@
+package drawing
+
+import (
+	"image"
+	"image/color"
+	"runtime"
+
+	"github.com/zzl/go-gdiplus/gdip"
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/framework/leaks"
+	"github.com/zzl/goforms/framework/scope"
+)
+
+// GraphicsCanvas implements canvas.Canvas with GDI+ on a Graphics.
+//
+// Gradient brushes repeat beyond their ends instead of extending the end colors,
+// as GDI+ linear gradients can't clamp.
+type GraphicsCanvas struct {
+	g      *Graphics
+	states []gdip.GraphicsState
+	fonts  map[canvas.Font]*Font
+}
+
+var _ canvas.Canvas = (*GraphicsCanvas)(nil)
+
+// NewGraphicsCanvas creates a canvas drawing on a Graphics.
+// The graphics stays owned by the caller.
+func NewGraphicsCanvas(s *Scope, g *Graphics) *GraphicsCanvas {
+	c := &GraphicsCanvas{g: g, fonts: make(map[canvas.Font]*Font)}
+	leaks.Track(c)
+	if s != nil {
+		s.Add(c)
+	}
+	setFinalizer(c)
+	return c
+}
+
+// Graphics returns the graphics drawn on.
+func (this *GraphicsCanvas) Graphics() *Graphics {
+	return this.g
+}
+
+// Dispose releases the fonts cached by the canvas.
+func (this *GraphicsCanvas) Dispose() {
+	leaks.Disposed(this)
+	for key, font := range this.fonts {
+		font.Dispose()
+		delete(this.fonts, key)
+	}
+	runtime.SetFinalizer(this, nil)
+}
+
+func (this *GraphicsCanvas) Bounds() geom.Rect {
+	state := this.g.Save()
+	defer this.g.Restore(state)
+	this.g.ResetTransform()
+	this.g.ResetClip()
+	return geomRectOf(this.g.GetVisibleClipBoundsF())
+}
+
+func (this *GraphicsCanvas) Save() {
+	this.states = append(this.states, this.g.Save())
+}
+
+func (this *GraphicsCanvas) Restore() {
+	if len(this.states) == 0 {
+		return
+	}
+	this.g.Restore(this.states[len(this.states)-1])
+	this.states = this.states[:len(this.states)-1]
+}
+
+func (this *GraphicsCanvas) Transform() geom.Affine {
+	return this.g.GetAffineTransform()
+}
+
+func (this *GraphicsCanvas) SetTransform(m geom.Affine) {
+	this.g.SetAffineTransform(m)
+}
+
+func (this *GraphicsCanvas) ClipRect(rect geom.Rect) {
+	this.g.IntersectClipF(rectFOf(rect))
+}
+
+func (this *GraphicsCanvas) ClipPath(path *geom.Path) {
+	scope.WithScope(func(s *Scope) {
+		this.g.SetClipPath(NewPathFromGeom(s, path), gdip.CombineModeIntersect)
+	})
+}
+
+func (this *GraphicsCanvas) ClipBounds() geom.Rect {
+	return geomRectOf(this.g.GetClipBoundsF())
+}
+
+func (this *GraphicsCanvas) Clear(c color.NRGBA) {
+	this.g.Clear(colorOfNRGBA(c))
+}
+
+func (this *GraphicsCanvas) FillPath(path *geom.Path, brush canvas.Brush) {
+	if path.IsEmpty() {
+		return
+	}
+	scope.WithScope(func(s *Scope) {
+		this.g.FillPath(brushOf(s, brush), NewPathFromGeom(s, path))
+	})
+}
+
+func (this *GraphicsCanvas) StrokePath(path *geom.Path, pen *canvas.Pen) {
+	if path.IsEmpty() {
+		return
+	}
+	scope.WithScope(func(s *Scope) {
+		this.g.DrawPath(penOf(s, pen), NewPathFromGeom(s, path))
+	})
+}
+
+func (this *GraphicsCanvas) DrawImage(img image.Image, src image.Rectangle, dst geom.Rect) {
+	src = src.Intersect(img.Bounds())
+	if src.Empty() {
+		return
+	}
+	bitmap := NewBitmapFromGoImage(nil, subImage{img, src})
+	defer bitmap.Dispose()
+	this.g.DrawImageRectRectF(bitmap.AsImage(), rectFOf(dst),
+		RectF{0, 0, float32(src.Dx()), float32(src.Dy())}, gdip.UnitPixel)
+}
+
+func (this *GraphicsCanvas) DrawString(text string, font *canvas.Font, brush canvas.Brush,
+	layout geom.Rect, format *canvas.StringFormat) {
+	scope.WithScope(func(s *Scope) {
+		this.g.DrawStringRectF(text, this.fontOf(font), brushOf(s, brush),
+			rectFOf(layout), stringFormatOf(s, format))
+	})
+}
+
+func (this *GraphicsCanvas) MeasureString(text string, font *canvas.Font, width float64,
+	format *canvas.StringFormat) geom.Size {
+	var size SizeF
+	scope.WithScope(func(s *Scope) {
+		size, _, _ = this.g.MeasureString(text, this.fontOf(font),
+			SizeF{Width: float32(max(width, 0))}, stringFormatOf(s, format))
+	})
+	return geom.Size{Width: float64(size.Width), Height: float64(size.Height)}
+}
+
+// fontOf returns the cached GDI+ font of a font description.
+func (this *GraphicsCanvas) fontOf(font *canvas.Font) *Font {
+	if f, ok := this.fonts[*font]; ok {
+		return f
+	}
+	family := NewFontFamilyOrDefault(nil, font.Family)
+	defer family.Dispose()
+	f := NewFontWithUnitStyle(nil, family, float32(font.Size),
+		gdip.UnitWorld, gdip.FontStyle(font.Style))
+	leaks.Owned(f) //disposed with the canvas
+	this.fonts[*font] = f
+	return f
+}
+
+// subImage restricts an image to a rect.
+type subImage struct {
+	image.Image
+	rect image.Rectangle
+}
+
+func (me subImage) Bounds() image.Rectangle {
+	return me.rect
+}
+
+func rectFOf(rect geom.Rect) RectF {
+	return RectF{X: float32(rect.X), Y: float32(rect.Y),
+		Width: float32(rect.Width), Height: float32(rect.Height)}
+}
+
+func geomRectOf(rect RectF) geom.Rect {
+	return geom.Rc(float64(rect.X), float64(rect.Y), float64(rect.Width), float64(rect.Height))
+}
+
+func colorOfNRGBA(c color.NRGBA) Color {
+	return Rgba(c.R, c.G, c.B, c.A)
+}
+
+func brushOf(s *Scope, brush canvas.Brush) *Brush {
+	switch b := brush.(type) {
+	case *canvas.SolidBrush:
+		return brushOf(s, *b)
+	case *canvas.HatchBrush:
+		return brushOf(s, *b)
+	case *canvas.LinearGradientBrush:
+		return brushOf(s, *b)
+	case canvas.HatchBrush:
+		return &NewHatchBrush(s, gdip.HatchStyle(b.Style),
+			colorOfNRGBA(b.ForeColor), colorOfNRGBA(b.BackColor)).Brush
+	case canvas.LinearGradientBrush:
+		stops := b.SortedStops()
+		if len(stops) == 0 || b.Start == b.End {
+			return brushOf(s, canvas.SolidBrush{Color: b.ColorAt(0)})
+		}
+		start := PointF{X: float32(b.Start.X), Y: float32(b.Start.Y)}
+		end := PointF{X: float32(b.End.X), Y: float32(b.End.Y)}
+		color1, color2 := colorOfNRGBA(b.ColorAt(0)), colorOfNRGBA(b.ColorAt(1))
+		var p *gdip.LineGradient
+		status := gdip.CreateLineBrush(&start, &end, color1.Argb(), color2.Argb(),
+			gdip.WrapModeTileFlipXY, &p)
+		checkStatus(status)
+		gradient := newLinearGradientBrush(s, p)
+		if len(stops) > 2 || stops[0].Offset != 0 || stops[len(stops)-1].Offset != 1 {
+			//preset blends must start at 0 and end at 1
+			colors := []Color{color1}
+			positions := []float32{0}
+			for _, stop := range stops {
+				if stop.Offset > 0 && stop.Offset < 1 {
+					colors = append(colors, colorOfNRGBA(stop.Color))
+					positions = append(positions, float32(stop.Offset))
+				}
+			}
+			colors = append(colors, color2)
+			positions = append(positions, 1)
+			gradient.SetInterpolationColors(colors, positions)
+		}
+		return &gradient.Brush
+	case canvas.SolidBrush:
+		return NewSolidBrush(s, colorOfNRGBA(b.Color)).AsBrush()
+	}
+	return NewSolidBrush(s, Rgba(0, 0, 0, 0)).AsBrush()
+}
+
+func dashCapOf(lineCap canvas.LineCap) gdip.DashCap {
+	switch lineCap {
+	case canvas.LineCapRound:
+		return gdip.DashCapRound
+	case canvas.LineCapTriangle:
+		return gdip.DashCapTriangle
+	}
+	return gdip.DashCapFlat
+}
+
+func penOf(s *Scope, pen *canvas.Pen) *Pen {
+	var p *Pen
+	if pen.Brush != nil {
+		p = NewPenFromBrushWithWidth(s, brushOf(s, pen.Brush), float32(pen.Width))
+	} else {
+		p = NewPenWithWidth(s, colorOfNRGBA(pen.Color), float32(pen.Width))
+	}
+	p.SetStartCap(gdip.LineCap(pen.StartCap))
+	p.SetEndCap(gdip.LineCap(pen.EndCap))
+	p.SetLineJoin(gdip.LineJoin(pen.Join))
+	p.SetMiterLimit(float32(pen.GetMiterLimit()))
+	if dashes := pen.GetDashPattern(); dashes != nil {
+		pattern := make([]float32, len(dashes))
+		for n, dash := range dashes {
+			pattern[n] = float32(dash)
+		}
+		p.SetDashPattern(pattern)
+		p.SetDashOffset(float32(pen.DashOffset))
+		p.SetDashCap(dashCapOf(pen.DashCap))
+	}
+	return p
+}
+
+func stringFormatOf(s *Scope, format *canvas.StringFormat) *StringFormat {
+	if format == nil {
+		return nil
+	}
+	f := NewStringFormat(s)
+	f.SetAlignment(gdip.StringAlignment(format.Alignment))
+	f.SetLineAlignment(gdip.StringAlignment(format.LineAlignment))
+	if format.NoWrap {
+		f.SetFormatFlags(f.GetFormatFlags() | gdip.StringFormatFlagsNoWrap)
+	}
+	return f
+}
@@ -0,0 +1,175 @@
+// Package canvas defines Canvas, a backend-neutral drawing surface.
+//
+// Code painting through a Canvas runs unchanged on GDI+ (drawing.GraphicsCanvas)
+// and on the pure-Go rasterizer of the drawing/raster package, which renders
+// to an *image.RGBA without Windows, e.g. for snapshot tests or server-side images.
+//
+// Pens, brushes, fonts and string formats are plain values here,
+// each backend creating its native resources from them as needed.
+// Coordinates are float64, with the y axis pointing down.
+package canvas
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// Canvas is a drawing surface.
+//
+// Drawing happens in user coordinates, mapped to device coordinates by the current transform.
+// Save and Restore push and pop the transform and the clip.
+type Canvas interface {
+	// Bounds returns the drawable area in device coordinates.
+	Bounds() geom.Rect
+
+	// Save pushes the current transform and clip.
+	Save()
+	// Restore pops the transform and clip pushed by the matching Save.
+	Restore()
+
+	// Transform returns the current transform from user to device coordinates.
+	Transform() geom.Affine
+	// SetTransform replaces the current transform.
+	SetTransform(m geom.Affine)
+
+	// ClipRect intersects the clip with a rect in user coordinates.
+	ClipRect(rect geom.Rect)
+	// ClipPath intersects the clip with the interior of a path in user coordinates.
+	ClipPath(path *geom.Path)
+	// ClipBounds returns the bounds of the clip in user coordinates.
+	ClipBounds() geom.Rect
+
+	// Clear fills the whole clip with a color, replacing what was drawn.
+	Clear(c color.NRGBA)
+
+	// FillPath fills the interior of a path according to its fill mode.
+	FillPath(path *geom.Path, brush Brush)
+	// StrokePath draws the outline of a path.
+	StrokePath(path *geom.Path, pen *Pen)
+
+	// DrawImage draws the src part of an image stretched into the dst rect.
+	DrawImage(img image.Image, src image.Rectangle, dst geom.Rect)
+
+	// DrawString draws text within a layout rect.
+	// A layout rect of zero width and height only positions the text.
+	DrawString(text string, font *Font, brush Brush, layout geom.Rect, format *StringFormat)
+	// MeasureString returns the size of text laid out within a width,
+	// or on unbounded lines if the width is not positive.
+	MeasureString(text string, font *Font, width float64, format *StringFormat) geom.Size
+}
+
+// Translate moves the origin of the user coordinates of the canvas.
+func Translate(c Canvas, dx, dy float64) {
+	c.SetTransform(c.Transform().Translate(dx, dy))
+}
+
+// Scale scales the user coordinates of the canvas.
+func Scale(c Canvas, sx, sy float64) {
+	c.SetTransform(c.Transform().Scale(sx, sy))
+}
+
+// Rotate rotates the user coordinates of the canvas, in degrees clockwise.
+func Rotate(c Canvas, degrees float64) {
+	c.SetTransform(c.Transform().Rotate(degrees))
+}
+
+// DrawLine draws a line.
+func DrawLine(c Canvas, pen *Pen, pt1, pt2 geom.Point) {
+	path := geom.NewPath()
+	path.AddLine(pt1, pt2)
+	c.StrokePath(path, pen)
+}
+
+// DrawLines draws a polyline.
+func DrawLines(c Canvas, pen *Pen, points []geom.Point) {
+	path := geom.NewPath()
+	path.AddLines(points)
+	c.StrokePath(path, pen)
+}
+
+// DrawRectangle draws the outline of a rect.
+func DrawRectangle(c Canvas, pen *Pen, rect geom.Rect) {
+	path := geom.NewPath()
+	path.AddRectangle(rect)
+	c.StrokePath(path, pen)
+}
+
+// FillRectangle fills a rect.
+func FillRectangle(c Canvas, brush Brush, rect geom.Rect) {
+	path := geom.NewPath()
+	path.AddRectangle(rect)
+	c.FillPath(path, brush)
+}
+
+// DrawRoundedRectangle draws the outline of a rect with elliptic corners.
+func DrawRoundedRectangle(c Canvas, pen *Pen, rect geom.Rect, rx, ry float64) {
+	path := geom.NewPath()
+	path.AddRoundedRectangle(rect, rx, ry)
+	c.StrokePath(path, pen)
+}
+
+// FillRoundedRectangle fills a rect with elliptic corners.
+func FillRoundedRectangle(c Canvas, brush Brush, rect geom.Rect, rx, ry float64) {
+	path := geom.NewPath()
+	path.AddRoundedRectangle(rect, rx, ry)
+	c.FillPath(path, brush)
+}
+
+// DrawEllipse draws the outline of the ellipse bounded by rect.
+func DrawEllipse(c Canvas, pen *Pen, rect geom.Rect) {
+	path := geom.NewPath()
+	path.AddEllipse(rect)
+	c.StrokePath(path, pen)
+}
+
+// FillEllipse fills the ellipse bounded by rect.
+func FillEllipse(c Canvas, brush Brush, rect geom.Rect) {
+	path := geom.NewPath()
+	path.AddEllipse(rect)
+	c.FillPath(path, brush)
+}
+
+// DrawArc draws an arc of the ellipse bounded by rect.
+func DrawArc(c Canvas, pen *Pen, rect geom.Rect, startAngle, sweepAngle float64) {
+	path := geom.NewPath()
+	path.AddArc(rect, startAngle, sweepAngle)
+	c.StrokePath(path, pen)
+}
+
+// DrawPie draws the outline of a pie slice of the ellipse bounded by rect.
+func DrawPie(c Canvas, pen *Pen, rect geom.Rect, startAngle, sweepAngle float64) {
+	path := geom.NewPath()
+	path.AddPie(rect, startAngle, sweepAngle)
+	c.StrokePath(path, pen)
+}
+
+// FillPie fills a pie slice of the ellipse bounded by rect.
+func FillPie(c Canvas, brush Brush, rect geom.Rect, startAngle, sweepAngle float64) {
+	path := geom.NewPath()
+	path.AddPie(rect, startAngle, sweepAngle)
+	c.FillPath(path, brush)
+}
+
+// DrawPolygon draws the outline of a polygon.
+func DrawPolygon(c Canvas, pen *Pen, points []geom.Point) {
+	path := geom.NewPath()
+	path.AddPolygon(points)
+	c.StrokePath(path, pen)
+}
+
+// FillPolygon fills a polygon with the fill mode.
+func FillPolygon(c Canvas, brush Brush, points []geom.Point, fillMode geom.FillMode) {
+	path := geom.NewPath()
+	path.FillMode = fillMode
+	path.AddPolygon(points)
+	c.FillPath(path, brush)
+}
+
+// DrawImageAt draws a whole image at its size.
+func DrawImageAt(c Canvas, img image.Image, x, y float64) {
+	bounds := img.Bounds()
+	c.DrawImage(img, bounds, geom.Rect{X: x, Y: y,
+		Width: float64(bounds.Dx()), Height: float64(bounds.Dy())})
+}
@@ -0,0 +1,275 @@
+package canvas
+
+import (
+	"image/color"
+	"math"
+	"sort"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// LineCap is the shape of the ends of open lines, with the values of the GDI+ LineCap.
+type LineCap = geom.LineCap
+
+const (
+	LineCapFlat     = geom.LineCapFlat     //ends at the end point
+	LineCapSquare   = geom.LineCapSquare   //extends half the width past the end point
+	LineCapRound    = geom.LineCapRound    //a half disc centered at the end point
+	LineCapTriangle = geom.LineCapTriangle //a triangle pointing half the width past the end point
+)
+
+// LineJoin is the shape of the corners of lines, with the values of the GDI+ LineJoin.
+type LineJoin = geom.LineJoin
+
+const (
+	LineJoinMiter        = geom.LineJoinMiter //sharp corners, beveled past the miter limit
+	LineJoinBevel        = geom.LineJoinBevel
+	LineJoinRound        = geom.LineJoinRound
+	LineJoinMiterClipped = geom.LineJoinMiterClipped //sharp corners, clipped at the miter limit
+)
+
+// DashStyle is the dash pattern of a pen, with the values of the GDI+ DashStyle.
+type DashStyle int
+
+const (
+	DashStyleSolid      DashStyle = 0
+	DashStyleDash       DashStyle = 1
+	DashStyleDot        DashStyle = 2
+	DashStyleDashDot    DashStyle = 3
+	DashStyleDashDotDot DashStyle = 4
+	DashStyleCustom     DashStyle = 5 //Pen.DashPattern
+)
+
+// DefaultMiterLimit is the miter limit of pens not specifying one, as in GDI+.
+const DefaultMiterLimit = 10
+
+// Pen describes how outlines are drawn.
+type Pen struct {
+	Color color.NRGBA
+	Brush Brush //if not nil, fills the outline instead of Color
+
+	// Width is the width of the lines in user coordinates.
+	// A zero width draws lines one device pixel wide.
+	Width float64
+
+	StartCap   LineCap
+	EndCap     LineCap
+	Join       LineJoin
+	MiterLimit float64 //DefaultMiterLimit if zero
+
+	DashStyle   DashStyle
+	DashPattern []float64 //dash and gap lengths in multiples of the width, for DashStyleCustom
+	DashOffset  float64   //in multiples of the width
+	DashCap     LineCap   //cap of the dashes, flat, round or triangle
+}
+
+// NewPen creates a solid pen.
+func NewPen(c color.NRGBA, width float64) *Pen {
+	return &Pen{Color: c, Width: width}
+}
+
+// GetBrush returns the brush filling the outline.
+func (this *Pen) GetBrush() Brush {
+	if this.Brush != nil {
+		return this.Brush
+	}
+	return SolidBrush{this.Color}
+}
+
+// GetMiterLimit returns the miter limit, or DefaultMiterLimit if not set.
+func (this *Pen) GetMiterLimit() float64 {
+	if this.MiterLimit <= 0 {
+		return DefaultMiterLimit
+	}
+	return this.MiterLimit
+}
+
+// GetDashPattern returns the dash and gap lengths in multiples of the width,
+// or nil for solid lines.
+func (this *Pen) GetDashPattern() []float64 {
+	switch this.DashStyle {
+	case DashStyleDash:
+		return []float64{3, 1}
+	case DashStyleDot:
+		return []float64{1, 1}
+	case DashStyleDashDot:
+		return []float64{3, 1, 1, 1}
+	case DashStyleDashDotDot:
+		return []float64{3, 1, 1, 1, 1, 1}
+	case DashStyleCustom:
+		if len(this.DashPattern) > 0 {
+			return this.DashPattern
+		}
+	}
+	return nil
+}
+
+// Stroke returns the geometry of the stroke of the pen with a width,
+// the width of the pen or that of hairlines in the coordinates stroked.
+func (this *Pen) Stroke(width float64) *geom.Stroke {
+	stroke := &geom.Stroke{
+		Width:      width,
+		StartCap:   this.StartCap,
+		EndCap:     this.EndCap,
+		Join:       this.Join,
+		MiterLimit: this.GetMiterLimit(),
+		DashOffset: this.DashOffset * width,
+		DashCap:    this.DashCap,
+	}
+	for _, dash := range this.GetDashPattern() {
+		stroke.Dashes = append(stroke.Dashes, dash*width)
+	}
+	return stroke
+}
+
+// Brush describes how areas are filled.
+// It is one of SolidBrush, HatchBrush and LinearGradientBrush.
+type Brush interface {
+	isBrush()
+}
+
+// SolidBrush fills with a color.
+type SolidBrush struct {
+	Color color.NRGBA
+}
+
+func (SolidBrush) isBrush() {}
+
+// HatchStyle is the pattern of a HatchBrush, with the values of the GDI+ HatchStyle.
+type HatchStyle int
+
+const (
+	HatchStyleHorizontal       HatchStyle = 0
+	HatchStyleVertical         HatchStyle = 1
+	HatchStyleForwardDiagonal  HatchStyle = 2
+	HatchStyleBackwardDiagonal HatchStyle = 3
+	HatchStyleCross            HatchStyle = 4
+	HatchStyleDiagonalCross    HatchStyle = 5
+	HatchStyle05Percent        HatchStyle = 6
+	HatchStyle10Percent        HatchStyle = 7
+	HatchStyle20Percent        HatchStyle = 8
+	HatchStyle25Percent        HatchStyle = 9
+	HatchStyle30Percent        HatchStyle = 10
+	HatchStyle40Percent        HatchStyle = 11
+	HatchStyle50Percent        HatchStyle = 12
+	HatchStyle60Percent        HatchStyle = 13
+	HatchStyle70Percent        HatchStyle = 14
+	HatchStyle75Percent        HatchStyle = 15
+	HatchStyle80Percent        HatchStyle = 16
+	HatchStyle90Percent        HatchStyle = 17
+)
+
+// HatchBrush fills with an 8x8 device pixel pattern of two colors.
+type HatchBrush struct {
+	Style     HatchStyle
+	ForeColor color.NRGBA
+	BackColor color.NRGBA
+}
+
+func (HatchBrush) isBrush() {}
+
+// HatchPattern returns the rows of the 8x8 pattern of a hatch style,
+// the most significant bit being the leftmost pixel in the foreground color.
+// Styles without a pattern here yield the 50 percent pattern.
+func HatchPattern(style HatchStyle) [8]byte {
+	switch style {
+	case HatchStyleHorizontal:
+		return [8]byte{0xff, 0, 0, 0, 0, 0, 0, 0}
+	case HatchStyleVertical:
+		return [8]byte{0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80}
+	case HatchStyleForwardDiagonal:
+		return [8]byte{0x80, 0x40, 0x20, 0x10, 0x08, 0x04, 0x02, 0x01}
+	case HatchStyleBackwardDiagonal:
+		return [8]byte{0x01, 0x02, 0x04, 0x08, 0x10, 0x20, 0x40, 0x80}
+	case HatchStyleCross:
+		return [8]byte{0xff, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80, 0x80}
+	case HatchStyleDiagonalCross:
+		return [8]byte{0x81, 0x42, 0x24, 0x18, 0x18, 0x24, 0x42, 0x81}
+	case HatchStyle05Percent:
+		return [8]byte{0x80, 0, 0, 0, 0x08, 0, 0, 0}
+	case HatchStyle10Percent:
+		return [8]byte{0x80, 0, 0x08, 0, 0x80, 0, 0x08, 0}
+	case HatchStyle20Percent:
+		return [8]byte{0x88, 0, 0x22, 0, 0x88, 0, 0x22, 0}
+	case HatchStyle25Percent:
+		return [8]byte{0x88, 0x22, 0x88, 0x22, 0x88, 0x22, 0x88, 0x22}
+	case HatchStyle30Percent:
+		return [8]byte{0xaa, 0x44, 0xaa, 0x11, 0xaa, 0x44, 0xaa, 0x11}
+	case HatchStyle40Percent:
+		return [8]byte{0xaa, 0x55, 0xaa, 0x51, 0xaa, 0x55, 0xaa, 0x15}
+	case HatchStyle60Percent:
+		return [8]byte{0xee, 0x55, 0xbb, 0x55, 0xee, 0x55, 0xbb, 0x55}
+	case HatchStyle70Percent:
+		return [8]byte{0x77, 0xdd, 0x77, 0xdd, 0x77, 0xdd, 0x77, 0xdd}
+	case HatchStyle75Percent:
+		return [8]byte{0xee, 0xbb, 0xee, 0xbb, 0xee, 0xbb, 0xee, 0xbb}
+	case HatchStyle80Percent:
+		return [8]byte{0xf7, 0xff, 0x7f, 0xff, 0xf7, 0xff, 0x7f, 0xff}
+	case HatchStyle90Percent:
+		return [8]byte{0xf7, 0xff, 0xff, 0xff, 0x7f, 0xff, 0xff, 0xff}
+	}
+	return [8]byte{0xaa, 0x55, 0xaa, 0x55, 0xaa, 0x55, 0xaa, 0x55}
+}
+
+// GradientStop is a color at an offset of a gradient, from 0 to 1.
+type GradientStop struct {
+	Offset float64
+	Color  color.NRGBA
+}
+
+// LinearGradientBrush fills with colors varying along the line from Start to End,
+// in user coordinates. Beyond the ends, the colors of the end stops extend.
+type LinearGradientBrush struct {
+	Start, End geom.Point
+	Stops      []GradientStop
+}
+
+func (LinearGradientBrush) isBrush() {}
+
+// NewLinearGradientBrush creates a two-color gradient brush.
+func NewLinearGradientBrush(start, end geom.Point, color1, color2 color.NRGBA) LinearGradientBrush {
+	return LinearGradientBrush{Start: start, End: end,
+		Stops: []GradientStop{{0, color1}, {1, color2}}}
+}
+
+// SortedStops returns the stops sorted by offset, with offsets clamped to [0, 1].
+func (me LinearGradientBrush) SortedStops() []GradientStop {
+	stops := make([]GradientStop, len(me.Stops))
+	for n, stop := range me.Stops {
+		stop.Offset = math.Max(0, math.Min(1, stop.Offset))
+		stops[n] = stop
+	}
+	sort.SliceStable(stops, func(i, j int) bool {
+		return stops[i].Offset < stops[j].Offset
+	})
+	return stops
+}
+
+// ColorAt returns the color at an offset along the gradient.
+func (me LinearGradientBrush) ColorAt(t float64) color.NRGBA {
+	stops := me.SortedStops()
+	if len(stops) == 0 {
+		return color.NRGBA{}
+	}
+	if t <= stops[0].Offset {
+		return stops[0].Color
+	}
+	for n := 1; n < len(stops); n++ {
+		s0, s1 := stops[n-1], stops[n]
+		if t <= s1.Offset {
+			if s1.Offset == s0.Offset {
+				return s1.Color
+			}
+			return LerpColor(s0.Color, s1.Color, (t-s0.Offset)/(s1.Offset-s0.Offset))
+		}
+	}
+	return stops[len(stops)-1].Color
+}
+
+// LerpColor interpolates linearly between two colors.
+func LerpColor(c1, c2 color.NRGBA, t float64) color.NRGBA {
+	lerp := func(a, b uint8) uint8 {
+		return uint8(math.Round(float64(a) + (float64(b)-float64(a))*t))
+	}
+	return color.NRGBA{lerp(c1.R, c2.R), lerp(c1.G, c2.G), lerp(c1.B, c2.B), lerp(c1.A, c2.A)}
+}
@@ -0,0 +1,242 @@
+package canvas
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// FontStyle is a set of font style flags, with the values of the GDI+ FontStyle.
+type FontStyle int
+
+const (
+	FontStyleRegular   FontStyle = 0
+	FontStyleBold      FontStyle = 1
+	FontStyleItalic    FontStyle = 2
+	FontStyleUnderline FontStyle = 4
+	FontStyleStrikeout FontStyle = 8
+)
+
+// Font describes the font of text.
+type Font struct {
+	Family string
+	Size   float64 //the em size in user coordinates
+	Style  FontStyle
+}
+
+// NewFont creates a font description.
+func NewFont(family string, size float64, style FontStyle) *Font {
+	return &Font{Family: family, Size: size, Style: style}
+}
+
+// StringAlignment is the alignment of text within its layout rect,
+// with the values of the GDI+ StringAlignment.
+type StringAlignment int
+
+const (
+	StringAlignmentNear   StringAlignment = 0 //left or top
+	StringAlignmentCenter StringAlignment = 1
+	StringAlignmentFar    StringAlignment = 2 //right or bottom
+)
+
+// StringFormat describes the layout of text.
+// A nil format is the default: near aligned and wrapped.
+type StringFormat struct {
+	Alignment     StringAlignment //horizontal alignment
+	LineAlignment StringAlignment //vertical alignment
+	NoWrap        bool            //don't wrap lines at the layout width
+}
+
+// FaceMetrics are the vertical metrics of a face, in em units.
+type FaceMetrics struct {
+	Ascent  float64 //from the baseline up to the top of the line
+	Descent float64 //from the baseline down to the bottom of the line
+	LineGap float64 //the extra space between lines
+}
+
+// LineHeight returns the distance between the baselines of lines, in em units.
+func (me FaceMetrics) LineHeight() float64 {
+	return me.Ascent + me.Descent + me.LineGap
+}
+
+// Face provides the glyphs of a font to the backends rendering text themselves.
+// Glyph outlines and advances are in em units,
+// with the origin on the baseline and the y axis pointing down.
+type Face interface {
+	Metrics() FaceMetrics
+	// Advance returns the horizontal advance of the glyph of a rune.
+	Advance(r rune) float64
+	// Glyph returns the outline of the glyph of a rune, or nil if it has none.
+	Glyph(r rune) *geom.Path
+}
+
+// FaceSource finds the face of a font.
+type FaceSource interface {
+	// Face returns the face of the font, or nil if there is none.
+	Face(font *Font) Face
+}
+
+// FaceSourceFunc adapts a function to FaceSource.
+type FaceSourceFunc func(font *Font) Face
+
+func (me FaceSourceFunc) Face(font *Font) Face {
+	return me(font)
+}
+
+// FallbackFace is the face used when no face is found for a font.
+// It has plausible metrics and draws each visible rune as an empty box,
+// so that text layout stays visible and deterministic.
+var FallbackFace Face = fallbackFace{}
+
+type fallbackFace struct{}
+
+func (fallbackFace) Metrics() FaceMetrics {
+	return FaceMetrics{Ascent: 0.9, Descent: 0.25, LineGap: 0}
+}
+
+func (fallbackFace) Advance(r rune) float64 {
+	if r == ' ' {
+		return 0.3
+	}
+	if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+		return 0
+	}
+	return 0.6
+}
+
+func (fallbackFace) Glyph(r rune) *geom.Path {
+	if unicode.IsSpace(r) || !unicode.IsPrint(r) {
+		return nil
+	}
+	path := geom.NewPath()
+	path.AddRectangle(geom.Rect{X: 0.08, Y: -0.7, Width: 0.44, Height: 0.7})
+	path.AddRectangle(geom.Rect{X: 0.14, Y: -0.64, Width: 0.32, Height: 0.58})
+	return path
+}
+
+// TextLine is a line of laid out text.
+type TextLine struct {
+	Text   string
+	Origin geom.Point //the left end of the baseline
+	Width  float64
+}
+
+// TextLayout is the result of LayoutText.
+type TextLayout struct {
+	Lines  []TextLine
+	Bounds geom.Rect //the bounds of the lines
+}
+
+// MeasureRunes returns the advance width of text.
+func MeasureRunes(face Face, size float64, text string) float64 {
+	var width float64
+	for _, r := range text {
+		width += face.Advance(r)
+	}
+	return width * size
+}
+
+// LayoutText breaks text into lines and positions them in the layout rect,
+// for backends rendering text with faces.
+// Lines are broken at newlines, and wrapped at spaces if the rect has a width,
+// unless the format says NoWrap. A rect of zero size aligns the text around its location.
+func LayoutText(text string, face Face, font *Font, layout geom.Rect,
+	format *StringFormat) TextLayout {
+	if format == nil {
+		format = &StringFormat{}
+	}
+	size := font.Size
+	metrics := face.Metrics()
+	lineHeight := metrics.LineHeight() * size
+	wrapWidth := 0.0
+	if layout.Width > 0 && !format.NoWrap {
+		wrapWidth = layout.Width
+	}
+
+	var texts []string
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	for _, paragraph := range strings.Split(text, "\n") {
+		texts = append(texts, wrapText(paragraph, face, size, wrapWidth)...)
+	}
+
+	totalHeight := lineHeight * float64(len(texts))
+	top := layout.Y
+	switch format.LineAlignment {
+	case StringAlignmentCenter:
+		top += (layout.Height - totalHeight) / 2
+	case StringAlignmentFar:
+		top += layout.Height - totalHeight
+	}
+
+	result := TextLayout{Bounds: geom.Rect{Y: top, Height: totalHeight}}
+	for n, lineText := range texts {
+		width := MeasureRunes(face, size, lineText)
+		x := layout.X
+		switch format.Alignment {
+		case StringAlignmentCenter:
+			x += (layout.Width - width) / 2
+		case StringAlignmentFar:
+			x += layout.Width - width
+		}
+		result.Lines = append(result.Lines, TextLine{
+			Text:   lineText,
+			Origin: geom.Point{X: x, Y: top + lineHeight*float64(n) + metrics.Ascent*size},
+			Width:  width,
+		})
+		if n == 0 {
+			result.Bounds.X, result.Bounds.Width = x, width
+			continue
+		}
+		if x < result.Bounds.X {
+			result.Bounds.Width += result.Bounds.X - x
+			result.Bounds.X = x
+		}
+		if right := x + width; right > result.Bounds.Right() {
+			result.Bounds.Width = right - result.Bounds.X
+		}
+	}
+	return result
+}
+
+// wrapText breaks a paragraph into lines no wider than width, if positive.
+// Lines break after spaces, or within words wider than the width.
+func wrapText(paragraph string, face Face, size float64, width float64) []string {
+	if width <= 0 {
+		return []string{paragraph}
+	}
+	var lines []string
+	runes := []rune(paragraph)
+	start := 0
+	for start < len(runes) {
+		lineWidth := 0.0
+		end := start
+		lastBreak := -1
+		for end < len(runes) {
+			advance := face.Advance(runes[end]) * size
+			if lineWidth+advance > width && end > start && !unicode.IsSpace(runes[end]) {
+				break
+			}
+			lineWidth += advance
+			if unicode.IsSpace(runes[end]) {
+				lastBreak = end + 1
+			}
+			end++
+		}
+		if end < len(runes) && lastBreak > start {
+			end = lastBreak
+		}
+		lines = append(lines, strings.TrimRightFunc(string(runes[start:end]), unicode.IsSpace))
+		start = end
+	}
+	if len(lines) == 0 {
+		lines = append(lines, "")
+	}
+	return lines
+}
+
+// MeasureText returns the size of text laid out with a face, as LayoutText does.
+func MeasureText(text string, face Face, font *Font, width float64,
+	format *StringFormat) geom.Size {
+	return LayoutText(text, face, font, geom.Rect{Width: width}, format).Bounds.Size()
+}
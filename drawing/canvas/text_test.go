@@ -0,0 +1,54 @@
+package canvas
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+func TestLayoutTextWrap(t *testing.T) {
+	//the fallback face advances 6 per rune and 3 per space at size 10
+	font := NewFont("", 10, FontStyleRegular)
+	tests := []struct {
+		name  string
+		text  string
+		width float64
+		want  []string
+	}{
+		{"no width", "aaa bbb", 0, []string{"aaa bbb"}},
+		{"fits", "aaa bbb", 39, []string{"aaa bbb"}},
+		{"wraps at space", "aaa bbb", 30, []string{"aaa", "bbb"}},
+		{"breaks long word", "aaaaaa", 20, []string{"aaa", "aaa"}},
+		{"newlines", "a\r\nb\nc", 0, []string{"a", "b", "c"}},
+	}
+	for _, test := range tests {
+		layout := LayoutText(test.text, FallbackFace, font, geom.Rc(0, 0, test.width, 0), nil)
+		var got []string
+		for _, line := range layout.Lines {
+			got = append(got, line.Text)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: lines = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLayoutTextAlignment(t *testing.T) {
+	font := NewFont("", 10, FontStyleRegular)
+	format := &StringFormat{Alignment: StringAlignmentCenter, LineAlignment: StringAlignmentFar}
+	layout := LayoutText("ab\nabcd", FallbackFace, font, geom.Rc(10, 0, 40, 100), format)
+	if len(layout.Lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(layout.Lines))
+	}
+	//lines are 11.5 high, the last one ending at the bottom of the rect
+	want := []geom.Point{{X: 24, Y: 77 + 9}, {X: 18, Y: 88.5 + 9}}
+	for n, line := range layout.Lines {
+		if line.Origin != want[n] {
+			t.Errorf("line %d origin = %v, want %v", n, line.Origin, want[n])
+		}
+	}
+	if want := geom.Rc(18, 77, 24, 23); layout.Bounds != want {
+		t.Errorf("bounds = %v, want %v", layout.Bounds, want)
+	}
+}
@@ -53,13 +53,11 @@ func ColorFromSysColor(colorIndex int) Color {
 
 func ColorFromName(name string) (Color, error) {
 	name = strings.ToLower(name)
-	for n, tName := range namedColorNames {
-		tName = strings.ToLower(tName)
-		if tName == name {
-			return ColorOfId(NamedColorId(n)), nil
-		}
+	id, ok := namedColorIds[name]
+	if !ok {
+		return Color{}, errors.New("Unknown color name " + name)
 	}
-	return Color{}, errors.New("Unknown color name " + name)
+	return ColorOfId(id), nil
 }
 
 //func ColorFromName(id NamedColorId) Color {
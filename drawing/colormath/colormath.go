@@ -0,0 +1,283 @@
+// Package colormath converts colors between color spaces, parses CSS colors
+// and does color math, on color.NRGBA values in pure Go.
+//
+// drawing.Color wraps it for GDI+ colors; it doesn't depend on Windows,
+// so that it can be used and tested on any platform.
+// Hues are in degrees, and saturation, lightness and value from 0 to 1.
+// Lab is CIE L*a*b* relative to the D50 white, as in CSS.
+package colormath
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+)
+
+// rgbaF returns the components of a color, from 0 to 1.
+func rgbaF(c color.NRGBA) (r, g, b, a float64) {
+	return float64(c.R) / 255, float64(c.G) / 255, float64(c.B) / 255, float64(c.A) / 255
+}
+
+// colorOfF returns the color of components from 0 to 1, clamping them.
+func colorOfF(r, g, b, a float64) color.NRGBA {
+	return color.NRGBA{unitByte(r), unitByte(g), unitByte(b), unitByte(a)}
+}
+
+func unitByte(v float64) byte {
+	if math.IsNaN(v) {
+		return 0
+	}
+	return byte(math.Round(math.Max(0, math.Min(1, v)) * 255))
+}
+
+// normalizeHue returns a hue in [0, 360).
+func normalizeHue(h float64) float64 {
+	h = math.Mod(h, 360)
+	if h < 0 {
+		h += 360
+	}
+	return h
+}
+
+// hueOf returns the hue of rgb components of which max is the largest,
+// d being the difference between the largest and smallest.
+func hueOf(r, g, b, max, d float64) float64 {
+	var h float64
+	switch max {
+	case r:
+		h = (g - b) / d
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+	return normalizeHue(h * 60)
+}
+
+// Hsl returns the hue, saturation and lightness of a color.
+func Hsl(c color.NRGBA) (h, s, l float64) {
+	r, g, b, _ := rgbaF(c)
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	l = (max + min) / 2
+	d := max - min
+	if d == 0 {
+		return 0, 0, l
+	}
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+	return hueOf(r, g, b, max, d), s, l
+}
+
+// Hsv returns the hue, saturation and value of a color.
+func Hsv(c color.NRGBA) (h, s, v float64) {
+	r, g, b, _ := rgbaF(c)
+	max, min := math.Max(r, math.Max(g, b)), math.Min(r, math.Min(g, b))
+	d := max - min
+	if d == 0 {
+		return 0, 0, max
+	}
+	return hueOf(r, g, b, max, d), d / max, max
+}
+
+// Lab returns the CIE lightness, from 0 to 100, and the a and b axes of a color.
+func Lab(c color.NRGBA) (l, a, b float64) {
+	r, g, bl, _ := rgbaF(c)
+	x, y, z := mul3(&linearSrgbToXyzD50, toLinear(r), toLinear(g), toLinear(bl))
+	return xyzToLab(x, y, z)
+}
+
+// FromHsl returns the color of a hue, saturation, lightness and alpha,
+// the saturation and lightness being clamped.
+func FromHsl(h, s, l float64, a byte) color.NRGBA {
+	r, g, b := hslToRgb(h, s, l)
+	return colorOfF(r, g, b, float64(a)/255)
+}
+
+// FromHsv returns the color of a hue, saturation, value and alpha,
+// the saturation and value being clamped.
+func FromHsv(h, s, v float64, a byte) color.NRGBA {
+	s, v = clampUnit(s), clampUnit(v)
+	f := func(n float64) float64 {
+		k := math.Mod(n+normalizeHue(h)/60, 6)
+		return v - v*s*math.Max(0, math.Min(k, math.Min(4-k, 1)))
+	}
+	return colorOfF(f(5), f(3), f(1), float64(a)/255)
+}
+
+// FromLab returns the color of a CIE lightness, a and b axes and alpha,
+// clamped to the sRGB gamut.
+func FromLab(l, a, b float64, alpha byte) color.NRGBA {
+	x, y, z := labToXyz(l, a, b)
+	r, g, bl := mul3(&xyzD50ToLinearSrgb, x, y, z)
+	return colorOfF(fromLinear(r), fromLinear(g), fromLinear(bl), float64(alpha)/255)
+}
+
+func clampUnit(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func hslToRgb(h, s, l float64) (r, g, b float64) {
+	s, l = clampUnit(s), clampUnit(l)
+	h = normalizeHue(h)
+	f := func(n float64) float64 {
+		k := math.Mod(n+h/30, 12)
+		a := s * math.Min(l, 1-l)
+		return l - a*math.Max(-1, math.Min(k-3, math.Min(9-k, 1)))
+	}
+	return f(0), f(8), f(4)
+}
+
+// hwbToRgb converts hue, whiteness and blackness, as CSS hwb().
+func hwbToRgb(h, w, bk float64) (r, g, b float64) {
+	w, bk = clampUnit(w), clampUnit(bk)
+	if w+bk >= 1 {
+		gray := w / (w + bk)
+		return gray, gray, gray
+	}
+	r, g, b = hslToRgb(h, 1, 0.5)
+	k := 1 - w - bk
+	return r*k + w, g*k + w, b*k + w
+}
+
+// toLinear converts an sRGB component to linear light.
+func toLinear(c float64) float64 {
+	if math.Abs(c) <= 0.04045 {
+		return c / 12.92
+	}
+	return math.Copysign(math.Pow((math.Abs(c)+0.055)/1.055, 2.4), c)
+}
+
+// fromLinear converts a linear light component to sRGB.
+func fromLinear(c float64) float64 {
+	if math.Abs(c) <= 0.0031308 {
+		return c * 12.92
+	}
+	return math.Copysign(1.055*math.Pow(math.Abs(c), 1/2.4)-0.055, c)
+}
+
+// The matrices of CSS Color 4, the D50 ones including the Bradford chromatic adaptation.
+var (
+	xyzD65ToLinearSrgb = [3][3]float64{
+		{3.2409699419045226, -1.537383177570094, -0.4986107602930034},
+		{-0.9692436362808796, 1.8759675015077202, 0.04155505740717559},
+		{0.05563007969699366, -0.20397695888897652, 1.0569715142428786},
+	}
+	linearSrgbToXyzD50 = [3][3]float64{
+		{0.436065742824811, 0.3851514688337912, 0.14307845442264197},
+		{0.22249319175623702, 0.7168870538238823, 0.06061979053616537},
+		{0.013923904500943465, 0.09708128566574634, 0.7140993584005155},
+	}
+	xyzD50ToLinearSrgb = [3][3]float64{
+		{3.1341359569958707, -1.6173863321612538, -0.4906619460083532},
+		{-0.978795502912089, 1.916254567259524, 0.03344273116131949},
+		{0.07195537988411677, -0.2289768264158322, 1.405386058324125},
+	}
+	d50White = [3]float64{0.3457 / 0.3585, 1, (1 - 0.3457 - 0.3585) / 0.3585}
+)
+
+func mul3(m *[3][3]float64, x, y, z float64) (float64, float64, float64) {
+	return m[0][0]*x + m[0][1]*y + m[0][2]*z,
+		m[1][0]*x + m[1][1]*y + m[1][2]*z,
+		m[2][0]*x + m[2][1]*y + m[2][2]*z
+}
+
+const (
+	labEpsilon = 216.0 / 24389
+	labKappa   = 24389.0 / 27
+)
+
+func xyzToLab(x, y, z float64) (l, a, b float64) {
+	f := func(t float64) float64 {
+		if t > labEpsilon {
+			return math.Cbrt(t)
+		}
+		return (labKappa*t + 16) / 116
+	}
+	fx, fy, fz := f(x/d50White[0]), f(y/d50White[1]), f(z/d50White[2])
+	return 116*fy - 16, 500 * (fx - fy), 200 * (fy - fz)
+}
+
+func labToXyz(l, a, b float64) (x, y, z float64) {
+	fy := (l + 16) / 116
+	fx := fy + a/500
+	fz := fy - b/200
+	f := func(t float64) float64 {
+		if t*t*t > labEpsilon {
+			return t * t * t
+		}
+		return (116*t - 16) / labKappa
+	}
+	y = l / labKappa
+	if l > labKappa*labEpsilon {
+		y = fy * fy * fy
+	}
+	return f(fx) * d50White[0], y * d50White[1], f(fz) * d50White[2]
+}
+
+// oklabToLinearSrgb converts Oklab to linear sRGB, as CSS oklab().
+func oklabToLinearSrgb(l, a, b float64) (float64, float64, float64) {
+	l1 := l + 0.3963377774*a + 0.2158037573*b
+	m1 := l - 0.1055613458*a - 0.0638541728*b
+	s1 := l - 0.0894841775*a - 1.2914855480*b
+	l3, m3, s3 := l1*l1*l1, m1*m1*m1, s1*s1*s1
+	return 4.0767416621*l3 - 3.3077115913*m3 + 0.2309699292*s3,
+		-1.2684380046*l3 + 2.6097574011*m3 - 0.3413193965*s3,
+		-0.0041960863*l3 - 0.7034186147*m3 + 1.7076147010*s3
+}
+
+// Luminance returns the relative luminance of a color as defined by WCAG,
+// from 0 for black to 1 for white.
+func Luminance(c color.NRGBA) float64 {
+	r, g, b, _ := rgbaF(c)
+	return 0.2126*toLinear(r) + 0.7152*toLinear(g) + 0.0722*toLinear(b)
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 to 21,
+// ignoring their alpha. Normal text needs at least 4.5, and large text 3.
+func ContrastRatio(c1, c2 color.NRGBA) float64 {
+	l1, l2 := Luminance(c1), Luminance(c2)
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// Blend returns the color at t between c1, at 0, and c2, at 1,
+// interpolated with premultiplied alpha.
+func Blend(c1, c2 color.NRGBA, t float64) color.NRGBA {
+	r1, g1, b1, a1 := rgbaF(c1)
+	r2, g2, b2, a2 := rgbaF(c2)
+	a := a1 + (a2-a1)*t
+	if a <= 0 {
+		return color.NRGBA{}
+	}
+	lerp := func(c1, c2 float64) float64 {
+		return (c1*a1 + (c2*a2-c1*a1)*t) / a
+	}
+	return colorOfF(lerp(r1, r2), lerp(g1, g2), lerp(b1, b2), a)
+}
+
+// Over returns a color composited over a backdrop, with the source-over operator.
+func Over(c, backdrop color.NRGBA) color.NRGBA {
+	rs, gs, bs, as := rgbaF(c)
+	rb, gb, bb, ab := rgbaF(backdrop)
+	a := as + ab*(1-as)
+	if a <= 0 {
+		return color.NRGBA{}
+	}
+	over := func(cs, cb float64) float64 {
+		return (cs*as + cb*ab*(1-as)) / a
+	}
+	return colorOfF(over(rs, rb), over(gs, gb), over(bs, bb), a)
+}
+
+// Hex returns a color as #rrggbb, or #rrggbbaa if it isn't opaque.
+func Hex(c color.NRGBA) string {
+	if c.A != 255 {
+		return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
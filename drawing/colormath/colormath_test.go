@@ -0,0 +1,111 @@
+package colormath
+
+import (
+	"image/color"
+	"math"
+	"testing"
+)
+
+func near(a, b, epsilon float64) bool {
+	return math.Abs(a-b) < epsilon
+}
+
+var (
+	black = color.NRGBA{0, 0, 0, 255}
+	white = color.NRGBA{255, 255, 255, 255}
+)
+
+func TestContrastRatio(t *testing.T) {
+	tests := []struct {
+		c1, c2 color.NRGBA
+		want   float64
+	}{
+		{black, white, 21},
+		{white, black, 21},
+		{white, white, 1},
+		{color.NRGBA{0x76, 0x76, 0x76, 255}, white, 4.54},
+		{color.NRGBA{0, 0, 255, 255}, white, 8.59},
+	}
+	for _, test := range tests {
+		if got := ContrastRatio(test.c1, test.c2); !near(got, test.want, 0.01) {
+			t.Errorf("ContrastRatio(%v, %v) = %v, want %v", test.c1, test.c2, got, test.want)
+		}
+	}
+}
+
+func TestHslHsv(t *testing.T) {
+	tests := []struct {
+		c       color.NRGBA
+		h, s, l float64
+		vs, v   float64
+	}{
+		{color.NRGBA{255, 0, 0, 255}, 0, 1, 0.5, 1, 1},
+		{color.NRGBA{0, 255, 0, 255}, 120, 1, 0.5, 1, 1},
+		{color.NRGBA{0, 0, 128, 255}, 240, 1, 0.25, 1, 128.0 / 255},
+		{color.NRGBA{255, 128, 128, 255}, 0, 1, 0.751, 0.498, 1},
+		{white, 0, 0, 1, 0, 1},
+	}
+	for _, test := range tests {
+		h, s, l := Hsl(test.c)
+		if !near(h, test.h, 0.5) || !near(s, test.s, 0.01) || !near(l, test.l, 0.01) {
+			t.Errorf("Hsl(%v) = %v, %v, %v, want %v, %v, %v", test.c, h, s, l, test.h, test.s, test.l)
+		}
+		if got := FromHsl(h, s, l, 255); got != test.c {
+			t.Errorf("FromHsl(Hsl(%v)) = %v", test.c, got)
+		}
+		h, s, v := Hsv(test.c)
+		if !near(h, test.h, 0.5) || !near(s, test.vs, 0.01) || !near(v, test.v, 0.01) {
+			t.Errorf("Hsv(%v) = %v, %v, %v, want %v, %v, %v", test.c, h, s, v, test.h, test.vs, test.v)
+		}
+		if got := FromHsv(h, s, v, 255); got != test.c {
+			t.Errorf("FromHsv(Hsv(%v)) = %v", test.c, got)
+		}
+	}
+}
+
+func TestLab(t *testing.T) {
+	tests := []struct {
+		c       color.NRGBA
+		l, a, b float64
+	}{
+		{white, 100, 0, 0},
+		{black, 0, 0, 0},
+		{color.NRGBA{255, 0, 0, 255}, 54.29, 80.8, 69.89},
+		{color.NRGBA{0, 0, 255, 255}, 29.57, 68.3, -112.03},
+	}
+	for _, test := range tests {
+		l, a, b := Lab(test.c)
+		if !near(l, test.l, 0.05) || !near(a, test.a, 0.05) || !near(b, test.b, 0.05) {
+			t.Errorf("Lab(%v) = %v, %v, %v, want %v, %v, %v", test.c, l, a, b, test.l, test.a, test.b)
+		}
+		if got := FromLab(l, a, b, 255); got != test.c {
+			t.Errorf("FromLab(Lab(%v)) = %v", test.c, got)
+		}
+	}
+}
+
+func TestBlendAndOver(t *testing.T) {
+	red := color.NRGBA{255, 0, 0, 255}
+	if got := Blend(red, white, 0.5); got != (color.NRGBA{255, 128, 128, 255}) {
+		t.Errorf("Blend(red, white, 0.5) = %v", got)
+	}
+	//transparent colors don't tint the blend
+	if got := Blend(red, color.NRGBA{0, 0, 255, 0}, 0.5); got != (color.NRGBA{255, 0, 0, 128}) {
+		t.Errorf("Blend(red, transparent blue, 0.5) = %v", got)
+	}
+	if got := Over(color.NRGBA{0, 0, 0, 128}, white); got != (color.NRGBA{127, 127, 127, 255}) {
+		t.Errorf("Over(half black, white) = %v", got)
+	}
+	if got := Over(color.NRGBA{}, color.NRGBA{}); got != (color.NRGBA{}) {
+		t.Errorf("Over(transparent, transparent) = %v", got)
+	}
+}
+
+func TestHex(t *testing.T) {
+	if got := Hex(color.NRGBA{0x1a, 0x2b, 0x3c, 0xff}); got != "#1a2b3c" {
+		t.Errorf("Hex = %q, want #1a2b3c", got)
+	}
+	if got := Hex(color.NRGBA{0x1a, 0x2b, 0x3c, 0x80}); got != "#1a2b3c80" {
+		t.Errorf("Hex = %q, want #1a2b3c80", got)
+	}
+}
@@ -0,0 +1,246 @@
+package colormath
+
+import (
+	"fmt"
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// cssNames maps the CSS keywords that aren't Windows color names to Windows color names,
+// the system colors of CSS being mapped to those of Windows.
+var cssNames = map[string]string{
+	"darkgrey":          "darkgray",
+	"darkslategrey":     "darkslategray",
+	"dimgrey":           "dimgray",
+	"grey":              "gray",
+	"lightgrey":         "lightgray",
+	"lightslategrey":    "lightslategray",
+	"slategrey":         "slategray",
+	"canvas":            "window",
+	"canvastext":        "windowtext",
+	"linktext":          "hottrack",
+	"visitedtext":       "hottrack",
+	"activetext":        "hottrack",
+	"buttonborder":      "controldark",
+	"buttontext":        "controltext",
+	"field":             "window",
+	"fieldtext":         "windowtext",
+	"selecteditem":      "highlight",
+	"selecteditemtext":  "highlighttext",
+	"accentcolor":       "highlight",
+	"accentcolortext":   "highlighttext",
+	"background":        "desktop",
+	"captiontext":       "activecaptiontext",
+	"infobackground":    "info",
+	"threeddarkshadow":  "controldarkdark",
+	"threedface":        "control",
+	"threedhighlight":   "controllightlight",
+	"threedlightshadow": "controllight",
+	"threedshadow":      "controldark",
+}
+
+// Parse parses a color in any CSS syntax: hex notations, the rgb, rgba, hsl, hsla,
+// hwb, lab, lch, oklab, oklch and color functions, with the legacy comma syntax or not,
+// and keywords. Colors outside of the sRGB gamut are clamped.
+//
+// Keywords are returned as name, for the caller to resolve as named or system colors:
+// in lower case, CSS names that Windows spells differently being replaced by
+// the Windows ones, e.g. grey by gray and buttonborder by controldark.
+func Parse(s string) (c color.NRGBA, name string, err error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	if strings.HasPrefix(text, "#") {
+		if c, ok := parseHex(text[1:]); ok {
+			return c, "", nil
+		}
+	} else if fn, args, ok := strings.Cut(text, "("); ok {
+		if args, ok := strings.CutSuffix(args, ")"); ok {
+			if c, ok := parseFunction(strings.TrimSpace(fn), args); ok {
+				return c, "", nil
+			}
+		}
+	} else if text == "rebeccapurple" {
+		return color.NRGBA{0x66, 0x33, 0x99, 0xff}, "", nil
+	} else if isKeyword(text) {
+		if name, ok := cssNames[text]; ok {
+			return color.NRGBA{}, name, nil
+		}
+		return color.NRGBA{}, text, nil
+	}
+	return color.NRGBA{}, "", fmt.Errorf("invalid color %q", s)
+}
+
+// isKeyword tells whether text is made of letters.
+func isKeyword(text string) bool {
+	for _, c := range text {
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return text != ""
+}
+
+// parseHex parses the digits of #rgb, #rgba, #rrggbb and #rrggbbaa.
+func parseHex(digits string) (color.NRGBA, bool) {
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	switch len(digits) {
+	case 3:
+		v = v<<4 | 0xf
+		fallthrough
+	case 4:
+		//each digit is repeated
+		r, g, b, a := byte(v>>12&0xf), byte(v>>8&0xf), byte(v>>4&0xf), byte(v&0xf)
+		return color.NRGBA{r * 0x11, g * 0x11, b * 0x11, a * 0x11}, true
+	case 6:
+		v = v<<8 | 0xff
+		fallthrough
+	case 8:
+		return color.NRGBA{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}, true
+	}
+	return color.NRGBA{}, false
+}
+
+// arg is an argument of a color function.
+type arg struct {
+	value   float64
+	percent bool
+}
+
+// number returns the argument as a number, percentages being of reference.
+func (me arg) number(reference float64) float64 {
+	if me.percent {
+		return me.value * reference / 100
+	}
+	return me.value
+}
+
+// parseArgs parses the arguments of a color function, with commas
+// or with whitespace and a slash before the alpha. It returns the components
+// and the alpha, 1 if omitted.
+func parseArgs(args string) ([]arg, float64, bool) {
+	var fields []string
+	alpha := "1"
+	if strings.Contains(args, ",") {
+		fields = strings.Split(args, ",")
+		for n := range fields {
+			fields[n] = strings.TrimSpace(fields[n])
+		}
+		if len(fields) == 4 {
+			alpha, fields = fields[3], fields[:3]
+		}
+	} else {
+		components, a, hasAlpha := strings.Cut(args, "/")
+		fields = strings.Fields(components)
+		if hasAlpha {
+			alpha = strings.TrimSpace(a)
+		}
+	}
+	if len(fields) != 3 {
+		return nil, 0, false
+	}
+	values := make([]arg, 3)
+	for n, field := range fields {
+		value, ok := parseArg(field)
+		if !ok {
+			return nil, 0, false
+		}
+		values[n] = value
+	}
+	a, ok := parseArg(alpha)
+	if !ok {
+		return nil, 0, false
+	}
+	return values, a.number(1), true
+}
+
+// angleUnits are the angle units with their lengths in degrees, grad before rad.
+var angleUnits = []struct {
+	unit    string
+	degrees float64
+}{
+	{"deg", 1},
+	{"grad", 0.9},
+	{"rad", 180 / math.Pi},
+	{"turn", 360},
+}
+
+// parseArg parses a number, a percentage, an angle in degrees or none, as 0.
+func parseArg(field string) (arg, bool) {
+	if field == "none" {
+		return arg{}, true
+	}
+	if number, ok := strings.CutSuffix(field, "%"); ok {
+		value, err := strconv.ParseFloat(number, 64)
+		return arg{value, true}, err == nil
+	}
+	for _, angle := range angleUnits {
+		if number, ok := strings.CutSuffix(field, angle.unit); ok {
+			value, err := strconv.ParseFloat(number, 64)
+			return arg{value * angle.degrees, false}, err == nil
+		}
+	}
+	value, err := strconv.ParseFloat(field, 64)
+	return arg{value, false}, err == nil
+}
+
+// parseFunction parses the arguments of a color function.
+func parseFunction(name, args string) (color.NRGBA, bool) {
+	if name == "color" {
+		return parseColorSpace(args)
+	}
+	values, a, ok := parseArgs(args)
+	if !ok {
+		return color.NRGBA{}, false
+	}
+	v0, v1, v2 := values[0], values[1], values[2]
+	switch name {
+	case "rgb", "rgba":
+		return colorOfF(v0.number(255)/255, v1.number(255)/255, v2.number(255)/255, a), true
+	case "hsl", "hsla":
+		r, g, b := hslToRgb(v0.value, v1.number(100)/100, v2.number(100)/100)
+		return colorOfF(r, g, b, a), true
+	case "hwb":
+		r, g, b := hwbToRgb(v0.value, v1.number(100)/100, v2.number(100)/100)
+		return colorOfF(r, g, b, a), true
+	case "lab":
+		return FromLab(v0.number(100), v1.number(125), v2.number(125), unitByte(a)), true
+	case "lch":
+		c, h := v1.number(150), v2.value*math.Pi/180
+		return FromLab(v0.number(100), c*math.Cos(h), c*math.Sin(h), unitByte(a)), true
+	case "oklab":
+		r, g, b := oklabToLinearSrgb(v0.number(1), v1.number(0.4), v2.number(0.4))
+		return colorOfF(fromLinear(r), fromLinear(g), fromLinear(b), a), true
+	case "oklch":
+		c, h := v1.number(0.4), v2.value*math.Pi/180
+		r, g, b := oklabToLinearSrgb(v0.number(1), c*math.Cos(h), c*math.Sin(h))
+		return colorOfF(fromLinear(r), fromLinear(g), fromLinear(b), a), true
+	}
+	return color.NRGBA{}, false
+}
+
+// parseColorSpace parses the arguments of the color function in the sRGB and XYZ spaces.
+func parseColorSpace(args string) (color.NRGBA, bool) {
+	space, args, _ := strings.Cut(strings.TrimSpace(args), " ")
+	values, a, ok := parseArgs(args)
+	if !ok || strings.Contains(args, ",") {
+		return color.NRGBA{}, false
+	}
+	v0, v1, v2 := values[0].number(1), values[1].number(1), values[2].number(1)
+	switch space {
+	case "srgb":
+		return colorOfF(v0, v1, v2, a), true
+	case "srgb-linear":
+		return colorOfF(fromLinear(v0), fromLinear(v1), fromLinear(v2), a), true
+	case "xyz", "xyz-d65":
+		r, g, b := mul3(&xyzD65ToLinearSrgb, v0, v1, v2)
+		return colorOfF(fromLinear(r), fromLinear(g), fromLinear(b), a), true
+	case "xyz-d50":
+		r, g, b := mul3(&xyzD50ToLinearSrgb, v0, v1, v2)
+		return colorOfF(fromLinear(r), fromLinear(g), fromLinear(b), a), true
+	}
+	return color.NRGBA{}, false
+}
@@ -0,0 +1,84 @@
+package colormath
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.NRGBA
+	}{
+		{"#f80", color.NRGBA{0xff, 0x88, 0x00, 0xff}},
+		{"#f808", color.NRGBA{0xff, 0x88, 0x00, 0x88}},
+		{"#1a2B3c", color.NRGBA{0x1a, 0x2b, 0x3c, 0xff}},
+		{"  #1A2B3C80 ", color.NRGBA{0x1a, 0x2b, 0x3c, 0x80}},
+		{"rgb(255, 128, 0)", color.NRGBA{255, 128, 0, 255}},
+		{"rgba(255, 128, 0, 0.5)", color.NRGBA{255, 128, 0, 128}},
+		{"rgb(100% 50% 0% / 25%)", color.NRGBA{255, 128, 0, 64}},
+		{"RGB(10 20 30)", color.NRGBA{10, 20, 30, 255}},
+		{"rgb(300 -20 none)", color.NRGBA{255, 0, 0, 255}},
+		{"hsl(120, 100%, 50%)", color.NRGBA{0, 255, 0, 255}},
+		{"hsl(0.5turn 100% 25%)", color.NRGBA{0, 128, 128, 255}},
+		{"hsla(240deg, 100%, 50%, 0.5)", color.NRGBA{0, 0, 255, 128}},
+		{"hsl(-120 100% 50%)", color.NRGBA{0, 0, 255, 255}},
+		{"hwb(0 0% 0%)", color.NRGBA{255, 0, 0, 255}},
+		{"hwb(90 60% 60%)", color.NRGBA{128, 128, 128, 255}},
+		{"lab(54.29 80.8 69.89)", color.NRGBA{255, 0, 0, 255}},
+		{"lab(100% 0 0)", color.NRGBA{255, 255, 255, 255}},
+		{"lch(54.29 106.84 40.85)", color.NRGBA{255, 0, 0, 255}},
+		{"oklab(0.628 0.2249 0.1258)", color.NRGBA{255, 0, 0, 255}},
+		{"oklch(0.628 0.2577 29.23)", color.NRGBA{255, 0, 0, 255}},
+		{"oklch(100% 0 0 / 0.5)", color.NRGBA{255, 255, 255, 128}},
+		{"color(srgb 1 0.5 0)", color.NRGBA{255, 128, 0, 255}},
+		{"color(srgb-linear 0.2158 0.2158 0.2158)", color.NRGBA{128, 128, 128, 255}},
+		{"color(xyz-d65 0.9505 1 1.089)", color.NRGBA{255, 255, 255, 255}},
+		{"color(xyz 0.4124 0.2126 0.0193)", color.NRGBA{255, 0, 0, 255}},
+		{"color(xyz-d50 0.9642 1 0.8251 / 50%)", color.NRGBA{255, 255, 255, 128}},
+		{"rebeccapurple", color.NRGBA{0x66, 0x33, 0x99, 0xff}},
+	}
+	for _, test := range tests {
+		got, name, err := Parse(test.in)
+		if err != nil || name != "" || got != test.want {
+			t.Errorf("Parse(%q) = %v, %q, %v, want %v", test.in, got, name, err, test.want)
+		}
+	}
+}
+
+func TestParseKeywords(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"red", "red"},
+		{"DarkGrey", "darkgray"},
+		{"grey", "gray"},
+		{"lightslategrey", "lightslategray"},
+		{"Canvas", "window"},
+		{"ButtonText", "controltext"},
+		{"buttonborder", "controldark"},
+		{"LinkText", "hottrack"},
+		{"background", "desktop"},
+		{"threedhighlight", "controllightlight"},
+		{"windowtext", "windowtext"},
+	}
+	for _, test := range tests {
+		c, name, err := Parse(test.in)
+		if err != nil || name != test.want || c != (color.NRGBA{}) {
+			t.Errorf("Parse(%q) = %v, %q, %v, want name %q", test.in, c, name, err, test.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", "#", "#12", "#12345", "#1234567", "#ggg",
+		"rgb(1, 2)", "rgb(1 2 3 4)", "rgb(1, 2, 3", "rgb(a b c)",
+		"foo(1 2 3)", "color(srgb 1, 0, 0)", "color(p3 1 0 0)",
+		"light-blue", "red1",
+	} {
+		if c, name, err := Parse(in); err == nil {
+			t.Errorf("Parse(%q) = %v, %q, want an error", in, c, name)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package drawing
+
+import (
+	"fmt"
+
+	"github.com/zzl/goforms/drawing/colormath"
+)
+
+// ParseColor parses a color in any CSS syntax, as colormath.Parse: hex notations,
+// the rgb, rgba, hsl, hsla, hwb, lab, lch, oklab, oklch and color functions,
+// named colors and system colors, by their CSS or Windows names.
+// Colors outside of the sRGB gamut are clamped, and named colors keep their ids.
+func ParseColor(s string) (Color, error) {
+	c, name, err := colormath.Parse(s)
+	if err != nil {
+		return Color{}, err
+	}
+	if name == "" {
+		return colorOfNRGBA(c), nil
+	}
+	if id, ok := namedColorIds[name]; ok {
+		return ColorOfId(id), nil
+	}
+	return Color{}, fmt.Errorf("invalid color %q", s)
+}
@@ -0,0 +1,107 @@
+package drawing
+
+import (
+	"image/color"
+
+	"github.com/zzl/goforms/drawing/colormath"
+)
+
+// Conversions between color spaces, and color math, by the colormath package.
+// Everything works on the ARGB values of colors, without GDI+, and returns custom colors.
+// Hues are in degrees, and saturation, lightness and value from 0 to 1.
+// Lab is CIE L*a*b* relative to the D50 white, as in CSS.
+
+func (this *Color) nrgba() color.NRGBA {
+	argb := this.Argb()
+	return color.NRGBA{byte(argb >> 16), byte(argb >> 8), byte(argb), byte(argb >> 24)}
+}
+
+// Hsl returns the hue, saturation and lightness of the color.
+func (this *Color) Hsl() (h, s, l float64) {
+	return colormath.Hsl(this.nrgba())
+}
+
+// Hsv returns the hue, saturation and value of the color.
+func (this *Color) Hsv() (h, s, v float64) {
+	return colormath.Hsv(this.nrgba())
+}
+
+// Lab returns the CIE lightness, from 0 to 100, and the a and b axes of the color.
+func (this *Color) Lab() (l, a, b float64) {
+	return colormath.Lab(this.nrgba())
+}
+
+func Hsl(h, s, l float64) Color {
+	return Hsla(h, s, l, 255)
+}
+
+// Hsla returns the color of a hue, saturation, lightness and alpha,
+// the saturation and lightness being clamped.
+func Hsla(h, s, l float64, a byte) Color {
+	return colorOfNRGBA(colormath.FromHsl(h, s, l, a))
+}
+
+func Hsv(h, s, v float64) Color {
+	return Hsva(h, s, v, 255)
+}
+
+// Hsva returns the color of a hue, saturation, value and alpha,
+// the saturation and value being clamped.
+func Hsva(h, s, v float64, a byte) Color {
+	return colorOfNRGBA(colormath.FromHsv(h, s, v, a))
+}
+
+func Lab(l, a, b float64) Color {
+	return Laba(l, a, b, 255)
+}
+
+// Laba returns the color of a CIE lightness, a and b axes and alpha,
+// clamped to the sRGB gamut.
+func Laba(l, a, b float64, alpha byte) Color {
+	return colorOfNRGBA(colormath.FromLab(l, a, b, alpha))
+}
+
+// Luminance returns the relative luminance of the color as defined by WCAG,
+// from 0 for black to 1 for white.
+func (this *Color) Luminance() float64 {
+	return colormath.Luminance(this.nrgba())
+}
+
+// ContrastRatio returns the WCAG contrast ratio of two colors, from 1 to 21,
+// ignoring their alpha. Normal text needs at least 4.5, and large text 3.
+func ContrastRatio(c1, c2 Color) float64 {
+	return colormath.ContrastRatio(c1.nrgba(), c2.nrgba())
+}
+
+// Blend returns the color at t between the color, at 0, and another, at 1,
+// interpolated with premultiplied alpha.
+func (this *Color) Blend(other Color, t float64) Color {
+	return colorOfNRGBA(colormath.Blend(this.nrgba(), other.nrgba(), t))
+}
+
+// Over returns the color composited over a backdrop, with the source-over operator.
+func (this *Color) Over(backdrop Color) Color {
+	return colorOfNRGBA(colormath.Over(this.nrgba(), backdrop.nrgba()))
+}
+
+// Lighten returns the color with its HSL lightness increased by amount, from 0 to 1.
+func (this *Color) Lighten(amount float64) Color {
+	h, s, l := this.Hsl()
+	return Hsla(h, s, l+amount, this.A())
+}
+
+// Darken returns the color with its HSL lightness decreased by amount, from 0 to 1.
+func (this *Color) Darken(amount float64) Color {
+	return this.Lighten(-amount)
+}
+
+// WithAlpha returns the color with another alpha.
+func (this *Color) WithAlpha(a byte) Color {
+	r, g, b := this.Rgb()
+	return Rgba(r, g, b, a)
+}
+
+// Hex returns the color as #rrggbb, or #rrggbbaa if it isn't opaque.
+func (this *Color) Hex() string {
+	return colormath.Hex(this.nrgba())
+}
@@ -0,0 +1,108 @@
+package displaylist
+
+import (
+	"bytes"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// maxDiffCells bounds the work of matching the differing middles of two lists.
+// Beyond it, the middles are considered entirely different.
+const maxDiffCells = 1 << 20
+
+// Diff is the difference between two lists.
+type Diff struct {
+	Removed []int // indexes of the commands of the old list not in the new one
+	Added   []int // indexes of the commands of the new list not in the old one
+
+	// Bounds is the union of the bounds of the removed and added drawing commands,
+	// the area to repaint to go from the old list to the new one.
+	Bounds geom.Rect
+}
+
+// IsEmpty tells whether the lists are the same.
+func (this *Diff) IsEmpty() bool {
+	return len(this.Removed) == 0 && len(this.Added) == 0
+}
+
+// DiffLists compares two lists, matching their commands by their JSON encoding.
+// As drawing commands include their device bounds, a change of transform or clip
+// shows in the drawing commands it affects.
+func DiffLists(old, new *List) *Diff {
+	oldKeys, newKeys := commandKeys(old), commandKeys(new)
+	diff := &Diff{}
+	start := 0
+	for start < len(oldKeys) && start < len(newKeys) &&
+		bytes.Equal(oldKeys[start], newKeys[start]) {
+		start++
+	}
+	oldEnd, newEnd := len(oldKeys), len(newKeys)
+	for oldEnd > start && newEnd > start && bytes.Equal(oldKeys[oldEnd-1], newKeys[newEnd-1]) {
+		oldEnd--
+		newEnd--
+	}
+	oldKept, newKept := matchCommands(oldKeys[start:oldEnd], newKeys[start:newEnd])
+	for n := start; n < oldEnd; n++ {
+		if !oldKept[n-start] {
+			diff.Removed = append(diff.Removed, n)
+			diff.Bounds = diff.Bounds.Union(old.Commands[n].Bounds)
+		}
+	}
+	for n := start; n < newEnd; n++ {
+		if !newKept[n-start] {
+			diff.Added = append(diff.Added, n)
+			diff.Bounds = diff.Bounds.Union(new.Commands[n].Bounds)
+		}
+	}
+	return diff
+}
+
+// commandKeys returns the JSON encodings of the commands of a list.
+// Commands that fail to encode get unique keys.
+func commandKeys(list *List) [][]byte {
+	keys := make([][]byte, len(list.Commands))
+	for n, cmd := range list.Commands {
+		key, err := cmd.MarshalJSON()
+		if err != nil {
+			key = nil
+		}
+		keys[n] = key
+	}
+	return keys
+}
+
+// matchCommands finds a longest common subsequence of two key lists,
+// returning which keys of each belong to it.
+func matchCommands(a, b [][]byte) (aKept, bKept []bool) {
+	aKept, bKept = make([]bool, len(a)), make([]bool, len(b))
+	if len(a) == 0 || len(b) == 0 || len(a)*len(b) > maxDiffCells {
+		return
+	}
+	equal := func(i, j int) bool {
+		return a[i] != nil && b[j] != nil && bytes.Equal(a[i], b[j])
+	}
+	//lengths[i][j] is the length of the LCS of a[i:] and b[j:]
+	width := len(b) + 1
+	lengths := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if equal(i, j) {
+				lengths[i*width+j] = lengths[(i+1)*width+j+1] + 1
+			} else {
+				lengths[i*width+j] = max(lengths[(i+1)*width+j], lengths[i*width+j+1])
+			}
+		}
+	}
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		if equal(i, j) {
+			aKept[i], bKept[j] = true, true
+			i++
+			j++
+		} else if lengths[(i+1)*width+j] >= lengths[i*width+j+1] {
+			i++
+		} else {
+			j++
+		}
+	}
+	return
+}
@@ -0,0 +1,149 @@
+// Package displaylist records drawing into lists of commands
+// that can be replayed onto any canvas.Canvas, serialized, compared and culled.
+//
+// A Recorder is a canvas.Canvas, so painting code written against canvas.Canvas
+// records unchanged. Replaying onto a drawing.GraphicsCanvas paints with GDI+,
+// e.g. to cache static backgrounds; replaying onto a raster.Canvas renders without Windows.
+package displaylist
+
+import (
+	"image"
+	"image/color"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// Op is the kind of a command.
+type Op int
+
+const (
+	OpSave         Op = iota + 1 //Canvas.Save
+	OpRestore                    //Canvas.Restore
+	OpSetTransform               //Canvas.SetTransform with Transform
+	OpClipRect                   //Canvas.ClipRect with Rect
+	OpClipPath                   //Canvas.ClipPath with Path
+	OpClear                      //Canvas.Clear with Color
+	OpFillPath                   //Canvas.FillPath with Path and Brush
+	OpStrokePath                 //Canvas.StrokePath with Path and Pen
+	OpDrawImage                  //Canvas.DrawImage with Image, Src and Rect
+	OpDrawString                 //Canvas.DrawString with Text, Font, Brush, Rect and Format
+)
+
+// Command is a recorded canvas call. Only the fields used by its Op are set.
+type Command struct {
+	Op        Op
+	Transform geom.Affine
+	Rect      geom.Rect
+	Path      *geom.Path
+	Color     color.NRGBA
+	Brush     canvas.Brush
+	Pen       *canvas.Pen
+	Image     image.Image
+	Src       image.Rectangle
+	Text      string
+	Font      *canvas.Font
+	Format    *canvas.StringFormat
+
+	// Bounds is the area the command may paint in device coordinates
+	// of the recording, within the clip. It is empty for state commands.
+	Bounds geom.Rect
+}
+
+// IsDrawing tells whether the command paints, as opposed to changing the state.
+func (this *Command) IsDrawing() bool {
+	return this.Op >= OpClear
+}
+
+// Replay performs the command on a canvas.
+// The transforms of SetTransform commands are relative to base.
+func (this *Command) Replay(c canvas.Canvas, base geom.Affine) {
+	switch this.Op {
+	case OpSave:
+		c.Save()
+	case OpRestore:
+		c.Restore()
+	case OpSetTransform:
+		c.SetTransform(this.Transform.Multiply(base))
+	case OpClipRect:
+		c.ClipRect(this.Rect)
+	case OpClipPath:
+		c.ClipPath(this.Path)
+	case OpClear:
+		c.Clear(this.Color)
+	case OpFillPath:
+		c.FillPath(this.Path, this.Brush)
+	case OpStrokePath:
+		c.StrokePath(this.Path, this.Pen)
+	case OpDrawImage:
+		c.DrawImage(this.Image, this.Src, this.Rect)
+	case OpDrawString:
+		c.DrawString(this.Text, this.Font, this.Brush, this.Rect, this.Format)
+	}
+}
+
+// List is a list of recorded commands.
+type List struct {
+	Commands []Command
+}
+
+// Len returns the number of commands.
+func (this *List) Len() int {
+	return len(this.Commands)
+}
+
+// Replay performs the commands on a canvas, relative to its current transform.
+// The state of the canvas is restored afterwards.
+func (this *List) Replay(c canvas.Canvas) {
+	base := c.Transform()
+	c.Save()
+	defer c.Restore()
+	depth := 0
+	for n := range this.Commands {
+		cmd := &this.Commands[n]
+		switch cmd.Op {
+		case OpSave:
+			depth++
+		case OpRestore:
+			if depth == 0 {
+				continue //unbalanced, don't pop the state saved above
+			}
+			depth--
+		}
+		cmd.Replay(c, base)
+	}
+	for ; depth > 0; depth-- {
+		c.Restore()
+	}
+}
+
+// ReplayRect replays the commands painting within a dirty rect,
+// in device coordinates of the recording, clipping to it.
+func (this *List) ReplayRect(c canvas.Canvas, dirty geom.Rect) {
+	c.Save()
+	defer c.Restore()
+	c.ClipRect(dirty)
+	this.Cull(dirty).Replay(c)
+}
+
+// Bounds returns the union of the bounds of the drawing commands.
+func (this *List) Bounds() geom.Rect {
+	var bounds geom.Rect
+	for n := range this.Commands {
+		bounds = bounds.Union(this.Commands[n].Bounds)
+	}
+	return bounds
+}
+
+// Cull returns a list without the drawing commands not painting within a dirty rect,
+// in device coordinates of the recording. State commands are kept.
+func (this *List) Cull(dirty geom.Rect) *List {
+	list := &List{}
+	for _, cmd := range this.Commands {
+		if cmd.IsDrawing() && !cmd.Bounds.IntersectsWith(dirty) {
+			continue
+		}
+		list.Commands = append(list.Commands, cmd)
+	}
+	return list
+}
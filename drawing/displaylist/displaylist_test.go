@@ -0,0 +1,153 @@
+package displaylist
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/drawing/raster"
+)
+
+var (
+	red  = color.NRGBA{R: 0xff, A: 0xff}
+	blue = color.NRGBA{B: 0xff, A: 0xff}
+)
+
+// paintScene paints two squares, (0, 0) to (10, 10) in red and (20, 0) to (30, 10)
+// in the color of the second, then state changes and other kinds of commands.
+func paintScene(c canvas.Canvas, second color.NRGBA) {
+	canvas.FillRectangle(c, canvas.SolidBrush{Color: red}, geom.Rc(0, 0, 10, 10))
+	canvas.FillRectangle(c, canvas.SolidBrush{Color: second}, geom.Rc(20, 0, 10, 10))
+	c.Save()
+	canvas.Translate(c, 0, 12)
+	c.ClipRect(geom.Rc(0, 0, 40, 8))
+	pen := canvas.NewPen(blue, 2)
+	pen.DashStyle = canvas.DashStyleDash
+	pen.StartCap = canvas.LineCapRound
+	canvas.DrawLine(c, pen, geom.Pt(0, 2), geom.Pt(40, 2))
+	canvas.FillEllipse(c, canvas.HatchBrush{Style: canvas.HatchStyleCross, ForeColor: red, BackColor: blue},
+		geom.Rc(0, 4, 8, 4))
+	canvas.FillRectangle(c, canvas.NewLinearGradientBrush(geom.Pt(10, 0), geom.Pt(20, 0), red, blue),
+		geom.Rc(10, 4, 10, 4))
+	c.Restore()
+	img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+	img.SetNRGBA(1, 1, red)
+	c.DrawImage(img, img.Bounds(), geom.Rc(32, 0, 4, 4))
+	c.DrawString("ab", canvas.NewFont("Arial", 8, canvas.FontStyleBold), canvas.SolidBrush{Color: blue},
+		geom.Rc(32, 4, 0, 0), &canvas.StringFormat{Alignment: canvas.StringAlignmentCenter})
+}
+
+func record(second color.NRGBA) *List {
+	r := NewRecorder(geom.Rc(0, 0, 40, 20))
+	paintScene(r, second)
+	return r.List()
+}
+
+func render(list *List, dirty *geom.Rect) *image.RGBA {
+	c := raster.NewRGBA(40, 20)
+	if dirty != nil {
+		list.ReplayRect(c, *dirty)
+	} else {
+		list.Replay(c)
+	}
+	return c.Image()
+}
+
+func TestReplay(t *testing.T) {
+	want := raster.NewRGBA(40, 20)
+	paintScene(want, red)
+	if got := render(record(red), nil); !bytes.Equal(got.Pix, want.Image().Pix) {
+		t.Error("replay differs from painting directly")
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	list := record(red)
+	data, err := list.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Len() != list.Len() {
+		t.Fatalf("decoded %d commands, want %d", decoded.Len(), list.Len())
+	}
+	again, err := decoded.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("encoding the decoded list differs:\n%s\nwant\n%s", again, data)
+	}
+	if !bytes.Equal(render(decoded, nil).Pix, render(list, nil).Pix) {
+		t.Error("replaying the decoded list differs")
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	for _, data := range []string{`[{`, `[{"Op":7}]`, `[{"Op":99}]`} {
+		if _, err := Unmarshal([]byte(data)); err == nil {
+			t.Errorf("Unmarshal(%s) succeeded", data)
+		}
+	}
+}
+
+func TestDiffLists(t *testing.T) {
+	old := record(red)
+	if diff := DiffLists(old, record(red)); !diff.IsEmpty() {
+		t.Errorf("diff of the same drawing = %+v, want empty", diff)
+	}
+	diff := DiffLists(old, record(blue))
+	if !reflect.DeepEqual(diff.Removed, []int{1}) || !reflect.DeepEqual(diff.Added, []int{1}) {
+		t.Errorf("diff removed %v and added %v, want [1] and [1]", diff.Removed, diff.Added)
+	}
+	//the square and the recording margin of one pixel, within the recorder bounds
+	if want := geom.Rc(19, 0, 12, 11); diff.Bounds != want {
+		t.Errorf("diff bounds = %v, want %v", diff.Bounds, want)
+	}
+
+	shorter := &List{Commands: old.Commands[1:]}
+	diff = DiffLists(old, shorter)
+	if !reflect.DeepEqual(diff.Removed, []int{0}) || len(diff.Added) != 0 {
+		t.Errorf("diff removed %v and added %v, want [0] and none", diff.Removed, diff.Added)
+	}
+}
+
+func TestCull(t *testing.T) {
+	list := record(red)
+	culled := list.Cull(geom.Rc(20, 4, 2, 2))
+	var ops []Op
+	for _, cmd := range culled.Commands {
+		ops = append(ops, cmd.Op)
+	}
+	//the second square, and the state commands around the clipped drawing
+	want := []Op{OpFillPath, OpSave, OpSetTransform, OpClipRect, OpRestore}
+	if !reflect.DeepEqual(ops, want) {
+		t.Errorf("culled ops = %v, want %v", ops, want)
+	}
+	if culled.Commands[0].Bounds != geom.Rc(19, 0, 12, 11) {
+		t.Errorf("culled the wrong square, bounds %v", culled.Commands[0].Bounds)
+	}
+}
+
+func TestReplayRect(t *testing.T) {
+	dirty := geom.Rc(20, 4, 2, 2)
+	img := render(record(red), &dirty)
+	for y := 0; y < 20; y++ {
+		for x := 0; x < 40; x++ {
+			got := img.RGBAAt(x, y)
+			inside := x >= 20 && x < 22 && y >= 4 && y < 6
+			if inside && got != (color.RGBA{R: 0xff, A: 0xff}) {
+				t.Errorf("pixel (%d, %d) in the dirty rect = %v, want red", x, y, got)
+			} else if !inside && got.A != 0 {
+				t.Errorf("pixel (%d, %d) out of the dirty rect = %v, want transparent", x, y, got)
+			}
+		}
+	}
+}
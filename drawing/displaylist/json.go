@@ -0,0 +1,210 @@
+package displaylist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// commandJSON is the JSON form of a Command, with only the fields used by its Op.
+type commandJSON struct {
+	Op        Op
+	Transform *geom.Affine         `json:",omitempty"`
+	Rect      *geom.Rect           `json:",omitempty"`
+	Path      *pathJSON            `json:",omitempty"`
+	Color     *color.NRGBA         `json:",omitempty"`
+	Brush     *brushJSON           `json:",omitempty"`
+	Pen       *penJSON             `json:",omitempty"`
+	Image     []byte               `json:",omitempty"` //PNG
+	Src       *image.Rectangle     `json:",omitempty"`
+	Text      *string              `json:",omitempty"`
+	Font      *canvas.Font         `json:",omitempty"`
+	Format    *canvas.StringFormat `json:",omitempty"`
+	Bounds    *geom.Rect           `json:",omitempty"`
+}
+
+type pathJSON struct {
+	Points   []geom.Point
+	Types    []byte
+	FillMode geom.FillMode
+}
+
+// brushJSON holds one of the brush kinds.
+type brushJSON struct {
+	Solid  *canvas.SolidBrush          `json:",omitempty"`
+	Hatch  *canvas.HatchBrush          `json:",omitempty"`
+	Linear *canvas.LinearGradientBrush `json:",omitempty"`
+}
+
+type penJSON struct {
+	canvas.Pen
+	Brush *brushJSON `json:",omitempty"` //shadows Pen.Brush
+}
+
+func toPathJSON(path *geom.Path) *pathJSON {
+	return &pathJSON{Points: path.Points, Types: path.Types, FillMode: path.FillMode}
+}
+
+func toBrushJSON(brush canvas.Brush) *brushJSON {
+	switch b := cloneBrush(brush).(type) {
+	case canvas.SolidBrush:
+		return &brushJSON{Solid: &b}
+	case canvas.HatchBrush:
+		return &brushJSON{Hatch: &b}
+	case canvas.LinearGradientBrush:
+		return &brushJSON{Linear: &b}
+	}
+	return nil
+}
+
+func (this *brushJSON) brush() canvas.Brush {
+	switch {
+	case this == nil:
+		return nil
+	case this.Solid != nil:
+		return *this.Solid
+	case this.Hatch != nil:
+		return *this.Hatch
+	case this.Linear != nil:
+		return *this.Linear
+	}
+	return nil
+}
+
+// MarshalJSON encodes the fields used by the op of the command. Images are encoded as PNG.
+func (me Command) MarshalJSON() ([]byte, error) {
+	j := commandJSON{Op: me.Op}
+	if me.IsDrawing() {
+		j.Bounds = &me.Bounds
+	}
+	switch me.Op {
+	case OpSetTransform:
+		j.Transform = &me.Transform
+	case OpClipRect:
+		j.Rect = &me.Rect
+	case OpClipPath:
+		j.Path = toPathJSON(me.Path)
+	case OpClear:
+		j.Color = &me.Color
+	case OpFillPath:
+		j.Path = toPathJSON(me.Path)
+		j.Brush = toBrushJSON(me.Brush)
+	case OpStrokePath:
+		j.Path = toPathJSON(me.Path)
+		j.Pen = &penJSON{Pen: *me.Pen, Brush: toBrushJSON(me.Pen.Brush)}
+	case OpDrawImage:
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, me.Image); err != nil {
+			return nil, err
+		}
+		j.Image = buf.Bytes()
+		j.Src = &me.Src
+		j.Rect = &me.Rect
+	case OpDrawString:
+		j.Text = &me.Text
+		j.Font = me.Font
+		j.Brush = toBrushJSON(me.Brush)
+		j.Rect = &me.Rect
+		j.Format = me.Format
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON decodes a command encoded by MarshalJSON.
+func (this *Command) UnmarshalJSON(data []byte) error {
+	var j commandJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	if j.Op < OpSave || j.Op > OpDrawString {
+		return fmt.Errorf("unknown display list op %d", j.Op)
+	}
+	cmd := Command{Op: j.Op, Font: j.Font, Format: j.Format, Brush: j.Brush.brush()}
+	if j.Transform != nil {
+		cmd.Transform = *j.Transform
+	}
+	if j.Rect != nil {
+		cmd.Rect = *j.Rect
+	}
+	if j.Path != nil {
+		cmd.Path = geom.NewPathFromData(j.Path.Points, j.Path.Types, j.Path.FillMode)
+	}
+	if j.Color != nil {
+		cmd.Color = *j.Color
+	}
+	if j.Pen != nil {
+		pen := j.Pen.Pen
+		pen.Brush = j.Pen.Brush.brush()
+		cmd.Pen = &pen
+	}
+	if j.Image != nil {
+		img, err := png.Decode(bytes.NewReader(j.Image))
+		if err != nil {
+			return err
+		}
+		cmd.Image = img
+	}
+	if j.Src != nil {
+		cmd.Src = *j.Src
+	}
+	if j.Text != nil {
+		cmd.Text = *j.Text
+	}
+	if j.Bounds != nil {
+		cmd.Bounds = *j.Bounds
+	}
+	if err := cmd.validate(); err != nil {
+		return err
+	}
+	*this = cmd
+	return nil
+}
+
+// validate checks that the fields needed to replay the command are set.
+func (this *Command) validate() error {
+	missing := ""
+	switch this.Op {
+	case OpClipPath, OpFillPath:
+		if this.Path == nil {
+			missing = "Path"
+		}
+	case OpStrokePath:
+		if this.Path == nil {
+			missing = "Path"
+		} else if this.Pen == nil {
+			missing = "Pen"
+		}
+	case OpDrawImage:
+		if this.Image == nil {
+			missing = "Image"
+		}
+	case OpDrawString:
+		if this.Font == nil {
+			missing = "Font"
+		}
+	}
+	if missing != "" {
+		return fmt.Errorf("display list op %d without %s", this.Op, missing)
+	}
+	return nil
+}
+
+// Marshal encodes the list as JSON.
+func (this *List) Marshal() ([]byte, error) {
+	return json.Marshal(this.Commands)
+}
+
+// Unmarshal decodes a list encoded by Marshal.
+func Unmarshal(data []byte) (*List, error) {
+	list := &List{}
+	if err := json.Unmarshal(data, &list.Commands); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
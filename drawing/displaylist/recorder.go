@@ -0,0 +1,200 @@
+package displaylist
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+type state struct {
+	transform geom.Affine
+	clip      geom.Rect //the bounds of the clip in device coordinates
+}
+
+// Recorder is a canvas.Canvas recording the calls into a List.
+//
+// Paths, pens, brushes, fonts and formats are copied when recorded,
+// so the caller may reuse them.
+type Recorder struct {
+	bounds geom.Rect
+	state  state
+	stack  []state
+	list   List
+
+	// Faces provides the faces measuring text. If nil or returning nil,
+	// canvas.FallbackFace is used.
+	Faces canvas.FaceSource
+}
+
+var _ canvas.Canvas = (*Recorder)(nil)
+
+// NewRecorder creates a recorder of drawing within bounds, in device coordinates.
+func NewRecorder(bounds geom.Rect) *Recorder {
+	return &Recorder{
+		bounds: bounds,
+		state:  state{transform: geom.Identity(), clip: bounds},
+	}
+}
+
+// List returns the commands recorded so far.
+func (this *Recorder) List() *List {
+	return &List{Commands: append([]Command{}, this.list.Commands...)}
+}
+
+// Reset discards the recorded commands and resets the state.
+func (this *Recorder) Reset() {
+	this.list.Commands = nil
+	this.stack = nil
+	this.state = state{transform: geom.Identity(), clip: this.bounds}
+}
+
+func (this *Recorder) record(cmd Command) {
+	this.list.Commands = append(this.list.Commands, cmd)
+}
+
+// recordDrawing records a drawing command painting within user bounds,
+// unless it paints nothing visible.
+func (this *Recorder) recordDrawing(cmd Command, bounds geom.Rect, deviceMargin float64) {
+	cmd.Bounds = this.state.transform.TransformRect(bounds).
+		Inflate(deviceMargin, deviceMargin).Intersect(this.state.clip)
+	if cmd.Bounds.IsEmpty() {
+		return
+	}
+	this.record(cmd)
+}
+
+func (this *Recorder) Bounds() geom.Rect {
+	return this.bounds
+}
+
+func (this *Recorder) Save() {
+	this.stack = append(this.stack, this.state)
+	this.record(Command{Op: OpSave})
+}
+
+func (this *Recorder) Restore() {
+	if len(this.stack) == 0 {
+		return
+	}
+	this.state = this.stack[len(this.stack)-1]
+	this.stack = this.stack[:len(this.stack)-1]
+	this.record(Command{Op: OpRestore})
+}
+
+func (this *Recorder) Transform() geom.Affine {
+	return this.state.transform
+}
+
+func (this *Recorder) SetTransform(m geom.Affine) {
+	this.state.transform = m
+	this.record(Command{Op: OpSetTransform, Transform: m})
+}
+
+func (this *Recorder) ClipRect(rect geom.Rect) {
+	this.state.clip = this.state.clip.Intersect(this.state.transform.TransformRect(rect))
+	this.record(Command{Op: OpClipRect, Rect: rect})
+}
+
+func (this *Recorder) ClipPath(path *geom.Path) {
+	bounds := this.state.transform.TransformRect(path.ControlBounds())
+	this.state.clip = this.state.clip.Intersect(bounds)
+	this.record(Command{Op: OpClipPath, Path: path.Clone()})
+}
+
+func (this *Recorder) ClipBounds() geom.Rect {
+	inverse, ok := this.state.transform.Invert()
+	if !ok {
+		return geom.Rect{}
+	}
+	return inverse.TransformRect(this.state.clip)
+}
+
+func (this *Recorder) Clear(c color.NRGBA) {
+	if this.state.clip.IsEmpty() {
+		return
+	}
+	this.record(Command{Op: OpClear, Color: c, Bounds: this.state.clip})
+}
+
+func (this *Recorder) FillPath(path *geom.Path, brush canvas.Brush) {
+	this.recordDrawing(Command{Op: OpFillPath, Path: path.Clone(), Brush: cloneBrush(brush)},
+		path.ControlBounds(), 1)
+}
+
+func (this *Recorder) StrokePath(path *geom.Path, pen *canvas.Pen) {
+	pen = clonePen(pen)
+	cmd := Command{Op: OpStrokePath, Path: path.Clone(), Pen: pen}
+	if pen.Width <= 0 {
+		this.recordDrawing(cmd, path.ControlBounds(), 1)
+		return
+	}
+	//the farthest a stroke reaches from the path: half the width
+	//times the miter limit for miters, or sqrt(2) for square caps
+	reach := math.Sqrt2
+	if pen.Join == canvas.LineJoinMiter || pen.Join == canvas.LineJoinMiterClipped {
+		reach = math.Max(reach, pen.GetMiterLimit())
+	}
+	margin := pen.Width / 2 * reach
+	this.recordDrawing(cmd, path.ControlBounds().Inflate(margin, margin), 1)
+}
+
+func (this *Recorder) DrawImage(img image.Image, src image.Rectangle, dst geom.Rect) {
+	this.recordDrawing(Command{Op: OpDrawImage, Image: img, Src: src, Rect: dst}, dst, 1)
+}
+
+func (this *Recorder) DrawString(text string, font *canvas.Font, brush canvas.Brush,
+	layout geom.Rect, format *canvas.StringFormat) {
+	f := *font
+	cmd := Command{Op: OpDrawString, Text: text, Font: &f, Brush: cloneBrush(brush), Rect: layout}
+	if format != nil {
+		sf := *format
+		cmd.Format = &sf
+	}
+	//the layout of the backend may differ, allow half an em around the measured text
+	bounds := canvas.LayoutText(text, this.face(font), font, layout, format).Bounds
+	if bounds.Width == 0 {
+		bounds.Width = font.Size
+	}
+	this.recordDrawing(cmd, bounds.Inflate(font.Size/2, font.Size/2), 1)
+}
+
+func (this *Recorder) MeasureString(text string, font *canvas.Font, width float64,
+	format *canvas.StringFormat) geom.Size {
+	return canvas.MeasureText(text, this.face(font), font, width, format)
+}
+
+// face returns the face of a font.
+func (this *Recorder) face(font *canvas.Font) canvas.Face {
+	if this.Faces != nil {
+		if face := this.Faces.Face(font); face != nil {
+			return face
+		}
+	}
+	return canvas.FallbackFace
+}
+
+func clonePen(pen *canvas.Pen) *canvas.Pen {
+	p := *pen
+	p.DashPattern = append([]float64(nil), pen.DashPattern...)
+	p.Brush = cloneBrush(pen.Brush)
+	return &p
+}
+
+// cloneBrush returns a copy of a brush as a value.
+func cloneBrush(brush canvas.Brush) canvas.Brush {
+	switch b := brush.(type) {
+	case *canvas.SolidBrush:
+		return *b
+	case *canvas.HatchBrush:
+		return *b
+	case *canvas.LinearGradientBrush:
+		return cloneBrush(*b)
+	case canvas.LinearGradientBrush:
+		b.Stops = append([]canvas.GradientStop(nil), b.Stops...)
+		return b
+	}
+	return brush
+}
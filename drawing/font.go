@@ -4,6 +4,7 @@ import (
 	"errors"
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/leaks"
 	"github.com/zzl/goforms/framework/scope"
 	"runtime"
 	"syscall"
@@ -132,21 +133,21 @@ func NewFontFamilyGeneric(s *Scope, generic GenericFontFamilies) *FontFamily {
 func GetGenericSansSerif(s *Scope) *FontFamily {
 	var pFamily *gdip.FontFamily
 	status := gdip.GetGenericFontFamilySansSerif(&pFamily)
-	ensureOk(status)
+	checkStatus(status)
 	return newFontFamily(s, pFamily)
 }
 
 func GetGenericSerif(s *Scope) *FontFamily {
 	var pFamily *gdip.FontFamily
 	status := gdip.GetGenericFontFamilySerif(&pFamily)
-	ensureOk(status)
+	checkStatus(status)
 	return newFontFamily(s, pFamily)
 }
 
 func GetGenericMonospace(s *Scope) *FontFamily {
 	var pFamily *gdip.FontFamily
 	status := gdip.GetGenericFontFamilyMonospace(&pFamily)
-	ensureOk(status)
+	checkStatus(status)
 	return newFontFamily(s, pFamily)
 }
 
@@ -208,10 +209,11 @@ type Font struct {
 
 func newFont(s *Scope, p *gdip.Font) *Font {
 	font := &Font{p}
+	leaks.Track(font)
 	if s != nil {
 		s.Add(font)
 	}
-	runtime.SetFinalizer(font, (*Font).Dispose)
+	setFinalizer(font)
 	return font
 }
 
@@ -293,6 +295,7 @@ func NewFontWithUnitStyle(s *Scope, family *FontFamily, emSize float32,
 }
 
 func (this *Font) Dispose() {
+	leaks.Disposed(this)
 	if this.p == nil {
 		return
 	}
@@ -301,16 +304,24 @@ func (this *Font) Dispose() {
 	runtime.SetFinalizer(this, nil)
 }
 
+// handle returns the native object, reporting use after dispose.
+func (this *Font) handle() *gdip.Font {
+	if this.p == nil {
+		leaks.Used(this)
+	}
+	return this.p
+}
+
 func (this *Font) Clone(s *Scope) *Font {
 	var pFont2 *gdip.Font
-	status := gdip.CloneFont(this.p, &pFont2)
+	status := gdip.CloneFont(this.handle(), &pFont2)
 	checkStatus(status)
 	return newFont(s, pFont2)
 }
 
 func (this *Font) GetFontFamily(s *Scope) *FontFamily {
 	var pFamily *gdip.FontFamily
-	status := gdip.GetFamily(this.p, &pFamily)
+	status := gdip.GetFamily(this.handle(), &pFamily)
 	checkStatus(status)
 	return newFontFamily(s, pFamily)
 }
@@ -335,28 +346,28 @@ func (this *Font) ToLogFont() *win32.LOGFONT {
 	var lf win32.LOGFONT
 	hdc := win32.GetDC(0)
 	g, _ := NewGraphicsFromHdc(nil, hdc)
-	gdip.GetLogFontW(this.p, g.p, &lf)
+	gdip.GetLogFontW(this.handle(), g.p, &lf)
 	g.Dispose()
 	return &lf
 }
 
 func (this *Font) GetHeight(g *Graphics) float32 {
 	var height float32
-	status := gdip.GetFontHeight(this.p, g.p, &height)
+	status := gdip.GetFontHeight(this.handle(), g.p, &height)
 	checkStatus(status)
 	return height
 }
 
 func (this *Font) GetStyle() gdip.FontStyle {
 	var style int32
-	status := gdip.GetFontStyle(this.p, &style)
+	status := gdip.GetFontStyle(this.handle(), &style)
 	checkStatus(status)
 	return gdip.FontStyle(style)
 }
 
 func (this *Font) GetSize() float32 {
 	var size float32
-	status := gdip.GetFontSize(this.p, &size)
+	status := gdip.GetFontSize(this.handle(), &size)
 	checkStatus(status)
 	return size
 }
@@ -382,7 +393,7 @@ func (this *Font) GetPointSize() float32 {
 
 func (this *Font) GetUnit() gdip.Unit {
 	var unit gdip.Unit
-	status := gdip.GetFontUnit(this.p, &unit)
+	status := gdip.GetFontUnit(this.handle(), &unit)
 	checkStatus(status)
 	return unit
 }
@@ -3,7 +3,8 @@ package gdi
 import (
 	"errors"
 	"github.com/zzl/go-win32api/v2/win32"
-	"log"
+	"github.com/zzl/goforms/drawing"
+	"github.com/zzl/goforms/framework/leaks"
 	"syscall"
 )
 
@@ -17,7 +18,7 @@ type Font struct {
 	Underline bool
 	StrikeOut bool
 
-	owned bool
+	owned bool //whether the handle is deleted on Dispose
 }
 
 func (this *Font) Init() {
@@ -25,6 +26,9 @@ func (this *Font) Init() {
 }
 
 func (this *Font) Dispose() {
+	if this.owned {
+		leaks.Disposed(this)
+	}
 	if this.Handle == 0 {
 		return
 	}
@@ -57,6 +61,9 @@ func NewFontFromHandle(hFont win32.HFONT, owned bool) *Font {
 		owned:  owned,
 	}
 	//fill fields from handle?
+	if owned {
+		leaks.Track(f)
+	}
 	return f
 }
 
@@ -67,11 +74,13 @@ func (this *Font) Create() error {
 		return errors.New("?")
 	}
 	this.Handle = hFont
+	this.owned = true //a created handle is always owned
+	leaks.Track(this)
 	return nil
 }
 
 func (this *Font) CopyUnowned() *Font {
-	this.EnsureCreated()
+	drawing.ReportError(this.EnsureCreated())
 	font := *this
 	font.owned = false
 	return &font
@@ -115,14 +124,12 @@ func (this *Font) createLogFont() *win32.LOGFONT {
 	return lf
 }
 
-func (this *Font) EnsureCreated() {
+// EnsureCreated creates the font handle if not created yet.
+func (this *Font) EnsureCreated() error {
 	if this.Handle != 0 {
-		return
-	}
-	err := this.Create()
-	if err != nil {
-		log.Fatal(err)
+		return nil
 	}
+	return this.Create()
 }
 
 func (this *Font) Derive(bold bool, italic bool) *Font {
@@ -0,0 +1,180 @@
+package geom
+
+import "math"
+
+// Affine is a 3x2 affine transformation matrix, laid out as the elements of a GDI+ matrix.
+// A point (x, y) maps to (x*M11 + y*M21 + DX, x*M12 + y*M22 + DY).
+// The zero value is not the identity, use Identity.
+type Affine struct {
+	M11, M12 float64
+	M21, M22 float64
+	DX, DY   float64
+}
+
+// Identity returns the identity transformation.
+func Identity() Affine {
+	return Affine{M11: 1, M22: 1}
+}
+
+// Translation returns a translation by (dx, dy).
+func Translation(dx, dy float64) Affine {
+	return Affine{M11: 1, M22: 1, DX: dx, DY: dy}
+}
+
+// Scaling returns a scaling by (sx, sy) about the origin.
+func Scaling(sx, sy float64) Affine {
+	return Affine{M11: sx, M22: sy}
+}
+
+// Rotation returns a rotation about the origin, in degrees clockwise on screen.
+func Rotation(degrees float64) Affine {
+	sin, cos := math.Sincos(degrees * math.Pi / 180)
+	return Affine{M11: cos, M12: sin, M21: -sin, M22: cos}
+}
+
+// Multiply returns the transformation applying this one, then other.
+func (me Affine) Multiply(other Affine) Affine {
+	return Affine{
+		M11: me.M11*other.M11 + me.M12*other.M21,
+		M12: me.M11*other.M12 + me.M12*other.M22,
+		M21: me.M21*other.M11 + me.M22*other.M21,
+		M22: me.M21*other.M12 + me.M22*other.M22,
+		DX:  me.DX*other.M11 + me.DY*other.M21 + other.DX,
+		DY:  me.DX*other.M12 + me.DY*other.M22 + other.DY,
+	}
+}
+
+// Translate returns the transformation translating by (dx, dy), then applying this one.
+// As with Graphics.TranslateTransform, it moves the origin of the local coordinates.
+func (me Affine) Translate(dx, dy float64) Affine {
+	return Translation(dx, dy).Multiply(me)
+}
+
+// Scale returns the transformation scaling by (sx, sy), then applying this one.
+func (me Affine) Scale(sx, sy float64) Affine {
+	return Scaling(sx, sy).Multiply(me)
+}
+
+// Rotate returns the transformation rotating by degrees, then applying this one.
+func (me Affine) Rotate(degrees float64) Affine {
+	return Rotation(degrees).Multiply(me)
+}
+
+// Determinant returns the determinant of the linear part.
+func (me Affine) Determinant() float64 {
+	return me.M11*me.M22 - me.M12*me.M21
+}
+
+// IsIdentity tells whether the transformation is the identity.
+func (me Affine) IsIdentity() bool {
+	return me == Identity()
+}
+
+// IsInvertible tells whether the transformation has an inverse.
+func (me Affine) IsInvertible() bool {
+	det := me.Determinant()
+	return det != 0 && !math.IsNaN(det) && !math.IsInf(det, 0)
+}
+
+// Invert returns the inverse transformation,
+// or the identity and false if there is none.
+func (me Affine) Invert() (Affine, bool) {
+	if !me.IsInvertible() {
+		return Identity(), false
+	}
+	det := me.Determinant()
+	return Affine{
+		M11: me.M22 / det,
+		M12: -me.M12 / det,
+		M21: -me.M21 / det,
+		M22: me.M11 / det,
+		DX:  (me.M21*me.DY - me.M22*me.DX) / det,
+		DY:  (me.M12*me.DX - me.M11*me.DY) / det,
+	}, true
+}
+
+// Transform maps a point.
+func (me Affine) Transform(pt Point) Point {
+	return Point{
+		pt.X*me.M11 + pt.Y*me.M21 + me.DX,
+		pt.X*me.M12 + pt.Y*me.M22 + me.DY,
+	}
+}
+
+// TransformVector maps a vector, ignoring the translation.
+func (me Affine) TransformVector(v Point) Point {
+	return Point{v.X*me.M11 + v.Y*me.M21, v.X*me.M12 + v.Y*me.M22}
+}
+
+// TransformPoints maps points in place.
+func (me Affine) TransformPoints(points []Point) {
+	for n, pt := range points {
+		points[n] = me.Transform(pt)
+	}
+}
+
+// Shearing returns a shear, with x moving by shx times y and y by shy times x,
+// as the GDI+ Matrix.Shear.
+func Shearing(shx, shy float64) Affine {
+	return Affine{M11: 1, M12: shy, M21: shx, M22: 1}
+}
+
+// RotationAt returns a rotation about a center, in degrees clockwise on screen.
+func RotationAt(degrees float64, center Point) Affine {
+	return Translation(-center.X, -center.Y).Multiply(Rotation(degrees)).
+		Multiply(Translation(center.X, center.Y))
+}
+
+// Shear returns the transformation shearing by (shx, shy), then applying this one.
+func (me Affine) Shear(shx, shy float64) Affine {
+	return Shearing(shx, shy).Multiply(me)
+}
+
+// RotateAt returns the transformation rotating by degrees about a center, then applying this one.
+// The center is in the coordinates before this transformation.
+func (me Affine) RotateAt(degrees float64, center Point) Affine {
+	return RotationAt(degrees, center).Multiply(me)
+}
+
+// TransformRect returns the bounds of a mapped rect.
+func (me Affine) TransformRect(rect Rect) Rect {
+	corners := []Point{rect.Location(), {rect.Right(), rect.Y},
+		{rect.Right(), rect.Bottom()}, {rect.X, rect.Bottom()}}
+	me.TransformPoints(corners)
+	return BoundsOf(corners)
+}
+
+// Decomposition is an affine transformation as a scaling, then a horizontal shear,
+// then a rotation, then a translation.
+type Decomposition struct {
+	ScaleX, ScaleY         float64
+	Shear                  float64 //x moves by Shear times y
+	Rotation               float64 //in degrees clockwise on screen
+	TranslateX, TranslateY float64
+}
+
+// Decompose returns the decomposition of the transformation.
+// ScaleX is never negative, reflections show as a negative ScaleY.
+// A transformation without inverse may not compose back the same.
+func (me Affine) Decompose() Decomposition {
+	d := Decomposition{TranslateX: me.DX, TranslateY: me.DY}
+	//the first row is ScaleX times the rotated x axis,
+	//the second ScaleY times the sheared and rotated y axis
+	d.ScaleX = math.Hypot(me.M11, me.M12)
+	var cos, sin float64 = 1, 0
+	if d.ScaleX != 0 {
+		cos, sin = me.M11/d.ScaleX, me.M12/d.ScaleX
+		d.Rotation = math.Atan2(me.M12, me.M11) * 180 / math.Pi
+	}
+	d.ScaleY = me.M22*cos - me.M21*sin
+	if d.ScaleY != 0 {
+		d.Shear = (me.M21*cos + me.M22*sin) / d.ScaleY
+	}
+	return d
+}
+
+// Affine returns the transformation of the decomposition.
+func (me Decomposition) Affine() Affine {
+	return Scaling(me.ScaleX, me.ScaleY).Multiply(Shearing(me.Shear, 0)).
+		Multiply(Rotation(me.Rotation)).Multiply(Translation(me.TranslateX, me.TranslateY))
+}
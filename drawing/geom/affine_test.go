@@ -0,0 +1,238 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < epsilon
+}
+
+func nearPoint(a, b Point) bool {
+	return near(a.X, b.X) && near(a.Y, b.Y)
+}
+
+func nearAffine(a, b Affine) bool {
+	return near(a.M11, b.M11) && near(a.M12, b.M12) && near(a.M21, b.M21) &&
+		near(a.M22, b.M22) && near(a.DX, b.DX) && near(a.DY, b.DY)
+}
+
+func TestAffineTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Affine
+		in   Point
+		want Point
+	}{
+		{"identity", Identity(), Pt(3, 4), Pt(3, 4)},
+		{"translation", Translation(10, -5), Pt(3, 4), Pt(13, -1)},
+		{"scaling", Scaling(2, -3), Pt(3, 4), Pt(6, -12)},
+		{"rotation", Rotation(90), Pt(1, 0), Pt(0, 1)},
+		{"rotation negative", Rotation(-90), Pt(1, 0), Pt(0, -1)},
+		{"shearing x", Shearing(2, 0), Pt(1, 3), Pt(7, 3)},
+		{"shearing y", Shearing(0, 2), Pt(3, 1), Pt(3, 7)},
+		{"rotation at", RotationAt(90, Pt(10, 10)), Pt(11, 10), Pt(10, 11)},
+		{"elements", Affine{1, 2, 3, 4, 5, 6}, Pt(1, 1), Pt(9, 12)},
+	}
+	for _, test := range tests {
+		if got := test.m.Transform(test.in); !nearPoint(got, test.want) {
+			t.Errorf("%s: Transform(%v) = %v, want %v", test.name, test.in, got, test.want)
+		}
+	}
+}
+
+func TestAffineTransformVector(t *testing.T) {
+	m := Translation(100, 200).Multiply(Scaling(2, 3))
+	if got := m.TransformVector(Pt(1, 1)); !nearPoint(got, Pt(2, 3)) {
+		t.Errorf("TransformVector = %v, want (2, 3)", got)
+	}
+}
+
+func TestAffineTransformPoints(t *testing.T) {
+	points := []Point{{0, 0}, {1, 2}}
+	Translation(1, 1).TransformPoints(points)
+	if points[0] != Pt(1, 1) || points[1] != Pt(2, 3) {
+		t.Errorf("TransformPoints = %v", points)
+	}
+}
+
+func TestAffineMultiplyOrder(t *testing.T) {
+	//scale then translate, against translate then scale
+	st := Scaling(2, 2).Multiply(Translation(10, 0))
+	ts := Translation(10, 0).Multiply(Scaling(2, 2))
+	if got := st.Transform(Pt(1, 1)); !nearPoint(got, Pt(12, 2)) {
+		t.Errorf("scale then translate = %v, want (12, 2)", got)
+	}
+	if got := ts.Transform(Pt(1, 1)); !nearPoint(got, Pt(22, 2)) {
+		t.Errorf("translate then scale = %v, want (22, 2)", got)
+	}
+	if !nearAffine(Identity().Multiply(st), st) || !nearAffine(st.Multiply(Identity()), st) {
+		t.Error("the identity isn't neutral")
+	}
+}
+
+func TestAffinePrepend(t *testing.T) {
+	//as with Graphics transforms, the methods apply in the local coordinates first
+	base := Translation(100, 0)
+	tests := []struct {
+		name string
+		got  Affine
+		want Affine
+	}{
+		{"Translate", base.Translate(1, 2), Translation(1, 2).Multiply(base)},
+		{"Scale", base.Scale(2, 3), Scaling(2, 3).Multiply(base)},
+		{"Rotate", base.Rotate(30), Rotation(30).Multiply(base)},
+		{"Shear", base.Shear(0.5, 0.25), Shearing(0.5, 0.25).Multiply(base)},
+		{"RotateAt", base.RotateAt(45, Pt(3, 4)), RotationAt(45, Pt(3, 4)).Multiply(base)},
+	}
+	for _, test := range tests {
+		if !nearAffine(test.got, test.want) {
+			t.Errorf("%s = %v, want %v", test.name, test.got, test.want)
+		}
+	}
+	if got := base.Scale(2, 2).Transform(Pt(1, 1)); !nearPoint(got, Pt(102, 2)) {
+		t.Errorf("Scale then Transform = %v, want (102, 2)", got)
+	}
+}
+
+func TestAffineRotateAtKeepsCenter(t *testing.T) {
+	center := Pt(7, -3)
+	for _, degrees := range []float64{0, 30, 90, 180, 270, -45} {
+		m := Scaling(2, 2).RotateAt(degrees, center)
+		if got, want := m.Transform(center), Scaling(2, 2).Transform(center); !nearPoint(got, want) {
+			t.Errorf("RotateAt(%v) moves the center to %v, want %v", degrees, got, want)
+		}
+	}
+}
+
+func TestAffineInvert(t *testing.T) {
+	matrices := []Affine{
+		Identity(),
+		Translation(3, -4),
+		Scaling(2, 0.5),
+		Rotation(33),
+		Shearing(0.3, -0.7),
+		Affine{1, 2, 3, 4, 5, 6},
+		Rotation(10).Multiply(Scaling(-1, 3)).Multiply(Translation(8, 9)),
+	}
+	for _, m := range matrices {
+		inverse, ok := m.Invert()
+		if !ok {
+			t.Errorf("%v: not invertible", m)
+			continue
+		}
+		if !nearAffine(m.Multiply(inverse), Identity()) || !nearAffine(inverse.Multiply(m), Identity()) {
+			t.Errorf("%v: inverse %v doesn't compose to the identity", m, inverse)
+		}
+		pt := Pt(1.5, -2.5)
+		if got := inverse.Transform(m.Transform(pt)); !nearPoint(got, pt) {
+			t.Errorf("%v: round trip of %v = %v", m, pt, got)
+		}
+	}
+}
+
+func TestAffineSingular(t *testing.T) {
+	for _, m := range []Affine{{}, Scaling(0, 1), {M11: 1, M12: 2, M21: 2, M22: 4}, {M11: math.NaN(), M22: 1}} {
+		if m.IsInvertible() {
+			t.Errorf("%v: invertible", m)
+		}
+		inverse, ok := m.Invert()
+		if ok || inverse != Identity() {
+			t.Errorf("%v: Invert = %v, %v, want the identity and false", m, inverse, ok)
+		}
+	}
+}
+
+func TestAffineDeterminant(t *testing.T) {
+	tests := []struct {
+		m    Affine
+		want float64
+	}{
+		{Identity(), 1},
+		{Scaling(2, 3), 6},
+		{Scaling(-1, 1), -1},
+		{Rotation(77), 1},
+		{Shearing(5, 0), 1},
+		{Translation(10, 10), 1},
+	}
+	for _, test := range tests {
+		if got := test.m.Determinant(); !near(got, test.want) {
+			t.Errorf("%v: Determinant = %v, want %v", test.m, got, test.want)
+		}
+	}
+}
+
+func TestAffineIsIdentity(t *testing.T) {
+	if !Identity().IsIdentity() || !Translation(0, 0).IsIdentity() || !Rotation(0).IsIdentity() {
+		t.Error("identity not recognized")
+	}
+	if (Affine{}).IsIdentity() || Translation(1, 0).IsIdentity() || Scaling(1, 2).IsIdentity() {
+		t.Error("non identity recognized as identity")
+	}
+}
+
+func TestAffineTransformRect(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Affine
+		in   Rect
+		want Rect
+	}{
+		{"identity", Identity(), Rc(1, 2, 3, 4), Rc(1, 2, 3, 4)},
+		{"translation", Translation(10, 20), Rc(1, 2, 3, 4), Rc(11, 22, 3, 4)},
+		{"reflection", Scaling(-1, 1), Rc(1, 2, 3, 4), Rc(-4, 2, 3, 4)},
+		{"rotation", Rotation(90), Rc(0, 0, 2, 1), Rc(-1, 0, 1, 2)},
+		{"rotation 45", Rotation(45), Rc(-1, -1, 2, 2), Rc(-math.Sqrt2, -math.Sqrt2, 2*math.Sqrt2, 2*math.Sqrt2)},
+	}
+	for _, test := range tests {
+		got := test.m.TransformRect(test.in)
+		if !near(got.X, test.want.X) || !near(got.Y, test.want.Y) ||
+			!near(got.Width, test.want.Width) || !near(got.Height, test.want.Height) {
+			t.Errorf("%s: TransformRect = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestAffineDecompose(t *testing.T) {
+	tests := []struct {
+		name string
+		m    Affine
+		want Decomposition
+	}{
+		{"identity", Identity(), Decomposition{ScaleX: 1, ScaleY: 1}},
+		{"translation", Translation(3, 4), Decomposition{ScaleX: 1, ScaleY: 1, TranslateX: 3, TranslateY: 4}},
+		{"scaling", Scaling(2, 3), Decomposition{ScaleX: 2, ScaleY: 3}},
+		{"rotation", Rotation(30), Decomposition{ScaleX: 1, ScaleY: 1, Rotation: 30}},
+		{"shearing", Shearing(0.5, 0), Decomposition{ScaleX: 1, ScaleY: 1, Shear: 0.5}},
+		{"reflection", Scaling(1, -1), Decomposition{ScaleX: 1, ScaleY: -1}},
+		{"all", Scaling(2, 3).Multiply(Shearing(0.25, 0)).Multiply(Rotation(-60)).Multiply(Translation(5, 6)),
+			Decomposition{ScaleX: 2, ScaleY: 3, Shear: 0.25, Rotation: -60, TranslateX: 5, TranslateY: 6}},
+	}
+	for _, test := range tests {
+		got := test.m.Decompose()
+		if !near(got.ScaleX, test.want.ScaleX) || !near(got.ScaleY, test.want.ScaleY) ||
+			!near(got.Shear, test.want.Shear) || !near(got.Rotation, test.want.Rotation) ||
+			!near(got.TranslateX, test.want.TranslateX) || !near(got.TranslateY, test.want.TranslateY) {
+			t.Errorf("%s: Decompose = %+v, want %+v", test.name, got, test.want)
+		}
+		if composed := got.Affine(); !nearAffine(composed, test.m) {
+			t.Errorf("%s: composes back to %v, want %v", test.name, composed, test.m)
+		}
+	}
+}
+
+func TestAffineDecomposeRoundTrip(t *testing.T) {
+	for _, m := range []Affine{
+		{1, 2, 3, 4, 5, 6},
+		{-0.5, 0.25, 2, -3, 0, 1},
+		Scaling(-2, 1).Multiply(Rotation(123)),
+		Shearing(0.3, 0.7).Multiply(Translation(-4, 2)),
+	} {
+		if composed := m.Decompose().Affine(); !nearAffine(composed, m) {
+			t.Errorf("%v: composes back to %v", m, composed)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package geom
+
+import "math"
+
+// DefaultFlatness is the default tolerance of flattening, as the GDI+ FlatnessDefault.
+const DefaultFlatness = 0.25
+
+// Polyline is a flattened figure of a path.
+type Polyline struct {
+	Points []Point
+	Closed bool
+}
+
+// Flatten converts the figures of the path into polylines, mapping the points through m.
+// Curves are subdivided so that they deviate from the polylines by at most tolerance,
+// measured after the mapping.
+func (this *Path) Flatten(m Affine, tolerance float64) []Polyline {
+	var lines []Polyline
+	this.Iterate(func(op PathOp, points []Point) {
+		if len(lines) == 0 && op != PathMoveTo {
+			if op == PathClose {
+				return
+			}
+			op, points = PathMoveTo, points[len(points)-1:]
+		}
+		if op == PathMoveTo {
+			lines = append(lines, Polyline{Points: []Point{m.Transform(points[0])}})
+			return
+		}
+		current := &lines[len(lines)-1]
+		switch op {
+		case PathLineTo:
+			current.Points = append(current.Points, m.Transform(points[0]))
+		case PathCubicTo:
+			p0 := current.Points[len(current.Points)-1]
+			p1, p2, p3 := m.Transform(points[0]), m.Transform(points[1]), m.Transform(points[2])
+			current.Points = flattenCubic(current.Points, p0, p1, p2, p3, tolerance)
+		case PathClose:
+			current.Closed = true
+		}
+	})
+	return lines
+}
+
+// flattenCubic appends the points of a flattened cubic Bezier curve, excluding p0.
+func flattenCubic(points []Point, p0, p1, p2, p3 Point, tolerance float64) []Point {
+	dd := math.Max(p0.Sub(p1.Mul(2)).Add(p2).Len(), p1.Sub(p2.Mul(2)).Add(p3).Len())
+	segments := int(math.Ceil(math.Sqrt(0.75 * dd / tolerance)))
+	if segments < 1 {
+		segments = 1
+	} else if segments > 1000 {
+		segments = 1000
+	}
+	for i := 1; i <= segments; i++ {
+		points = append(points, cubicAt(p0, p1, p2, p3, float64(i)/float64(segments)))
+	}
+	return points
+}
+
+// cubicAt returns the point at t of a cubic Bezier curve.
+func cubicAt(p0, p1, p2, p3 Point, t float64) Point {
+	u := 1 - t
+	a, b, c, d := u*u*u, 3*u*u*t, 3*u*t*t, t*t*t
+	return Point{
+		X: a*p0.X + b*p1.X + c*p2.X + d*p3.X,
+		Y: a*p0.Y + b*p1.Y + c*p2.Y + d*p3.Y,
+	}
+}
+
+// Bounds returns the tight bounding rect of the path, whose curves may be
+// within the bounds of their control points.
+func (this *Path) Bounds() Rect {
+	var points []Point
+	var last Point
+	this.Iterate(func(op PathOp, pts []Point) {
+		switch op {
+		case PathMoveTo, PathLineTo:
+			points = append(points, pts[0])
+		case PathCubicTo:
+			points = append(points, pts[2])
+			for _, t := range cubicExtrema(last, pts[0], pts[1], pts[2]) {
+				points = append(points, cubicAt(last, pts[0], pts[1], pts[2], t))
+			}
+		case PathClose:
+			return
+		}
+		last = pts[len(pts)-1]
+	})
+	return BoundsOf(points)
+}
+
+// cubicExtrema returns the parameters in (0, 1) where a cubic Bezier curve
+// has a horizontal or vertical tangent.
+func cubicExtrema(p0, p1, p2, p3 Point) []float64 {
+	var ts []float64
+	for _, c := range [][4]float64{{p0.X, p1.X, p2.X, p3.X}, {p0.Y, p1.Y, p2.Y, p3.Y}} {
+		//the derivative is the quadratic a t^2 + b t + c, divided by 3
+		a := -c[0] + 3*c[1] - 3*c[2] + c[3]
+		b := 2 * (c[0] - 2*c[1] + c[2])
+		k := c[1] - c[0]
+		for _, t := range quadraticRoots(a, b, k) {
+			if t > 0 && t < 1 {
+				ts = append(ts, t)
+			}
+		}
+	}
+	return ts
+}
+
+// quadraticRoots returns the real roots of a t^2 + b t + c.
+func quadraticRoots(a, b, c float64) []float64 {
+	if math.Abs(a) < 1e-12 {
+		if b == 0 {
+			return nil
+		}
+		return []float64{-c / b}
+	}
+	d := b*b - 4*a*c
+	if d < 0 {
+		return nil
+	}
+	sqrt := math.Sqrt(d)
+	return []float64{(-b + sqrt) / (2 * a), (-b - sqrt) / (2 * a)}
+}
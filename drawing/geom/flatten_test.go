@@ -0,0 +1,107 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func nearRect(a, b Rect, tolerance float64) bool {
+	return math.Abs(a.X-b.X) <= tolerance && math.Abs(a.Y-b.Y) <= tolerance &&
+		math.Abs(a.Width-b.Width) <= tolerance && math.Abs(a.Height-b.Height) <= tolerance
+}
+
+func TestBounds(t *testing.T) {
+	bezier := NewPath()
+	bezier.AddBezier(Pt(0, 0), Pt(0, 10), Pt(10, 10), Pt(10, 0))
+	ellipse := NewPath()
+	ellipse.AddEllipse(Rc(10, 20, 30, 40))
+	arc := NewPath()
+	arc.AddArc(Rc(0, 0, 20, 20), 0, 90)
+	rotated := NewPath()
+	rotated.AddEllipse(Rc(-10, -5, 20, 10))
+	rotated.Transform(Rotation(90))
+	lines := NewPath()
+	lines.AddLines([]Point{Pt(3, 4), Pt(-1, 8), Pt(5, 6)})
+	tests := []struct {
+		name string
+		path *Path
+		want Rect
+	}{
+		//the curve peaks at 3/4 of the height of its control points
+		{"bezier", bezier, Rc(0, 0, 10, 7.5)},
+		{"ellipse", ellipse, Rc(10, 20, 30, 40)},
+		{"quarter arc", arc, Rc(10, 10, 10, 10)},
+		{"rotated ellipse", rotated, Rc(-5, -10, 10, 20)},
+		{"lines", lines, Rc(-1, 4, 6, 4)},
+		{"empty", NewPath(), Rect{}},
+	}
+	for _, test := range tests {
+		if got := test.path.Bounds(); !nearRect(got, test.want, 1e-3) {
+			t.Errorf("%s: Bounds() = %v, want %v", test.name, got, test.want)
+		}
+	}
+	//the tight bounds are within the bounds of the control points
+	if control := bezier.ControlBounds(); control != Rc(0, 0, 10, 10) {
+		t.Errorf("ControlBounds() = %v, want the control points", control)
+	}
+}
+
+// TestBoundsSampled compares the bounds of curves overshooting their end points
+// with the bounds of points sampled along them.
+func TestBoundsSampled(t *testing.T) {
+	curves := [][4]Point{
+		{Pt(0, 0), Pt(30, 0), Pt(-20, 10), Pt(10, 10)},
+		{Pt(0, 0), Pt(10, -10), Pt(20, 20), Pt(0, 5)},
+		{Pt(5, 5), Pt(-5, 15), Pt(15, -5), Pt(5, 5)}, //a loop
+		{Pt(0, 0), Pt(0, 0), Pt(10, 10), Pt(10, 10)}, //a line
+	}
+	for _, c := range curves {
+		path := NewPath()
+		path.AddBezier(c[0], c[1], c[2], c[3])
+		points := make([]Point, 0, 1001)
+		for i := 0; i <= 1000; i++ {
+			points = append(points, cubicAt(c[0], c[1], c[2], c[3], float64(i)/1000))
+		}
+		want := BoundsOf(points)
+		got := path.Bounds()
+		if !nearRect(got, want, 1e-3) {
+			t.Errorf("curve %v: Bounds() = %v, sampled %v", c, got, want)
+		}
+	}
+}
+
+func TestFlattenTolerance(t *testing.T) {
+	circle := NewPath()
+	circle.AddEllipse(Rc(-100, -100, 200, 200))
+	for _, tolerance := range []float64{1, 0.25, 0.01} {
+		lines := circle.Flatten(Identity(), tolerance)
+		if len(lines) != 1 || !lines[0].Closed {
+			t.Fatalf("flattened into %d lines", len(lines))
+		}
+		points := lines[0].Points
+		for n, p0 := range points {
+			p1 := points[(n+1)%len(points)]
+			//the middle of each chord deviates from the circle by at most the tolerance,
+			//plus the error of the Bezier approximation of the circle
+			if deviation := 100 - p0.Lerp(p1, 0.5).Len(); deviation > tolerance+0.03 {
+				t.Errorf("tolerance %v: chord %d deviates by %v", tolerance, n, deviation)
+				break
+			}
+		}
+	}
+	//a coarser tolerance gives fewer points
+	if coarse, fine := len(circle.Flatten(Identity(), 1)[0].Points),
+		len(circle.Flatten(Identity(), 0.01)[0].Points); coarse >= fine {
+		t.Errorf("%d points at tolerance 1, %d at 0.01", coarse, fine)
+	}
+}
+
+func TestFlattenTransform(t *testing.T) {
+	path := NewPath()
+	path.AddLine(Pt(1, 2), Pt(3, 4))
+	lines := path.Flatten(Translation(10, 20), DefaultFlatness)
+	if len(lines) != 1 || len(lines[0].Points) != 2 || lines[0].Closed ||
+		lines[0].Points[0] != Pt(11, 22) || lines[0].Points[1] != Pt(13, 24) {
+		t.Errorf("Flatten = %+v", lines)
+	}
+}
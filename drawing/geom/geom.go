@@ -0,0 +1,178 @@
+// Package geom holds pure-Go geometry value types shared by the drawing backends.
+//
+// Unlike the types of the drawing package, they don't wrap GDI+ objects,
+// so they can be used headless and on any platform.
+// Coordinates follow GDI+: the y axis points down and angles are in degrees,
+// clockwise on screen.
+package geom
+
+import "math"
+
+// Point is a point or a vector.
+type Point struct {
+	X, Y float64
+}
+
+// Pt creates a point.
+func Pt(x, y float64) Point {
+	return Point{x, y}
+}
+
+func (me Point) Add(pt Point) Point {
+	return Point{me.X + pt.X, me.Y + pt.Y}
+}
+
+func (me Point) Sub(pt Point) Point {
+	return Point{me.X - pt.X, me.Y - pt.Y}
+}
+
+func (me Point) Mul(k float64) Point {
+	return Point{me.X * k, me.Y * k}
+}
+
+// Dot returns the dot product of two vectors.
+func (me Point) Dot(pt Point) float64 {
+	return me.X*pt.X + me.Y*pt.Y
+}
+
+// Cross returns the z component of the cross product of two vectors.
+func (me Point) Cross(pt Point) float64 {
+	return me.X*pt.Y - me.Y*pt.X
+}
+
+// Len returns the length of the vector.
+func (me Point) Len() float64 {
+	return math.Hypot(me.X, me.Y)
+}
+
+// Normalize returns the unit vector of the same direction, or the zero vector.
+func (me Point) Normalize() Point {
+	l := me.Len()
+	if l == 0 {
+		return Point{}
+	}
+	return Point{me.X / l, me.Y / l}
+}
+
+// Lerp interpolates linearly between the points.
+func (me Point) Lerp(pt Point, t float64) Point {
+	return Point{me.X + (pt.X-me.X)*t, me.Y + (pt.Y-me.Y)*t}
+}
+
+// Size is a width and height.
+type Size struct {
+	Width, Height float64
+}
+
+// Rect is a rectangle of a location and a size.
+type Rect struct {
+	X, Y, Width, Height float64
+}
+
+// Rc creates a rect.
+func Rc(x, y, width, height float64) Rect {
+	return Rect{x, y, width, height}
+}
+
+// RectFromPoints returns the rect spanning two corners.
+func RectFromPoints(pt1, pt2 Point) Rect {
+	x1, x2 := math.Min(pt1.X, pt2.X), math.Max(pt1.X, pt2.X)
+	y1, y2 := math.Min(pt1.Y, pt2.Y), math.Max(pt1.Y, pt2.Y)
+	return Rect{x1, y1, x2 - x1, y2 - y1}
+}
+
+func (me Rect) Location() Point {
+	return Point{me.X, me.Y}
+}
+
+func (me Rect) Size() Size {
+	return Size{me.Width, me.Height}
+}
+
+func (me Rect) Right() float64 {
+	return me.X + me.Width
+}
+
+func (me Rect) Bottom() float64 {
+	return me.Y + me.Height
+}
+
+func (me Rect) Center() Point {
+	return Point{me.X + me.Width/2, me.Y + me.Height/2}
+}
+
+// IsEmpty tells whether the rect has no area.
+func (me Rect) IsEmpty() bool {
+	return me.Width <= 0 || me.Height <= 0
+}
+
+// Contains tells whether the point is within the rect,
+// including the left and top edges only.
+func (me Rect) Contains(pt Point) bool {
+	return me.X <= pt.X && pt.X < me.X+me.Width &&
+		me.Y <= pt.Y && pt.Y < me.Y+me.Height
+}
+
+// ContainsRect tells whether the rect is entirely within this one.
+func (me Rect) ContainsRect(rect Rect) bool {
+	return me.X <= rect.X && rect.Right() <= me.Right() &&
+		me.Y <= rect.Y && rect.Bottom() <= me.Bottom()
+}
+
+// Intersect returns the intersection of the rects, or an empty rect.
+func (me Rect) Intersect(rect Rect) Rect {
+	x1 := math.Max(me.X, rect.X)
+	x2 := math.Min(me.Right(), rect.Right())
+	y1 := math.Max(me.Y, rect.Y)
+	y2 := math.Min(me.Bottom(), rect.Bottom())
+	if x2 > x1 && y2 > y1 {
+		return Rect{x1, y1, x2 - x1, y2 - y1}
+	}
+	return Rect{}
+}
+
+// IntersectsWith tells whether the rects overlap.
+func (me Rect) IntersectsWith(rect Rect) bool {
+	return rect.X < me.Right() && me.X < rect.Right() &&
+		rect.Y < me.Bottom() && me.Y < rect.Bottom()
+}
+
+// Union returns the smallest rect containing both rects.
+// An empty rect does not contribute.
+func (me Rect) Union(rect Rect) Rect {
+	if rect.IsEmpty() {
+		return me
+	}
+	if me.IsEmpty() {
+		return rect
+	}
+	x1 := math.Min(me.X, rect.X)
+	x2 := math.Max(me.Right(), rect.Right())
+	y1 := math.Min(me.Y, rect.Y)
+	y2 := math.Max(me.Bottom(), rect.Bottom())
+	return Rect{x1, y1, x2 - x1, y2 - y1}
+}
+
+// Inflate grows the rect by dx on the left and right, and by dy on the top and bottom.
+func (me Rect) Inflate(dx, dy float64) Rect {
+	return Rect{me.X - dx, me.Y - dy, me.Width + 2*dx, me.Height + 2*dy}
+}
+
+// Offset moves the rect.
+func (me Rect) Offset(dx, dy float64) Rect {
+	return Rect{me.X + dx, me.Y + dy, me.Width, me.Height}
+}
+
+// BoundsOf returns the bounding rect of points, or an empty rect if there are none.
+func BoundsOf(points []Point) Rect {
+	if len(points) == 0 {
+		return Rect{}
+	}
+	x1, y1 := points[0].X, points[0].Y
+	x2, y2 := x1, y1
+	for _, pt := range points[1:] {
+		x1, x2 = math.Min(x1, pt.X), math.Max(x2, pt.X)
+		y1, y2 = math.Min(y1, pt.Y), math.Max(y2, pt.Y)
+	}
+	return Rect{x1, y1, x2 - x1, y2 - y1}
+}
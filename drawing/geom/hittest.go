@@ -0,0 +1,81 @@
+package geom
+
+import "math"
+
+// Contains tells whether a point is in the interior of the path according to its fill mode,
+// open figures being closed as when filled. Curves are flattened with tolerance.
+func (this *Path) Contains(pt Point, tolerance float64) bool {
+	if !this.ControlBounds().Contains(pt) {
+		return false
+	}
+	winding := 0
+	for _, line := range this.Flatten(Identity(), tolerance) {
+		winding += windingOf(line.Points, pt)
+	}
+	if this.FillMode == FillWinding {
+		return winding != 0
+	}
+	return winding%2 != 0
+}
+
+// windingOf returns the winding number of a closed polygon around a point.
+func windingOf(polygon []Point, pt Point) int {
+	winding := 0
+	for n, p0 := range polygon {
+		p1 := polygon[(n+1)%len(polygon)]
+		if p0.Y <= pt.Y {
+			if p1.Y > pt.Y && p1.Sub(p0).Cross(pt.Sub(p0)) > 0 {
+				winding++
+			}
+		} else if p1.Y <= pt.Y && p1.Sub(p0).Cross(pt.Sub(p0)) < 0 {
+			winding--
+		}
+	}
+	return winding
+}
+
+// Distance returns the distance from a point to the outline of the path,
+// closed figures including their closing lines, or +Inf if the path is empty.
+// Curves are flattened with tolerance.
+func (this *Path) Distance(pt Point, tolerance float64) float64 {
+	distance := math.Inf(1)
+	for _, line := range this.Flatten(Identity(), tolerance) {
+		points := line.Points
+		if len(points) == 1 {
+			distance = math.Min(distance, pt.Sub(points[0]).Len())
+		}
+		for n := 1; n < len(points); n++ {
+			distance = math.Min(distance, segmentDistance(pt, points[n-1], points[n]))
+		}
+		if line.Closed && len(points) > 2 {
+			distance = math.Min(distance, segmentDistance(pt, points[len(points)-1], points[0]))
+		}
+	}
+	return distance
+}
+
+// segmentDistance returns the distance from a point to the segment from p0 to p1.
+func segmentDistance(pt, p0, p1 Point) float64 {
+	d := p1.Sub(p0)
+	length := d.Dot(d)
+	if length == 0 {
+		return pt.Sub(p0).Len()
+	}
+	t := math.Max(0, math.Min(1, pt.Sub(p0).Dot(d)/length))
+	return pt.Sub(p0.Add(d.Mul(t))).Len()
+}
+
+// OutlineContains tells whether a point is within the stroke of the path,
+// with its joins, caps and dashes. Curves are flattened with tolerance.
+func (this *Path) OutlineContains(pt Point, stroke *Stroke, tolerance float64) bool {
+	//nothing of the stroke reaches farther than the longest miter or square cap
+	reach := stroke.Width / 2 * math.Max(math.Sqrt2, stroke.MiterLimit)
+	if !this.ControlBounds().Inflate(reach, reach).Contains(pt) {
+		return false
+	}
+	winding := 0
+	for _, polygon := range stroke.Outline(this.Flatten(Identity(), tolerance), tolerance) {
+		winding += windingOf(polygon, pt)
+	}
+	return winding != 0
+}
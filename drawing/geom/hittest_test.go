@@ -0,0 +1,204 @@
+package geom
+
+import (
+	"math"
+	"testing"
+)
+
+func rectPath(rect Rect) *Path {
+	path := NewPath()
+	path.AddRectangle(rect)
+	return path
+}
+
+func TestContains(t *testing.T) {
+	rect := rectPath(Rc(0, 0, 10, 10))
+	ellipse := NewPath()
+	ellipse.AddEllipse(Rc(0, 0, 20, 10))
+	tests := []struct {
+		name string
+		path *Path
+		pt   Point
+		want bool
+	}{
+		{"rect inside", rect, Pt(5, 5), true},
+		{"rect outside", rect, Pt(15, 5), false},
+		{"rect outside above", rect, Pt(5, -0.001), false},
+		//edges are half open like Rect.Contains: left and top in, right and bottom out
+		{"rect left edge", rect, Pt(0, 5), true},
+		{"rect top edge", rect, Pt(5, 0), true},
+		{"rect right edge", rect, Pt(10, 5), false},
+		{"rect bottom edge", rect, Pt(5, 10), false},
+		{"rect top left corner", rect, Pt(0, 0), true},
+		{"rect bottom right corner", rect, Pt(10, 10), false},
+		{"ellipse center", ellipse, Pt(10, 5), true},
+		{"ellipse near the left end", ellipse, Pt(0.5, 5), true},
+		{"ellipse within the bounds but outside", ellipse, Pt(1, 1), false},
+		{"ellipse outside", ellipse, Pt(21, 5), false},
+	}
+	for _, test := range tests {
+		if got := test.path.Contains(test.pt, DefaultFlatness); got != test.want {
+			t.Errorf("%s: Contains(%v) = %v, want %v", test.name, test.pt, got, test.want)
+		}
+	}
+}
+
+// TestContainsSharedEdge checks that a point on the edge shared by two
+// adjacent figures belongs to exactly one of them.
+func TestContainsSharedEdge(t *testing.T) {
+	left, right := rectPath(Rc(0, 0, 10, 10)), rectPath(Rc(10, 0, 10, 10))
+	top, bottom := rectPath(Rc(0, 0, 10, 10)), rectPath(Rc(0, 10, 10, 10))
+	for i := 0; i < 10; i++ {
+		v := float64(i)
+		if left.Contains(Pt(10, v), 0.25) == right.Contains(Pt(10, v), 0.25) {
+			t.Errorf("(10, %v) is in both or neither of the left and right rects", v)
+		}
+		if top.Contains(Pt(v, 10), 0.25) == bottom.Contains(Pt(v, 10), 0.25) {
+			t.Errorf("(%v, 10) is in both or neither of the top and bottom rects", v)
+		}
+	}
+}
+
+func TestContainsFillMode(t *testing.T) {
+	//a rect within a rect, both clockwise
+	path := rectPath(Rc(0, 0, 30, 30))
+	path.AddRectangle(Rc(10, 10, 10, 10))
+	if path.Contains(Pt(15, 15), DefaultFlatness) {
+		t.Error("the hole of an alternate path contains its center")
+	}
+	if !path.Contains(Pt(5, 15), DefaultFlatness) {
+		t.Error("the ring of an alternate path doesn't contain its points")
+	}
+	path.FillMode = FillWinding
+	if !path.Contains(Pt(15, 15), DefaultFlatness) {
+		t.Error("a winding path doesn't contain the center of its inner figure")
+	}
+
+	//an open figure is closed as when filled
+	open := NewPath()
+	open.AddLines([]Point{Pt(0, 0), Pt(10, 0), Pt(10, 10)})
+	if !open.Contains(Pt(8, 2), DefaultFlatness) || open.Contains(Pt(2, 8), DefaultFlatness) {
+		t.Error("the open triangle isn't closed as when filled")
+	}
+	if NewPath().Contains(Pt(0, 0), DefaultFlatness) {
+		t.Error("an empty path contains a point")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	rect := rectPath(Rc(0, 0, 10, 10))
+	line := NewPath()
+	line.AddLine(Pt(0, 0), Pt(10, 0))
+	circle := NewPath()
+	circle.AddEllipse(Rc(-10, -10, 20, 20))
+	tests := []struct {
+		name string
+		path *Path
+		pt   Point
+		want float64
+	}{
+		{"on the edge", rect, Pt(10, 5), 0},
+		{"inside", rect, Pt(3, 5), 3},
+		{"outside", rect, Pt(13, 14), 5},
+		{"closing line", rect, Pt(-2, 5), 2},
+		{"past the end of a line", line, Pt(13, 4), 5},
+		{"beside a line", line, Pt(5, -2), 2},
+		{"circle center", circle, Pt(0, 0), 10},
+		{"outside the circle", circle, Pt(0, 15), 5},
+	}
+	for _, test := range tests {
+		//the flattening tolerance bounds the error on curves
+		if got := test.path.Distance(test.pt, 0.01); math.Abs(got-test.want) > 0.01 {
+			t.Errorf("%s: Distance(%v) = %v, want %v", test.name, test.pt, got, test.want)
+		}
+	}
+	if !math.IsInf(NewPath().Distance(Pt(0, 0), DefaultFlatness), 1) {
+		t.Error("the distance to an empty path isn't infinite")
+	}
+}
+
+func TestOutlineContains(t *testing.T) {
+	line := NewPath()
+	line.AddLine(Pt(0, 0), Pt(100, 0))
+	tests := []struct {
+		name   string
+		stroke Stroke
+		pt     Point
+		want   bool
+	}{
+		{"on the line", Stroke{Width: 4}, Pt(50, 0), true},
+		{"within half the width", Stroke{Width: 4}, Pt(50, 1.9), true},
+		{"beyond half the width", Stroke{Width: 4}, Pt(50, 2.1), false},
+		{"past a flat cap", Stroke{Width: 4}, Pt(101, 0), false},
+		{"within a square cap", Stroke{Width: 4, StartCap: LineCapSquare, EndCap: LineCapSquare},
+			Pt(101.9, 1.9), true},
+		{"within a round cap", Stroke{Width: 4, StartCap: LineCapRound, EndCap: LineCapRound},
+			Pt(101.4, 1.4), true},
+		{"past the corner of a round cap", Stroke{Width: 4, StartCap: LineCapRound, EndCap: LineCapRound},
+			Pt(101.9, 1.9), false},
+		{"within a dash", Stroke{Width: 2, Dashes: []float64{10, 10}}, Pt(5, 0), true},
+		{"within a gap", Stroke{Width: 2, Dashes: []float64{10, 10}}, Pt(15, 0), false},
+		{"gap shifted by the offset", Stroke{Width: 2, Dashes: []float64{10, 10}, DashOffset: 10},
+			Pt(5, 0), false},
+	}
+	for _, test := range tests {
+		stroke := test.stroke
+		if got := line.OutlineContains(test.pt, &stroke, 0.01); got != test.want {
+			t.Errorf("%s: OutlineContains(%v) = %v, want %v", test.name, test.pt, got, test.want)
+		}
+	}
+}
+
+func TestOutlineContainsJoins(t *testing.T) {
+	//a right angle at (10, 0), whose miter tip is at (12, -2) with a width of 4
+	corner := NewPath()
+	corner.AddLines([]Point{Pt(0, 0), Pt(10, 0), Pt(10, 10)})
+	tip := Pt(11.9, -1.9)
+	tests := []struct {
+		join       LineJoin
+		miterLimit float64
+		want       bool
+	}{
+		{LineJoinMiter, 10, true},
+		{LineJoinMiter, 1, false}, //beveled past the limit
+		{LineJoinBevel, 10, false},
+		{LineJoinRound, 10, false},
+	}
+	for _, test := range tests {
+		stroke := &Stroke{Width: 4, Join: test.join, MiterLimit: test.miterLimit}
+		if got := corner.OutlineContains(tip, stroke, 0.01); got != test.want {
+			t.Errorf("join %d, miter limit %v: OutlineContains(%v) = %v, want %v",
+				test.join, test.miterLimit, tip, got, test.want)
+		}
+		if !corner.OutlineContains(Pt(10.5, -0.5), stroke, 0.01) {
+			t.Errorf("join %d: the inner part of the corner isn't covered", test.join)
+		}
+	}
+}
+
+func TestWiden(t *testing.T) {
+	line := NewPath()
+	line.AddLine(Pt(0, 0), Pt(100, 0))
+	tests := []struct {
+		name   string
+		stroke Stroke
+		want   Rect
+	}{
+		{"flat caps", Stroke{Width: 4}, Rc(0, -2, 100, 4)},
+		{"square caps", Stroke{Width: 4, StartCap: LineCapSquare, EndCap: LineCapSquare}, Rc(-2, -2, 104, 4)},
+		{"round end cap", Stroke{Width: 4, EndCap: LineCapRound}, Rc(0, -2, 102, 4)},
+		{"triangle start cap", Stroke{Width: 4, StartCap: LineCapTriangle}, Rc(-2, -2, 102, 4)},
+		//the last dash ends at 90
+		{"dashes", Stroke{Width: 2, Dashes: []float64{30, 30}}, Rc(0, -1, 90, 2)},
+	}
+	for _, test := range tests {
+		stroke := test.stroke
+		outline := line.Widen(&stroke, 0.01)
+		if outline.FillMode != FillWinding {
+			t.Errorf("%s: the outline doesn't use the winding fill mode", test.name)
+		}
+		if got := outline.Bounds(); !nearRect(got, test.want, 0.01) {
+			t.Errorf("%s: outline bounds %v, want %v", test.name, got, test.want)
+		}
+	}
+}
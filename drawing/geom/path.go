@@ -0,0 +1,370 @@
+package geom
+
+import "math"
+
+// Path point types, with the values of the GDI+ PathPointType.
+const (
+	PathPointStart        byte = 0    //the start of a figure
+	PathPointLine         byte = 1    //the end of a line from the previous point
+	PathPointBezier       byte = 3    //a control or end point of a cubic Bezier curve
+	PathPointTypeMask     byte = 0x07 //mask of the point type
+	PathPointMarker       byte = 0x20 //a marker
+	PathPointCloseSubpath byte = 0x80 //the last point of a closed figure
+)
+
+// FillMode tells how the interior of a path is determined,
+// with the values of the GDI+ FillMode.
+type FillMode int
+
+const (
+	FillAlternate FillMode = 0 //even-odd rule
+	FillWinding   FillMode = 1 //non-zero winding rule
+)
+
+// PathOp is the kind of a segment reported by Path.Iterate.
+type PathOp int
+
+const (
+	PathMoveTo  PathOp = iota //starts a figure at a point
+	PathLineTo                //a line to a point
+	PathCubicTo               //a cubic Bezier curve through two control points to a point
+	PathClose                 //closes the figure
+)
+
+// Path is a sequence of figures made of lines and cubic Bezier curves.
+// Its points and types have the layout of drawing.PathData,
+// so it converts to and from GDI+ paths without loss.
+type Path struct {
+	Points   []Point
+	Types    []byte
+	FillMode FillMode
+
+	startNew bool
+	current  Point
+	figStart Point
+}
+
+// NewPath creates an empty path with the alternate fill mode.
+func NewPath() *Path {
+	return &Path{startNew: true}
+}
+
+// NewPathFromData creates a path from points and types laid out as in drawing.PathData.
+func NewPathFromData(points []Point, types []byte, fillMode FillMode) *Path {
+	path := &Path{
+		Points:   append([]Point{}, points...),
+		Types:    append([]byte{}, types...),
+		FillMode: fillMode,
+		startNew: true,
+	}
+	if n := len(points); n > 0 && types[n-1]&PathPointCloseSubpath == 0 {
+		path.startNew = false
+		path.current = points[n-1]
+		for i := n - 1; i >= 0; i-- {
+			if types[i]&PathPointTypeMask == PathPointStart {
+				path.figStart = points[i]
+				break
+			}
+		}
+	}
+	return path
+}
+
+// Clone returns a copy of the path.
+func (this *Path) Clone() *Path {
+	path := *this
+	path.Points = append([]Point{}, this.Points...)
+	path.Types = append([]byte{}, this.Types...)
+	return &path
+}
+
+// Reset empties the path.
+func (this *Path) Reset() {
+	this.Points = this.Points[:0]
+	this.Types = this.Types[:0]
+	this.startNew = true
+}
+
+// IsEmpty tells whether the path has no points.
+func (this *Path) IsEmpty() bool {
+	return len(this.Points) == 0
+}
+
+// CurrentPoint returns the point the next segment starts from.
+func (this *Path) CurrentPoint() Point {
+	return this.current
+}
+
+func (this *Path) add(pt Point, typ byte) {
+	this.Points = append(this.Points, pt)
+	this.Types = append(this.Types, typ)
+	this.current = pt
+}
+
+// connect starts a new figure at pt, or continues the open figure
+// with a line to pt unless it is the current point.
+func (this *Path) connect(pt Point) {
+	if this.startNew || len(this.Points) == 0 {
+		this.startNew = false
+		this.figStart = pt
+		this.add(pt, PathPointStart)
+	} else if pt != this.current {
+		this.add(pt, PathPointLine)
+	}
+}
+
+// StartFigure makes the next segment start a new figure, leaving the current one open.
+func (this *Path) StartFigure() {
+	this.startNew = true
+}
+
+// CloseFigure closes the current figure.
+func (this *Path) CloseFigure() {
+	if !this.startNew && len(this.Types) > 0 {
+		this.Types[len(this.Types)-1] |= PathPointCloseSubpath
+		this.current = this.figStart
+	}
+	this.startNew = true
+}
+
+// MoveTo starts a new figure at (x, y).
+func (this *Path) MoveTo(x, y float64) {
+	pt := Point{x, y}
+	if n := len(this.Types); n > 0 && !this.startNew &&
+		this.Types[n-1] == PathPointStart {
+		this.Points[n-1] = pt //an empty figure
+		this.figStart, this.current = pt, pt
+		return
+	}
+	this.startNew = true
+	this.connect(pt)
+}
+
+// LineTo adds a line from the current point to (x, y).
+func (this *Path) LineTo(x, y float64) {
+	if this.startNew {
+		this.connect(this.current)
+	}
+	this.add(Point{x, y}, PathPointLine)
+}
+
+// BezierTo adds a cubic Bezier curve from the current point to (x, y).
+func (this *Path) BezierTo(x1, y1, x2, y2, x, y float64) {
+	if this.startNew {
+		this.connect(this.current)
+	}
+	this.add(Point{x1, y1}, PathPointBezier)
+	this.add(Point{x2, y2}, PathPointBezier)
+	this.add(Point{x, y}, PathPointBezier)
+}
+
+// QuadTo adds a quadratic Bezier curve from the current point to (x, y),
+// as the equivalent cubic curve.
+func (this *Path) QuadTo(x1, y1, x, y float64) {
+	p0 := this.current
+	c := Point{x1, y1}
+	p := Point{x, y}
+	c1 := p0.Add(c.Sub(p0).Mul(2.0 / 3))
+	c2 := p.Add(c.Sub(p).Mul(2.0 / 3))
+	this.BezierTo(c1.X, c1.Y, c2.X, c2.Y, x, y)
+}
+
+// AddLine adds a line to the current figure.
+func (this *Path) AddLine(pt1, pt2 Point) {
+	this.connect(pt1)
+	this.add(pt2, PathPointLine)
+}
+
+// AddLines adds a polyline to the current figure.
+func (this *Path) AddLines(points []Point) {
+	if len(points) == 0 {
+		return
+	}
+	this.connect(points[0])
+	for _, pt := range points[1:] {
+		this.add(pt, PathPointLine)
+	}
+}
+
+// AddBezier adds a cubic Bezier curve to the current figure.
+func (this *Path) AddBezier(pt1, pt2, pt3, pt4 Point) {
+	this.connect(pt1)
+	this.add(pt2, PathPointBezier)
+	this.add(pt3, PathPointBezier)
+	this.add(pt4, PathPointBezier)
+}
+
+// AddArc adds an arc of the ellipse bounded by rect to the current figure.
+// The angles are in degrees, clockwise from the x axis, as in GDI+.
+func (this *Path) AddArc(rect Rect, startAngle, sweepAngle float64) {
+	points := arcBeziers(rect, startAngle, sweepAngle)
+	this.connect(points[0])
+	for _, pt := range points[1:] {
+		this.add(pt, PathPointBezier)
+	}
+}
+
+// AddRectangle adds a rectangle as a closed figure.
+func (this *Path) AddRectangle(rect Rect) {
+	this.AddPolygon([]Point{
+		{rect.X, rect.Y}, {rect.Right(), rect.Y},
+		{rect.Right(), rect.Bottom()}, {rect.X, rect.Bottom()},
+	})
+}
+
+// AddRoundedRectangle adds a rectangle with elliptic corners as a closed figure.
+func (this *Path) AddRoundedRectangle(rect Rect, rx, ry float64) {
+	rx = math.Min(math.Max(rx, 0), rect.Width/2)
+	ry = math.Min(math.Max(ry, 0), rect.Height/2)
+	if rx == 0 || ry == 0 {
+		this.AddRectangle(rect)
+		return
+	}
+	w, h := 2*rx, 2*ry
+	this.StartFigure()
+	this.AddArc(Rect{rect.Right() - w, rect.Y, w, h}, 270, 90)
+	this.AddArc(Rect{rect.Right() - w, rect.Bottom() - h, w, h}, 0, 90)
+	this.AddArc(Rect{rect.X, rect.Bottom() - h, w, h}, 90, 90)
+	this.AddArc(Rect{rect.X, rect.Y, w, h}, 180, 90)
+	this.CloseFigure()
+}
+
+// AddEllipse adds the ellipse bounded by rect as a closed figure.
+func (this *Path) AddEllipse(rect Rect) {
+	this.StartFigure()
+	this.AddArc(rect, 0, 360)
+	this.CloseFigure()
+}
+
+// AddPie adds a pie slice of the ellipse bounded by rect as a closed figure.
+func (this *Path) AddPie(rect Rect, startAngle, sweepAngle float64) {
+	this.StartFigure()
+	this.connect(rect.Center())
+	this.AddArc(rect, startAngle, sweepAngle)
+	this.CloseFigure()
+}
+
+// AddPolygon adds a polygon as a closed figure.
+func (this *Path) AddPolygon(points []Point) {
+	if len(points) == 0 {
+		return
+	}
+	this.StartFigure()
+	this.AddLines(points)
+	this.CloseFigure()
+}
+
+// AddPath adds the figures of another path.
+// If connect is set, the first figure continues the current one.
+func (this *Path) AddPath(path *Path, connect bool) {
+	first := true
+	path.Iterate(func(op PathOp, points []Point) {
+		switch op {
+		case PathMoveTo:
+			if !(first && connect) {
+				this.StartFigure()
+			}
+			this.connect(points[0])
+			first = false
+		case PathLineTo:
+			this.add(points[0], PathPointLine)
+		case PathCubicTo:
+			this.add(points[0], PathPointBezier)
+			this.add(points[1], PathPointBezier)
+			this.add(points[2], PathPointBezier)
+		case PathClose:
+			this.CloseFigure()
+		}
+	})
+}
+
+// Transform maps the points of the path.
+func (this *Path) Transform(m Affine) {
+	m.TransformPoints(this.Points)
+	this.current = m.Transform(this.current)
+	this.figStart = m.Transform(this.figStart)
+}
+
+// ControlBounds returns the bounding rect of the points of the path,
+// which contains the path but may exceed its curves.
+func (this *Path) ControlBounds() Rect {
+	return BoundsOf(this.Points)
+}
+
+// Iterate reports the segments of the path in order.
+// The points passed are the end point, preceded by the control points of curves.
+func (this *Path) Iterate(fn func(op PathOp, points []Point)) {
+	count := len(this.Points)
+	for n := 0; n < count; n++ {
+		typ := this.Types[n]
+		switch typ & PathPointTypeMask {
+		case PathPointStart:
+			fn(PathMoveTo, this.Points[n:n+1])
+		case PathPointBezier:
+			if n+2 < count {
+				fn(PathCubicTo, this.Points[n:n+3])
+				n += 2
+				typ = this.Types[n]
+			} else {
+				fn(PathLineTo, this.Points[n:n+1])
+			}
+		default:
+			fn(PathLineTo, this.Points[n:n+1])
+		}
+		if typ&PathPointCloseSubpath != 0 {
+			fn(PathClose, nil)
+		}
+	}
+}
+
+// ellipseParam converts an angle of GDI+ arcs into the parametric angle,
+// in radians, of the ellipse with radii rx and ry.
+func ellipseParam(degrees, rx, ry float64) float64 {
+	rad := degrees * math.Pi / 180
+	sin, cos := math.Sincos(rad)
+	d := math.Atan2(rx*sin, ry*cos) - math.Atan2(sin, cos)
+	if d > math.Pi {
+		d -= 2 * math.Pi
+	} else if d < -math.Pi {
+		d += 2 * math.Pi
+	}
+	return rad + d
+}
+
+// arcBeziers returns the start point and the Bezier points of an elliptic arc.
+func arcBeziers(rect Rect, startAngle, sweepAngle float64) []Point {
+	sweepAngle = math.Max(-360, math.Min(360, sweepAngle))
+	rx, ry := rect.Width/2, rect.Height/2
+	cx, cy := rect.X+rx, rect.Y+ry
+	t1 := ellipseParam(startAngle, rx, ry)
+	t2 := ellipseParam(startAngle+sweepAngle, rx, ry)
+	if math.Abs(sweepAngle) == 360 {
+		t2 = t1 + sweepAngle*math.Pi/180
+	}
+	at := func(t float64) Point {
+		return Point{cx + rx*math.Cos(t), cy + ry*math.Sin(t)}
+	}
+	tangent := func(t float64) Point {
+		return Point{-rx * math.Sin(t), ry * math.Cos(t)}
+	}
+	segments := int(math.Ceil(math.Abs(t2-t1)/(math.Pi/2) - 1e-9))
+	if segments < 1 {
+		segments = 1
+	}
+	delta := (t2 - t1) / float64(segments)
+	k := 4.0 / 3 * math.Tan(delta/4)
+	points := []Point{at(t1)}
+	for i := 0; i < segments; i++ {
+		a := t1 + delta*float64(i)
+		b := a + delta
+		if i == segments-1 {
+			b = t2
+		}
+		p0, p3 := at(a), at(b)
+		points = append(points,
+			p0.Add(tangent(a).Mul(k)),
+			p3.Sub(tangent(b).Mul(k)),
+			p3)
+	}
+	return points
+}
@@ -0,0 +1,318 @@
+package geom
+
+import "math"
+
+// LineCap is the shape of the ends of open lines, with the values of the GDI+ LineCap.
+type LineCap int
+
+const (
+	LineCapFlat     LineCap = 0 //ends at the end point
+	LineCapSquare   LineCap = 1 //extends half the width past the end point
+	LineCapRound    LineCap = 2 //a half disc centered at the end point
+	LineCapTriangle LineCap = 3 //a triangle pointing half the width past the end point
+)
+
+// LineJoin is the shape of the corners of lines, with the values of the GDI+ LineJoin.
+type LineJoin int
+
+const (
+	LineJoinMiter        LineJoin = 0 //sharp corners, beveled past the miter limit
+	LineJoinBevel        LineJoin = 1
+	LineJoinRound        LineJoin = 2
+	LineJoinMiterClipped LineJoin = 3 //sharp corners, clipped at the miter limit
+)
+
+// Stroke describes the geometry of the outline of stroked lines.
+type Stroke struct {
+	Width      float64
+	StartCap   LineCap
+	EndCap     LineCap
+	Join       LineJoin
+	MiterLimit float64   //the longest miter, in multiples of half the width
+	Dashes     []float64 //dash and gap lengths, nil for solid lines
+	DashOffset float64   //the distance into the dash pattern at the start of lines
+	DashCap    LineCap   //cap of the ends of dashes within lines
+}
+
+// Outline returns the polygons covering the stroke of polylines.
+// All the polygons are oriented alike, so that filling them
+// with the winding rule paints the stroke. Round joins and caps deviate
+// from their arcs by at most tolerance.
+func (this *Stroke) Outline(lines []Polyline, tolerance float64) [][]Point {
+	s := &stroker{style: this, tolerance: tolerance}
+	total := 0.0
+	for _, dash := range this.Dashes {
+		total += math.Max(dash, 0)
+	}
+	s.dashed = total > 0
+	for _, line := range lines {
+		s.stroke(line)
+	}
+	return s.polygons
+}
+
+// Widen returns the outline of the stroke of the path, as a path with the winding fill mode.
+// Curves are flattened with tolerance.
+func (this *Path) Widen(stroke *Stroke, tolerance float64) *Path {
+	outline := NewPath()
+	outline.FillMode = FillWinding
+	for _, polygon := range stroke.Outline(this.Flatten(Identity(), tolerance), tolerance) {
+		outline.AddPolygon(polygon)
+	}
+	return outline
+}
+
+// stroker converts polylines into polygons covering their strokes.
+type stroker struct {
+	style     *Stroke
+	tolerance float64
+	dashed    bool
+	polygons  [][]Point
+}
+
+// addPolygon adds a polygon, oriented positively.
+func (this *stroker) addPolygon(points ...Point) {
+	area := 0.0
+	for n, p := range points {
+		q := points[(n+1)%len(points)]
+		area += p.Cross(q)
+	}
+	if area < 0 {
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	this.polygons = append(this.polygons, points)
+}
+
+// stroke adds the polygons of a polyline, dashed if the style says so.
+func (this *stroker) stroke(line Polyline) {
+	points := dedupe(line.Points)
+	if line.Closed && len(points) > 1 && points[0] == points[len(points)-1] {
+		points = points[:len(points)-1]
+	}
+	if !this.dashed {
+		this.strokePolyline(points, line.Closed, this.style.StartCap, this.style.EndCap)
+		return
+	}
+	if line.Closed && len(points) > 1 {
+		points = append(points, points[0])
+	}
+	this.dash(points)
+}
+
+func dedupe(points []Point) []Point {
+	result := make([]Point, 0, len(points))
+	for n, pt := range points {
+		if n == 0 || pt != result[len(result)-1] {
+			result = append(result, pt)
+		}
+	}
+	return result
+}
+
+// dash splits an open polyline into dashes and strokes them.
+func (this *stroker) dash(points []Point) {
+	dashes := make([]float64, len(this.style.Dashes))
+	total := 0.0
+	for n, d := range this.style.Dashes {
+		dashes[n] = math.Max(d, 0)
+		total += dashes[n]
+	}
+	index := 0
+	remaining := dashes[0]
+	offset := math.Mod(this.style.DashOffset, total)
+	if offset < 0 {
+		offset += total
+	}
+	for offset > 0 {
+		if offset < remaining {
+			remaining -= offset
+			break
+		}
+		offset -= remaining
+		index = (index + 1) % len(dashes)
+		remaining = dashes[index]
+	}
+
+	var dash []Point
+	on := index%2 == 0
+	first := on //whether the dash starts at the start of the line
+	if on {
+		dash = append(dash, points[0])
+	}
+	emit := func(last bool) {
+		if len(dash) > 0 {
+			startCap, endCap := this.style.DashCap, this.style.DashCap
+			if first {
+				startCap = this.style.StartCap
+			}
+			if last {
+				endCap = this.style.EndCap
+			}
+			this.strokePolyline(dash, false, startCap, endCap)
+		}
+		first = false
+		dash = nil
+	}
+	for n := 1; n < len(points); n++ {
+		p0, p1 := points[n-1], points[n]
+		segment := p1.Sub(p0).Len()
+		pos := 0.0
+		for segment-pos > remaining {
+			pos += remaining
+			pt := p0.Lerp(p1, pos/segment)
+			if on {
+				dash = append(dash, pt)
+				emit(false)
+			} else {
+				dash = []Point{pt}
+			}
+			on = !on
+			index = (index + 1) % len(dashes)
+			remaining = dashes[index]
+		}
+		remaining -= segment - pos
+		if on {
+			dash = append(dash, p1)
+		}
+	}
+	if on {
+		emit(true)
+	}
+}
+
+// strokePolyline adds the polygons of the segments, joins and caps of a polyline.
+func (this *stroker) strokePolyline(points []Point, closed bool, startCap, endCap LineCap) {
+	hw := this.style.Width / 2
+	if len(points) == 0 {
+		return
+	}
+	if len(points) == 1 {
+		this.dot(points[0], startCap)
+		return
+	}
+	if len(points) == 2 && closed {
+		closed = false
+	}
+	count := len(points)
+	segments := count - 1
+	if closed {
+		segments = count
+	}
+	for n := 0; n < segments; n++ {
+		p0, p1 := points[n], points[(n+1)%count]
+		normal := normalOf(p0, p1).Mul(hw)
+		this.addPolygon(p0.Add(normal), p1.Add(normal), p1.Sub(normal), p0.Sub(normal))
+	}
+	for n := 0; n < count; n++ {
+		if !closed && (n == 0 || n == count-1) {
+			continue
+		}
+		prev, next := points[(n+count-1)%count], points[(n+1)%count]
+		this.joinAt(prev, points[n], next)
+	}
+	if !closed {
+		this.capAt(points[0], points[1], startCap)
+		this.capAt(points[count-1], points[count-2], endCap)
+	}
+}
+
+// normalOf returns the unit normal of the direction from p0 to p1.
+func normalOf(p0, p1 Point) Point {
+	d := p1.Sub(p0).Normalize()
+	return Point{X: -d.Y, Y: d.X}
+}
+
+// joinAt adds the join at vertex v between the segments from prev and to next.
+func (this *stroker) joinAt(prev, v, next Point) {
+	hw := this.style.Width / 2
+	d0 := v.Sub(prev).Normalize()
+	d1 := next.Sub(v).Normalize()
+	cross := d0.Cross(d1)
+	if math.Abs(cross) < 1e-12 && d0.Dot(d1) > 0 {
+		return //straight
+	}
+	if this.style.Join == LineJoinRound {
+		this.circle(v, hw)
+		return
+	}
+	side := 1.0
+	if cross > 0 {
+		side = -1
+	}
+	n0 := Point{X: -d0.Y, Y: d0.X}.Mul(side)
+	n1 := Point{X: -d1.Y, Y: d1.X}.Mul(side)
+	a0 := v.Add(n0.Mul(hw))
+	a1 := v.Add(n1.Mul(hw))
+	if this.style.Join == LineJoinBevel {
+		this.addPolygon(v, a0, a1)
+		return
+	}
+	m := n0.Add(n1).Normalize()
+	cosHalf := m.Dot(n0)
+	if cosHalf <= 1e-9 {
+		this.addPolygon(v, a0, a1)
+		return
+	}
+	miterLength := hw / cosHalf
+	limit := this.style.MiterLimit * hw
+	if miterLength <= limit {
+		this.addPolygon(v, a0, v.Add(m.Mul(miterLength)), a1)
+		return
+	}
+	if this.style.Join != LineJoinMiterClipped {
+		this.addPolygon(v, a0, a1)
+		return
+	}
+	//clip the miter at the limit, perpendicularly to its direction
+	s0 := (limit - a0.Sub(v).Dot(m)) / d0.Dot(m)
+	s1 := (limit - a1.Sub(v).Dot(m)) / -d1.Dot(m)
+	this.addPolygon(v, a0, a0.Add(d0.Mul(s0)), a1.Sub(d1.Mul(s1)), a1)
+}
+
+// capAt adds the cap at the end point p of a line coming from the point from.
+func (this *stroker) capAt(p, from Point, lineCap LineCap) {
+	hw := this.style.Width / 2
+	d := p.Sub(from).Normalize()
+	n := Point{X: -d.Y, Y: d.X}.Mul(hw)
+	switch lineCap {
+	case LineCapSquare:
+		ext := d.Mul(hw)
+		this.addPolygon(p.Add(n), p.Add(n).Add(ext), p.Sub(n).Add(ext), p.Sub(n))
+	case LineCapRound:
+		this.circle(p, hw)
+	case LineCapTriangle:
+		this.addPolygon(p.Add(n), p.Add(d.Mul(hw)), p.Sub(n))
+	}
+}
+
+// dot adds the stroke of a zero-length line, which only has caps.
+func (this *stroker) dot(p Point, lineCap LineCap) {
+	hw := this.style.Width / 2
+	switch lineCap {
+	case LineCapSquare:
+		this.addPolygon(Pt(p.X-hw, p.Y-hw), Pt(p.X+hw, p.Y-hw),
+			Pt(p.X+hw, p.Y+hw), Pt(p.X-hw, p.Y+hw))
+	case LineCapRound:
+		this.circle(p, hw)
+	}
+}
+
+func (this *stroker) circle(center Point, radius float64) {
+	if radius <= 0 {
+		return
+	}
+	segments := 8
+	if this.tolerance > 0 && this.tolerance < radius {
+		step := 2 * math.Acos(1-this.tolerance/radius)
+		segments = max(segments, int(math.Ceil(2*math.Pi/step)))
+	}
+	segments = min(segments, 256)
+	points := make([]Point, segments)
+	for n := range points {
+		sin, cos := math.Sincos(2 * math.Pi * float64(n) / float64(segments))
+		points[n] = Pt(center.X+radius*cos, center.Y+radius*sin)
+	}
+	this.addPolygon(points...)
+}
@@ -3,7 +3,7 @@ package drawing
 import (
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
-	"log"
+	"github.com/zzl/goforms/drawing/geom"
 	"runtime"
 	"syscall"
 )
@@ -180,17 +180,31 @@ func (this *Graphics) SetInterpolationMode(mode gdip.InterpolationMode) {
 }
 
 func (this *Graphics) GetTransform(s *Scope) *Matrix {
-	var pMatrix *gdip.Matrix
-	status := gdip.GetWorldTransform(this.p, pMatrix)
+	matrix := NewMatrix(s)
+	status := gdip.GetWorldTransform(this.p, matrix.p)
 	checkStatus(status)
-	return newMatrix(s, pMatrix)
+	return matrix
 }
 
 func (this *Graphics) SetTransform(matrix *Matrix) {
-	status := gdip.SetWorldTransform(this.p, matrix.p)
+	status := gdip.SetWorldTransform(this.p, matrix.handle())
 	checkStatus(status)
 }
 
+// GetAffineTransform returns the world transform as an affine transformation.
+func (this *Graphics) GetAffineTransform() geom.Affine {
+	matrix := this.GetTransform(nil)
+	defer matrix.Dispose()
+	return matrix.Affine()
+}
+
+// SetAffineTransform sets the world transform to an affine transformation.
+func (this *Graphics) SetAffineTransform(m geom.Affine) {
+	matrix := NewMatrixFromAffine(nil, m)
+	defer matrix.Dispose()
+	this.SetTransform(matrix)
+}
+
 func (this *Graphics) GetPageUnit() gdip.Unit {
 	var unit gdip.Unit
 	status := gdip.GetPageUnit(this.p, &unit)
@@ -255,7 +269,7 @@ func (this *Graphics) MultiplyTransform(matrix *Matrix, append bool) {
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.MultiplyWorldTransform(this.p, matrix.p, order)
+	status := gdip.MultiplyWorldTransform(this.p, matrix.handle(), order)
 	checkStatus(status)
 }
 
@@ -301,7 +315,7 @@ func (this *Graphics) TransformPoints(dstSpace gdip.CoordinateSpace,
 }
 
 func (this *Graphics) DrawLine(pen *Pen, x1, y1, x2, y2 int32) {
-	status := gdip.DrawLineI(this.p, pen.p, x1, y1, x2, y2)
+	status := gdip.DrawLineI(this.p, pen.handle(), x1, y1, x2, y2)
 	checkStatus(status)
 }
 
@@ -310,7 +324,7 @@ func (this *Graphics) DrawLinePt(pen *Pen, pt1, pt2 Point) {
 }
 
 func (this *Graphics) DrawLineF(pen *Pen, x1, y1, x2, y2 float32) {
-	status := gdip.DrawLine(this.p, pen.p, x1, y1, x2, y2)
+	status := gdip.DrawLine(this.p, pen.handle(), x1, y1, x2, y2)
 	checkStatus(status)
 }
 
@@ -319,187 +333,185 @@ func (this *Graphics) DrawLinePtF(pen *Pen, pt1, pt2 PointF) {
 }
 
 func (this *Graphics) DrawLines(pen *Pen, points []Point) {
-	status := gdip.DrawLinesI(this.p, pen.p, &points[0], int32(len(points)))
+	status := gdip.DrawLinesI(this.p, pen.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawLinesF(pen *Pen, points []PointF) {
-	status := gdip.DrawLines(this.p, pen.p, &points[0], int32(len(points)))
+	status := gdip.DrawLines(this.p, pen.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawArc(pen *Pen, x, y, w, h int32, startAngle, sweepAngle float32) {
-	status := gdip.DrawArcI(this.p, pen.p, x, y, w, h, startAngle, sweepAngle)
+	status := gdip.DrawArcI(this.p, pen.handle(), x, y, w, h, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawArcF(pen *Pen, x, y, w, h, startAngle, sweepAngle float32) {
-	status := gdip.DrawArc(this.p, pen.p, x, y, w, h, startAngle, sweepAngle)
+	status := gdip.DrawArc(this.p, pen.handle(), x, y, w, h, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawArcRect(pen *Pen, rect Rect, startAngle, sweepAngle float32) {
-	status := gdip.DrawArcI(this.p, pen.p, rect.X, rect.Y,
+	status := gdip.DrawArcI(this.p, pen.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawArcRectF(pen *Pen, rect RectF, startAngle, sweepAngle float32) {
-	status := gdip.DrawArc(this.p, pen.p, rect.X, rect.Y,
+	status := gdip.DrawArc(this.p, pen.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawBezier(pen *Pen, pt1, pt2, pt3, pt4 Point) {
-	status := gdip.DrawBezierI(this.p, pen.p, pt1.X, pt1.Y,
+	status := gdip.DrawBezierI(this.p, pen.handle(), pt1.X, pt1.Y,
 		pt2.X, pt2.Y, pt3.X, pt3.Y, pt4.X, pt4.Y)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawBezierF(pen *Pen, pt1, pt2, pt3, pt4 PointF) {
-	status := gdip.DrawBezier(this.p, pen.p, pt1.X, pt1.Y,
+	status := gdip.DrawBezier(this.p, pen.handle(), pt1.X, pt1.Y,
 		pt2.X, pt2.Y, pt3.X, pt3.Y, pt4.X, pt4.Y)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawBeziers(pen *Pen, points []Point) {
-	status := gdip.DrawBeziersI(this.p, pen.p, &points[0], int32(len(points)))
+	status := gdip.DrawBeziersI(this.p, pen.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawBeziersF(pen *Pen, points []PointF) {
-	status := gdip.DrawBeziers(this.p, pen.p, &points[0], int32(len(points)))
+	status := gdip.DrawBeziers(this.p, pen.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawRectangle(pen *Pen, x, y, width, height int32) {
-	status := gdip.DrawRectangleI(this.p, pen.p, x, y, width, height)
+	status := gdip.DrawRectangleI(this.p, pen.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawRectangleF(pen *Pen, x, y, width, height float32) {
-	status := gdip.DrawRectangle(this.p, pen.p, x, y, width, height)
+	status := gdip.DrawRectangle(this.p, pen.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawRectangleRect(pen *Pen, rect Rect) {
-	status := gdip.DrawRectangleI(this.p, pen.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.DrawRectangleI(this.p, pen.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawRectangleRectF(pen *Pen, rect RectF) {
-	status := gdip.DrawRectangle(this.p, pen.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.DrawRectangle(this.p, pen.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawRectangles(pen *Pen, rects []Rect) {
-	status := gdip.DrawRectanglesI(this.p, pen.p,
+	status := gdip.DrawRectanglesI(this.p, pen.handle(),
 		(*gdip.Rect)(&rects[0]), int32(len(rects)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawRectanglesF(pen *Pen, rects []RectF) {
-	status := gdip.DrawRectangles(this.p, pen.p,
+	status := gdip.DrawRectangles(this.p, pen.handle(),
 		(*gdip.RectF)(&rects[0]), int32(len(rects)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawEllipse(pen *Pen, x, y, width, height int32) {
-	status := gdip.DrawEllipseI(this.p, pen.p, x, y, width, height)
+	status := gdip.DrawEllipseI(this.p, pen.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawEllipseF(pen *Pen, x, y, width, height float32) {
-	status := gdip.DrawEllipse(this.p, pen.p, x, y, width, height)
+	status := gdip.DrawEllipse(this.p, pen.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawEllipseRect(pen *Pen, rect Rect) {
-	status := gdip.DrawEllipseI(this.p, pen.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.DrawEllipseI(this.p, pen.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawEllipseRectF(pen *Pen, rect RectF) {
-	status := gdip.DrawEllipse(this.p, pen.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.DrawEllipse(this.p, pen.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawPie(pen *Pen, x, y, width, height int32, startAngle, sweepAngle float32) {
-	status := gdip.DrawPieI(this.p, pen.p, x, y, width, height, startAngle, sweepAngle)
+	status := gdip.DrawPieI(this.p, pen.handle(), x, y, width, height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawPieF(pen *Pen, x, y, width, height, startAngle, sweepAngle float32) {
-	status := gdip.DrawPie(this.p, pen.p, x, y, width, height, startAngle, sweepAngle)
+	status := gdip.DrawPie(this.p, pen.handle(), x, y, width, height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawPieRect(pen *Pen, rect Rect, startAngle, sweepAngle float32) {
-	status := gdip.DrawPieI(this.p, pen.p, rect.X, rect.Y,
+	status := gdip.DrawPieI(this.p, pen.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawPieRectF(pen *Pen, rect RectF, startAngle, sweepAngle float32) {
-	status := gdip.DrawPie(this.p, pen.p, rect.X, rect.Y,
+	status := gdip.DrawPie(this.p, pen.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawPolygon(pen *Pen, pts []Point) {
-	status := gdip.DrawPolygonI(this.p, pen.p, &pts[0], int32(len(pts)))
+	status := gdip.DrawPolygonI(this.p, pen.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawPolygonF(pen *Pen, pts []PointF) {
-	status := gdip.DrawPolygon(this.p, pen.p, &pts[0], int32(len(pts)))
-	if status != gdip.Ok {
-		log.Fatal(status)
-	}
+	status := gdip.DrawPolygon(this.p, pen.handle(), &pts[0], int32(len(pts)))
+	checkStatus(status)
 }
 
 func (this *Graphics) DrawPath(pen *Pen, path *Path) {
-	status := gdip.DrawPath(this.p, pen.p, path.p)
+	status := gdip.DrawPath(this.p, pen.handle(), path.handle())
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawCurve(pen *Pen, pts []Point) {
-	status := gdip.DrawCurveI(this.p, pen.p, &pts[0], int32(len(pts)))
+	status := gdip.DrawCurveI(this.p, pen.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawCurveF(pen *Pen, pts []PointF) {
-	status := gdip.DrawCurve(this.p, pen.p, &pts[0], int32(len(pts)))
+	status := gdip.DrawCurve(this.p, pen.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawCurve2(pen *Pen, pts []Point, tension float32) {
-	status := gdip.DrawCurve2I(this.p, pen.p, &pts[0], int32(len(pts)), tension)
+	status := gdip.DrawCurve2I(this.p, pen.handle(), &pts[0], int32(len(pts)), tension)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawCurve2F(pen *Pen, pts []PointF, tension float32) {
-	status := gdip.DrawCurve2(this.p, pen.p, &pts[0], int32(len(pts)), tension)
+	status := gdip.DrawCurve2(this.p, pen.handle(), &pts[0], int32(len(pts)), tension)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawClosedCurve(pen *Pen, pts []Point) {
-	status := gdip.DrawClosedCurveI(this.p, pen.p, &pts[0], int32(len(pts)))
+	status := gdip.DrawClosedCurveI(this.p, pen.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawClosedCurveF(pen *Pen, pts []PointF) {
-	status := gdip.DrawClosedCurve(this.p, pen.p, &pts[0], int32(len(pts)))
+	status := gdip.DrawClosedCurve(this.p, pen.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawClosedCurve2(pen *Pen, pts []Point, tension float32) {
-	status := gdip.DrawClosedCurve2I(this.p, pen.p, &pts[0], int32(len(pts)), tension)
+	status := gdip.DrawClosedCurve2I(this.p, pen.handle(), &pts[0], int32(len(pts)), tension)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawClosedCurve2F(pen *Pen, pts []PointF, tension float32) {
-	status := gdip.DrawClosedCurve2(this.p, pen.p, &pts[0], int32(len(pts)), tension)
+	status := gdip.DrawClosedCurve2(this.p, pen.handle(), &pts[0], int32(len(pts)), tension)
 	checkStatus(status)
 }
 
@@ -509,33 +521,33 @@ func (this *Graphics) Clear(color Color) {
 }
 
 func (this *Graphics) FillRectangle(brush *Brush, x, y, width, height int32) {
-	status := gdip.FillRectangleI(this.p, brush.p, x, y, width, height)
+	status := gdip.FillRectangleI(this.p, brush.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillRectangleF(brush *Brush, x, y, width, height float32) {
-	status := gdip.FillRectangle(this.p, brush.p, x, y, width, height)
+	status := gdip.FillRectangle(this.p, brush.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillRectangleRect(brush *Brush, rect Rect) {
-	status := gdip.FillRectangleI(this.p, brush.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.FillRectangleI(this.p, brush.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillRectangleRectF(brush *Brush, rect RectF) {
-	status := gdip.FillRectangle(this.p, brush.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.FillRectangle(this.p, brush.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillRectangles(brush *Brush, rects []Rect) {
-	status := gdip.FillRectanglesI(this.p, brush.p,
+	status := gdip.FillRectanglesI(this.p, brush.handle(),
 		(*gdip.Rect)(&rects[0]), int32(len(rects)))
 	checkStatus(status)
 }
 
 func (this *Graphics) FillRectanglesF(brush *Brush, rects []RectF) {
-	status := gdip.FillRectangles(this.p, brush.p,
+	status := gdip.FillRectangles(this.p, brush.handle(),
 		(*gdip.RectF)(&rects[0]), int32(len(rects)))
 	checkStatus(status)
 }
@@ -545,7 +557,7 @@ func (this *Graphics) FillPolygon(brush *Brush, pts []Point, fillWinding bool) {
 	if fillWinding {
 		fillMode = gdip.FillModeWinding
 	}
-	status := gdip.FillPolygonI(this.p, brush.p, &pts[0], int32(len(pts)), fillMode)
+	status := gdip.FillPolygonI(this.p, brush.handle(), &pts[0], int32(len(pts)), fillMode)
 	checkStatus(status)
 }
 
@@ -554,64 +566,64 @@ func (this *Graphics) FillPolygonF(brush *Brush, pts []PointF, fillWinding bool)
 	if fillWinding {
 		fillMode = gdip.FillModeWinding
 	}
-	status := gdip.FillPolygon(this.p, brush.p, &pts[0], int32(len(pts)), fillMode)
+	status := gdip.FillPolygon(this.p, brush.handle(), &pts[0], int32(len(pts)), fillMode)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillEllipse(brush *Brush, x, y, width, height int32) {
-	status := gdip.FillEllipseI(this.p, brush.p, x, y, width, height)
+	status := gdip.FillEllipseI(this.p, brush.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillEllipseF(brush *Brush, x, y, width, height float32) {
-	status := gdip.FillEllipse(this.p, brush.p, x, y, width, height)
+	status := gdip.FillEllipse(this.p, brush.handle(), x, y, width, height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillEllipseRect(brush *Brush, rect Rect) {
-	status := gdip.FillEllipseI(this.p, brush.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.FillEllipseI(this.p, brush.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillEllipseRectF(brush *Brush, rect RectF) {
-	status := gdip.FillEllipse(this.p, brush.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.FillEllipse(this.p, brush.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillPie(brush *Brush, x, y, w, h int32, startAngle, sweepAngle float32) {
-	status := gdip.FillPieI(this.p, brush.p, x, y, w, h, startAngle, sweepAngle)
+	status := gdip.FillPieI(this.p, brush.handle(), x, y, w, h, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillPieF(brush *Brush, x, y, w, h, startAngle, sweepAngle float32) {
-	status := gdip.FillPie(this.p, brush.p, x, y, w, h, startAngle, sweepAngle)
+	status := gdip.FillPie(this.p, brush.handle(), x, y, w, h, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillPieRect(brush *Brush, rect Rect, startAngle, sweepAngle float32) {
-	status := gdip.FillPieI(this.p, brush.p, rect.X, rect.Y,
+	status := gdip.FillPieI(this.p, brush.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillPieRectF(brush *Brush, rect RectF, startAngle, sweepAngle float32) {
-	status := gdip.FillPie(this.p, brush.p, rect.X, rect.Y,
+	status := gdip.FillPie(this.p, brush.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillPath(brush *Brush, path *Path) {
-	status := gdip.FillPath(this.p, brush.p, path.p)
+	status := gdip.FillPath(this.p, brush.handle(), path.handle())
 	checkStatus(status)
 }
 
 func (this *Graphics) FillClosedCurve(brush *Brush, pts []Point) {
-	status := gdip.FillClosedCurveI(this.p, brush.p, &pts[0], int32(len(pts)))
+	status := gdip.FillClosedCurveI(this.p, brush.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Graphics) FillClosedCurveF(brush *Brush, pts []PointF) {
-	status := gdip.FillClosedCurve(this.p, brush.p, &pts[0], int32(len(pts)))
+	status := gdip.FillClosedCurve(this.p, brush.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
@@ -620,7 +632,7 @@ func (this *Graphics) FillClosedCurve2(brush *Brush, pts []Point, tension float3
 	if fillWinding {
 		fillMode = gdip.FillModeWinding
 	}
-	status := gdip.FillClosedCurve2I(this.p, brush.p, &pts[0], int32(len(pts)), tension, fillMode)
+	status := gdip.FillClosedCurve2I(this.p, brush.handle(), &pts[0], int32(len(pts)), tension, fillMode)
 	checkStatus(status)
 }
 
@@ -629,12 +641,12 @@ func (this *Graphics) FillClosedCurve2F(brush *Brush, pts []PointF, tension floa
 	if fillWinding {
 		fillMode = gdip.FillModeWinding
 	}
-	status := gdip.FillClosedCurve2(this.p, brush.p, &pts[0], int32(len(pts)), tension, fillMode)
+	status := gdip.FillClosedCurve2(this.p, brush.handle(), &pts[0], int32(len(pts)), tension, fillMode)
 	checkStatus(status)
 }
 
 func (this *Graphics) FillRegion(brush *Brush, region *Region) {
-	status := gdip.FillRegion(this.p, brush.p, region.p)
+	status := gdip.FillRegion(this.p, brush.handle(), region.handle())
 	checkStatus(status)
 }
 
@@ -661,7 +673,7 @@ func (this *Graphics) DrawStringRectF(str string, font *Font, brush *Brush,
 		pFormat = format.p
 	}
 	status := gdip.DrawString(this.p, &wsz[0], int32(len(wsz)-1),
-		font.p, (*gdip.RectF)(&layoutRect), pFormat, brush.p)
+		font.handle(), (*gdip.RectF)(&layoutRect), pFormat, brush.handle())
 	checkStatus(status)
 }
 
@@ -675,7 +687,7 @@ func (this *Graphics) MeasureString(str string, font *Font, layoutArea SizeF, fo
 		pFormat = format.p
 	}
 	status := gdip.MeasureString(this.p, &wsz[0], int32(len(wsz)-1),
-		font.p, (*gdip.RectF)(&layoutRect), pFormat,
+		font.handle(), (*gdip.RectF)(&layoutRect), pFormat,
 		(*gdip.RectF)(&boundBox), &charsFitted, &linesFilled)
 	checkStatus(status)
 	size = SizeF{boundBox.Width, boundBox.Height}
@@ -695,7 +707,7 @@ func (this *Graphics) MeasureCharacterRanges(str string, font *Font,
 
 	pRegions := make([]*gdip.Region, count)
 	status := gdip.MeasureCharacterRanges(this.p, &wsz[0], int32(len(wsz)-1),
-		font.p, (*gdip.RectF)(&layoutRect), pFormat, count, &pRegions[0])
+		font.handle(), (*gdip.RectF)(&layoutRect), pFormat, count, &pRegions[0])
 	checkStatus(status)
 
 	regions := make([]*Region, count)
@@ -835,12 +847,12 @@ func (this *Graphics) SetClipGraphics(g *Graphics, combineMode gdip.CombineMode)
 }
 
 func (this *Graphics) SetClipPath(path *Path, combineMode gdip.CombineMode) {
-	status := gdip.SetClipPath(this.p, path.p, combineMode)
+	status := gdip.SetClipPath(this.p, path.handle(), combineMode)
 	checkStatus(status)
 }
 
 func (this *Graphics) SetClipRegion(region *Region, combineMode gdip.CombineMode) {
-	status := gdip.SetClipRegion(this.p, region.p, combineMode)
+	status := gdip.SetClipRegion(this.p, region.handle(), combineMode)
 	checkStatus(status)
 }
 
@@ -895,7 +907,7 @@ func (this *Graphics) GetClipBoundsF() RectF {
 
 func (this *Graphics) GetClip() *Region {
 	region := &Region{}
-	status := gdip.GetClip(this.p, region.p)
+	status := gdip.GetClip(this.p, region.handle())
 	checkStatus(status)
 	return region
 }
@@ -1013,12 +1025,12 @@ func (this *Graphics) DrawBitmapRect(bitmap *Bitmap, x, y, cx, cy int32) {
 }
 
 func (this *Graphics) DrawRect(pen *Pen, x, y, w, h float32) {
-	status := gdip.DrawRectangle(this.p, pen.p, x, y, w, h)
+	status := gdip.DrawRectangle(this.p, pen.handle(), x, y, w, h)
 	checkStatus(status)
 }
 
 func (this *Graphics) DrawCircle(pen *Pen, x, y, w, h float32) {
-	status := gdip.DrawEllipse(this.p, pen.p, x, y, w, h)
+	status := gdip.DrawEllipse(this.p, pen.handle(), x, y, w, h)
 	checkStatus(status)
 }
 
@@ -1028,7 +1040,7 @@ func (this *Graphics) Scale(ratio float32) {
 }
 
 func (this *Graphics) DrawPolyline(pen *Pen, pts []Point) {
-	status := gdip.DrawLinesI(this.p, pen.p, &pts[0], int32(len(pts)))
+	status := gdip.DrawLinesI(this.p, pen.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
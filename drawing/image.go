@@ -135,7 +135,7 @@ func (this *Image) WriteToWithParams(writer io.Writer, formatExt string,
 func (this *Image) GetSize() Size {
 	var w, h float32
 	status := gdip.GetImageDimension(this.p, &w, &h)
-	ensureOk(status)
+	checkStatus(status)
 	return Size{int32(w), int32(h)}
 }
 
@@ -206,6 +206,13 @@ func (this *ImageAttributes) SetColorKey(colorLow Color, colorHigh Color) {
 	checkStatus(status)
 }
 
+// SetWrapMode sets how images are sampled outside of their bounds,
+// e.g. WrapModeTileFlipXY to avoid blending their edges with transparent pixels when scaled.
+func (this *ImageAttributes) SetWrapMode(mode gdip.WrapMode) {
+	status := gdip.SetImageAttributesWrapMode(this.p, mode, 0, win32.FALSE)
+	checkStatus(status)
+}
+
 var extClsIdMap map[string]win32.CLSID
 
 func init() {
@@ -0,0 +1,56 @@
+package drawing
+
+import (
+	"testing"
+
+	"github.com/zzl/goforms/framework/leaks"
+	"github.com/zzl/goforms/framework/scope"
+)
+
+// recordLeaks turns leak tracking on for the test and collects the problems reported.
+func recordLeaks(t *testing.T) *[]*leaks.Problem {
+	var problems []*leaks.Problem
+	oriReporter := leaks.SetReporter(func(problem *leaks.Problem) {
+		problems = append(problems, problem)
+	})
+	oriHandler := SetErrorHandler(func(err error) {}) //the gdi+ errors of disposed objects
+	leaks.Enable(true)
+	t.Cleanup(func() {
+		leaks.Enable(false)
+		leaks.SetReporter(oriReporter)
+		SetErrorHandler(oriHandler)
+		leaks.Reset()
+	})
+	return &problems
+}
+
+func TestScopeLeak(t *testing.T) {
+	problems := recordLeaks(t)
+	var leaked *Pen
+	scope.WithScope(func(s *Scope) {
+		NewPen(s, ColorOf(0xFF000000))
+		leaked = NewPen(nil, ColorOf(0xFF000000))
+		owned := NewSolidBrush(nil, ColorOf(0xFF000000))
+		leaks.Owned(owned)
+		defer owned.Dispose()
+	})
+	if len(*problems) != 1 || (*problems)[0].Kind != leaks.Leaked ||
+		(*problems)[0].Record.Type != "drawing.Pen" {
+		t.Fatalf("reported %v, want the pen created without a scope", *problems)
+	}
+	leaked.Dispose()
+}
+
+func TestUseAfterDispose(t *testing.T) {
+	problems := recordLeaks(t)
+	pen := NewPen(nil, ColorOf(0xFF000000))
+	pen.Dispose()
+	pen.GetWidth()
+	matrix := NewMatrix(nil)
+	matrix.Dispose()
+	matrix.IsIdentity()
+	if len(*problems) != 2 || (*problems)[0].Kind != leaks.UseAfterDispose ||
+		(*problems)[1].Record.Type != "drawing.Matrix" {
+		t.Errorf("reported %v, want the uses of the disposed pen and matrix", *problems)
+	}
+}
@@ -3,6 +3,8 @@ package drawing
 import (
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/framework/leaks"
 	"runtime"
 )
 
@@ -12,10 +14,11 @@ type Matrix struct {
 
 func newMatrix(s *Scope, pMatrix *gdip.Matrix) *Matrix {
 	matrix := &Matrix{p: pMatrix}
+	leaks.Track(matrix)
 	if s != nil {
 		s.Add(matrix)
 	}
-	runtime.SetFinalizer(matrix, (*Matrix).Dispose)
+	setFinalizer(matrix)
 	return matrix
 }
 
@@ -33,6 +36,12 @@ func NewMatrixWithValues(s *Scope, m11, m12, m21, m22, dx, dy float32) *Matrix {
 	return newMatrix(s, pMatrix)
 }
 
+// NewMatrixFromAffine creates a matrix with the elements of an affine transformation.
+func NewMatrixFromAffine(s *Scope, m geom.Affine) *Matrix {
+	return NewMatrixWithValues(s, float32(m.M11), float32(m.M12),
+		float32(m.M21), float32(m.M22), float32(m.DX), float32(m.DY))
+}
+
 func NewMatrixFromRectPoints(s *Scope, rect Rect, plgpts []Point) *Matrix {
 	var pMatrix *gdip.Matrix
 	status := gdip.CreateMatrix3I((*gdip.Rect)(&rect), &plgpts[0], &pMatrix)
@@ -48,6 +57,7 @@ func NewMatrixFromRectPointsF(s *Scope, rect RectF, plgpts []PointF) *Matrix {
 }
 
 func (this *Matrix) Dispose() {
+	leaks.Disposed(this)
 	if this.p == nil {
 		return
 	}
@@ -57,20 +67,42 @@ func (this *Matrix) Dispose() {
 	runtime.SetFinalizer(this, nil)
 }
 
+// handle returns the native object, reporting use after dispose.
+func (this *Matrix) handle() *gdip.Matrix {
+	if this.p == nil {
+		leaks.Used(this)
+	}
+	return this.p
+}
+
 func (this *Matrix) Clone(s *Scope) *Matrix {
 	var pMatrix2 *gdip.Matrix
-	status := gdip.CloneMatrix(this.p, &pMatrix2)
+	status := gdip.CloneMatrix(this.handle(), &pMatrix2)
 	checkStatus(status)
 	return newMatrix(s, pMatrix2)
 }
 
 func (this *Matrix) GetElements() []float32 {
 	elems := make([]float32, 6)
-	status := gdip.GetMatrixElements(this.p, &elems[0])
+	status := gdip.GetMatrixElements(this.handle(), &elems[0])
 	checkStatus(status)
 	return elems
 }
 
+// Affine returns the elements of the matrix as an affine transformation.
+func (this *Matrix) Affine() geom.Affine {
+	e := this.GetElements()
+	return geom.Affine{M11: float64(e[0]), M12: float64(e[1]), M21: float64(e[2]),
+		M22: float64(e[3]), DX: float64(e[4]), DY: float64(e[5])}
+}
+
+// SetAffine sets the elements of the matrix to an affine transformation.
+func (this *Matrix) SetAffine(m geom.Affine) {
+	status := gdip.SetMatrixElements(this.handle(), float32(m.M11), float32(m.M12),
+		float32(m.M21), float32(m.M22), float32(m.DX), float32(m.DY))
+	checkStatus(status)
+}
+
 func (this *Matrix) GetOffsetX() float32 {
 	return this.GetElements()[4]
 }
@@ -80,7 +112,7 @@ func (this *Matrix) GetOffsetY() float32 {
 }
 
 func (this *Matrix) Reset() {
-	status := gdip.SetMatrixElements(this.p, 1, 0, 0, 1, 0, 0)
+	status := gdip.SetMatrixElements(this.handle(), 1, 0, 0, 1, 0, 0)
 	checkStatus(status)
 }
 
@@ -89,7 +121,7 @@ func (this *Matrix) Multiply(matrix *Matrix, append bool) {
 	if append {
 		order = gdip.MatrixOrderPrepend
 	}
-	status := gdip.MultiplyMatrix(this.p, matrix.p, order)
+	status := gdip.MultiplyMatrix(this.handle(), matrix.handle(), order)
 	checkStatus(status)
 }
 
@@ -98,7 +130,7 @@ func (this *Matrix) Translate(offsetX, offsetY float32, append bool) {
 	if append {
 		order = gdip.MatrixOrderPrepend
 	}
-	status := gdip.TranslateMatrix(this.p, offsetX, offsetY, order)
+	status := gdip.TranslateMatrix(this.handle(), offsetX, offsetY, order)
 	checkStatus(status)
 }
 
@@ -107,7 +139,7 @@ func (this *Matrix) Scale(scaleX, scaleY float32, append bool) {
 	if append {
 		order = gdip.MatrixOrderPrepend
 	}
-	status := gdip.ScaleMatrix(this.p, scaleX, scaleY, order)
+	status := gdip.ScaleMatrix(this.handle(), scaleX, scaleY, order)
 	checkStatus(status)
 }
 
@@ -116,7 +148,7 @@ func (this *Matrix) Rotate(angle float32, append bool) {
 	if append {
 		order = gdip.MatrixOrderPrepend
 	}
-	status := gdip.RotateMatrix(this.p, angle, order)
+	status := gdip.RotateMatrix(this.handle(), angle, order)
 	checkStatus(status)
 }
 
@@ -127,13 +159,13 @@ func (this *Matrix) RotateAt(angle float32, point PointF, append bool) {
 	}
 	var status gdip.Status
 	if order == gdip.MatrixOrderPrepend {
-		status = gdip.TranslateMatrix(this.p, point.X, point.Y, order)
-		status |= gdip.RotateMatrix(this.p, angle, order)
-		status |= gdip.TranslateMatrix(this.p, -point.X, -point.Y, order)
+		status = gdip.TranslateMatrix(this.handle(), point.X, point.Y, order)
+		status |= gdip.RotateMatrix(this.handle(), angle, order)
+		status |= gdip.TranslateMatrix(this.handle(), -point.X, -point.Y, order)
 	} else {
-		status |= gdip.TranslateMatrix(this.p, -point.X, -point.Y, order)
-		status |= gdip.RotateMatrix(this.p, angle, order)
-		status = gdip.TranslateMatrix(this.p, point.X, point.Y, order)
+		status |= gdip.TranslateMatrix(this.handle(), -point.X, -point.Y, order)
+		status |= gdip.RotateMatrix(this.handle(), angle, order)
+		status = gdip.TranslateMatrix(this.handle(), point.X, point.Y, order)
 	}
 	checkStatus(status)
 }
@@ -143,52 +175,52 @@ func (this *Matrix) Shear(shearX, shearY float32, append bool) {
 	if append {
 		order = gdip.MatrixOrderPrepend
 	}
-	status := gdip.ShearMatrix(this.p, shearX, shearY, order)
+	status := gdip.ShearMatrix(this.handle(), shearX, shearY, order)
 	checkStatus(status)
 }
 
 func (this *Matrix) Invert() {
-	status := gdip.InvertMatrix(this.p)
+	status := gdip.InvertMatrix(this.handle())
 	checkStatus(status)
 }
 
 func (this *Matrix) TransformPoints(pts []Point) {
-	status := gdip.TransformMatrixPointsI(this.p, &pts[0], int32(len(pts)))
+	status := gdip.TransformMatrixPointsI(this.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Matrix) TransformPointsF(pts []PointF) {
-	status := gdip.TransformMatrixPoints(this.p, &pts[0], int32(len(pts)))
+	status := gdip.TransformMatrixPoints(this.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Matrix) TransformVectors(pts []Point) {
-	status := gdip.VectorTransformMatrixPointsI(this.p, &pts[0], int32(len(pts)))
+	status := gdip.VectorTransformMatrixPointsI(this.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Matrix) TransformVectorsF(pts []PointF) {
-	status := gdip.VectorTransformMatrixPoints(this.p, &pts[0], int32(len(pts)))
+	status := gdip.VectorTransformMatrixPoints(this.handle(), &pts[0], int32(len(pts)))
 	checkStatus(status)
 }
 
 func (this *Matrix) IsInvertible() bool {
 	var b win32.BOOL
-	status := gdip.IsMatrixInvertible(this.p, &b)
+	status := gdip.IsMatrixInvertible(this.handle(), &b)
 	checkStatus(status)
 	return b != 0
 }
 
 func (this *Matrix) IsIdentity() bool {
 	var b win32.BOOL
-	status := gdip.IsMatrixIdentity(this.p, &b)
+	status := gdip.IsMatrixIdentity(this.handle(), &b)
 	checkStatus(status)
 	return b != 0
 }
 
 func (this *Matrix) Equals(other *Matrix) bool {
 	var b win32.BOOL
-	status := gdip.IsMatrixEqual(this.p, other.p, &b)
+	status := gdip.IsMatrixEqual(this.handle(), other.handle(), &b)
 	checkStatus(status)
 	return b != 0
 }
@@ -4,6 +4,7 @@ import (
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
 	"github.com/zzl/goforms/drawing/utils"
+	"strings"
 )
 
 type NamedColorId int32
@@ -562,6 +563,15 @@ var namedColorValues = []gdip.ARGB{
 	0xFFFFB6C1, //LightPink
 }
 
+// namedColorIds maps the lower case names of named colors to their ids.
+var namedColorIds = make(map[string]NamedColorId)
+
+func init() {
+	for id := ColorIdActiveBorder; int(id) < len(namedColorNames); id++ {
+		namedColorIds[strings.ToLower(namedColorNames[id])] = id
+	}
+}
+
 func GetColorName(id NamedColorId) string {
 	return namedColorNames[id]
 }
@@ -0,0 +1,291 @@
+package drawing
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"math"
+	"os"
+	"runtime"
+
+	"github.com/zzl/go-gdiplus/gdip"
+	"github.com/zzl/goforms/framework/leaks"
+)
+
+// ErrNinePatchFormat is returned for images without valid nine-patch markers.
+var ErrNinePatchFormat = errors.New("drawing: not a nine-patch image")
+
+// Insets are the widths of the four borders of a rect.
+type Insets struct {
+	Left, Top, Right, Bottom int32
+}
+
+// NinePatchMode tells how the edges and center of a nine-patch fill their cells.
+type NinePatchMode int
+
+const (
+	NinePatchStretch NinePatchMode = iota //scaled to fill the cells
+	NinePatchTile                         //repeated at the scale of the corners
+)
+
+// NinePatch is an image drawn into rects of any size by keeping its corners,
+// stretching or tiling its edges along their lengths and its center both ways,
+// e.g. the background of a skinned button, popup border or panel.
+// Its insets are in pixels of the bitmap, which is designed for Dpi
+// and scaled to the DPI of the graphics it is drawn on.
+type NinePatch struct {
+	bitmap     *Bitmap
+	ownsBitmap bool
+	attrs      *ImageAttributes
+
+	Stretch Insets //the fixed borders around the stretchable area
+	Content Insets //the padding of the content drawn over the image
+	Mode    NinePatchMode
+	Dpi     float32 //the DPI the bitmap is designed for, 96 if zero
+}
+
+// NewNinePatch creates a nine-patch of a bitmap, which must outlive it.
+func NewNinePatch(s *Scope, bitmap *Bitmap, stretch, content Insets) *NinePatch {
+	np := &NinePatch{
+		bitmap:  bitmap,
+		attrs:   NewImageAttributes(nil),
+		Stretch: stretch,
+		Content: content,
+		Dpi:     96,
+	}
+	//sample beyond the cells as if mirrored, so scaled edges don't fade out
+	np.attrs.SetWrapMode(gdip.WrapModeTileFlipXY)
+	leaks.Track(np)
+	if s != nil {
+		s.Add(np)
+	}
+	setFinalizer(np)
+	return np
+}
+
+// NewNinePatchFromPng creates a nine-patch of a .9.png image, the insets being marked
+// by black pixels in its 1-pixel border as in Android: the top and left lines mark
+// the stretchable area, and the optional bottom and right lines the content area.
+func NewNinePatchFromPng(s *Scope, data []byte) (*NinePatch, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bounds, stretch, content, err := parseNinePatch(img)
+	if err != nil {
+		return nil, err
+	}
+	inner := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(inner, inner.Bounds(), img, bounds.Min, draw.Src)
+	np := NewNinePatch(s, NewBitmapFromGoImage(nil, inner), stretch, content)
+	np.ownsBitmap = true
+	return np, nil
+}
+
+// NewNinePatchFromFile creates a nine-patch of a .9.png file.
+func NewNinePatchFromFile(s *Scope, filename string) (*NinePatch, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	return NewNinePatchFromPng(s, data)
+}
+
+func (this *NinePatch) Dispose() {
+	leaks.Disposed(this)
+	if this.attrs == nil {
+		return
+	}
+	this.attrs.Dispose()
+	this.attrs = nil
+	if this.ownsBitmap {
+		this.bitmap.Dispose()
+	}
+	this.bitmap = nil
+	runtime.SetFinalizer(this, nil)
+}
+
+func (this *NinePatch) Bitmap() *Bitmap {
+	return this.bitmap
+}
+
+// parseNinePatch reads the markers in the border of a nine-patch image,
+// returning the bounds of the image inside the border and the insets marked.
+// Missing content markers make the content area that of the stretchable area.
+func parseNinePatch(img image.Image) (image.Rectangle, Insets, Insets, error) {
+	b := img.Bounds()
+	inner := image.Rect(b.Min.X+1, b.Min.Y+1, b.Max.X-1, b.Max.Y-1)
+	if inner.Dx() < 1 || inner.Dy() < 1 {
+		return inner, Insets{}, Insets{}, ErrNinePatchFormat
+	}
+	//span returns the insets of the first and last marked pixels of a border line,
+	//and false if none is marked
+	span := func(horizontal bool, line int) (int32, int32, bool, error) {
+		start, end, length := -1, -1, inner.Dy()
+		if horizontal {
+			length = inner.Dx()
+		}
+		for n := 0; n < length; n++ {
+			x, y := line, inner.Min.Y+n
+			if horizontal {
+				x, y = inner.Min.X+n, line
+			}
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			switch {
+			case c == color.NRGBA{A: 0xff}:
+				if start < 0 {
+					start = n
+				}
+				end = n + 1
+			case c.A == 0, c == color.NRGBA{R: 0xff, A: 0xff}:
+				//transparent, or the red of layout bounds, which are ignored
+			default:
+				return 0, 0, false, ErrNinePatchFormat
+			}
+		}
+		return int32(start), int32(length - end), start >= 0, nil
+	}
+	var stretch, content Insets
+	var ok bool
+	var err error
+	if stretch.Left, stretch.Right, ok, err = span(true, b.Min.Y); err != nil || !ok {
+		return inner, stretch, content, ErrNinePatchFormat
+	}
+	if stretch.Top, stretch.Bottom, ok, err = span(false, b.Min.X); err != nil || !ok {
+		return inner, stretch, content, ErrNinePatchFormat
+	}
+	if content.Left, content.Right, ok, err = span(true, b.Max.Y-1); err != nil {
+		return inner, stretch, content, err
+	} else if !ok {
+		content.Left, content.Right = stretch.Left, stretch.Right
+	}
+	if content.Top, content.Bottom, ok, err = span(false, b.Max.X-1); err != nil {
+		return inner, stretch, content, err
+	} else if !ok {
+		content.Top, content.Bottom = stretch.Top, stretch.Bottom
+	}
+	return inner, stretch, content, nil
+}
+
+// designDpi returns the DPI the bitmap is designed for, a zero Dpi meaning 96.
+func (this *NinePatch) designDpi() float32 {
+	if this.Dpi <= 0 {
+		return 96
+	}
+	return this.Dpi
+}
+
+// scale returns a length in pixels of the bitmap at a DPI.
+func (this *NinePatch) scale(v int32, dpi float32) int32 {
+	return int32(math.Round(float64(v) * float64(dpi) / float64(this.designDpi())))
+}
+
+// Padding returns the content insets at a DPI.
+func (this *NinePatch) Padding(dpi float32) Insets {
+	c := this.Content
+	return Insets{this.scale(c.Left, dpi), this.scale(c.Top, dpi),
+		this.scale(c.Right, dpi), this.scale(c.Bottom, dpi)}
+}
+
+// MinSize returns the size of the fixed borders at a DPI,
+// below which they are shrunk to fit.
+func (this *NinePatch) MinSize(dpi float32) Size {
+	st := this.Stretch
+	return Size{Width: this.scale(st.Left+st.Right, dpi), Height: this.scale(st.Top+st.Bottom, dpi)}
+}
+
+// GetPreferredSize returns the size of a nine-patch around content of a size at a DPI,
+// as the preferred size of a control drawing it as its background.
+func (this *NinePatch) GetPreferredSize(cxContent, cyContent int, dpi float32) (int, int) {
+	padding := this.Padding(dpi)
+	minSize := this.MinSize(dpi)
+	cx := max(cxContent+int(padding.Left+padding.Right), int(minSize.Width))
+	cy := max(cyContent+int(padding.Top+padding.Bottom), int(minSize.Height))
+	return cx, cy
+}
+
+// ContentRect returns the area of the content in a rect the nine-patch is drawn into,
+// at a DPI.
+func (this *NinePatch) ContentRect(rect Rect, dpi float32) Rect {
+	p := this.Padding(dpi)
+	return Rect{rect.X + p.Left, rect.Y + p.Top,
+		max(0, rect.Width-p.Left-p.Right), max(0, rect.Height-p.Top-p.Bottom)}
+}
+
+// ninePatchEdges returns the edges of the cells along an axis of a rect,
+// the fixed borders of the bitmap being scaled by k, and shrunk if they don't fit.
+func ninePatchEdges(start, length, border1, border2 int32, k float32) [4]float32 {
+	b1, b2 := float64(border1)*float64(k), float64(border2)*float64(k)
+	if b1+b2 > float64(length) {
+		f := float64(length) / (b1 + b2)
+		b1, b2 = b1*f, b2*f
+	}
+	s, e := float64(start), float64(start+length)
+	return [4]float32{float32(s), float32(math.Round(s + b1)),
+		float32(math.Round(e - b2)), float32(e)}
+}
+
+// Draw draws the nine-patch into a rect, scaled to the DPI of the graphics.
+func (this *NinePatch) Draw(g *Graphics, rect Rect) {
+	if this.bitmap == nil || rect.Width <= 0 || rect.Height <= 0 {
+		return
+	}
+	w, h := this.bitmap.GetSizeDecomposed()
+	st := this.Stretch
+	kx, ky := g.GetDpiX()/this.designDpi(), g.GetDpiY()/this.designDpi()
+	srcXs := [4]float32{0, float32(st.Left), float32(w - st.Right), float32(w)}
+	srcYs := [4]float32{0, float32(st.Top), float32(h - st.Bottom), float32(h)}
+	dstXs := ninePatchEdges(rect.X, rect.Width, st.Left, st.Right, kx)
+	dstYs := ninePatchEdges(rect.Y, rect.Height, st.Top, st.Bottom, ky)
+
+	state := g.Save()
+	defer g.Restore(state)
+	g.SetPixelOffsetMode(gdip.PixelOffsetModeHalf)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 3; col++ {
+			src := RectF{srcXs[col], srcYs[row], srcXs[col+1] - srcXs[col], srcYs[row+1] - srcYs[row]}
+			dst := RectF{dstXs[col], dstYs[row], dstXs[col+1] - dstXs[col], dstYs[row+1] - dstYs[row]}
+			if src.Width <= 0 || src.Height <= 0 || dst.Width <= 0 || dst.Height <= 0 {
+				continue
+			}
+			if this.Mode != NinePatchTile || row != 1 && col != 1 {
+				g.DrawImageRectRectAttrF(this.bitmap.AsImage(), dst, src, gdip.UnitPixel, this.attrs)
+				continue
+			}
+			//edges tile along their lengths, and the center both ways
+			tileWidth, tileHeight := dst.Width, dst.Height
+			if col == 1 {
+				tileWidth = max(1, src.Width*kx)
+			}
+			if row == 1 {
+				tileHeight = max(1, src.Height*ky)
+			}
+			this.drawTiles(g, dst, src, tileWidth, tileHeight)
+		}
+	}
+}
+
+// drawTiles fills a cell with tiles of its source rect, cropping the last ones.
+func (this *NinePatch) drawTiles(g *Graphics, dst, src RectF, tileWidth, tileHeight float32) {
+	right, bottom := dst.X+dst.Width, dst.Y+dst.Height
+	for y := dst.Y; y < bottom; y += tileHeight {
+		y1 := min(y+tileHeight, bottom)
+		for x := dst.X; x < right; x += tileWidth {
+			x1 := min(x+tileWidth, right)
+			//the tiles are snapped to pixels so that they join without seams
+			tile := RectF{roundF(x), roundF(y), roundF(x1) - roundF(x), roundF(y1) - roundF(y)}
+			if tile.Width <= 0 || tile.Height <= 0 {
+				continue
+			}
+			crop := RectF{src.X, src.Y, src.Width * (x1 - x) / tileWidth, src.Height * (y1 - y) / tileHeight}
+			g.DrawImageRectRectAttrF(this.bitmap.AsImage(), tile, crop, gdip.UnitPixel, this.attrs)
+		}
+	}
+}
+
+func roundF(v float32) float32 {
+	return float32(math.Round(float64(v)))
+}
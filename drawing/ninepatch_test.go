@@ -0,0 +1,116 @@
+package drawing
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"testing"
+)
+
+var (
+	black = color.NRGBA{A: 0xff}
+	red   = color.NRGBA{R: 0xff, A: 0xff}
+	gray  = color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}
+)
+
+// ninePatchImage returns a nine-patch image of an inner size, whose border lines
+// are marked in the spans given as [start, end) pairs of inner pixels.
+func ninePatchImage(width, height int, top, left, bottom, right []int, c color.Color) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, width+2, height+2))
+	mark := func(spans []int, horizontal bool, line int) {
+		for n := 0; n+1 < len(spans); n += 2 {
+			for v := spans[n]; v < spans[n+1]; v++ {
+				if horizontal {
+					img.Set(v+1, line, c)
+				} else {
+					img.Set(line, v+1, c)
+				}
+			}
+		}
+	}
+	mark(top, true, 0)
+	mark(left, false, 0)
+	mark(bottom, true, height+1)
+	mark(right, false, width+1)
+	return img
+}
+
+func TestParseNinePatch(t *testing.T) {
+	withRed := ninePatchImage(10, 8, []int{3, 7}, []int{2, 5}, nil, nil, black)
+	withRed.Set(1, 9, red)
+	withRed.Set(11, 1, red)
+	tests := []struct {
+		name    string
+		img     image.Image
+		stretch Insets
+		content Insets
+	}{
+		{"markers", ninePatchImage(10, 8, []int{3, 7}, []int{2, 5}, []int{1, 9}, []int{1, 6}, black),
+			Insets{3, 2, 3, 3}, Insets{1, 1, 1, 2}},
+		{"missing content markers", ninePatchImage(10, 8, []int{3, 7}, []int{2, 5}, nil, nil, black),
+			Insets{3, 2, 3, 3}, Insets{3, 2, 3, 3}},
+		{"one content line", ninePatchImage(10, 8, []int{3, 7}, []int{2, 5}, []int{0, 10}, nil, black),
+			Insets{3, 2, 3, 3}, Insets{0, 2, 0, 3}},
+		//the first and last markers make the span
+		{"split markers", ninePatchImage(10, 8, []int{2, 3, 6, 8}, []int{0, 8}, nil, nil, black),
+			Insets{2, 0, 2, 0}, Insets{2, 0, 2, 0}},
+		{"layout bounds ignored", withRed, Insets{3, 2, 3, 3}, Insets{3, 2, 3, 3}},
+	}
+	for _, test := range tests {
+		bounds, stretch, content, err := parseNinePatch(test.img)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if bounds != image.Rect(1, 1, 11, 9) || stretch != test.stretch || content != test.content {
+			t.Errorf("%s: bounds %v, stretch %v, content %v, want %v, %v",
+				test.name, bounds, stretch, content, test.stretch, test.content)
+		}
+	}
+}
+
+func TestParseNinePatchErrors(t *testing.T) {
+	grayContent := ninePatchImage(10, 8, []int{3, 7}, []int{2, 5}, nil, nil, black)
+	grayContent.Set(11, 3, gray)
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"no markers", ninePatchImage(10, 8, nil, nil, nil, nil, black)},
+		{"no left markers", ninePatchImage(10, 8, []int{3, 7}, nil, nil, nil, black)},
+		{"no top markers", ninePatchImage(10, 8, nil, []int{2, 5}, nil, nil, black)},
+		{"gray markers", ninePatchImage(10, 8, []int{3, 7}, []int{2, 5}, nil, nil, gray)},
+		{"gray content marker", grayContent},
+		{"half transparent markers", ninePatchImage(10, 8, []int{3, 7}, []int{2, 5}, nil, nil,
+			color.NRGBA{A: 0x80})},
+		{"no inner pixels", image.NewNRGBA(image.Rect(0, 0, 2, 5))},
+	}
+	for _, test := range tests {
+		if _, _, _, err := parseNinePatch(test.img); !errors.Is(err, ErrNinePatchFormat) {
+			t.Errorf("%s: err %v, want ErrNinePatchFormat", test.name, err)
+		}
+	}
+}
+
+func TestNinePatchEdges(t *testing.T) {
+	tests := []struct {
+		name                            string
+		start, length, border1, border2 int32
+		k                               float32
+		want                            [4]float32
+	}{
+		{"fitting", 10, 100, 4, 6, 1, [4]float32{10, 14, 104, 110}},
+		{"scaled", 0, 100, 4, 6, 1.5, [4]float32{0, 6, 91, 100}},
+		{"exactly fitting", 0, 10, 4, 6, 1, [4]float32{0, 4, 4, 10}},
+		//overflowing borders shrink in proportion, meeting without a middle cell
+		{"overflowing", 10, 10, 8, 12, 1, [4]float32{10, 14, 14, 20}},
+		{"overflowing when scaled", 0, 20, 8, 12, 2, [4]float32{0, 8, 8, 20}},
+		{"empty", 5, 0, 8, 12, 1, [4]float32{5, 5, 5, 5}},
+	}
+	for _, test := range tests {
+		got := ninePatchEdges(test.start, test.length, test.border1, test.border2, test.k)
+		if got != test.want {
+			t.Errorf("%s: edges %v, want %v", test.name, got, test.want)
+		}
+	}
+}
@@ -3,6 +3,9 @@ package drawing
 import (
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/drawing/svg"
+	"github.com/zzl/goforms/framework/leaks"
 	"runtime"
 	"syscall"
 	"unsafe"
@@ -20,10 +23,11 @@ type PathData struct {
 
 func newPath(s *Scope, p *gdip.Path) *Path {
 	path := &Path{p}
+	leaks.Track(path)
 	if s != nil {
 		s.Add(path)
 	}
-	runtime.SetFinalizer(path, (*Path).Dispose)
+	setFinalizer(path)
 	return path
 }
 
@@ -63,6 +67,7 @@ func NewPathWithPointsModeF(s *Scope, pts []PointF, types []byte, fillMode gdip.
 }
 
 func (this *Path) Dispose() {
+	leaks.Disposed(this)
 	if this.p == nil {
 		return
 	}
@@ -72,26 +77,34 @@ func (this *Path) Dispose() {
 	runtime.SetFinalizer(this, nil)
 }
 
+// handle returns the native object, reporting use after dispose.
+func (this *Path) handle() *gdip.Path {
+	if this.p == nil {
+		leaks.Used(this)
+	}
+	return this.p
+}
+
 func (this *Path) Clone(s *Scope) *Path {
 	var pPath2 *gdip.Path
-	gdip.ClonePath(this.p, &pPath2)
+	gdip.ClonePath(this.handle(), &pPath2)
 	return newPath(s, pPath2)
 }
 
 func (this *Path) Reset() {
-	status := gdip.ResetPath(this.p)
+	status := gdip.ResetPath(this.handle())
 	checkStatus(status)
 }
 
 func (this *Path) GetFillMode() gdip.FillMode {
 	var mode gdip.FillMode
-	status := gdip.GetPathFillMode(this.p, &mode)
+	status := gdip.GetPathFillMode(this.handle(), &mode)
 	checkStatus(status)
 	return mode
 }
 
 func (this *Path) SetFillMode(mode gdip.FillMode) {
-	status := gdip.SetPathFillMode(this.p, mode)
+	status := gdip.SetPathFillMode(this.handle(), mode)
 	checkStatus(status)
 }
 
@@ -110,44 +123,80 @@ func (this *Path) GetPathData() PathData {
 		points: &data.Points[0],
 		types:  &data.Types[0],
 	}
-	status := gdip.GetPathData(this.p, (*gdip.PathData)(unsafe.Pointer(&memPathData)))
+	status := gdip.GetPathData(this.handle(), (*gdip.PathData)(unsafe.Pointer(&memPathData)))
 	checkStatus(status)
 	return data
 }
 
+// NewPathFromGeom creates a native path with the figures of a pure-Go path.
+func NewPathFromGeom(s *Scope, path *geom.Path) *Path {
+	if path.IsEmpty() {
+		return NewPathWithMode(s, gdip.FillMode(path.FillMode))
+	}
+	points := make([]PointF, len(path.Points))
+	for n, pt := range path.Points {
+		points[n] = PointF{X: float32(pt.X), Y: float32(pt.Y)}
+	}
+	return NewPathWithPointsModeF(s, points, path.Types, gdip.FillMode(path.FillMode))
+}
+
+// NewPathFromSvg creates a path from SVG path data, the d attribute of path elements.
+// On a syntax error, the path holds the segments before the error.
+func NewPathFromSvg(s *Scope, d string) (*Path, error) {
+	path, err := svg.ParsePath(d)
+	return NewPathFromGeom(s, path), err
+}
+
+// Geom returns the figures of the path as a pure-Go path,
+// for geometry without native objects.
+func (this *Path) Geom() *geom.Path {
+	fillMode := geom.FillMode(this.GetFillMode())
+	if this.GetPointCount() == 0 {
+		path := geom.NewPath()
+		path.FillMode = fillMode
+		return path
+	}
+	data := this.GetPathData()
+	points := make([]geom.Point, len(data.Points))
+	for n, pt := range data.Points {
+		points[n] = geom.Pt(float64(pt.X), float64(pt.Y))
+	}
+	return geom.NewPathFromData(points, data.Types, fillMode)
+}
+
 func (this *Path) StartFigure() {
-	status := gdip.StartPathFigure(this.p)
+	status := gdip.StartPathFigure(this.handle())
 	checkStatus(status)
 }
 
 func (this *Path) CloseFigure() {
-	status := gdip.ClosePathFigure(this.p)
+	status := gdip.ClosePathFigure(this.handle())
 	checkStatus(status)
 }
 
 func (this *Path) CloseAllFigures() {
-	status := gdip.ClosePathFigures(this.p)
+	status := gdip.ClosePathFigures(this.handle())
 	checkStatus(status)
 }
 
 func (this *Path) SetMarkers() {
-	status := gdip.SetPathMarker(this.p)
+	status := gdip.SetPathMarker(this.handle())
 	checkStatus(status)
 }
 
 func (this *Path) ClearMarkers() {
-	status := gdip.ClearPathMarkers(this.p)
+	status := gdip.ClearPathMarkers(this.handle())
 	checkStatus(status)
 }
 
 func (this *Path) Reverse() {
-	status := gdip.ReversePath(this.p)
+	status := gdip.ReversePath(this.handle())
 	checkStatus(status)
 }
 
 func (this *Path) GetLastPoint() PointF {
 	var point PointF
-	status := gdip.GetPathLastPoint(this.p, &point)
+	status := gdip.GetPathLastPoint(this.handle(), &point)
 	checkStatus(status)
 	return point
 }
@@ -166,7 +215,7 @@ func (this *Path) IsVisibleInGraph(x, y int32, g *Graphics) bool {
 		pGraphics = g.p
 	}
 	var result win32.BOOL
-	status := gdip.IsVisiblePathPointI(this.p, x, y, pGraphics, &result)
+	status := gdip.IsVisiblePathPointI(this.handle(), x, y, pGraphics, &result)
 	checkStatus(status)
 	return result != 0
 }
@@ -177,7 +226,7 @@ func (this *Path) IsVisibleInGraphF(x, y float32, g *Graphics) bool {
 		pGraphics = g.p
 	}
 	var result win32.BOOL
-	status := gdip.IsVisiblePathPoint(this.p, x, y, pGraphics, &result)
+	status := gdip.IsVisiblePathPoint(this.handle(), x, y, pGraphics, &result)
 	checkStatus(status)
 	return result != 0
 }
@@ -196,7 +245,7 @@ func (this *Path) IsOutlineVisibleInGraph(x, y int32, pen *Pen, g *Graphics) boo
 	if g != nil {
 		pGraphics = g.p
 	}
-	status := gdip.IsOutlineVisiblePathPointI(this.p, x, y, pen.p, pGraphics, &result)
+	status := gdip.IsOutlineVisiblePathPointI(this.handle(), x, y, pen.handle(), pGraphics, &result)
 	checkStatus(status)
 	return result != 0
 }
@@ -207,157 +256,157 @@ func (this *Path) IsOutlineVisibleInGraphF(x, y float32, pen *Pen, g *Graphics)
 	if g != nil {
 		pGraphics = g.p
 	}
-	status := gdip.IsOutlineVisiblePathPoint(this.p, x, y, pen.p, pGraphics, &result)
+	status := gdip.IsOutlineVisiblePathPoint(this.handle(), x, y, pen.handle(), pGraphics, &result)
 	checkStatus(status)
 	return result != 0
 }
 
 func (this *Path) AddLine(pt1, pt2 Point) {
-	status := gdip.AddPathLineI(this.p, pt1.X, pt1.Y, pt2.X, pt2.Y)
+	status := gdip.AddPathLineI(this.handle(), pt1.X, pt1.Y, pt2.X, pt2.Y)
 	checkStatus(status)
 }
 
 func (this *Path) AddLineF(pt1, pt2 PointF) {
-	status := gdip.AddPathLine(this.p, pt1.X, pt1.Y, pt2.X, pt2.Y)
+	status := gdip.AddPathLine(this.handle(), pt1.X, pt1.Y, pt2.X, pt2.Y)
 	checkStatus(status)
 }
 
 func (this *Path) AddLines(points []Point) {
-	status := gdip.AddPathLine2I(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathLine2I(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddLinesF(points []PointF) {
-	status := gdip.AddPathLine2(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathLine2(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddArc(rect Rect, startAngle, sweepAngle float32) {
-	status := gdip.AddPathArcI(this.p, rect.X, rect.Y,
+	status := gdip.AddPathArcI(this.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Path) AddArcF(rect RectF, startAngle, sweepAngle float32) {
-	status := gdip.AddPathArc(this.p, rect.X, rect.Y,
+	status := gdip.AddPathArc(this.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Path) AddBezier(p1, p2, p3, p4 Point) {
-	status := gdip.AddPathBezierI(this.p, p1.X, p1.Y, p2.X, p2.Y, p3.X, p3.Y, p4.X, p4.Y)
+	status := gdip.AddPathBezierI(this.handle(), p1.X, p1.Y, p2.X, p2.Y, p3.X, p3.Y, p4.X, p4.Y)
 	checkStatus(status)
 }
 
 func (this *Path) AddBezierF(p1, p2, p3, p4 PointF) {
-	status := gdip.AddPathBezier(this.p, p1.X, p1.Y, p2.X, p2.Y, p3.X, p3.Y, p4.X, p4.Y)
+	status := gdip.AddPathBezier(this.handle(), p1.X, p1.Y, p2.X, p2.Y, p3.X, p3.Y, p4.X, p4.Y)
 	checkStatus(status)
 }
 
 func (this *Path) AddBeziers(points []Point) {
-	status := gdip.AddPathBeziersI(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathBeziersI(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddBeziersF(points []PointF) {
-	status := gdip.AddPathBeziers(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathBeziers(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddCurve(points []Point) {
-	status := gdip.AddPathCurveI(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathCurveI(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddCurveF(points []PointF) {
-	status := gdip.AddPathCurve(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathCurve(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddCurveTension(points []Point, tension float32) {
-	status := gdip.AddPathCurve2I(this.p, &points[0], int32(len(points)), tension)
+	status := gdip.AddPathCurve2I(this.handle(), &points[0], int32(len(points)), tension)
 	checkStatus(status)
 }
 
 func (this *Path) AddCurveTensionF(points []PointF, tension float32) {
-	status := gdip.AddPathCurve2(this.p, &points[0], int32(len(points)), tension)
+	status := gdip.AddPathCurve2(this.handle(), &points[0], int32(len(points)), tension)
 	checkStatus(status)
 }
 
 func (this *Path) AddClosedCurve(points []Point) {
-	status := gdip.AddPathClosedCurveI(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathClosedCurveI(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddClosedCurveF(points []PointF) {
-	status := gdip.AddPathClosedCurve(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathClosedCurve(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddClosedCurveTension(points []Point, tension float32) {
-	status := gdip.AddPathClosedCurve2I(this.p, &points[0], int32(len(points)), tension)
+	status := gdip.AddPathClosedCurve2I(this.handle(), &points[0], int32(len(points)), tension)
 	checkStatus(status)
 }
 
 func (this *Path) AddClosedCurveTensionF(points []PointF, tension float32) {
-	status := gdip.AddPathClosedCurve2(this.p, &points[0], int32(len(points)), tension)
+	status := gdip.AddPathClosedCurve2(this.handle(), &points[0], int32(len(points)), tension)
 	checkStatus(status)
 }
 
 func (this *Path) AddRectangle(rect Rect) {
-	status := gdip.AddPathRectangleI(this.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.AddPathRectangleI(this.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Path) AddRectangleF(rect RectF) {
-	status := gdip.AddPathRectangle(this.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.AddPathRectangle(this.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Path) AddRectangles(rects []Rect) {
-	status := gdip.AddPathRectanglesI(this.p, (*gdip.Rect)(&rects[0]), int32(len(rects)))
+	status := gdip.AddPathRectanglesI(this.handle(), (*gdip.Rect)(&rects[0]), int32(len(rects)))
 	checkStatus(status)
 }
 
 func (this *Path) AddRectanglesF(rects []RectF) {
-	status := gdip.AddPathRectangles(this.p, (*gdip.RectF)(&rects[0]), int32(len(rects)))
+	status := gdip.AddPathRectangles(this.handle(), (*gdip.RectF)(&rects[0]), int32(len(rects)))
 	checkStatus(status)
 }
 
 func (this *Path) AddEllipse(rect Rect) {
-	status := gdip.AddPathEllipseI(this.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.AddPathEllipseI(this.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Path) AddEllipseF(rect RectF) {
-	status := gdip.AddPathEllipse(this.p, rect.X, rect.Y, rect.Width, rect.Height)
+	status := gdip.AddPathEllipse(this.handle(), rect.X, rect.Y, rect.Width, rect.Height)
 	checkStatus(status)
 }
 
 func (this *Path) AddPie(rect Rect, startAngle, sweepAngle float32) {
-	status := gdip.AddPathPieI(this.p, rect.X, rect.Y,
+	status := gdip.AddPathPieI(this.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Path) AddPieF(rect RectF, startAngle, sweepAngle float32) {
-	status := gdip.AddPathPie(this.p, rect.X, rect.Y,
+	status := gdip.AddPathPie(this.handle(), rect.X, rect.Y,
 		rect.Width, rect.Height, startAngle, sweepAngle)
 	checkStatus(status)
 }
 
 func (this *Path) AddPolygon(points []Point) {
-	status := gdip.AddPathPolygonI(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathPolygonI(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddPolygonF(points []PointF) {
-	status := gdip.AddPathPolygon(this.p, &points[0], int32(len(points)))
+	status := gdip.AddPathPolygon(this.handle(), &points[0], int32(len(points)))
 	checkStatus(status)
 }
 
 func (this *Path) AddPath(addingPath *Path, connect bool) {
-	status := gdip.AddPathPath(this.p, addingPath.p, win32.BoolToBOOL(connect))
+	status := gdip.AddPathPath(this.handle(), addingPath.handle(), win32.BoolToBOOL(connect))
 	checkStatus(status)
 }
 
@@ -365,7 +414,7 @@ func (this *Path) AddString(s string, family *FontFamily,
 	style gdip.FontStyle, emSize float32, origin Point, format *StringFormat) {
 	wsz, _ := syscall.UTF16FromString(s)
 	rect := Rect{origin.X, origin.Y, 0, 0}
-	status := gdip.AddPathStringI(this.p, &wsz[0], int32(len(wsz)-1), family.p,
+	status := gdip.AddPathStringI(this.handle(), &wsz[0], int32(len(wsz)-1), family.p,
 		style, emSize, (*gdip.Rect)(&rect), format.p)
 	checkStatus(status)
 }
@@ -374,7 +423,7 @@ func (this *Path) AddStringF(s string, family *FontFamily,
 	style gdip.FontStyle, emSize float32, origin PointF, format *StringFormat) {
 	wsz, _ := syscall.UTF16FromString(s)
 	rect := RectF{origin.X, origin.Y, 0, 0}
-	status := gdip.AddPathString(this.p, &wsz[0], int32(len(wsz)-1), family.p,
+	status := gdip.AddPathString(this.handle(), &wsz[0], int32(len(wsz)-1), family.p,
 		style, emSize, (*gdip.RectF)(&rect), format.p)
 	checkStatus(status)
 }
@@ -382,7 +431,7 @@ func (this *Path) AddStringF(s string, family *FontFamily,
 func (this *Path) AddStringRect(s string, family *FontFamily,
 	style gdip.FontStyle, emSize float32, layoutRect Rect, format *StringFormat) {
 	wsz, _ := syscall.UTF16FromString(s)
-	status := gdip.AddPathStringI(this.p, &wsz[0], int32(len(wsz)-1), family.p,
+	status := gdip.AddPathStringI(this.handle(), &wsz[0], int32(len(wsz)-1), family.p,
 		style, emSize, (*gdip.Rect)(&layoutRect), format.p)
 	checkStatus(status)
 }
@@ -390,13 +439,13 @@ func (this *Path) AddStringRect(s string, family *FontFamily,
 func (this *Path) AddStringRectF(s string, family *FontFamily,
 	style gdip.FontStyle, emSize float32, layoutRect RectF, format *StringFormat) {
 	wsz, _ := syscall.UTF16FromString(s)
-	status := gdip.AddPathString(this.p, &wsz[0], int32(len(wsz)-1), family.p,
+	status := gdip.AddPathString(this.handle(), &wsz[0], int32(len(wsz)-1), family.p,
 		style, emSize, (*gdip.RectF)(&layoutRect), format.p)
 	checkStatus(status)
 }
 
 func (this *Path) Transform(matrix *Matrix) {
-	status := gdip.TransformPath(this.p, matrix.p)
+	status := gdip.TransformPath(this.handle(), matrix.handle())
 	checkStatus(status)
 }
 
@@ -404,13 +453,13 @@ func (this *Path) GetBounds(matrix *Matrix, pen *Pen) Rect {
 	var bounds Rect
 	var pPen *gdip.Pen
 	if pen != nil {
-		pPen = pen.p
+		pPen = pen.handle()
 	}
 	var pMatrix *gdip.Matrix
 	if matrix != nil {
-		pMatrix = matrix.p
+		pMatrix = matrix.handle()
 	}
-	gdip.GetPathWorldBoundsI(this.p, (*gdip.Rect)(&bounds), pMatrix, pPen)
+	gdip.GetPathWorldBoundsI(this.handle(), (*gdip.Rect)(&bounds), pMatrix, pPen)
 	return bounds
 }
 
@@ -418,31 +467,31 @@ func (this *Path) GetBoundsF(matrix *Matrix, pen *Pen) RectF {
 	var bounds RectF
 	var pPen *gdip.Pen
 	if pen != nil {
-		pPen = pen.p
+		pPen = pen.handle()
 	}
 	var pMatrix *gdip.Matrix
 	if matrix != nil {
-		pMatrix = matrix.p
+		pMatrix = matrix.handle()
 	}
-	gdip.GetPathWorldBounds(this.p, (*gdip.RectF)(&bounds), pMatrix, pPen)
+	gdip.GetPathWorldBounds(this.handle(), (*gdip.RectF)(&bounds), pMatrix, pPen)
 	return bounds
 }
 
 func (this *Path) Flatten(matrix *Matrix, flatness float32) {
 	var pMatrix *gdip.Matrix
 	if matrix != nil {
-		pMatrix = matrix.p
+		pMatrix = matrix.handle()
 	}
-	status := gdip.FlattenPath(this.p, pMatrix, flatness)
+	status := gdip.FlattenPath(this.handle(), pMatrix, flatness)
 	checkStatus(status)
 }
 
 func (this *Path) Widen(pen *Pen, matrix *Matrix, flatness float32) {
 	var pMatrix *gdip.Matrix
 	if matrix != nil {
-		pMatrix = matrix.p
+		pMatrix = matrix.handle()
 	}
-	status := gdip.WidenPath(this.p, pen.p, pMatrix, flatness)
+	status := gdip.WidenPath(this.handle(), pen.handle(), pMatrix, flatness)
 	checkStatus(status)
 }
 
@@ -463,16 +512,16 @@ func (this *Path) WarpWithMatrixModeFlat(dstPoints []PointF, srcRect RectF,
 	matrix *Matrix, warpMode gdip.WarpMode, flatness float32) {
 	var pMatrix *gdip.Matrix
 	if matrix != nil {
-		pMatrix = matrix.p
+		pMatrix = matrix.handle()
 	}
-	status := gdip.WarpPath(this.p, pMatrix, &dstPoints[0], int32(len(dstPoints)),
+	status := gdip.WarpPath(this.handle(), pMatrix, &dstPoints[0], int32(len(dstPoints)),
 		srcRect.X, srcRect.Y, srcRect.Width, srcRect.Height, warpMode, flatness)
 	checkStatus(status)
 }
 
 func (this *Path) GetPointCount() int32 {
 	var count int32
-	status := gdip.GetPointCount(this.p, &count)
+	status := gdip.GetPointCount(this.handle(), &count)
 	checkStatus(status)
 	return count
 }
@@ -480,7 +529,7 @@ func (this *Path) GetPointCount() int32 {
 func (this *Path) GetPathTypes() []byte {
 	count := this.GetPointCount()
 	types := make([]byte, count)
-	status := gdip.GetPathTypes(this.p, (*win32.BYTE)(&types[0]), count)
+	status := gdip.GetPathTypes(this.handle(), (*win32.BYTE)(&types[0]), count)
 	checkStatus(status)
 	return types
 }
@@ -488,7 +537,7 @@ func (this *Path) GetPathTypes() []byte {
 func (this *Path) GetPathPoints() []Point {
 	count := this.GetPointCount()
 	points := make([]Point, count)
-	status := gdip.GetPathPointsI(this.p, &points[0], count)
+	status := gdip.GetPathPointsI(this.handle(), &points[0], count)
 	checkStatus(status)
 	return points
 }
@@ -496,7 +545,7 @@ func (this *Path) GetPathPoints() []Point {
 func (this *Path) GetPathPointsF() []PointF {
 	count := this.GetPointCount()
 	points := make([]PointF, count)
-	status := gdip.GetPathPoints(this.p, &points[0], count)
+	status := gdip.GetPathPoints(this.handle(), &points[0], count)
 	checkStatus(status)
 	return points
 }
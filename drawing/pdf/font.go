@@ -0,0 +1,249 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"sort"
+	"unicode/utf16"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/drawing/truetype"
+)
+
+// embeddedFont is a TrueType font embedded as a subset of the glyphs used.
+type embeddedFont struct {
+	font  *truetype.Font
+	name  string
+	runes map[uint16]rune //the glyphs used, with the first rune drawn with each
+}
+
+// embed returns the embedding of a font, written as a Type0 font with Identity-H encoding,
+// so that text is written as glyph indexes.
+func (this *Document) embed(font *truetype.Font) *embeddedFont {
+	if ef := this.fonts[font]; ef != nil {
+		return ef
+	}
+	ef := &embeddedFont{font: font, runes: make(map[uint16]rune)}
+	ef.name = this.resource("Font", "F", fmt.Sprintf("%p", font), ef.write)
+	this.fonts[font] = ef
+	return ef
+}
+
+// glyphs returns the glyphs of text, noting them as used.
+func (this *embeddedFont) glyphs(text string) []uint16 {
+	var glyphs []uint16
+	for _, r := range text {
+		g := this.font.GlyphIndex(r)
+		if _, ok := this.runes[g]; !ok {
+			this.runes[g] = r
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
+
+func (this *embeddedFont) write(w *writer) int {
+	glyphs := make([]uint16, 0, len(this.runes))
+	for g := range this.runes {
+		glyphs = append(glyphs, g)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	data := this.font.Subset(glyphs)
+
+	//the subset tag, six letters identifying the subset
+	hash := sha256.Sum256(data)
+	tag := make([]byte, 6)
+	for n := range tag {
+		tag[n] = 'A' + hash[n]%26
+	}
+	baseFont := pdfName(string(tag) + "+" + this.font.Name())
+
+	scale := 1000 / float64(this.font.UnitsPerEm())
+	metrics := this.font.FontMetrics()
+	flags := 4 //symbolic, as glyphs are referred to by index
+	if metrics.FixedPitch {
+		flags |= 1
+	}
+	if metrics.ItalicAngle != 0 {
+		flags |= 64
+	}
+	fontFile := w.add(w.stream(fmt.Sprintf(" /Length1 %d", len(data)), data, true))
+	descriptor := w.add(fmt.Sprintf("<< /Type /FontDescriptor /FontName %s /Flags %d "+
+		"/FontBBox [%s %s %s %s] /ItalicAngle %s /Ascent %s /Descent %s /CapHeight %s "+
+		"/StemV %d /FontFile2 %d 0 R >>",
+		baseFont, flags,
+		num(float64(metrics.BBox[0])*scale), num(float64(metrics.BBox[1])*scale),
+		num(float64(metrics.BBox[2])*scale), num(float64(metrics.BBox[3])*scale),
+		num(metrics.ItalicAngle), num(float64(metrics.Ascent)*scale),
+		num(-float64(metrics.Descent)*scale), num(float64(metrics.CapHeight)*scale),
+		metrics.Weight/5, fontFile))
+
+	//the widths of runs of consecutive glyphs
+	var widths bytes.Buffer
+	for n := 0; n < len(glyphs); {
+		fmt.Fprintf(&widths, "%d [", glyphs[n])
+		for start := n; n < len(glyphs) && int(glyphs[n]) == int(glyphs[start])+n-start; n++ {
+			if n > start {
+				widths.WriteByte(' ')
+			}
+			widths.WriteString(num(float64(this.font.GlyphAdvance(glyphs[n])) * scale))
+		}
+		widths.WriteString("] ")
+	}
+	cidFont := w.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont %s "+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+		"/FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		baseFont, descriptor, bytes.TrimSpace(widths.Bytes())))
+	toUnicode := w.add(w.stream("", this.toUnicode(glyphs), true))
+	return w.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont %s /Encoding /Identity-H "+
+		"/DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>", baseFont, cidFont, toUnicode))
+}
+
+// toUnicode returns the CMap mapping the glyphs to their runes, for text extraction.
+func (this *embeddedFont) toUnicode(glyphs []uint16) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	var mapped []uint16
+	for _, g := range glyphs {
+		if g != 0 { //the missing glyph stands for any rune
+			mapped = append(mapped, g)
+		}
+	}
+	//at most 100 mappings per block
+	for start := 0; start < len(mapped); start += 100 {
+		end := min(start+100, len(mapped))
+		fmt.Fprintf(&buf, "%d beginbfchar\n", end-start)
+		for _, g := range mapped[start:end] {
+			fmt.Fprintf(&buf, "<%04X> <", g)
+			for _, unit := range utf16.Encode([]rune{this.runes[g]}) {
+				fmt.Fprintf(&buf, "%04X", unit)
+			}
+			buf.WriteString(">\n")
+		}
+		buf.WriteString("endbfchar\n")
+	}
+	buf.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return buf.Bytes()
+}
+
+// helvetica returns the name of the standard Helvetica font, for invisible text.
+func (this *Document) helvetica() string {
+	dict := "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>"
+	return this.resource("Font", "F", dict, func(w *writer) int {
+		return w.add(dict)
+	})
+}
+
+func (this *Page) DrawString(text string, font *canvas.Font, brush canvas.Brush,
+	layout geom.Rect, format *canvas.StringFormat) {
+	face := this.face(font)
+	size := font.Size
+	textLayout := canvas.LayoutText(text, face, font, layout, format)
+	if len(textLayout.Lines) == 0 {
+		return
+	}
+	this.beginDrawing()
+	this.setFill(brush, this.state.transform, textLayout.Bounds.Inflate(size, size))
+	if tt, ok := face.(*truetype.Font); ok {
+		ef := this.doc.embed(tt)
+		fmt.Fprintf(&this.content, "BT\n/%s %s Tf\n", ef.name, num(size))
+		for _, line := range textLayout.Lines {
+			//flip the text space back up, for upright glyphs
+			fmt.Fprintf(&this.content, "1 0 0 -1 %s %s Tm\n<", num(line.Origin.X), num(line.Origin.Y))
+			for _, g := range ef.glyphs(line.Text) {
+				fmt.Fprintf(&this.content, "%04X", g)
+			}
+			this.content.WriteString("> Tj\n")
+		}
+		this.content.WriteString("ET\n")
+	} else {
+		for _, line := range textLayout.Lines {
+			x := line.Origin.X
+			for _, r := range line.Text {
+				if glyph := face.Glyph(r); glyph != nil {
+					glyph.Transform(geom.Scaling(size, size).Multiply(geom.Translation(x, line.Origin.Y)))
+					writePath(&this.content, glyph)
+					if glyph.FillMode == geom.FillWinding {
+						this.content.WriteString("f\n")
+					} else {
+						this.content.WriteString("f*\n")
+					}
+				}
+				x += face.Advance(r) * size
+			}
+		}
+		//invisible text over the outlines, for extraction
+		fmt.Fprintf(&this.content, "BT\n3 Tr\n/%s %s Tf\n", this.doc.helvetica(), num(size))
+		for _, line := range textLayout.Lines {
+			fmt.Fprintf(&this.content, "1 0 0 -1 %s %s Tm\n%s Tj\n",
+				num(line.Origin.X), num(line.Origin.Y), winAnsiString(line.Text))
+		}
+		this.content.WriteString("ET\n")
+	}
+	thickness := size / 16
+	for _, line := range textLayout.Lines {
+		if font.Style&canvas.FontStyleUnderline != 0 {
+			fmt.Fprintf(&this.content, "%s %s %s %s re f\n", num(line.Origin.X),
+				num(line.Origin.Y+size/10-thickness/2), num(line.Width), num(thickness))
+		}
+		if font.Style&canvas.FontStyleStrikeout != 0 {
+			fmt.Fprintf(&this.content, "%s %s %s %s re f\n", num(line.Origin.X),
+				num(line.Origin.Y-size*0.3-thickness/2), num(line.Width), num(thickness))
+		}
+	}
+	this.endDrawing()
+}
+
+// winAnsiSpecials are the runes of WinAnsiEncoding from 0x80 to 0x9f.
+var winAnsiSpecials = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'ˆ': 0x88, '‰': 0x89, 'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e,
+	'‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97,
+	'˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// winAnsiString returns a PDF string of text in WinAnsiEncoding, with ? for the runes it lacks.
+func winAnsiString(text string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('(')
+	for _, r := range text {
+		c, ok := winAnsiSpecials[r]
+		switch {
+		case ok:
+		case r >= 0x20 && r < 0x7f || r >= 0xa0 && r <= 0xff:
+			c = byte(r)
+		default:
+			c = '?'
+		}
+		switch {
+		case c == '(' || c == ')' || c == '\\':
+			buf.WriteByte('\\')
+			buf.WriteByte(c)
+		case c >= 0x80:
+			fmt.Fprintf(&buf, "\\%03o", c)
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	buf.WriteByte(')')
+	return buf.String()
+}
+
+// pdfName returns a PDF name, escaping the characters not allowed as is.
+func pdfName(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('/')
+	for _, c := range []byte(s) {
+		if c <= ' ' || c >= 0x7f || bytes.IndexByte([]byte("#()<>[]{}/%"), c) >= 0 {
+			fmt.Fprintf(&buf, "#%02X", c)
+		} else {
+			buf.WriteByte(c)
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,260 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+type state struct {
+	transform  geom.Affine
+	clipped    bool
+	clipBounds geom.Rect //in device coordinates
+}
+
+// Page is a page of a document, and a canvas.Canvas drawing on it.
+//
+// Device coordinates are points from the top left corner of the page.
+type Page struct {
+	doc     *Document
+	size    geom.Size
+	content bytes.Buffer
+	state   state
+	stack   []state
+}
+
+var _ canvas.Canvas = (*Page)(nil)
+
+func newPage(doc *Document, size geom.Size) *Page {
+	page := &Page{doc: doc, size: size}
+	page.state = state{transform: geom.Identity(), clipBounds: page.Bounds()}
+	page.begin()
+	return page
+}
+
+// begin starts the content, flipping the y axis of PDF to point down.
+func (this *Page) begin() {
+	fmt.Fprintf(&this.content, "1 0 0 -1 0 %s cm\n", num(this.size.Height))
+}
+
+// Size returns the size of the page in points.
+func (this *Page) Size() geom.Size {
+	return this.size
+}
+
+// contentBytes returns the content stream, with the graphics states left saved restored.
+func (this *Page) contentBytes() []byte {
+	return append(this.content.Bytes(), strings.Repeat("Q\n", len(this.stack))...)
+}
+
+// deviceTransform returns the transform from device coordinates to the PDF default space.
+func (this *Page) deviceTransform() geom.Affine {
+	return geom.Affine{M11: 1, M22: -1, DY: this.size.Height}
+}
+
+func (this *Page) Bounds() geom.Rect {
+	return geom.Rc(0, 0, this.size.Width, this.size.Height)
+}
+
+func (this *Page) Save() {
+	this.stack = append(this.stack, this.state)
+	this.content.WriteString("q\n")
+}
+
+func (this *Page) Restore() {
+	if len(this.stack) == 0 {
+		return
+	}
+	this.state = this.stack[len(this.stack)-1]
+	this.stack = this.stack[:len(this.stack)-1]
+	this.content.WriteString("Q\n")
+}
+
+func (this *Page) Transform() geom.Affine {
+	return this.state.transform
+}
+
+func (this *Page) SetTransform(m geom.Affine) {
+	this.state.transform = m
+}
+
+func (this *Page) ClipRect(rect geom.Rect) {
+	path := geom.NewPath()
+	path.AddRectangle(rect)
+	this.ClipPath(path)
+}
+
+// ClipPath intersects the clip of the graphics state with the path in device coordinates,
+// so that the clip doesn't depend on the transform of later drawing.
+func (this *Page) ClipPath(path *geom.Path) {
+	m := this.state.transform
+	device := path.Clone()
+	device.Transform(m)
+	if device.IsEmpty() {
+		this.content.WriteString("0 0 0 0 re W n\n")
+	} else {
+		writePath(&this.content, device)
+		if path.FillMode == geom.FillWinding {
+			this.content.WriteString("W n\n")
+		} else {
+			this.content.WriteString("W* n\n")
+		}
+	}
+	this.state.clipped = true
+	this.state.clipBounds = this.state.clipBounds.Intersect(m.TransformRect(path.ControlBounds()))
+}
+
+func (this *Page) ClipBounds() geom.Rect {
+	inverse, ok := this.state.transform.Invert()
+	if !ok {
+		return geom.Rect{}
+	}
+	return inverse.TransformRect(this.state.clipBounds)
+}
+
+func (this *Page) Clear(c color.NRGBA) {
+	if !this.state.clipped {
+		//nothing is clipped, so that the states left saved can be saved anew
+		this.content.Reset()
+		this.begin()
+		this.content.WriteString(strings.Repeat("q\n", len(this.stack)))
+	}
+	if c.A == 0 {
+		return
+	}
+	this.content.WriteString("q\n")
+	this.setFill(canvas.SolidBrush{Color: c}, geom.Identity(), geom.Rect{})
+	fmt.Fprintf(&this.content, "0 0 %s %s re f\nQ\n", num(this.size.Width), num(this.size.Height))
+}
+
+// beginDrawing saves the graphics state and applies the transform of drawing.
+func (this *Page) beginDrawing() {
+	this.content.WriteString("q\n")
+	if m := this.state.transform; !m.IsIdentity() {
+		fmt.Fprintf(&this.content, "%s cm\n", matrix(m))
+	}
+}
+
+func (this *Page) endDrawing() {
+	this.content.WriteString("Q\n")
+}
+
+func (this *Page) FillPath(path *geom.Path, brush canvas.Brush) {
+	if path.IsEmpty() {
+		return
+	}
+	this.beginDrawing()
+	this.setFill(brush, this.state.transform, path.ControlBounds())
+	writePath(&this.content, path)
+	if path.FillMode == geom.FillWinding {
+		this.content.WriteString("f\n")
+	} else {
+		this.content.WriteString("f*\n")
+	}
+	this.endDrawing()
+}
+
+func (this *Page) StrokePath(path *geom.Path, pen *canvas.Pen) {
+	if path.IsEmpty() {
+		return
+	}
+	this.beginDrawing()
+	width := pen.Width
+	if width < 0 {
+		width = 0
+	}
+	//the farthest a stroke reaches, for the bounds of soft masks
+	margin := max(width, 1) * max(pen.GetMiterLimit(), 2)
+	this.setStroke(pen.GetBrush(), this.state.transform, path.ControlBounds().Inflate(margin, margin))
+
+	//a zero width draws the thinnest line, as the hairlines of the other backends
+	fmt.Fprintf(&this.content, "%s w\n", num(width))
+	dashes := pen.GetDashPattern()
+	lineCap := pen.StartCap
+	if dashes != nil {
+		lineCap = pen.DashCap
+	}
+	switch lineCap {
+	case canvas.LineCapRound, canvas.LineCapTriangle:
+		this.content.WriteString("1 J\n")
+	case canvas.LineCapSquare:
+		this.content.WriteString("2 J\n")
+	}
+	switch pen.Join {
+	case canvas.LineJoinMiter, canvas.LineJoinMiterClipped:
+		fmt.Fprintf(&this.content, "%s M\n", num(max(pen.GetMiterLimit(), 1)))
+	case canvas.LineJoinRound:
+		this.content.WriteString("1 j\n")
+	case canvas.LineJoinBevel:
+		this.content.WriteString("2 j\n")
+	}
+	if dashes != nil {
+		unit := width
+		if unit == 0 {
+			unit = 1
+		}
+		values := make([]string, len(dashes))
+		for n, dash := range dashes {
+			values[n] = num(dash * unit)
+		}
+		fmt.Fprintf(&this.content, "[%s] %s d\n", strings.Join(values, " "), num(pen.DashOffset*unit))
+	}
+	writePath(&this.content, path)
+	this.content.WriteString("S\n")
+	this.endDrawing()
+}
+
+func (this *Page) DrawImage(img image.Image, src image.Rectangle, dst geom.Rect) {
+	src = src.Intersect(img.Bounds())
+	if src.Empty() || dst.IsEmpty() {
+		return
+	}
+	name := this.doc.image(img, src)
+	this.beginDrawing()
+	//the image space maps the unit square to the image with its first row at the top
+	fmt.Fprintf(&this.content, "%s 0 0 %s %s %s cm\n/%s Do\n",
+		num(dst.Width), num(-dst.Height), num(dst.X), num(dst.Bottom()), name)
+	this.endDrawing()
+}
+
+func (this *Page) MeasureString(text string, font *canvas.Font, width float64,
+	format *canvas.StringFormat) geom.Size {
+	return canvas.MeasureText(text, this.face(font), font, width, format)
+}
+
+// face returns the face of a font.
+func (this *Page) face(font *canvas.Font) canvas.Face {
+	if this.doc.Faces != nil {
+		if face := this.doc.Faces.Face(font); face != nil {
+			return face
+		}
+	}
+	return canvas.FallbackFace
+}
+
+// writePath writes the construction operators of a path.
+func writePath(buf *bytes.Buffer, path *geom.Path) {
+	path.Iterate(func(op geom.PathOp, points []geom.Point) {
+		for _, pt := range points {
+			buf.WriteString(num(pt.X))
+			buf.WriteByte(' ')
+			buf.WriteString(num(pt.Y))
+			buf.WriteByte(' ')
+		}
+		switch op {
+		case geom.PathMoveTo:
+			buf.WriteString("m\n")
+		case geom.PathLineTo:
+			buf.WriteString("l\n")
+		case geom.PathCubicTo:
+			buf.WriteString("c\n")
+		case geom.PathClose:
+			buf.WriteString("h\n")
+		}
+	})
+}
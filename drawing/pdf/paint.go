@@ -0,0 +1,234 @@
+package pdf
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// setFill sets the fill paint of the graphics state to a brush, used to fill
+// within bounds in user coordinates mapped to device coordinates by m.
+func (this *Page) setFill(brush canvas.Brush, m geom.Affine, bounds geom.Rect) {
+	this.setPaint(brush, m, bounds, false)
+}
+
+// setStroke sets the stroke paint of the graphics state to a brush, as setFill.
+func (this *Page) setStroke(brush canvas.Brush, m geom.Affine, bounds geom.Rect) {
+	this.setPaint(brush, m, bounds, true)
+}
+
+func (this *Page) setPaint(brush canvas.Brush, m geom.Affine, bounds geom.Rect, stroke bool) {
+	doc := this.doc
+	switch b := brush.(type) {
+	case *canvas.SolidBrush:
+		this.setPaint(*b, m, bounds, stroke)
+	case *canvas.HatchBrush:
+		this.setPaint(*b, m, bounds, stroke)
+	case *canvas.LinearGradientBrush:
+		this.setPaint(*b, m, bounds, stroke)
+	case canvas.SolidBrush:
+		this.content.WriteString(colorOperator(b.Color, stroke))
+		if b.Color.A != 255 {
+			fmt.Fprintf(&this.content, "/%s gs\n", doc.alphaState(b.Color.A, stroke))
+		}
+	case canvas.HatchBrush:
+		this.setPattern(doc.hatchPattern(b, this.deviceTransform()), stroke)
+	case canvas.LinearGradientBrush:
+		stops := b.SortedStops()
+		if len(stops) == 0 || b.Start == b.End {
+			this.setPaint(canvas.SolidBrush{Color: b.ColorAt(0)}, m, bounds, stroke)
+			return
+		}
+		this.setPattern(doc.gradientPattern(b, m.Multiply(this.deviceTransform())), stroke)
+		alpha, uniform := stops[0].Color.A, true
+		for _, stop := range stops {
+			uniform = uniform && stop.Color.A == alpha
+		}
+		if !uniform {
+			//drawing happens in user coordinates, where the mask is defined
+			fmt.Fprintf(&this.content, "/%s gs\n", doc.gradientMask(b, bounds))
+		} else if alpha != 255 {
+			fmt.Fprintf(&this.content, "/%s gs\n", doc.alphaState(alpha, stroke))
+		}
+	default:
+		//no paint: draw nothing visible
+		fmt.Fprintf(&this.content, "/%s gs\n", doc.alphaState(0, stroke))
+	}
+}
+
+func (this *Page) setPattern(name string, stroke bool) {
+	if stroke {
+		fmt.Fprintf(&this.content, "/Pattern CS /%s SCN\n", name)
+	} else {
+		fmt.Fprintf(&this.content, "/Pattern cs /%s scn\n", name)
+	}
+}
+
+// colorOperator returns the operator setting the fill or stroke color, ignoring the alpha.
+func colorOperator(c color.NRGBA, stroke bool) string {
+	op := "rg"
+	if stroke {
+		op = "RG"
+	}
+	return fmt.Sprintf("%s %s %s %s\n", component(c.R), component(c.G), component(c.B), op)
+}
+
+func component(v uint8) string {
+	return num(float64(v) / 255)
+}
+
+// alphaState returns the name of the graphics state setting the fill or stroke alpha.
+func (this *Document) alphaState(alpha uint8, stroke bool) string {
+	entry := "ca"
+	if stroke {
+		entry = "CA"
+	}
+	dict := fmt.Sprintf("<< /Type /ExtGState /%s %s >>", entry, component(alpha))
+	return this.resource("ExtGState", "G", dict, func(w *writer) int {
+		return w.add(dict)
+	})
+}
+
+// hatchPattern returns the name of the tiling pattern of a hatch brush,
+// aligned with device pixels, the device being mapped to the default space by d.
+func (this *Document) hatchPattern(brush canvas.HatchBrush, d geom.Affine) string {
+	var content bytes.Buffer
+	if brush.BackColor.A != 0 {
+		content.WriteString(colorOperator(brush.BackColor, false))
+		if brush.BackColor.A != 255 {
+			fmt.Fprintf(&content, "/%s gs\n", this.alphaState(brush.BackColor.A, false))
+		}
+		content.WriteString("0 0 8 8 re f\n")
+	}
+	content.WriteString(colorOperator(brush.ForeColor, false))
+	if brush.ForeColor.A != 255 {
+		fmt.Fprintf(&content, "/%s gs\n", this.alphaState(brush.ForeColor.A, false))
+	}
+	pattern := canvas.HatchPattern(brush.Style)
+	for y, bits := range pattern {
+		for x := 0; x < 8; x++ {
+			if bits&(0x80>>x) != 0 {
+				fmt.Fprintf(&content, "%d %d 1 1 re\n", x, y)
+			}
+		}
+	}
+	content.WriteString("f\n")
+	dict := fmt.Sprintf(" /Type /Pattern /PatternType 1 /PaintType 1 /TilingType 1 "+
+		"/BBox [0 0 8 8] /XStep 8 /YStep 8 /Matrix [%s]", matrix(d))
+	key := dict + "\x00" + content.String()
+	return this.resource("Pattern", "P", key, func(w *writer) int {
+		return w.add(w.stream(fmt.Sprintf("%s /Resources %d 0 R", dict, w.resources),
+			content.Bytes(), true))
+	})
+}
+
+// gradientPattern returns the name of the shading pattern of a gradient brush,
+// its user coordinates being mapped to the default space by m.
+func (this *Document) gradientPattern(brush canvas.LinearGradientBrush, m geom.Affine) string {
+	function := stopsFunction(brush.SortedStops(), func(c color.NRGBA) string {
+		return component(c.R) + " " + component(c.G) + " " + component(c.B)
+	})
+	dict := fmt.Sprintf("<< /Type /Pattern /PatternType 2 /Matrix [%s] "+
+		"/Shading << /ShadingType 2 /ColorSpace /DeviceRGB %s >> >>",
+		matrix(m), axialShading(brush, function))
+	return this.resource("Pattern", "P", dict, func(w *writer) int {
+		return w.add(dict)
+	})
+}
+
+// gradientMask returns the name of the graphics state masking drawing within bounds
+// with the alpha of a gradient brush.
+func (this *Document) gradientMask(brush canvas.LinearGradientBrush, bounds geom.Rect) string {
+	function := stopsFunction(brush.SortedStops(), func(c color.NRGBA) string {
+		return component(c.A)
+	})
+	shading := fmt.Sprintf("<< /ShadingType 2 /ColorSpace /DeviceGray %s >>",
+		axialShading(brush, function))
+	bbox := fmt.Sprintf("%s %s %s %s",
+		num(bounds.X), num(bounds.Y), num(bounds.Right()), num(bounds.Bottom()))
+	return this.resource("ExtGState", "G", bbox+"\x00"+shading, func(w *writer) int {
+		form := w.add(w.stream(fmt.Sprintf(" /Type /XObject /Subtype /Form /BBox [%s] "+
+			"/Group << /S /Transparency /CS /DeviceGray >> /Resources << /Shading << /S %d 0 R >> >>",
+			bbox, w.add(shading)), []byte("/S sh\n"), true))
+		return w.add(fmt.Sprintf("<< /Type /ExtGState /SMask << /Type /Mask /S /Luminosity /G %d 0 R >> >>",
+			form))
+	})
+}
+
+// axialShading returns the entries of the axial shading of a gradient brush with a function.
+func axialShading(brush canvas.LinearGradientBrush, function string) string {
+	return fmt.Sprintf("/Coords [%s %s %s %s] /Function %s /Extend [true true]",
+		num(brush.Start.X), num(brush.Start.Y), num(brush.End.X), num(brush.End.Y), function)
+}
+
+// stopsFunction returns the function interpolating the values of gradient stops,
+// sorted and not empty, from 0 to 1.
+func stopsFunction(stops []canvas.GradientStop, value func(c color.NRGBA) string) string {
+	//extend the end stops to the ends and drop the segments of no width,
+	//leaving steps between the segments around them
+	if stops[0].Offset > 0 {
+		stops = append([]canvas.GradientStop{{Offset: 0, Color: stops[0].Color}}, stops...)
+	}
+	if last := stops[len(stops)-1]; last.Offset < 1 {
+		stops = append(stops, canvas.GradientStop{Offset: 1, Color: last.Color})
+	}
+	var functions, bounds, encode []string
+	for n := 1; n < len(stops); n++ {
+		s0, s1 := stops[n-1], stops[n]
+		if s1.Offset == s0.Offset {
+			continue
+		}
+		if len(functions) > 0 {
+			bounds = append(bounds, num(s0.Offset))
+		}
+		functions = append(functions, fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>",
+			value(s0.Color), value(s1.Color)))
+		encode = append(encode, "0 1")
+	}
+	if len(functions) == 0 {
+		c := value(stops[0].Color)
+		return fmt.Sprintf("<< /FunctionType 2 /Domain [0 1] /C0 [%s] /C1 [%s] /N 1 >>", c, c)
+	}
+	if len(functions) == 1 {
+		return functions[0]
+	}
+	return fmt.Sprintf("<< /FunctionType 3 /Domain [0 1] /Functions [%s] /Bounds [%s] /Encode [%s] >>",
+		strings.Join(functions, " "), strings.Join(bounds, " "), strings.Join(encode, " "))
+}
+
+// image returns the name of the image XObject of the src part of an image.
+// Identical images share their XObject.
+func (this *Document) image(img image.Image, src image.Rectangle) string {
+	width, height := src.Dx(), src.Dy()
+	rgb := make([]byte, 0, 3*width*height)
+	alpha := make([]byte, 0, width*height)
+	opaque := true
+	for y := src.Min.Y; y < src.Max.Y; y++ {
+		for x := src.Min.X; x < src.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			rgb = append(rgb, c.R, c.G, c.B)
+			alpha = append(alpha, c.A)
+			opaque = opaque && c.A == 255
+		}
+	}
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%dx%d", width, height)
+	hash.Write(rgb)
+	hash.Write(alpha)
+	dict := fmt.Sprintf(" /Type /XObject /Subtype /Image /Width %d /Height %d "+
+		"/BitsPerComponent 8 /Interpolate true", width, height)
+	return this.resource("XObject", "I", string(hash.Sum(nil)), func(w *writer) int {
+		smask := ""
+		if !opaque {
+			smask = fmt.Sprintf(" /SMask %d 0 R",
+				w.add(w.stream(dict+" /ColorSpace /DeviceGray", alpha, true)))
+		}
+		return w.add(w.stream(dict+" /ColorSpace /DeviceRGB"+smask, rgb, true))
+	})
+}
@@ -0,0 +1,249 @@
+// Package pdf implements canvas.Canvas by writing PDF documents, in pure Go.
+//
+// Each page of a Document is a canvas, in points with the origin at the top left
+// and the y axis pointing down. Paths, brushes, pens, clips and images map to
+// their PDF equivalents. Text drawn with a *truetype.Font face is written as text
+// in an embedded subset of the font, with a ToUnicode map so that it can be
+// extracted and searched. Other faces are drawn as glyph outlines, under
+// invisible Helvetica text so that it can still be extracted.
+//
+// The output is deterministic: streams are compressed the same way, no dates are
+// written and resources are numbered in order of first use.
+//
+// Features without a PDF equivalent degrade: different start and end caps use
+// the start cap, triangle caps are drawn round, clipped miters are drawn as miters,
+// and Clear only clears the page when nothing is clipped, otherwise painting over.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"unicode/utf16"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/drawing/truetype"
+)
+
+// Page sizes in points, portrait.
+var (
+	SizeA3     = geom.Size{Width: 841.89, Height: 1190.55}
+	SizeA4     = geom.Size{Width: 595.28, Height: 841.89}
+	SizeA5     = geom.Size{Width: 419.53, Height: 595.28}
+	SizeLetter = geom.Size{Width: 612, Height: 792}
+	SizeLegal  = geom.Size{Width: 612, Height: 1008}
+)
+
+// resource is a named resource shared by the pages.
+type resource struct {
+	kind  string //the resource dictionary entry: ExtGState, Pattern, XObject or Font
+	name  string
+	write func(w *writer) int //writes the objects of the resource, returning its object
+}
+
+// Document is a PDF document of pages.
+type Document struct {
+	pages []*Page
+
+	resources []*resource
+	names     map[string]string //resource key to name
+	fonts     map[*truetype.Font]*embeddedFont
+
+	// Title and Author are written in the document information, if set.
+	Title, Author string
+
+	// Faces provides the faces of fonts. If nil or returning nil,
+	// text is laid out and drawn with canvas.FallbackFace.
+	Faces canvas.FaceSource
+}
+
+// New creates an empty document.
+func New() *Document {
+	return &Document{
+		names: make(map[string]string),
+		fonts: make(map[*truetype.Font]*embeddedFont),
+	}
+}
+
+// AddPage adds a page of a size in points.
+func (this *Document) AddPage(size geom.Size) *Page {
+	page := newPage(this, size)
+	this.pages = append(this.pages, page)
+	return page
+}
+
+// Pages returns the pages of the document.
+func (this *Document) Pages() []*Page {
+	return this.pages
+}
+
+// resource returns the name of the resource of a key, adding it with write if new.
+// Names are a prefix followed by the number of the resource.
+func (this *Document) resource(kind, prefix, key string, write func(w *writer) int) string {
+	key = kind + "\x00" + key
+	if name, ok := this.names[key]; ok {
+		return name
+	}
+	name := prefix + strconv.Itoa(len(this.resources)+1)
+	this.names[key] = name
+	this.resources = append(this.resources, &resource{kind: kind, name: name, write: write})
+	return name
+}
+
+// WriteTo writes the document.
+func (this *Document) WriteTo(w io.Writer) (int64, error) {
+	out := &writer{}
+	catalog, pages, resources := out.alloc(), out.alloc(), out.alloc()
+	out.resources = resources
+	out.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	info := 0
+	if this.Title != "" || this.Author != "" {
+		info = out.alloc()
+		var dict bytes.Buffer
+		dict.WriteString("<<")
+		if this.Title != "" {
+			fmt.Fprintf(&dict, " /Title %s", textString(this.Title))
+		}
+		if this.Author != "" {
+			fmt.Fprintf(&dict, " /Author %s", textString(this.Author))
+		}
+		dict.WriteString(" >>")
+		out.set(info, dict.String())
+	}
+
+	var kids bytes.Buffer
+	for n, page := range this.pages {
+		if n > 0 {
+			kids.WriteByte(' ')
+		}
+		pageObject, content := out.alloc(), out.alloc()
+		fmt.Fprintf(&kids, "%d 0 R", pageObject)
+		out.set(pageObject, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] "+
+			"/Resources %d 0 R /Contents %d 0 R >>",
+			pages, num(page.size.Width), num(page.size.Height), resources, content))
+		out.set(content, out.stream("", page.contentBytes(), true))
+	}
+	out.set(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", kids.String(), len(this.pages)))
+
+	//the resources shared by the pages, also used by patterns and forms
+	entries := make(map[string]*bytes.Buffer)
+	var kinds []string
+	for _, res := range this.resources {
+		ref := res.write(out)
+		entry := entries[res.kind]
+		if entry == nil {
+			entry = &bytes.Buffer{}
+			entries[res.kind] = entry
+			kinds = append(kinds, res.kind)
+		}
+		fmt.Fprintf(entry, " /%s %d 0 R", res.name, ref)
+	}
+	var dict bytes.Buffer
+	dict.WriteString("<< /ProcSet [/PDF /Text /ImageB /ImageC]")
+	for _, kind := range kinds {
+		fmt.Fprintf(&dict, " /%s <<%s >>", kind, entries[kind].String())
+	}
+	dict.WriteString(" >>")
+	out.set(resources, dict.String())
+
+	return out.writeTo(w, catalog, info)
+}
+
+// Bytes returns the document.
+func (this *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	this.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+// writer collects the objects of a document, numbered from 1.
+type writer struct {
+	objects   [][]byte
+	resources int //the object of the resources shared by the pages
+}
+
+// alloc reserves the number of an object, set later.
+func (this *writer) alloc() int {
+	this.objects = append(this.objects, nil)
+	return len(this.objects)
+}
+
+func (this *writer) set(object int, content string) {
+	this.objects[object-1] = []byte(content)
+}
+
+// add adds an object and returns its number.
+func (this *writer) add(content string) int {
+	object := this.alloc()
+	this.set(object, content)
+	return object
+}
+
+// stream returns a stream object with the entries of dict, without brackets.
+func (this *writer) stream(dict string, data []byte, compress bool) string {
+	if compress {
+		var buf bytes.Buffer
+		z := zlib.NewWriter(&buf)
+		z.Write(data)
+		z.Close()
+		data = buf.Bytes()
+		dict += " /Filter /FlateDecode"
+	}
+	return fmt.Sprintf("<<%s /Length %d >>\nstream\n%s\nendstream", dict, len(data), data)
+}
+
+func (this *writer) writeTo(w io.Writer, root, info int) (int64, error) {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(this.objects))
+	for n, object := range this.objects {
+		offsets[n] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", n+1)
+		buf.Write(object)
+		buf.WriteString("\nendobj\n")
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(this.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root %d 0 R", len(this.objects)+1, root)
+	if info != 0 {
+		fmt.Fprintf(&buf, " /Info %d 0 R", info)
+	}
+	fmt.Fprintf(&buf, " >>\nstartxref\n%d\n%%%%EOF\n", xref)
+	return buf.WriteTo(w)
+}
+
+// num formats a number with at most 4 decimals.
+func num(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "0"
+	}
+	v = math.Round(v*1e4) / 1e4
+	if v == 0 {
+		return "0" //no -0
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// matrix formats the operands of a transform, as for the cm operator.
+func matrix(m geom.Affine) string {
+	return fmt.Sprintf("%s %s %s %s %s %s",
+		num(m.M11), num(m.M12), num(m.M21), num(m.M22), num(m.DX), num(m.DY))
+}
+
+// textString returns a PDF text string, in UTF-16 with a byte order mark.
+func textString(s string) string {
+	var buf bytes.Buffer
+	buf.WriteString("<FEFF")
+	for _, unit := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&buf, "%04X", unit)
+	}
+	buf.WriteByte('>')
+	return buf.String()
+}
@@ -0,0 +1,323 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/drawing/truetype"
+)
+
+var black = canvas.SolidBrush{Color: color.NRGBA{A: 0xff}}
+
+// object is an object of a document, with its stream decompressed if it has one.
+type object struct {
+	dict   string
+	stream []byte
+}
+
+var objectPattern = regexp.MustCompile(`(?s)(\d+) 0 obj\n(.*?)\nendobj\n`)
+
+// parseObjects returns the objects of a document written by WriteTo, by number.
+func parseObjects(t *testing.T, data []byte) map[int]object {
+	t.Helper()
+	objects := make(map[int]object)
+	for _, match := range objectPattern.FindAllSubmatch(data, -1) {
+		number, _ := strconv.Atoi(string(match[1]))
+		body := match[2]
+		obj := object{dict: string(body)}
+		if n := bytes.Index(body, []byte("\nstream\n")); n >= 0 {
+			obj.dict = string(body[:n])
+			obj.stream = bytes.TrimSuffix(body[n+len("\nstream\n"):], []byte("\nendstream"))
+			if strings.Contains(obj.dict, "/FlateDecode") {
+				z, err := zlib.NewReader(bytes.NewReader(obj.stream))
+				if err != nil {
+					t.Fatalf("object %d: %v", number, err)
+				}
+				if obj.stream, err = io.ReadAll(z); err != nil {
+					t.Fatalf("object %d: %v", number, err)
+				}
+			}
+		}
+		objects[number] = obj
+	}
+	return objects
+}
+
+// ref returns the object referred to by an entry of a dict.
+func ref(t *testing.T, objects map[int]object, dict, key string) object {
+	t.Helper()
+	match := regexp.MustCompile(`/` + key + ` (\d+) 0 R`).FindStringSubmatch(dict)
+	if match == nil {
+		t.Fatalf("no /%s in %s", key, dict)
+	}
+	number, _ := strconv.Atoi(match[1])
+	return objects[number]
+}
+
+// pages returns the page objects in order.
+func pages(objects map[int]object) []object {
+	var numbers []int
+	for number, obj := range objects {
+		if strings.HasPrefix(obj.dict, "<< /Type /Page ") {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+	var result []object
+	for _, number := range numbers {
+		result = append(result, objects[number])
+	}
+	return result
+}
+
+// literalStrings returns the bytes of the literal strings shown with Tj in a content stream.
+func literalStrings(content []byte) []string {
+	escape := regexp.MustCompile(`\\([0-7]{3}|.)`)
+	var result []string
+	for _, match := range regexp.MustCompile(`\(((?:[^()\\]|\\.)*)\) Tj`).FindAllSubmatch(content, -1) {
+		s := escape.ReplaceAllFunc(match[1], func(escaped []byte) []byte {
+			if len(escaped) == 4 {
+				c, _ := strconv.ParseUint(string(escaped[1:]), 8, 8)
+				return []byte{byte(c)}
+			}
+			return escaped[1:]
+		})
+		result = append(result, string(s))
+	}
+	return result
+}
+
+// newTestFont returns a TrueType font with box glyphs for H (1), i (2) and € (3).
+func newTestFont(t *testing.T) *truetype.Font {
+	t.Helper()
+	be := binary.BigEndian
+	head := make([]byte, 54)
+	be.PutUint32(head, 0x00010000)
+	be.PutUint32(head[12:], 0x5f0f3cf5)
+	be.PutUint16(head[18:], 1000)
+	be.PutUint16(head[40:], 600)
+	be.PutUint16(head[42:], 700)
+	hhea := make([]byte, 36)
+	be.PutUint32(hhea, 0x00010000)
+	be.PutUint16(hhea[4:], 800)
+	be.PutUint16(hhea[6:], uint16(0x10000-200))
+	be.PutUint16(hhea[34:], 4)
+	maxp := make([]byte, 6)
+	be.PutUint32(maxp, 0x00005000)
+	be.PutUint16(maxp[4:], 4)
+
+	var hmtx, glyf, loca []byte
+	loca = be.AppendUint16(loca, 0)
+	for g, width := range []int{500, 600, 300, 600} {
+		hmtx = be.AppendUint16(hmtx, uint16(width))
+		hmtx = be.AppendUint16(hmtx, 0)
+		if g > 0 {
+			//a box of the advance width and 700 units high, as four on-curve points
+			w := uint16(width - 100)
+			glyf = be.AppendUint16(glyf, 1)
+			for _, v := range []uint16{0, 0, w, 700, 3, 0} { //bbox, end point, instructions
+				glyf = be.AppendUint16(glyf, v)
+			}
+			glyf = append(glyf, 1, 1, 1, 1)
+			for _, v := range []uint16{0, w, 0, -w, 0, 0, 700, 0} {
+				glyf = be.AppendUint16(glyf, v)
+			}
+		}
+		loca = be.AppendUint16(loca, uint16(len(glyf)/2))
+	}
+
+	cmap := []byte{0, 0, 0, 1, 0, 3, 0, 10, 0, 0, 0, 12}
+	cmap = be.AppendUint16(cmap, 12)
+	cmap = be.AppendUint16(cmap, 0)
+	cmap = be.AppendUint32(cmap, 16+3*12)
+	cmap = be.AppendUint32(cmap, 0)
+	cmap = be.AppendUint32(cmap, 3)
+	for g, r := range []rune{'H', 'i', '€'} {
+		cmap = be.AppendUint32(cmap, uint32(r))
+		cmap = be.AppendUint32(cmap, uint32(r))
+		cmap = be.AppendUint32(cmap, uint32(g+1))
+	}
+
+	tables := map[string][]byte{"head": head, "hhea": hhea, "maxp": maxp,
+		"hmtx": hmtx, "loca": loca, "glyf": glyf, "cmap": cmap}
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	data := make([]byte, 12+16*len(tags))
+	be.PutUint32(data, 0x00010000)
+	be.PutUint16(data[4:], uint16(len(tags)))
+	for n, tag := range tags {
+		record := data[12+16*n:]
+		copy(record, tag)
+		be.PutUint32(record[8:], uint32(len(data)))
+		be.PutUint32(record[12:], uint32(len(tables[tag])))
+		data = append(data, tables[tag]...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	font, err := truetype.Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return font
+}
+
+func TestFallbackText(t *testing.T) {
+	doc := New()
+	page := doc.AddPage(SizeA4)
+	font := canvas.NewFont("Arial", 12, canvas.FontStyleRegular)
+	page.DrawString("Hello (world)\nÀ l'été €5", font, black, geom.Rc(72, 72, 0, 0), nil)
+
+	objects := parseObjects(t, doc.Bytes())
+	content := ref(t, objects, pages(objects)[0].dict, "Contents").stream
+	got := literalStrings(content)
+	want := []string{"Hello (world)", "\xc0 l'\xe9t\xe9 \x805"} //in WinAnsiEncoding
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tj strings = %q, want %q", got, want)
+	}
+	if !bytes.Contains(content, []byte("3 Tr")) {
+		t.Error("the text over the outlines isn't invisible")
+	}
+	if !bytes.Contains(doc.Bytes(), []byte("/BaseFont /Helvetica /Encoding /WinAnsiEncoding")) {
+		t.Error("no Helvetica font resource")
+	}
+}
+
+func TestEmbeddedFontText(t *testing.T) {
+	tt := newTestFont(t)
+	doc := New()
+	doc.Faces = canvas.FaceSourceFunc(func(*canvas.Font) canvas.Face { return tt })
+	page := doc.AddPage(SizeA4)
+	page.DrawString("Hi€H?", canvas.NewFont("Test", 10, canvas.FontStyleRegular), black,
+		geom.Rc(10, 10, 0, 0), nil)
+
+	objects := parseObjects(t, doc.Bytes())
+	content := ref(t, objects, pages(objects)[0].dict, "Contents").stream
+	match := regexp.MustCompile(`<([0-9A-F]*)> Tj`).FindSubmatch(content)
+	if match == nil {
+		t.Fatalf("no glyph string in %s", content)
+	}
+	glyphs, _ := hex.DecodeString(string(match[1]))
+	if want := []byte{0, 1, 0, 2, 0, 3, 0, 1, 0, 0}; !bytes.Equal(glyphs, want) {
+		t.Errorf("glyphs = % X, want % X", glyphs, want)
+	}
+
+	var fontDict string
+	for _, obj := range objects {
+		if strings.Contains(obj.dict, "/Subtype /Type0") {
+			fontDict = obj.dict
+		}
+	}
+	if !regexp.MustCompile(`/BaseFont /[A-Z]{6}\+Font `).MatchString(fontDict) {
+		t.Errorf("font without subset tag: %s", fontDict)
+	}
+	cmap := string(ref(t, objects, fontDict, "ToUnicode").stream)
+	if start, end := strings.Index(cmap, "beginbfchar"), strings.LastIndex(cmap, "endbfchar"); start < 0 || end < start {
+		t.Fatalf("no bfchar mappings in %s", cmap)
+	} else {
+		cmap = cmap[start:end]
+	}
+	toUnicode := make(map[uint16]string)
+	for _, m := range regexp.MustCompile(`<([0-9A-F]{4})> <([0-9A-F]+)>`).FindAllStringSubmatch(cmap, -1) {
+		g, _ := strconv.ParseUint(m[1], 16, 16)
+		units, _ := hex.DecodeString(m[2])
+		var u16s []uint16
+		for n := 0; n+1 < len(units); n += 2 {
+			u16s = append(u16s, binary.BigEndian.Uint16(units[n:]))
+		}
+		toUnicode[uint16(g)] = string(utf16.Decode(u16s))
+	}
+	var text strings.Builder
+	for n := 0; n+1 < len(glyphs); n += 2 {
+		text.WriteString(toUnicode[binary.BigEndian.Uint16(glyphs[n:])])
+	}
+	//the missing glyph is not mapped
+	if got := text.String(); got != "Hi€H" {
+		t.Errorf("extracted text = %q, want %q", got, "Hi€H")
+	}
+
+	descriptor := ref(t, objects, findDescendant(t, objects, fontDict), "FontDescriptor")
+	fontFile := ref(t, objects, descriptor.dict, "FontFile2")
+	if !strings.Contains(fontFile.dict, fmt.Sprintf("/Length1 %d", len(fontFile.stream))) {
+		t.Errorf("font file %s of %d bytes", fontFile.dict, len(fontFile.stream))
+	}
+}
+
+// findDescendant returns the dict of the descendant font of a Type0 font.
+func findDescendant(t *testing.T, objects map[int]object, fontDict string) string {
+	t.Helper()
+	match := regexp.MustCompile(`/DescendantFonts \[(\d+) 0 R\]`).FindStringSubmatch(fontDict)
+	if match == nil {
+		t.Fatalf("no descendant font in %s", fontDict)
+	}
+	number, _ := strconv.Atoi(match[1])
+	return objects[number].dict
+}
+
+func TestPageSizes(t *testing.T) {
+	doc := New()
+	for _, size := range []geom.Size{SizeA4, SizeLetter, {Width: 200.5, Height: 100}} {
+		doc.AddPage(size)
+	}
+	objects := parseObjects(t, doc.Bytes())
+	var got []string
+	for _, page := range pages(objects) {
+		got = append(got, regexp.MustCompile(`/MediaBox \[[^]]*\]`).FindString(page.dict))
+	}
+	want := []string{"/MediaBox [0 0 595.28 841.89]", "/MediaBox [0 0 612 792]", "/MediaBox [0 0 200.5 100]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("media boxes = %q, want %q", got, want)
+	}
+	if !bytes.Contains(doc.Bytes(), []byte("/Count 3 >>")) {
+		t.Error("page count isn't 3")
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	tt := newTestFont(t)
+	build := func() []byte {
+		doc := New()
+		doc.Title = "Test"
+		doc.Faces = canvas.FaceSourceFunc(func(font *canvas.Font) canvas.Face {
+			if font.Family == "Test" {
+				return tt
+			}
+			return nil
+		})
+		for n := 0; n < 2; n++ {
+			page := doc.AddPage(SizeLetter)
+			canvas.FillRectangle(page, canvas.NewLinearGradientBrush(geom.Pt(0, 0), geom.Pt(100, 0),
+				color.NRGBA{R: 0xff, A: 0xff}, color.NRGBA{B: 0xff, A: 0x80}), geom.Rc(0, 0, 100, 50))
+			img := image.NewNRGBA(image.Rect(0, 0, 2, 2))
+			img.SetNRGBA(1, 0, color.NRGBA{G: 0xff, A: 0xff})
+			page.DrawImage(img, img.Bounds(), geom.Rc(10, 60, 20, 20))
+			page.DrawString("Hi", canvas.NewFont("Test", 10, canvas.FontStyleRegular), black,
+				geom.Rc(10, 100, 0, 0), nil)
+			page.DrawString("Hi", canvas.NewFont("Arial", 10, canvas.FontStyleUnderline), black,
+				geom.Rc(10, 120, 0, 0), nil)
+		}
+		return doc.Bytes()
+	}
+	first, second := build(), build()
+	if !bytes.Equal(first, second) {
+		t.Error("documents differ across runs")
+	}
+}
@@ -2,6 +2,7 @@ package drawing
 
 import (
 	"github.com/zzl/go-gdiplus/gdip"
+	"github.com/zzl/goforms/framework/leaks"
 	"github.com/zzl/goforms/framework/scope"
 	"runtime"
 )
@@ -12,10 +13,11 @@ type Pen struct {
 
 func newPen(s *Scope, p *gdip.Pen) *Pen {
 	pen := &Pen{p: p}
+	leaks.Track(pen)
 	if s != nil {
 		s.Add(pen)
 	}
-	runtime.SetFinalizer(pen, (*Pen).Dispose)
+	setFinalizer(pen)
 	return pen
 }
 
@@ -37,19 +39,20 @@ func NewPenFromBrush(s *scope.Scope, brush *Brush) *Pen {
 
 func NewPenFromBrushWithWidth(s *scope.Scope, brush *Brush, width float32) *Pen {
 	var p *gdip.Pen
-	status := gdip.CreatePen2(brush.p, width, gdip.UnitWorld, &p)
+	status := gdip.CreatePen2(brush.handle(), width, gdip.UnitWorld, &p)
 	checkStatus(status)
 	return newPen(s, p)
 }
 
 func (this *Pen) Clone(s *Scope) *Pen {
 	var p2 *gdip.Pen
-	status := gdip.ClonePen(this.p, &p2)
+	status := gdip.ClonePen(this.handle(), &p2)
 	checkStatus(status)
 	return newPen(s, p2)
 }
 
 func (this *Pen) Dispose() {
+	leaks.Disposed(this)
 	if this.p == nil {
 		return
 	}
@@ -59,133 +62,141 @@ func (this *Pen) Dispose() {
 	runtime.SetFinalizer(this, nil)
 }
 
+// handle returns the native object, reporting use after dispose.
+func (this *Pen) handle() *gdip.Pen {
+	if this.p == nil {
+		leaks.Used(this)
+	}
+	return this.p
+}
+
 func (this *Pen) GetWidth() float32 {
 	var width float32
-	status := gdip.GetPenWidth(this.p, &width)
+	status := gdip.GetPenWidth(this.handle(), &width)
 	checkStatus(status)
 	return width
 }
 
 func (this *Pen) SetWidth(width float32) {
-	status := gdip.SetPenWidth(this.p, width)
+	status := gdip.SetPenWidth(this.handle(), width)
 	checkStatus(status)
 }
 
 func (this *Pen) SetLineCap(startCap, endCap, dashCap gdip.LineCap) {
-	status := gdip.SetPenLineCap197819(this.p, startCap, endCap, gdip.DashCap(dashCap))
+	status := gdip.SetPenLineCap197819(this.handle(), startCap, endCap, gdip.DashCap(dashCap))
 	checkStatus(status)
 }
 
 func (this *Pen) GetStartCap() gdip.LineCap {
 	var cap gdip.LineCap
-	status := gdip.GetPenStartCap(this.p, &cap)
+	status := gdip.GetPenStartCap(this.handle(), &cap)
 	checkStatus(status)
 	return gdip.LineCap(cap)
 }
 
 func (this *Pen) SetStartCap(cap gdip.LineCap) {
-	status := gdip.SetPenStartCap(this.p, gdip.LineCap(cap))
+	status := gdip.SetPenStartCap(this.handle(), gdip.LineCap(cap))
 	checkStatus(status)
 }
 
 func (this *Pen) GetEndCap() gdip.LineCap {
 	var cap gdip.LineCap
-	status := gdip.GetPenEndCap(this.p, &cap)
+	status := gdip.GetPenEndCap(this.handle(), &cap)
 	checkStatus(status)
 	return gdip.LineCap(cap)
 }
 
 func (this *Pen) SetEndCap(cap gdip.LineCap) {
-	status := gdip.SetPenEndCap(this.p, gdip.LineCap(cap))
+	status := gdip.SetPenEndCap(this.handle(), gdip.LineCap(cap))
 	checkStatus(status)
 }
 
 func (this *Pen) GetDashCap() gdip.LineCap {
 	var cap gdip.DashCap
-	status := gdip.GetPenDashCap197819(this.p, &cap)
+	status := gdip.GetPenDashCap197819(this.handle(), &cap)
 	checkStatus(status)
 	return gdip.LineCap(cap)
 }
 
 func (this *Pen) SetDashCap(cap gdip.DashCap) {
-	status := gdip.SetPenDashCap197819(this.p, cap)
+	status := gdip.SetPenDashCap197819(this.handle(), cap)
 	checkStatus(status)
 }
 
 func (this *Pen) GetLineJoin() gdip.LineJoin {
 	var join gdip.LineJoin
-	status := gdip.GetPenLineJoin(this.p, &join)
+	status := gdip.GetPenLineJoin(this.handle(), &join)
 	checkStatus(status)
 	return gdip.LineJoin(join)
 }
 
 func (this *Pen) SetLineJoin(join gdip.LineJoin) {
-	status := gdip.SetPenLineJoin(this.p, gdip.LineJoin(join))
+	status := gdip.SetPenLineJoin(this.handle(), gdip.LineJoin(join))
 	checkStatus(status)
 }
 
 func (this *Pen) GetCustomStartCap() *gdip.CustomLineCap {
 	var cap *gdip.CustomLineCap
-	status := gdip.GetPenCustomStartCap(this.p, &cap)
+	status := gdip.GetPenCustomStartCap(this.handle(), &cap)
 	checkStatus(status)
 	return (*gdip.CustomLineCap)(cap)
 }
 
 func (this *Pen) SetCustomLineCap(cap *gdip.CustomLineCap) {
-	status := gdip.SetPenCustomStartCap(this.p, (*gdip.CustomLineCap)(cap))
+	status := gdip.SetPenCustomStartCap(this.handle(), (*gdip.CustomLineCap)(cap))
 	checkStatus(status)
 }
 
 func (this *Pen) GetCustomEndCap() *gdip.CustomLineCap {
 	var cap *gdip.CustomLineCap
-	status := gdip.GetPenCustomEndCap(this.p, &cap)
+	status := gdip.GetPenCustomEndCap(this.handle(), &cap)
 	checkStatus(status)
 	return (*gdip.CustomLineCap)(cap)
 }
 
 func (this *Pen) SetCustomEndCap(cap *gdip.CustomLineCap) {
-	status := gdip.SetPenCustomEndCap(this.p, (*gdip.CustomLineCap)(cap))
+	status := gdip.SetPenCustomEndCap(this.handle(), (*gdip.CustomLineCap)(cap))
 	checkStatus(status)
 }
 
 func (this *Pen) GetMiterLimit() float32 {
 	var limit float32
-	status := gdip.GetPenMiterLimit(this.p, &limit)
+	status := gdip.GetPenMiterLimit(this.handle(), &limit)
 	checkStatus(status)
 	return limit
 }
 
 func (this *Pen) SetMiterLimit(limit float32) {
-	status := gdip.SetPenMiterLimit(this.p, limit)
+	status := gdip.SetPenMiterLimit(this.handle(), limit)
 	checkStatus(status)
 }
 
 func (this *Pen) GetAlignment() gdip.PenAlignment {
 	var align gdip.PenAlignment
-	status := gdip.GetPenMode(this.p, &align)
+	status := gdip.GetPenMode(this.handle(), &align)
 	checkStatus(status)
 	return gdip.PenAlignment(align)
 }
 
 func (this *Pen) SetAlignment(align gdip.PenAlignment) {
-	status := gdip.SetPenMode(this.p, gdip.PenAlignment(align))
+	status := gdip.SetPenMode(this.handle(), gdip.PenAlignment(align))
 	checkStatus(status)
 }
 
 func (this *Pen) GetTransform(s *Scope) *Matrix {
 	var pMatrix *gdip.Matrix
-	status := gdip.GetPenTransform(this.p, pMatrix)
+	status := gdip.GetPenTransform(this.handle(), pMatrix)
 	checkStatus(status)
 	return newMatrix(s, pMatrix)
 }
 
 func (this *Pen) SetTransform(matrix *Matrix) {
-	status := gdip.SetPenTransform(this.p, matrix.p)
+	status := gdip.SetPenTransform(this.handle(), matrix.handle())
 	checkStatus(status)
 }
 
 func (this *Pen) ResetTransform() {
-	status := gdip.ResetPenTransform(this.p)
+	status := gdip.ResetPenTransform(this.handle())
 	checkStatus(status)
 }
 
@@ -194,7 +205,7 @@ func (this *Pen) MultiplyTransform(matrix *Matrix, append bool) {
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.MultiplyPenTransform(this.p, matrix.p, order)
+	status := gdip.MultiplyPenTransform(this.handle(), matrix.handle(), order)
 	checkStatus(status)
 }
 
@@ -203,7 +214,7 @@ func (this *Pen) TranslateTransform(dx, dy float32, append bool) {
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.TranslatePenTransform(this.p, dx, dy, order)
+	status := gdip.TranslatePenTransform(this.handle(), dx, dy, order)
 	checkStatus(status)
 }
 
@@ -212,7 +223,7 @@ func (this *Pen) ScaleTransform(sx, sy float32, append bool) {
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.ScalePenTransform(this.p, sx, sy, order)
+	status := gdip.ScalePenTransform(this.handle(), sx, sy, order)
 	checkStatus(status)
 }
 
@@ -221,89 +232,89 @@ func (this *Pen) RotateTransform(angle float32, append bool) {
 	if append {
 		order = gdip.MatrixOrderAppend
 	}
-	status := gdip.RotatePenTransform(this.p, angle, order)
+	status := gdip.RotatePenTransform(this.handle(), angle, order)
 	checkStatus(status)
 }
 
 func (this *Pen) GetPenType() gdip.PenType {
 	var penType gdip.PenType
-	status := gdip.GetPenFillType(this.p, &penType)
+	status := gdip.GetPenFillType(this.handle(), &penType)
 	checkStatus(status)
 	return penType
 }
 
 func (this *Pen) GetColor() Color {
 	var argb gdip.ARGB
-	status := gdip.GetPenColor(this.p, &argb)
+	status := gdip.GetPenColor(this.handle(), &argb)
 	checkStatus(status)
 	return ColorOf(argb)
 }
 
 func (this *Pen) SetColor(color Color) {
-	status := gdip.SetPenColor(this.p, color.Argb())
+	status := gdip.SetPenColor(this.handle(), color.Argb())
 	checkStatus(status)
 }
 
 func (this *Pen) GetBrush(s *Scope) *Brush {
 	var pBrush *gdip.Brush
-	status := gdip.GetPenBrushFill(this.p, &pBrush)
+	status := gdip.GetPenBrushFill(this.handle(), &pBrush)
 	checkStatus(status)
 	return newBrush(s, pBrush)
 }
 
 func (this *Pen) SetBrush(brush *Brush) {
-	status := gdip.SetPenBrushFill(this.p, brush.p)
+	status := gdip.SetPenBrushFill(this.handle(), brush.handle())
 	checkStatus(status)
 }
 
 func (this *Pen) GetDashStyle() gdip.DashStyle {
 	var style gdip.DashStyle
-	status := gdip.GetPenDashStyle(this.p, &style)
+	status := gdip.GetPenDashStyle(this.handle(), &style)
 	checkStatus(status)
 	return gdip.DashStyle(style)
 }
 
 func (this *Pen) SetDashStyle(style gdip.DashStyle) {
-	status := gdip.SetPenDashStyle(this.p, gdip.DashStyle(style))
+	status := gdip.SetPenDashStyle(this.handle(), gdip.DashStyle(style))
 	checkStatus(status)
 }
 
 func (this *Pen) GetDashOffset() float32 {
 	var offset float32
-	status := gdip.GetPenDashOffset(this.p, &offset)
+	status := gdip.GetPenDashOffset(this.handle(), &offset)
 	checkStatus(status)
 	return offset
 }
 
 func (this *Pen) SetDashOffset(offset float32) {
-	status := gdip.SetPenDashOffset(this.p, offset)
+	status := gdip.SetPenDashOffset(this.handle(), offset)
 	checkStatus(status)
 }
 
 func (this *Pen) GetDashPattern() []float32 {
 	var count32 int32
-	gdip.GetPenDashCount(this.p, &count32)
+	gdip.GetPenDashCount(this.handle(), &count32)
 	dashes := make([]float32, int(count32))
-	status := gdip.GetPenDashArray(this.p, &dashes[0], count32)
+	status := gdip.GetPenDashArray(this.handle(), &dashes[0], count32)
 	checkStatus(status)
 	return dashes
 }
 
 func (this *Pen) SetDashPattern(dashes []float32) {
-	status := gdip.SetPenDashArray(this.p, &dashes[0], int32(len(dashes)))
+	status := gdip.SetPenDashArray(this.handle(), &dashes[0], int32(len(dashes)))
 	checkStatus(status)
 }
 
 func (this *Pen) GetCompoundArray() []float32 {
 	var count32 int32
-	gdip.GetPenCompoundCount(this.p, &count32)
+	gdip.GetPenCompoundCount(this.handle(), &count32)
 	arr := make([]float32, int(count32))
-	status := gdip.GetPenCompoundArray(this.p, &arr[0], count32)
+	status := gdip.GetPenCompoundArray(this.handle(), &arr[0], count32)
 	checkStatus(status)
 	return arr
 }
 
 func (this *Pen) SetCompoundArray(arr []float32) {
-	status := gdip.SetPenCompoundArray(this.p, &arr[0], int32(len(arr)))
+	status := gdip.SetPenCompoundArray(this.handle(), &arr[0], int32(len(arr)))
 	checkStatus(status)
 }
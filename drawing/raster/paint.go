@@ -0,0 +1,171 @@
+package raster
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// paint yields the premultiplied color of device pixels.
+type paint interface {
+	at(x, y int) color.RGBA
+}
+
+type solidPaint struct {
+	c color.RGBA
+}
+
+func (this *solidPaint) at(x, y int) color.RGBA {
+	return this.c
+}
+
+type hatchPaint struct {
+	pattern    [8]byte
+	fore, back color.RGBA
+}
+
+func (this *hatchPaint) at(x, y int) color.RGBA {
+	if this.pattern[y&7]&(0x80>>(x&7)) != 0 {
+		return this.fore
+	}
+	return this.back
+}
+
+// gradientPaint samples a linear gradient through the inverse of the transformation.
+type gradientPaint struct {
+	inverse    geom.Affine
+	start, dir geom.Point //dir is scaled by the inverse of its squared length
+	colors     [256]color.RGBA
+}
+
+func (this *gradientPaint) at(x, y int) color.RGBA {
+	pt := this.inverse.Transform(geom.Pt(float64(x)+0.5, float64(y)+0.5))
+	t := pt.Sub(this.start).Dot(this.dir)
+	return this.colors[int(math.Round(math.Max(0, math.Min(1, t))*255))]
+}
+
+// imagePaint samples an image bilinearly through the inverse of the mapping
+// from the source rect to device pixels.
+type imagePaint struct {
+	img     image.Image
+	src     image.Rectangle
+	inverse geom.Affine
+}
+
+func (this *imagePaint) at(x, y int) color.RGBA {
+	pt := this.inverse.Transform(geom.Pt(float64(x)+0.5, float64(y)+0.5))
+	fx, fy := pt.X-0.5, pt.Y-0.5
+	x0, y0 := math.Floor(fx), math.Floor(fy)
+	tx, ty := fx-x0, fy-y0
+	c00 := this.pixel(int(x0), int(y0))
+	c10 := this.pixel(int(x0)+1, int(y0))
+	c01 := this.pixel(int(x0), int(y0)+1)
+	c11 := this.pixel(int(x0)+1, int(y0)+1)
+	mix := func(a, b, c, d uint8) uint8 {
+		top := float64(a) + (float64(b)-float64(a))*tx
+		bottom := float64(c) + (float64(d)-float64(c))*tx
+		return uint8(math.Round(top + (bottom-top)*ty))
+	}
+	return color.RGBA{
+		mix(c00.R, c10.R, c01.R, c11.R),
+		mix(c00.G, c10.G, c01.G, c11.G),
+		mix(c00.B, c10.B, c01.B, c11.B),
+		mix(c00.A, c10.A, c01.A, c11.A),
+	}
+}
+
+// pixel returns a pixel of the source rect, clamping at its edges.
+func (this *imagePaint) pixel(x, y int) color.RGBA {
+	x = max(this.src.Min.X, min(this.src.Max.X-1, x))
+	y = max(this.src.Min.Y, min(this.src.Max.Y-1, y))
+	return color.RGBAModel.Convert(this.img.At(x, y)).(color.RGBA)
+}
+
+// premultiply converts a color to premultiplied alpha.
+func premultiply(c color.NRGBA) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+// newPaint creates the paint of a brush under the transformation m.
+func newPaint(brush canvas.Brush, m geom.Affine) paint {
+	switch b := brush.(type) {
+	case canvas.SolidBrush:
+		return &solidPaint{premultiply(b.Color)}
+	case *canvas.SolidBrush:
+		return &solidPaint{premultiply(b.Color)}
+	case canvas.HatchBrush:
+		return &hatchPaint{canvas.HatchPattern(b.Style), premultiply(b.ForeColor), premultiply(b.BackColor)}
+	case *canvas.HatchBrush:
+		return newPaint(*b, m)
+	case canvas.LinearGradientBrush:
+		return newGradientPaint(b, m)
+	case *canvas.LinearGradientBrush:
+		return newGradientPaint(*b, m)
+	}
+	return &solidPaint{}
+}
+
+func newGradientPaint(brush canvas.LinearGradientBrush, m geom.Affine) paint {
+	inverse, ok := m.Invert()
+	d := brush.End.Sub(brush.Start)
+	lenSq := d.Dot(d)
+	if !ok || lenSq == 0 {
+		return &solidPaint{premultiply(brush.ColorAt(0))}
+	}
+	p := &gradientPaint{inverse: inverse, start: brush.Start, dir: d.Mul(1 / lenSq)}
+	for n := range p.colors {
+		p.colors[n] = premultiply(brush.ColorAt(float64(n) / 255))
+	}
+	return p
+}
+
+// blendRow composites a paint over a row of pixels with source-over,
+// weighting it by the coverage of the pixels and the clip mask.
+func blendRow(img *image.RGBA, p paint, clip *mask, y, x0 int, coverage []float32) {
+	for n, cov := range coverage {
+		x := x0 + n
+		if clip != nil {
+			cov *= clip.at(x, y)
+		}
+		if cov <= 0 {
+			continue
+		}
+		src := p.at(x, y)
+		if src.A == 0 {
+			continue
+		}
+		i := img.PixOffset(x, y)
+		pix := img.Pix[i : i+4 : i+4]
+		k := float32(cov)
+		inv := 1 - float32(src.A)*k/255
+		pix[0] = uint8(float32(src.R)*k + float32(pix[0])*inv + 0.5)
+		pix[1] = uint8(float32(src.G)*k + float32(pix[1])*inv + 0.5)
+		pix[2] = uint8(float32(src.B)*k + float32(pix[2])*inv + 0.5)
+		pix[3] = uint8(float32(src.A)*k + float32(pix[3])*inv + 0.5)
+	}
+}
+
+// copyRow replaces a row of pixels with a paint,
+// interpolating by the coverage of the pixels and the clip mask.
+func copyRow(img *image.RGBA, p paint, clip *mask, y, x0 int, coverage []float32) {
+	for n, cov := range coverage {
+		x := x0 + n
+		if clip != nil {
+			cov *= clip.at(x, y)
+		}
+		if cov <= 0 {
+			continue
+		}
+		src := p.at(x, y)
+		i := img.PixOffset(x, y)
+		pix := img.Pix[i : i+4 : i+4]
+		k := float32(cov)
+		pix[0] = uint8(float32(src.R)*k + float32(pix[0])*(1-k) + 0.5)
+		pix[1] = uint8(float32(src.G)*k + float32(pix[1])*(1-k) + 0.5)
+		pix[2] = uint8(float32(src.B)*k + float32(pix[2])*(1-k) + 0.5)
+		pix[3] = uint8(float32(src.A)*k + float32(pix[3])*(1-k) + 0.5)
+	}
+}
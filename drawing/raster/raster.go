@@ -0,0 +1,270 @@
+// Package raster implements canvas.Canvas with a pure-Go anti-aliasing rasterizer
+// drawing to an *image.RGBA.
+//
+// It doesn't depend on Windows, so that painting code written against canvas.Canvas
+// can run in tests and tools on any platform. Text is drawn with the faces
+// of Canvas.Faces, falling back to canvas.FallbackFace.
+package raster
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// tolerance is the maximal distance in device pixels between curves and their flattening.
+const tolerance = 0.2
+
+// mask is the coverage of the clip, from 0 to 1, over a rect of device pixels.
+type mask struct {
+	rect  image.Rectangle
+	alpha []float32
+}
+
+func (this *mask) at(x, y int) float32 {
+	if !(image.Point{x, y}).In(this.rect) {
+		return 0
+	}
+	return this.alpha[(y-this.rect.Min.Y)*this.rect.Dx()+x-this.rect.Min.X]
+}
+
+type state struct {
+	transform geom.Affine
+	clip      *mask //nil if not clipped; never modified once set
+}
+
+// Canvas is a canvas.Canvas drawing to an *image.RGBA.
+type Canvas struct {
+	img   *image.RGBA
+	state state
+	stack []state
+
+	// Faces provides the faces of fonts. If nil or returning nil,
+	// text is drawn with canvas.FallbackFace.
+	Faces canvas.FaceSource
+}
+
+var _ canvas.Canvas = (*Canvas)(nil)
+
+// New creates a canvas drawing to an image.
+func New(img *image.RGBA) *Canvas {
+	return &Canvas{img: img, state: state{transform: geom.Identity()}}
+}
+
+// NewRGBA creates a canvas drawing to a new transparent image.
+func NewRGBA(width, height int) *Canvas {
+	return New(image.NewRGBA(image.Rect(0, 0, width, height)))
+}
+
+// Image returns the image drawn to.
+func (this *Canvas) Image() *image.RGBA {
+	return this.img
+}
+
+func (this *Canvas) Bounds() geom.Rect {
+	b := this.img.Bounds()
+	return geom.Rc(float64(b.Min.X), float64(b.Min.Y), float64(b.Dx()), float64(b.Dy()))
+}
+
+func (this *Canvas) Save() {
+	this.stack = append(this.stack, this.state)
+}
+
+func (this *Canvas) Restore() {
+	if len(this.stack) == 0 {
+		return
+	}
+	this.state = this.stack[len(this.stack)-1]
+	this.stack = this.stack[:len(this.stack)-1]
+}
+
+func (this *Canvas) Transform() geom.Affine {
+	return this.state.transform
+}
+
+func (this *Canvas) SetTransform(m geom.Affine) {
+	this.state.transform = m
+}
+
+// clipRect returns the device pixels that may be drawn.
+func (this *Canvas) clipRect() image.Rectangle {
+	if this.state.clip != nil {
+		return this.state.clip.rect
+	}
+	return this.img.Bounds()
+}
+
+func (this *Canvas) ClipRect(rect geom.Rect) {
+	path := geom.NewPath()
+	path.AddRectangle(rect)
+	this.ClipPath(path)
+}
+
+func (this *Canvas) ClipPath(path *geom.Path) {
+	r := newRasterizer()
+	for _, line := range path.Flatten(this.state.transform, tolerance) {
+		r.addPolygon(line.Points)
+	}
+	old := this.state.clip
+	clip := &mask{rect: r.bounds(this.clipRect())}
+	clip.alpha = make([]float32, clip.rect.Dx()*clip.rect.Dy())
+	r.render(clip.rect, path.FillMode == geom.FillWinding, func(y, x0 int, coverage []float32) {
+		offset := (y-clip.rect.Min.Y)*clip.rect.Dx() + x0 - clip.rect.Min.X
+		for n, cov := range coverage {
+			if old != nil {
+				cov *= old.at(x0+n, y)
+			}
+			clip.alpha[offset+n] = cov
+		}
+	})
+	this.state.clip = clip
+}
+
+func (this *Canvas) ClipBounds() geom.Rect {
+	inverse, ok := this.state.transform.Invert()
+	if !ok {
+		return geom.Rect{}
+	}
+	r := this.clipRect()
+	return inverse.TransformRect(geom.Rc(float64(r.Min.X), float64(r.Min.Y),
+		float64(r.Dx()), float64(r.Dy())))
+}
+
+func (this *Canvas) Clear(c color.NRGBA) {
+	src := premultiply(c)
+	if this.state.clip == nil {
+		draw.Draw(this.img, this.img.Bounds(), image.NewUniform(src), image.Point{}, draw.Src)
+		return
+	}
+	rect := this.state.clip.rect
+	coverage := make([]float32, rect.Dx())
+	for n := range coverage {
+		coverage[n] = 1
+	}
+	p := &solidPaint{src}
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		copyRow(this.img, p, this.state.clip, y, rect.Min.X, coverage)
+	}
+}
+
+// fill composites a paint over the interior of polygons in device coordinates.
+func (this *Canvas) fill(polygons [][]geom.Point, winding bool, p paint) {
+	r := newRasterizer()
+	for _, polygon := range polygons {
+		r.addPolygon(polygon)
+	}
+	r.render(this.clipRect(), winding, func(y, x0 int, coverage []float32) {
+		blendRow(this.img, p, this.state.clip, y, x0, coverage)
+	})
+}
+
+func (this *Canvas) FillPath(path *geom.Path, brush canvas.Brush) {
+	m := this.state.transform
+	var polygons [][]geom.Point
+	for _, line := range path.Flatten(m, tolerance) {
+		polygons = append(polygons, line.Points)
+	}
+	this.fill(polygons, path.FillMode == geom.FillWinding, newPaint(brush, m))
+}
+
+func (this *Canvas) StrokePath(path *geom.Path, pen *canvas.Pen) {
+	m := this.state.transform
+	if pen.Width <= 0 {
+		//hairlines are one device pixel wide whatever the transform
+		polygons := pen.Stroke(1).Outline(path.Flatten(m, tolerance), tolerance)
+		this.fill(polygons, true, newPaint(pen.GetBrush(), m))
+		return
+	}
+	scale := scaleOf(m)
+	if scale == 0 {
+		return
+	}
+	polygons := pen.Stroke(pen.Width).Outline(path.Flatten(geom.Identity(), tolerance/scale), tolerance/scale)
+	for _, polygon := range polygons {
+		m.TransformPoints(polygon)
+	}
+	this.fill(polygons, true, newPaint(pen.GetBrush(), m))
+}
+
+// scaleOf returns the average scale factor of a transformation.
+func scaleOf(m geom.Affine) float64 {
+	return math.Sqrt(math.Abs(m.Determinant()))
+}
+
+func (this *Canvas) DrawImage(img image.Image, src image.Rectangle, dst geom.Rect) {
+	src = src.Intersect(img.Bounds())
+	if src.Empty() || dst.IsEmpty() {
+		return
+	}
+	mapping := geom.Translation(-float64(src.Min.X), -float64(src.Min.Y)).
+		Multiply(geom.Scaling(dst.Width/float64(src.Dx()), dst.Height/float64(src.Dy()))).
+		Multiply(geom.Translation(dst.X, dst.Y)).
+		Multiply(this.state.transform)
+	inverse, ok := mapping.Invert()
+	if !ok {
+		return
+	}
+	polygon := []geom.Point{
+		geom.Pt(dst.X, dst.Y), geom.Pt(dst.Right(), dst.Y),
+		geom.Pt(dst.Right(), dst.Bottom()), geom.Pt(dst.X, dst.Bottom()),
+	}
+	this.state.transform.TransformPoints(polygon)
+	this.fill([][]geom.Point{polygon}, false, &imagePaint{img: img, src: src, inverse: inverse})
+}
+
+// face returns the face of a font.
+func (this *Canvas) face(font *canvas.Font) canvas.Face {
+	if this.Faces != nil {
+		if face := this.Faces.Face(font); face != nil {
+			return face
+		}
+	}
+	return canvas.FallbackFace
+}
+
+func (this *Canvas) DrawString(text string, font *canvas.Font, brush canvas.Brush,
+	layout geom.Rect, format *canvas.StringFormat) {
+	face := this.face(font)
+	size := font.Size
+	m := this.state.transform
+	p := newPaint(brush, m)
+	textLayout := canvas.LayoutText(text, face, font, layout, format)
+	for _, line := range textLayout.Lines {
+		x := line.Origin.X
+		for _, r := range line.Text {
+			if glyph := face.Glyph(r); glyph != nil {
+				glyphTransform := geom.Scaling(size, size).
+					Multiply(geom.Translation(x, line.Origin.Y)).Multiply(m)
+				var polygons [][]geom.Point
+				for _, poly := range glyph.Flatten(glyphTransform, tolerance) {
+					polygons = append(polygons, poly.Points)
+				}
+				this.fill(polygons, glyph.FillMode == geom.FillWinding, p)
+			}
+			x += face.Advance(r) * size
+		}
+		thickness := size / 16
+		if font.Style&canvas.FontStyleUnderline != 0 {
+			this.FillPath(lineRect(line, line.Origin.Y+size/10, thickness), brush)
+		}
+		if font.Style&canvas.FontStyleStrikeout != 0 {
+			this.FillPath(lineRect(line, line.Origin.Y-size*0.3, thickness), brush)
+		}
+	}
+}
+
+// lineRect returns a rect as wide as a line of text, centered vertically at y.
+func lineRect(line canvas.TextLine, y, thickness float64) *geom.Path {
+	path := geom.NewPath()
+	path.AddRectangle(geom.Rc(line.Origin.X, y-thickness/2, line.Width, thickness))
+	return path
+}
+
+func (this *Canvas) MeasureString(text string, font *canvas.Font, width float64,
+	format *canvas.StringFormat) geom.Size {
+	return canvas.MeasureText(text, this.face(font), font, width, format)
+}
@@ -0,0 +1,130 @@
+package raster
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+var (
+	black = color.NRGBA{A: 0xff}
+	white = color.NRGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+)
+
+// alphaAt returns the alpha of a pixel, i.e. its coverage when drawn in opaque colors
+// on a transparent image.
+func alphaAt(c *Canvas, x, y int) uint8 {
+	return c.Image().RGBAAt(x, y).A
+}
+
+func TestFillRectEdges(t *testing.T) {
+	tests := []struct {
+		name string
+		rect geom.Rect
+		x, y int
+		want uint8
+	}{
+		{"inside", geom.Rc(2, 2, 4, 4), 3, 3, 0xff},
+		{"left edge", geom.Rc(2, 2, 4, 4), 2, 3, 0xff},
+		{"right edge", geom.Rc(2, 2, 4, 4), 5, 3, 0xff},
+		{"outside left", geom.Rc(2, 2, 4, 4), 1, 3, 0},
+		{"outside right", geom.Rc(2, 2, 4, 4), 6, 3, 0},
+		{"outside bottom", geom.Rc(2, 2, 4, 4), 3, 6, 0},
+		{"half pixel left", geom.Rc(2.5, 2, 3, 4), 2, 3, 0x80},
+		{"half pixel top", geom.Rc(2, 2.5, 4, 3), 3, 2, 0x80},
+		{"quarter pixel corner", geom.Rc(2.5, 2.5, 3, 3), 2, 2, 0x40},
+	}
+	for _, test := range tests {
+		c := NewRGBA(8, 8)
+		canvas.FillRectangle(c, canvas.SolidBrush{Color: black}, test.rect)
+		if got := alphaAt(c, test.x, test.y); absDiff(got, test.want) > 1 {
+			t.Errorf("%s: alpha at (%d, %d) = %#x, want %#x", test.name, test.x, test.y, got, test.want)
+		}
+	}
+}
+
+func TestClippedClear(t *testing.T) {
+	c := NewRGBA(8, 8)
+	c.Clear(black)
+	c.Save()
+	c.ClipRect(geom.Rc(2, 3, 4, 2))
+	c.Clear(white)
+	c.Restore()
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			want := uint8(0)
+			if x >= 2 && x < 6 && y >= 3 && y < 5 {
+				want = 0xff
+			}
+			if got := c.Image().RGBAAt(x, y); got.R != want || got.A != 0xff {
+				t.Errorf("pixel (%d, %d) = %v, want red %#x", x, y, got, want)
+			}
+		}
+	}
+	//the clip is restored
+	c.Clear(white)
+	if got := c.Image().RGBAAt(0, 0); got.R != 0xff {
+		t.Errorf("pixel (0, 0) after Restore = %v, want white", got)
+	}
+}
+
+func TestTransformedClip(t *testing.T) {
+	c := NewRGBA(8, 8)
+	canvas.Translate(c, 4, 4)
+	c.ClipRect(geom.Rc(0, 0, 2, 2))
+	canvas.FillRectangle(c, canvas.SolidBrush{Color: black}, geom.Rc(-4, -4, 8, 8))
+	if got := alphaAt(c, 4, 4); got != 0xff {
+		t.Errorf("alpha inside the clip = %#x, want 0xff", got)
+	}
+	if got := alphaAt(c, 3, 4); got != 0 {
+		t.Errorf("alpha left of the clip = %#x, want 0", got)
+	}
+	if got := alphaAt(c, 6, 4); got != 0 {
+		t.Errorf("alpha right of the clip = %#x, want 0", got)
+	}
+}
+
+func TestDashedStroke(t *testing.T) {
+	c := NewRGBA(20, 4)
+	pen := canvas.NewPen(black, 2)
+	pen.DashStyle = canvas.DashStyleDash //dashes of 6 and gaps of 2 pixels
+	canvas.DrawLine(c, pen, geom.Pt(0, 2), geom.Pt(20, 2))
+	for x := 0; x < 20; x++ {
+		want := uint8(0xff)
+		if x%8 >= 6 {
+			want = 0
+		}
+		if got := alphaAt(c, x, 1); got != want {
+			t.Errorf("alpha at x=%d = %#x, want %#x", x, got, want)
+		}
+		if got := alphaAt(c, x, 0); got != 0 {
+			t.Errorf("alpha above the stroke at x=%d = %#x, want 0", x, got)
+		}
+	}
+}
+
+func TestHairline(t *testing.T) {
+	c := NewRGBA(8, 8)
+	canvas.Scale(c, 4, 4)
+	pen := canvas.NewPen(black, 0)
+	canvas.DrawLine(c, pen, geom.Pt(0, 0.625), geom.Pt(2, 0.625))
+	//one device pixel wide whatever the scale, centered on y = 2.5
+	if got := alphaAt(c, 3, 2); got != 0xff {
+		t.Errorf("alpha on the hairline = %#x, want 0xff", got)
+	}
+	if got := alphaAt(c, 3, 1); got != 0 {
+		t.Errorf("alpha above the hairline = %#x, want 0", got)
+	}
+	if got := alphaAt(c, 3, 3); got != 0 {
+		t.Errorf("alpha below the hairline = %#x, want 0", got)
+	}
+}
+
+func absDiff(a, b uint8) uint8 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
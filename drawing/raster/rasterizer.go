@@ -0,0 +1,193 @@
+package raster
+
+import (
+	"image"
+	"math"
+	"sort"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// subSamples is the number of sub-scanlines sampled per pixel row.
+// The horizontal coverage is computed exactly.
+const subSamples = 16
+
+type edge struct {
+	x0, y0 float64 //the top end
+	y1     float64 //the bottom
+	slope  float64 //dx/dy
+	dir    int     //+1 downwards, -1 upwards
+}
+
+// rasterizer computes the anti-aliased coverage of polygons.
+type rasterizer struct {
+	edges                  []edge
+	minX, minY, maxX, maxY float64
+}
+
+func newRasterizer() *rasterizer {
+	return &rasterizer{
+		minX: math.Inf(1), minY: math.Inf(1),
+		maxX: math.Inf(-1), maxY: math.Inf(-1),
+	}
+}
+
+// addPolygon adds a polygon, implicitly closed.
+func (this *rasterizer) addPolygon(points []geom.Point) {
+	count := len(points)
+	if count < 2 {
+		return
+	}
+	for n := 0; n < count; n++ {
+		this.addEdge(points[n], points[(n+1)%count])
+	}
+}
+
+func (this *rasterizer) addEdge(p0, p1 geom.Point) {
+	if math.IsNaN(p0.X+p0.Y+p1.X+p1.Y) || math.IsInf(p0.X+p0.Y+p1.X+p1.Y, 0) {
+		return
+	}
+	this.minX = math.Min(this.minX, math.Min(p0.X, p1.X))
+	this.maxX = math.Max(this.maxX, math.Max(p0.X, p1.X))
+	if p0.Y == p1.Y {
+		return
+	}
+	dir := 1
+	if p0.Y > p1.Y {
+		p0, p1 = p1, p0
+		dir = -1
+	}
+	this.minY = math.Min(this.minY, p0.Y)
+	this.maxY = math.Max(this.maxY, p1.Y)
+	this.edges = append(this.edges, edge{
+		x0: p0.X, y0: p0.Y, y1: p1.Y,
+		slope: (p1.X - p0.X) / (p1.Y - p0.Y),
+		dir:   dir,
+	})
+}
+
+// bounds returns the pixels touched by the polygons, within clip.
+func (this *rasterizer) bounds(clip image.Rectangle) image.Rectangle {
+	if len(this.edges) == 0 {
+		return image.Rectangle{}
+	}
+	rect := image.Rect(int(math.Floor(this.minX)), int(math.Floor(this.minY)),
+		int(math.Ceil(this.maxX))+1, int(math.Ceil(this.maxY)))
+	return rect.Intersect(clip)
+}
+
+type crossing struct {
+	x   float64
+	dir int
+}
+
+// render calls fn with the coverage of each row of pixels within clip,
+// from 0 to 1, starting at the pixel x0 of the row y.
+// The coverage slice is only valid during the call.
+func (this *rasterizer) render(clip image.Rectangle, winding bool,
+	fn func(y int, x0 int, coverage []float32)) {
+	rect := this.bounds(clip)
+	if rect.Empty() {
+		return
+	}
+	sort.Slice(this.edges, func(i, j int) bool {
+		return this.edges[i].y0 < this.edges[j].y0
+	})
+	width := rect.Dx()
+	coverage := make([]float32, width+1)
+	delta := make([]float32, width+2)
+	var active []*edge
+	var crossings []crossing
+	next := 0
+	xMin, xMax := float64(rect.Min.X), float64(rect.Max.X)
+	const weight = 1.0 / subSamples
+
+	addSpan := func(xa, xb float64) {
+		xa = math.Max(xa, xMin)
+		xb = math.Min(xb, xMax)
+		if xb <= xa {
+			return
+		}
+		ia, ib := math.Floor(xa), math.Floor(xb)
+		i := int(ia) - rect.Min.X
+		if ia == ib {
+			coverage[i] += float32((xb - xa) * weight)
+			return
+		}
+		coverage[i] += float32((ia + 1 - xa) * weight)
+		j := int(ib) - rect.Min.X
+		delta[i+1] += weight
+		delta[j] -= weight
+		if j < width {
+			coverage[j] += float32((xb - ib) * weight)
+		}
+	}
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		fy := float64(y)
+		for next < len(this.edges) && this.edges[next].y0 < fy+1 {
+			active = append(active, &this.edges[next])
+			next++
+		}
+		live := active[:0]
+		for _, e := range active {
+			if e.y1 > fy {
+				live = append(live, e)
+			}
+		}
+		active = live
+		if len(active) == 0 {
+			continue
+		}
+		for i := 0; i < subSamples; i++ {
+			sy := fy + (float64(i)+0.5)/subSamples
+			crossings = crossings[:0]
+			for _, e := range active {
+				if e.y0 <= sy && sy < e.y1 {
+					crossings = append(crossings, crossing{e.x0 + (sy-e.y0)*e.slope, e.dir})
+				}
+			}
+			if len(crossings) < 2 {
+				continue
+			}
+			for a := 1; a < len(crossings); a++ {
+				for b := a; b > 0 && crossings[b].x < crossings[b-1].x; b-- {
+					crossings[b], crossings[b-1] = crossings[b-1], crossings[b]
+				}
+			}
+			count := 0
+			spanStart := 0.0
+			for _, c := range crossings {
+				wasInside := isInside(count, winding)
+				count += c.dir
+				inside := isInside(count, winding)
+				if inside && !wasInside {
+					spanStart = c.x
+				} else if !inside && wasInside {
+					addSpan(spanStart, c.x)
+				}
+			}
+		}
+		var run float32
+		for x := 0; x < width; x++ {
+			run += delta[x]
+			coverage[x] += run
+			if coverage[x] > 1 {
+				coverage[x] = 1
+			}
+			delta[x] = 0
+		}
+		delta[width], delta[width+1] = 0, 0
+		fn(y, rect.Min.X, coverage[:width])
+		for x := range coverage {
+			coverage[x] = 0
+		}
+	}
+}
+
+func isInside(count int, winding bool) bool {
+	if winding {
+		return count != 0
+	}
+	return count&1 != 0
+}
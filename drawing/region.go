@@ -1,21 +1,30 @@
 package drawing
 
 import (
+	"errors"
 	"github.com/zzl/go-gdiplus/gdip"
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing/region"
+	"github.com/zzl/goforms/framework/leaks"
+	"github.com/zzl/goforms/framework/scope"
 	"runtime"
+	"unsafe"
 )
 
+// ErrInvalidRegion is returned for a GDI region whose data can not be retrieved.
+var ErrInvalidRegion = errors.New("drawing: invalid GDI region")
+
 type Region struct {
 	p *gdip.Region
 }
 
 func newRegion(s *Scope, p *gdip.Region) *Region {
 	region := &Region{p}
+	leaks.Track(region)
 	if s != nil {
 		s.Add(region)
 	}
-	runtime.SetFinalizer(region, (*Region).Dispose)
+	setFinalizer(region)
 	return region
 }
 
@@ -42,7 +51,7 @@ func NewRegionOfRectF(s *Scope, rect RectF) *Region {
 
 func NewRegionOfPath(s *Scope, path *Path) *Region {
 	var pRegion *gdip.Region
-	status := gdip.CreateRegionPath(path.p, &pRegion)
+	status := gdip.CreateRegionPath(path.handle(), &pRegion)
 	checkStatus(status)
 	return newRegion(s, pRegion)
 }
@@ -62,7 +71,38 @@ func NewRegionFromHrgn(s *Scope, hRgn win32.HRGN) *Region {
 	return newRegion(s, pRegion)
 }
 
+// NewRegionOfRects creates a region covering the rects of a pure-Go region.
+func NewRegionOfRects(s *Scope, r *region.Region) *Region {
+	hRgn := NewHrgnOfRects(r)
+	defer win32.DeleteObject(win32.HGDIOBJ(hRgn))
+	return NewRegionFromHrgn(s, hRgn)
+}
+
+// NewHrgnOfRects creates a GDI region covering the rects of a pure-Go region.
+// The caller deletes it with DeleteObject.
+func NewHrgnOfRects(r *region.Region) win32.HRGN {
+	data := r.Data()
+	return win32.ExtCreateRegion(nil, uint32(len(data)),
+		(*win32.RGNDATA)(unsafe.Pointer(&data[0])))
+}
+
+// RectsOfHrgn returns the rects of a GDI region as a pure-Go region.
+// It returns ErrInvalidRegion if the region data can not be retrieved,
+// and region.ErrFormat if it can not be parsed.
+func RectsOfHrgn(hRgn win32.HRGN) (*region.Region, error) {
+	size := win32.GetRegionData(hRgn, 0, nil)
+	if size == 0 {
+		return nil, ErrInvalidRegion
+	}
+	data := make([]byte, size)
+	if win32.GetRegionData(hRgn, size, (*win32.RGNDATA)(unsafe.Pointer(&data[0]))) == 0 {
+		return nil, ErrInvalidRegion
+	}
+	return region.FromData(data)
+}
+
 func (this *Region) Dispose() {
+	leaks.Disposed(this)
 	if this.p == nil {
 		return
 	}
@@ -71,42 +111,50 @@ func (this *Region) Dispose() {
 	runtime.SetFinalizer(this, nil)
 }
 
+// handle returns the native object, reporting use after dispose.
+func (this *Region) handle() *gdip.Region {
+	if this.p == nil {
+		leaks.Used(this)
+	}
+	return this.p
+}
+
 func (this *Region) Clone(s *Scope) *Region {
 	var pRegion2 *gdip.Region
-	status := gdip.CloneRegion(this.p, &pRegion2)
+	status := gdip.CloneRegion(this.handle(), &pRegion2)
 	checkStatus(status)
 	return newRegion(s, pRegion2)
 }
 
 func (this *Region) MakeInfinite() {
-	status := gdip.SetInfinite(this.p)
+	status := gdip.SetInfinite(this.handle())
 	checkStatus(status)
 }
 
 func (this *Region) MakeEmpty() {
-	status := gdip.SetEmpty(this.p)
+	status := gdip.SetEmpty(this.handle())
 	checkStatus(status)
 }
 
 func (this *Region) InterceptRect(rect Rect) {
-	status := gdip.CombineRegionRectI(this.p,
+	status := gdip.CombineRegionRectI(this.handle(),
 		(*gdip.Rect)(&rect), gdip.CombineModeIntersect)
 	checkStatus(status)
 }
 
 func (this *Region) InterceptRectF(rect RectF) {
-	status := gdip.CombineRegionRect(this.p,
+	status := gdip.CombineRegionRect(this.handle(),
 		(*gdip.RectF)(&rect), gdip.CombineModeIntersect)
 	checkStatus(status)
 }
 
 func (this *Region) InterceptPath(path *Path) {
-	status := gdip.CombineRegionPath(this.p, path.p, gdip.CombineModeIntersect)
+	status := gdip.CombineRegionPath(this.handle(), path.handle(), gdip.CombineModeIntersect)
 	checkStatus(status)
 }
 
 func (this *Region) InterceptRegion(region *Region) {
-	status := gdip.CombineRegionRegion(this.p, region.p, gdip.CombineModeIntersect)
+	status := gdip.CombineRegionRegion(this.handle(), region.handle(), gdip.CombineModeIntersect)
 	checkStatus(status)
 }
 
@@ -114,205 +162,205 @@ func (this *Region) InterceptRegion(region *Region) {
 //}
 
 func (this *Region) UnionRect(rect Rect) {
-	status := gdip.CombineRegionRectI(this.p,
+	status := gdip.CombineRegionRectI(this.handle(),
 		(*gdip.Rect)(&rect), gdip.CombineModeUnion)
 	checkStatus(status)
 }
 
 func (this *Region) UnionRectF(rect RectF) {
-	status := gdip.CombineRegionRect(this.p,
+	status := gdip.CombineRegionRect(this.handle(),
 		(*gdip.RectF)(&rect), gdip.CombineModeUnion)
 	checkStatus(status)
 }
 
 func (this *Region) UnionPath(path *Path) {
-	status := gdip.CombineRegionPath(this.p, path.p, gdip.CombineModeUnion)
+	status := gdip.CombineRegionPath(this.handle(), path.handle(), gdip.CombineModeUnion)
 	checkStatus(status)
 }
 
 func (this *Region) UnionRegion(region *Region) {
-	status := gdip.CombineRegionRegion(this.p, region.p, gdip.CombineModeUnion)
+	status := gdip.CombineRegionRegion(this.handle(), region.handle(), gdip.CombineModeUnion)
 	checkStatus(status)
 }
 
 func (this *Region) XorRect(rect Rect) {
-	status := gdip.CombineRegionRectI(this.p,
+	status := gdip.CombineRegionRectI(this.handle(),
 		(*gdip.Rect)(&rect), gdip.CombineModeXor)
 	checkStatus(status)
 }
 
 func (this *Region) XorRectF(rect RectF) {
-	status := gdip.CombineRegionRect(this.p,
+	status := gdip.CombineRegionRect(this.handle(),
 		(*gdip.RectF)(&rect), gdip.CombineModeXor)
 	checkStatus(status)
 }
 
 func (this *Region) XorPath(path *Path) {
-	status := gdip.CombineRegionPath(this.p, path.p, gdip.CombineModeXor)
+	status := gdip.CombineRegionPath(this.handle(), path.handle(), gdip.CombineModeXor)
 	checkStatus(status)
 }
 
 func (this *Region) XorRegion(region *Region) {
-	status := gdip.CombineRegionRegion(this.p, region.p, gdip.CombineModeXor)
+	status := gdip.CombineRegionRegion(this.handle(), region.handle(), gdip.CombineModeXor)
 	checkStatus(status)
 }
 
 func (this *Region) ExcludeRect(rect Rect) {
-	status := gdip.CombineRegionRectI(this.p,
+	status := gdip.CombineRegionRectI(this.handle(),
 		(*gdip.Rect)(&rect), gdip.CombineModeExclude)
 	checkStatus(status)
 }
 
 func (this *Region) ExcludeRectF(rect RectF) {
-	status := gdip.CombineRegionRect(this.p,
+	status := gdip.CombineRegionRect(this.handle(),
 		(*gdip.RectF)(&rect), gdip.CombineModeExclude)
 	checkStatus(status)
 }
 
 func (this *Region) ExcludePath(path *Path) {
-	status := gdip.CombineRegionPath(this.p, path.p, gdip.CombineModeExclude)
+	status := gdip.CombineRegionPath(this.handle(), path.handle(), gdip.CombineModeExclude)
 	checkStatus(status)
 }
 
 func (this *Region) ExcludeRegion(region *Region) {
-	status := gdip.CombineRegionRegion(this.p, region.p, gdip.CombineModeExclude)
+	status := gdip.CombineRegionRegion(this.handle(), region.handle(), gdip.CombineModeExclude)
 	checkStatus(status)
 }
 
 func (this *Region) ComplementRect(rect Rect) {
-	status := gdip.CombineRegionRectI(this.p,
+	status := gdip.CombineRegionRectI(this.handle(),
 		(*gdip.Rect)(&rect), gdip.CombineModeComplement)
 	checkStatus(status)
 }
 
 func (this *Region) ComplementRectF(rect RectF) {
-	status := gdip.CombineRegionRect(this.p,
+	status := gdip.CombineRegionRect(this.handle(),
 		(*gdip.RectF)(&rect), gdip.CombineModeComplement)
 	checkStatus(status)
 }
 
 func (this *Region) ComplementPath(path *Path) {
-	status := gdip.CombineRegionPath(this.p, path.p, gdip.CombineModeComplement)
+	status := gdip.CombineRegionPath(this.handle(), path.handle(), gdip.CombineModeComplement)
 	checkStatus(status)
 }
 
 func (this *Region) ComplementRegion(region *Region) {
-	status := gdip.CombineRegionRegion(this.p, region.p, gdip.CombineModeComplement)
+	status := gdip.CombineRegionRegion(this.handle(), region.handle(), gdip.CombineModeComplement)
 	checkStatus(status)
 }
 
 func (this *Region) CombineRect(rect Rect, mode gdip.CombineMode) {
-	status := gdip.CombineRegionRectI(this.p, (*gdip.Rect)(&rect), mode)
+	status := gdip.CombineRegionRectI(this.handle(), (*gdip.Rect)(&rect), mode)
 	checkStatus(status)
 }
 
 func (this *Region) CombineRectF(rect RectF, mode gdip.CombineMode) {
-	status := gdip.CombineRegionRect(this.p, (*gdip.RectF)(&rect), mode)
+	status := gdip.CombineRegionRect(this.handle(), (*gdip.RectF)(&rect), mode)
 	checkStatus(status)
 }
 
 func (this *Region) CombinePath(path *Path, mode gdip.CombineMode) {
-	status := gdip.CombineRegionPath(this.p, path.p, mode)
+	status := gdip.CombineRegionPath(this.handle(), path.handle(), mode)
 	checkStatus(status)
 }
 
 func (this *Region) CombineRegion(region *Region, mode gdip.CombineMode) {
-	status := gdip.CombineRegionRegion(this.p, region.p, mode)
+	status := gdip.CombineRegionRegion(this.handle(), region.handle(), mode)
 	checkStatus(status)
 }
 
 func (this *Region) Translate(dx, dy int32) {
-	status := gdip.TranslateRegionI(this.p, dx, dy)
+	status := gdip.TranslateRegionI(this.handle(), dx, dy)
 	checkStatus(status)
 }
 
 func (this *Region) TranslateF(dx, dy float32) {
-	status := gdip.TranslateRegion(this.p, dx, dy)
+	status := gdip.TranslateRegion(this.handle(), dx, dy)
 	checkStatus(status)
 }
 
 func (this *Region) Transform(matrix *Matrix) {
-	status := gdip.TransformRegion(this.p, matrix.p)
+	status := gdip.TransformRegion(this.handle(), matrix.handle())
 	checkStatus(status)
 }
 
 func (this *Region) GetBounds(g *Graphics) Rect {
 	var rect Rect
-	status := gdip.GetRegionBoundsI(this.p, g.p, (*gdip.Rect)(&rect))
+	status := gdip.GetRegionBoundsI(this.handle(), g.p, (*gdip.Rect)(&rect))
 	checkStatus(status)
 	return rect
 }
 
 func (this *Region) GetBoundsF(g *Graphics) RectF {
 	var rect RectF
-	status := gdip.GetRegionBounds(this.p, g.p, (*gdip.RectF)(&rect))
+	status := gdip.GetRegionBounds(this.handle(), g.p, (*gdip.RectF)(&rect))
 	checkStatus(status)
 	return rect
 }
 
 func (this *Region) GetHrgn(g *Graphics) win32.HRGN {
 	var hrgn win32.HRGN
-	status := gdip.GetRegionHRgn(this.p, g.p, &hrgn)
+	status := gdip.GetRegionHRgn(this.handle(), g.p, &hrgn)
 	checkStatus(status)
 	return hrgn
 }
 
 func (this *Region) IsEmpty(g *Graphics) bool {
 	var result win32.BOOL
-	status := gdip.IsEmptyRegion(this.p, g.p, &result)
+	status := gdip.IsEmptyRegion(this.handle(), g.p, &result)
 	checkStatus(status)
 	return result != 0
 }
 
 func (this *Region) IsInfinite(g *Graphics) bool {
 	var result win32.BOOL
-	status := gdip.IsInfiniteRegion(this.p, g.p, &result)
+	status := gdip.IsInfiniteRegion(this.handle(), g.p, &result)
 	checkStatus(status)
 	return result != 0
 }
 
 func (this *Region) Equals(region *Region, g *Graphics) bool {
 	var result win32.BOOL
-	status := gdip.IsEqualRegion(this.p, region.p, g.p, &result)
+	status := gdip.IsEqualRegion(this.handle(), region.handle(), g.p, &result)
 	checkStatus(status)
 	return result != 0
 }
 
 func (this *Region) GetRegionData() []byte {
 	var cb uint32
-	status := gdip.GetRegionDataSize(this.p, &cb)
+	status := gdip.GetRegionDataSize(this.handle(), &cb)
 	checkStatus(status)
 	data := make([]byte, cb)
 	var cbFilled uint32
-	status = gdip.GetRegionData(this.p, (*win32.BYTE)(&data[0]), cb, &cbFilled)
+	status = gdip.GetRegionData(this.handle(), (*win32.BYTE)(&data[0]), cb, &cbFilled)
 	checkStatus(status)
 	return data[:cbFilled]
 }
 
 func (this *Region) IsVisiblePoint(point Point) bool {
 	var result win32.BOOL
-	status := gdip.IsVisibleRegionPointI(this.p, point.X, point.Y, nil, &result)
+	status := gdip.IsVisibleRegionPointI(this.handle(), point.X, point.Y, nil, &result)
 	checkStatus(status)
 	return result != 0
 }
 
 func (this *Region) IsVisiblePointF(point PointF) bool {
 	var result win32.BOOL
-	status := gdip.IsVisibleRegionPoint(this.p, point.X, point.Y, nil, &result)
+	status := gdip.IsVisibleRegionPoint(this.handle(), point.X, point.Y, nil, &result)
 	checkStatus(status)
 	return result != 0
 }
 
 func (this *Region) IsVisiblePointG(point Point, g *Graphics) bool {
 	var result win32.BOOL
-	status := gdip.IsVisibleRegionPointI(this.p, point.X, point.Y, g.p, &result)
+	status := gdip.IsVisibleRegionPointI(this.handle(), point.X, point.Y, g.p, &result)
 	checkStatus(status)
 	return result != 0
 }
 
 func (this *Region) IsVisiblePointGF(point PointF, g *Graphics) bool {
 	var result win32.BOOL
-	status := gdip.IsVisibleRegionPoint(this.p, point.X, point.Y, g.p, &result)
+	status := gdip.IsVisibleRegionPoint(this.handle(), point.X, point.Y, g.p, &result)
 	checkStatus(status)
 	return result != 0
 }
@@ -331,7 +379,7 @@ func (this *Region) IsVisibleRectG(rect Rect, g *Graphics) bool {
 		pGraphics = g.p
 	}
 	var result win32.BOOL
-	status := gdip.IsVisibleRegionRectI(this.p,
+	status := gdip.IsVisibleRegionRectI(this.handle(),
 		rect.X, rect.Y, rect.Width, rect.Height, pGraphics, &result)
 	checkStatus(status)
 	return result != 0
@@ -343,7 +391,7 @@ func (this *Region) IsVisibleRectGF(rect RectF, g *Graphics) bool {
 		pGraphics = g.p
 	}
 	var result win32.BOOL
-	status := gdip.IsVisibleRegionRect(this.p,
+	status := gdip.IsVisibleRegionRect(this.handle(),
 		rect.X, rect.Y, rect.Width, rect.Height, pGraphics, &result)
 	checkStatus(status)
 	return result != 0
@@ -351,22 +399,39 @@ func (this *Region) IsVisibleRectGF(rect RectF, g *Graphics) bool {
 
 func (this *Region) GetRegionScans(matrix *Matrix) []Rect {
 	var count uint32
-	status := gdip.GetRegionScansCount(this.p, &count, matrix.p)
+	status := gdip.GetRegionScansCount(this.handle(), &count, matrix.handle())
 	checkStatus(status)
+	if count == 0 {
+		return nil
+	}
 	rects := make([]Rect, count)
 	nCount := int32(count)
-	status = gdip.GetRegionScansI(this.p, (*gdip.Rect)(&rects[0]), &nCount, matrix.p)
+	status = gdip.GetRegionScansI(this.handle(), (*gdip.Rect)(&rects[0]), &nCount, matrix.handle())
 	checkStatus(status)
 	return rects[:nCount]
 }
 
 func (this *Region) GetRegionScansF(matrix *Matrix) []RectF {
 	var count uint32
-	status := gdip.GetRegionScansCount(this.p, &count, matrix.p)
+	status := gdip.GetRegionScansCount(this.handle(), &count, matrix.handle())
 	checkStatus(status)
+	if count == 0 {
+		return nil
+	}
 	rects := make([]RectF, count)
 	nCount := int32(count)
-	status = gdip.GetRegionScans(this.p, (*gdip.RectF)(&rects[0]), &nCount, matrix.p)
+	status = gdip.GetRegionScans(this.handle(), (*gdip.RectF)(&rects[0]), &nCount, matrix.handle())
 	checkStatus(status)
 	return rects[:nCount]
 }
+
+// Banded returns the region as a pure-Go banded region, of its scans rounded to integer coordinates.
+func (this *Region) Banded() *region.Region {
+	var rects []region.Rect
+	scope.WithScope(func(s *Scope) {
+		for _, rect := range this.GetRegionScans(NewMatrix(s)) {
+			rects = append(rects, region.Rect(rect))
+		}
+	})
+	return region.FromRects(rects)
+}
@@ -0,0 +1,330 @@
+// Package region implements regions as sets of rectangles, in pure Go.
+//
+// A Region is stored as y-x banded rectangles, as GDI stores regions:
+// horizontal bands of equal height, each holding sorted disjoint spans,
+// with touching bands of equal spans merged. The representation is canonical,
+// so regions covering the same pixels are equal.
+// drawing.NewRegionOfRects creates a GDI+ region of a Region, and drawing.Region.Banded
+// returns the Region of a GDI+ region.
+package region
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"sort"
+)
+
+// ErrFormat is returned for data that isn't valid region data.
+var ErrFormat = errors.New("region: invalid region data")
+
+// Rect is a rectangle with integer coordinates, with the layout of drawing.Rect.
+type Rect struct {
+	X      int32
+	Y      int32
+	Width  int32
+	Height int32
+}
+
+// Rc returns a Rect with the given location and size.
+func Rc(x, y, width, height int32) Rect {
+	return Rect{X: x, Y: y, Width: width, Height: height}
+}
+
+func (me Rect) Right() int32 {
+	return me.X + me.Width
+}
+
+func (me Rect) Bottom() int32 {
+	return me.Y + me.Height
+}
+
+func (me Rect) IsEmpty() bool {
+	return me.Width <= 0 || me.Height <= 0
+}
+
+// band is a horizontal strip of a region.
+type band struct {
+	top, bottom int32
+	xs          []int32 //the left and right edges of the spans, in pairs
+}
+
+// Region is a set of pixels, made of rectangles. The zero value is the empty region.
+type Region struct {
+	bands []band
+}
+
+// New returns an empty region.
+func New() *Region {
+	return &Region{}
+}
+
+// FromRect returns a region covering a rect.
+func FromRect(rect Rect) *Region {
+	region := &Region{}
+	if !rect.IsEmpty() {
+		region.bands = []band{{rect.Y, rect.Bottom(), []int32{rect.X, rect.Right()}}}
+	}
+	return region
+}
+
+// FromRects returns the union of rects.
+func FromRects(rects []Rect) *Region {
+	switch len(rects) {
+	case 0:
+		return &Region{}
+	case 1:
+		return FromRect(rects[0])
+	}
+	//union halves, to keep the bands small while merging
+	region := FromRects(rects[:len(rects)/2])
+	region.Union(FromRects(rects[len(rects)/2:]))
+	return region
+}
+
+func (this *Region) Clone() *Region {
+	bands := make([]band, len(this.bands))
+	for n, b := range this.bands {
+		bands[n] = band{b.top, b.bottom, slices.Clone(b.xs)}
+	}
+	return &Region{bands}
+}
+
+func (this *Region) IsEmpty() bool {
+	return len(this.bands) == 0
+}
+
+// Equals tells whether two regions cover the same pixels.
+func (this *Region) Equals(region *Region) bool {
+	return slices.EqualFunc(this.bands, region.bands, func(b1, b2 band) bool {
+		return b1.top == b2.top && b1.bottom == b2.bottom && slices.Equal(b1.xs, b2.xs)
+	})
+}
+
+// Bounds returns the bounding rect of the region, empty if the region is.
+func (this *Region) Bounds() Rect {
+	if len(this.bands) == 0 {
+		return Rect{}
+	}
+	left, right := this.bands[0].xs[0], this.bands[0].xs[len(this.bands[0].xs)-1]
+	for _, b := range this.bands[1:] {
+		left = min(left, b.xs[0])
+		right = max(right, b.xs[len(b.xs)-1])
+	}
+	top, bottom := this.bands[0].top, this.bands[len(this.bands)-1].bottom
+	return Rect{X: left, Y: top, Width: right - left, Height: bottom - top}
+}
+
+// Contains tells whether the pixel at x, y is in the region.
+func (this *Region) Contains(x, y int32) bool {
+	n := sort.Search(len(this.bands), func(n int) bool {
+		return this.bands[n].bottom > y
+	})
+	if n == len(this.bands) || this.bands[n].top > y {
+		return false
+	}
+	xs := this.bands[n].xs
+	//the count of edges at or left of x is odd inside spans
+	return sort.Search(len(xs), func(n int) bool { return xs[n] > x })%2 == 1
+}
+
+// Rects returns the rects of the region, y-x banded: sorted by top then left,
+// rects of a band having the same top and bottom.
+func (this *Region) Rects() []Rect {
+	var rects []Rect
+	this.Iterate(func(rect Rect) {
+		rects = append(rects, rect)
+	})
+	return rects
+}
+
+// Iterate calls fn with the rects of the region, in the order of Rects.
+func (this *Region) Iterate(fn func(rect Rect)) {
+	for _, b := range this.bands {
+		for n := 0; n < len(b.xs); n += 2 {
+			fn(Rect{X: b.xs[n], Y: b.top, Width: b.xs[n+1] - b.xs[n], Height: b.bottom - b.top})
+		}
+	}
+}
+
+func (this *Region) Translate(dx, dy int32) {
+	for n := range this.bands {
+		b := &this.bands[n]
+		b.top += dy
+		b.bottom += dy
+		for i := range b.xs {
+			b.xs[i] += dx
+		}
+	}
+}
+
+// Union adds the pixels of another region.
+func (this *Region) Union(region *Region) {
+	this.combine(region, func(in1, in2 bool) bool { return in1 || in2 })
+}
+
+func (this *Region) UnionRect(rect Rect) {
+	this.Union(FromRect(rect))
+}
+
+// Intersect keeps the pixels that are also in another region.
+func (this *Region) Intersect(region *Region) {
+	this.combine(region, func(in1, in2 bool) bool { return in1 && in2 })
+}
+
+func (this *Region) IntersectRect(rect Rect) {
+	this.Intersect(FromRect(rect))
+}
+
+// Xor keeps the pixels that are in either region but not in both.
+func (this *Region) Xor(region *Region) {
+	this.combine(region, func(in1, in2 bool) bool { return in1 != in2 })
+}
+
+func (this *Region) XorRect(rect Rect) {
+	this.Xor(FromRect(rect))
+}
+
+// Exclude removes the pixels of another region.
+func (this *Region) Exclude(region *Region) {
+	this.combine(region, func(in1, in2 bool) bool { return in1 && !in2 })
+}
+
+func (this *Region) ExcludeRect(rect Rect) {
+	this.Exclude(FromRect(rect))
+}
+
+// Complement replaces the region with the pixels of another region that aren't in it,
+// as the GDI+ CombineModeComplement.
+func (this *Region) Complement(region *Region) {
+	this.combine(region, func(in1, in2 bool) bool { return in2 && !in1 })
+}
+
+func (this *Region) ComplementRect(rect Rect) {
+	this.Complement(FromRect(rect))
+}
+
+// combine replaces the region with the pixels for which op is true,
+// given whether they are in this region and in the other.
+func (this *Region) combine(region *Region, op func(in1, in2 bool) bool) {
+	var ys []int32
+	for _, bands := range [][]band{this.bands, region.bands} {
+		for _, b := range bands {
+			ys = append(ys, b.top, b.bottom)
+		}
+	}
+	slices.Sort(ys)
+	ys = slices.Compact(ys)
+
+	var bands []band
+	n1, n2 := 0, 0
+	for n := 1; n < len(ys); n++ {
+		top, bottom := ys[n-1], ys[n]
+		xs1 := spansAt(this.bands, &n1, top)
+		xs2 := spansAt(region.bands, &n2, top)
+		xs := combineSpans(xs1, xs2, op)
+		if len(xs) == 0 {
+			continue
+		}
+		if last := len(bands) - 1; last >= 0 && bands[last].bottom == top && slices.Equal(bands[last].xs, xs) {
+			bands[last].bottom = bottom
+			continue
+		}
+		bands = append(bands, band{top, bottom, xs})
+	}
+	this.bands = bands
+}
+
+// spansAt returns the spans of the band containing y, advancing *n past the bands above y.
+// Successive calls must be for increasing y.
+func spansAt(bands []band, n *int, y int32) []int32 {
+	for *n < len(bands) && bands[*n].bottom <= y {
+		*n++
+	}
+	if *n < len(bands) && bands[*n].top <= y {
+		return bands[*n].xs
+	}
+	return nil
+}
+
+// combineSpans returns the spans covering the x for which op is true,
+// given whether they are in the spans of xs1 and of xs2.
+func combineSpans(xs1, xs2 []int32, op func(in1, in2 bool) bool) []int32 {
+	var xs []int32
+	n1, n2 := 0, 0
+	in1, in2, in := false, false, false
+	for n1 < len(xs1) || n2 < len(xs2) {
+		var x int32
+		if n2 == len(xs2) || n1 < len(xs1) && xs1[n1] <= xs2[n2] {
+			x = xs1[n1]
+		} else {
+			x = xs2[n2]
+		}
+		//cross all the edges at x before deciding, so that touching spans merge
+		if n1 < len(xs1) && xs1[n1] == x {
+			in1 = !in1
+			n1++
+		}
+		if n2 < len(xs2) && xs2[n2] == x {
+			in2 = !in2
+			n2++
+		}
+		if op(in1, in2) != in {
+			in = !in
+			xs = append(xs, x)
+		}
+	}
+	return xs
+}
+
+// rgnDataHeaderSize is the size of the Win32 RGNDATAHEADER.
+const rgnDataHeaderSize = 32
+
+// Data returns the region as Win32 RGNDATA, as accepted by ExtCreateRegion.
+func (this *Region) Data() []byte {
+	rects := this.Rects()
+	data := make([]byte, rgnDataHeaderSize+16*len(rects))
+	le := binary.LittleEndian
+	bounds := this.Bounds()
+	le.PutUint32(data[0:], rgnDataHeaderSize)
+	le.PutUint32(data[4:], 1) //RDH_RECTANGLES
+	le.PutUint32(data[8:], uint32(len(rects)))
+	le.PutUint32(data[12:], uint32(16*len(rects)))
+	putRect(data[16:], bounds)
+	for n, rect := range rects {
+		putRect(data[rgnDataHeaderSize+16*n:], rect)
+	}
+	return data
+}
+
+// putRect writes a rect as a Win32 RECT.
+func putRect(data []byte, rect Rect) {
+	le := binary.LittleEndian
+	le.PutUint32(data[0:], uint32(rect.X))
+	le.PutUint32(data[4:], uint32(rect.Y))
+	le.PutUint32(data[8:], uint32(rect.Right()))
+	le.PutUint32(data[12:], uint32(rect.Bottom()))
+}
+
+// FromData returns the region of Win32 RGNDATA, as returned by GetRegionData.
+// The rects needn't be banded.
+func FromData(data []byte) (*Region, error) {
+	le := binary.LittleEndian
+	if len(data) < rgnDataHeaderSize {
+		return nil, ErrFormat
+	}
+	size := le.Uint32(data[0:])
+	count := le.Uint32(data[8:])
+	if size < rgnDataHeaderSize || le.Uint32(data[4:]) != 1 ||
+		uint64(len(data)) < uint64(size)+16*uint64(count) {
+		return nil, ErrFormat
+	}
+	rects := make([]Rect, count)
+	for n := range rects {
+		p := data[size+16*uint32(n):]
+		left, top := int32(le.Uint32(p[0:])), int32(le.Uint32(p[4:]))
+		right, bottom := int32(le.Uint32(p[8:])), int32(le.Uint32(p[12:]))
+		rects[n] = Rect{X: left, Y: top, Width: right - left, Height: bottom - top}
+	}
+	return FromRects(rects), nil
+}
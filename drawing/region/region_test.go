@@ -0,0 +1,192 @@
+package region
+
+import (
+	"encoding/binary"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestBandedOps(t *testing.T) {
+	a, b := Rc(0, 0, 10, 10), Rc(5, 5, 10, 10)
+	tests := []struct {
+		name string
+		op   func(r *Region, other *Region)
+		want []Rect
+	}{
+		{"union", (*Region).Union,
+			[]Rect{Rc(0, 0, 10, 5), Rc(0, 5, 15, 5), Rc(5, 10, 10, 5)}},
+		{"intersect", (*Region).Intersect,
+			[]Rect{Rc(5, 5, 5, 5)}},
+		{"xor", (*Region).Xor,
+			[]Rect{Rc(0, 0, 10, 5), Rc(0, 5, 5, 5), Rc(10, 5, 5, 5), Rc(5, 10, 10, 5)}},
+		{"exclude", (*Region).Exclude,
+			[]Rect{Rc(0, 0, 10, 5), Rc(0, 5, 5, 5)}},
+		{"complement", (*Region).Complement,
+			[]Rect{Rc(10, 5, 5, 5), Rc(5, 10, 10, 5)}},
+	}
+	for _, test := range tests {
+		r := FromRect(a)
+		test.op(r, FromRect(b))
+		if got := r.Rects(); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: rects = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	whole := FromRect(Rc(0, 0, 10, 10))
+	tests := []struct {
+		name  string
+		rects []Rect
+	}{
+		{"side by side", []Rect{Rc(0, 0, 4, 10), Rc(4, 0, 6, 10)}},
+		{"stacked", []Rect{Rc(0, 0, 10, 3), Rc(0, 3, 10, 7)}},
+		{"overlapping", []Rect{Rc(0, 0, 8, 8), Rc(2, 2, 8, 8), Rc(0, 5, 10, 5), Rc(5, 0, 5, 5)}},
+		{"with empty", []Rect{Rc(0, 0, 10, 10), Rc(3, 3, 0, 5)}},
+	}
+	for _, test := range tests {
+		r := FromRects(test.rects)
+		if !r.Equals(whole) {
+			t.Errorf("%s: rects = %v, want %v", test.name, r.Rects(), whole.Rects())
+		}
+	}
+}
+
+// checkBanded checks the invariants of the bands of a region.
+func checkBanded(t *testing.T, r *Region) {
+	t.Helper()
+	for n, b := range r.bands {
+		if b.top >= b.bottom || len(b.xs) == 0 || len(b.xs)%2 != 0 {
+			t.Fatalf("invalid band %v", b)
+		}
+		for i := 1; i < len(b.xs); i++ {
+			if b.xs[i] <= b.xs[i-1] {
+				t.Fatalf("unsorted or touching spans %v", b.xs)
+			}
+		}
+		if n > 0 {
+			prev := r.bands[n-1]
+			if b.top < prev.bottom {
+				t.Fatalf("overlapping bands %v and %v", prev, b)
+			}
+			if b.top == prev.bottom && reflect.DeepEqual(b.xs, prev.xs) {
+				t.Fatalf("unmerged bands %v and %v", prev, b)
+			}
+		}
+	}
+}
+
+// TestRandomOps compares the ops with their definitions on the pixels of random regions.
+func TestRandomOps(t *testing.T) {
+	const size = 24
+	ops := []struct {
+		name string
+		op   func(r *Region, other *Region)
+		in   func(in1, in2 bool) bool
+	}{
+		{"union", (*Region).Union, func(in1, in2 bool) bool { return in1 || in2 }},
+		{"intersect", (*Region).Intersect, func(in1, in2 bool) bool { return in1 && in2 }},
+		{"xor", (*Region).Xor, func(in1, in2 bool) bool { return in1 != in2 }},
+		{"exclude", (*Region).Exclude, func(in1, in2 bool) bool { return in1 && !in2 }},
+		{"complement", (*Region).Complement, func(in1, in2 bool) bool { return in2 && !in1 }},
+	}
+	rnd := rand.New(rand.NewSource(1))
+	randomRegion := func() *Region {
+		rects := make([]Rect, 1+rnd.Intn(5))
+		for n := range rects {
+			x, y := rnd.Int31n(size), rnd.Int31n(size)
+			rects[n] = Rc(x, y, rnd.Int31n(size-x+1), rnd.Int31n(size-y+1))
+		}
+		return FromRects(rects)
+	}
+	for iteration := 0; iteration < 200; iteration++ {
+		r1, r2 := randomRegion(), randomRegion()
+		for _, op := range ops {
+			r := r1.Clone()
+			op.op(r, r2)
+			checkBanded(t, r)
+			for y := int32(-1); y <= size; y++ {
+				for x := int32(-1); x <= size; x++ {
+					want := op.in(r1.Contains(x, y), r2.Contains(x, y))
+					if r.Contains(x, y) != want {
+						t.Fatalf("%s of %v and %v: Contains(%d, %d) = %v, want %v",
+							op.name, r1.Rects(), r2.Rects(), x, y, !want, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBoundsAndTranslate(t *testing.T) {
+	r := FromRects([]Rect{Rc(2, 1, 3, 2), Rc(0, 5, 2, 2)})
+	if got, want := r.Bounds(), Rc(0, 1, 5, 6); got != want {
+		t.Errorf("bounds = %v, want %v", got, want)
+	}
+	r.Translate(10, -1)
+	want := []Rect{Rc(12, 0, 3, 2), Rc(10, 4, 2, 2)}
+	if got := r.Rects(); !reflect.DeepEqual(got, want) {
+		t.Errorf("translated rects = %v, want %v", got, want)
+	}
+	if New().Bounds() != (Rect{}) || !New().IsEmpty() {
+		t.Error("the empty region isn't empty")
+	}
+}
+
+func TestDataRoundTrip(t *testing.T) {
+	r := FromRects([]Rect{Rc(0, 0, 10, 5), Rc(-5, 3, 4, 10), Rc(20, 20, 1, 1)})
+	data := r.Data()
+	le := binary.LittleEndian
+	count := len(r.Rects())
+	if len(data) != rgnDataHeaderSize+16*count || int(le.Uint32(data[8:])) != count {
+		t.Fatalf("data of %d bytes for %d rects, header count %d", len(data), count, le.Uint32(data[8:]))
+	}
+	//the bounds in the header, as a RECT
+	bounds := [4]int32{int32(le.Uint32(data[16:])), int32(le.Uint32(data[20:])),
+		int32(le.Uint32(data[24:])), int32(le.Uint32(data[28:]))}
+	if bounds != [4]int32{-5, 0, 21, 21} {
+		t.Errorf("header bounds = %v, want [-5 0 21 21]", bounds)
+	}
+	decoded, err := FromData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.Equals(r) {
+		t.Errorf("decoded rects = %v, want %v", decoded.Rects(), r.Rects())
+	}
+	if decoded, err := FromData(New().Data()); err != nil || !decoded.IsEmpty() {
+		t.Errorf("decoding the empty region = %v, %v", decoded, err)
+	}
+}
+
+func TestFromDataUnbanded(t *testing.T) {
+	//overlapping rects, as other producers may write
+	data := make([]byte, rgnDataHeaderSize+32)
+	le := binary.LittleEndian
+	le.PutUint32(data[0:], rgnDataHeaderSize)
+	le.PutUint32(data[4:], 1)
+	le.PutUint32(data[8:], 2)
+	putRect(data[rgnDataHeaderSize:], Rc(0, 0, 10, 10))
+	putRect(data[rgnDataHeaderSize+16:], Rc(5, 5, 10, 10))
+	r, err := FromData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := FromRects([]Rect{Rc(0, 0, 10, 10), Rc(5, 5, 10, 10)})
+	if !r.Equals(want) {
+		t.Errorf("rects = %v, want %v", r.Rects(), want.Rects())
+	}
+
+	wrongType := append([]byte{}, data...)
+	le.PutUint32(wrongType[4:], 2)
+	for name, bad := range map[string][]byte{
+		"short":     data[:rgnDataHeaderSize-1],
+		"truncated": data[:len(data)-1],
+		"type":      wrongType,
+	} {
+		if _, err := FromData(bad); err != ErrFormat {
+			t.Errorf("%s: err = %v, want ErrFormat", name, err)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package svg
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// ParsePath parses SVG path data, the d attribute of path elements, into a path.
+// On a syntax error, the path holds the segments before the error, which is how
+// SVG renders erroneous paths, and the error tells the offset of the error.
+func ParsePath(d string) (*geom.Path, error) {
+	p := &pathParser{scanner: scanner{s: d}, path: geom.NewPath()}
+	err := p.parse()
+	return p.path, err
+}
+
+// scanner reads numbers separated by whitespace and commas, as in SVG attributes.
+type scanner struct {
+	s   string
+	pos int
+}
+
+func (this *scanner) skipSpace() {
+	for this.pos < len(this.s) && isSpace(this.s[this.pos]) {
+		this.pos++
+	}
+}
+
+// skipSeparator skips whitespace with at most one comma.
+func (this *scanner) skipSeparator() {
+	this.skipSpace()
+	if this.pos < len(this.s) && this.s[this.pos] == ',' {
+		this.pos++
+		this.skipSpace()
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func (this *scanner) done() bool {
+	this.skipSpace()
+	return this.pos == len(this.s)
+}
+
+// atNumber tells whether a number starts at the current position.
+func (this *scanner) atNumber() bool {
+	if this.pos == len(this.s) {
+		return false
+	}
+	c := this.s[this.pos]
+	return isDigit(c) || c == '-' || c == '+' || c == '.'
+}
+
+func (this *scanner) errorf(format string, args ...any) error {
+	return fmt.Errorf("svg: %s at offset %d", fmt.Sprintf(format, args...), this.pos)
+}
+
+// number reads a number after an optional separator.
+func (this *scanner) number() (float64, error) {
+	this.skipSeparator()
+	start := this.pos
+	if this.pos < len(this.s) && (this.s[this.pos] == '-' || this.s[this.pos] == '+') {
+		this.pos++
+	}
+	digits := this.digits()
+	if this.pos < len(this.s) && this.s[this.pos] == '.' {
+		this.pos++
+		digits += this.digits()
+	}
+	if digits == 0 {
+		this.pos = start
+		return 0, this.errorf("expected a number")
+	}
+	if this.pos < len(this.s) && (this.s[this.pos] == 'e' || this.s[this.pos] == 'E') {
+		//an exponent, unless the e isn't followed by one
+		end := this.pos
+		this.pos++
+		if this.pos < len(this.s) && (this.s[this.pos] == '-' || this.s[this.pos] == '+') {
+			this.pos++
+		}
+		if this.digits() == 0 {
+			this.pos = end
+		}
+	}
+	value, err := strconv.ParseFloat(this.s[start:this.pos], 64)
+	if err != nil {
+		this.pos = start
+		return 0, this.errorf("invalid number")
+	}
+	return value, nil
+}
+
+func (this *scanner) digits() int {
+	start := this.pos
+	for this.pos < len(this.s) && isDigit(this.s[this.pos]) {
+		this.pos++
+	}
+	return this.pos - start
+}
+
+// flag reads an arc flag, which needn't be separated from what follows.
+func (this *scanner) flag() (bool, error) {
+	this.skipSeparator()
+	if this.pos < len(this.s) && (this.s[this.pos] == '0' || this.s[this.pos] == '1') {
+		this.pos++
+		return this.s[this.pos-1] == '1', nil
+	}
+	return false, this.errorf("expected a flag")
+}
+
+// numbers reads the numbers up to the end or to what isn't a number.
+func (this *scanner) numbers() ([]float64, error) {
+	var values []float64
+	for {
+		this.skipSeparator()
+		if !this.atNumber() {
+			return values, nil
+		}
+		value, err := this.number()
+		if err != nil {
+			return values, err
+		}
+		values = append(values, value)
+	}
+}
+
+// argCounts are the numbers of arguments of the commands, by upper case letter.
+var argCounts = map[byte]int{'M': 2, 'Z': 0, 'L': 2, 'H': 1, 'V': 1, 'C': 6, 'S': 4, 'Q': 4, 'T': 2, 'A': 7}
+
+type pathParser struct {
+	scanner
+	path *geom.Path
+
+	command byte       //the last command
+	control geom.Point //the last control point of the last curve, for S and T
+}
+
+func (this *pathParser) parse() error {
+	for !this.done() {
+		c := this.s[this.pos]
+		if this.command == 0 && c != 'M' && c != 'm' {
+			return this.errorf("expected a moveto")
+		}
+		switch c {
+		case 'M', 'm', 'Z', 'z', 'L', 'l', 'H', 'h', 'V', 'v',
+			'C', 'c', 'S', 's', 'Q', 'q', 'T', 't', 'A', 'a':
+			this.pos++
+			if err := this.segment(c); err != nil {
+				return err
+			}
+		default:
+			if !this.atNumber() || this.command == 'Z' || this.command == 'z' {
+				return this.errorf("unexpected %q", c)
+			}
+			//an implicit repetition of the last command, linetos after movetos
+			command := this.command
+			if command == 'M' {
+				command = 'L'
+			} else if command == 'm' {
+				command = 'l'
+			}
+			if err := this.segment(command); err != nil {
+				return err
+			}
+		}
+		this.skipSeparator()
+	}
+	return nil
+}
+
+// segment reads the arguments of a command and adds its segment.
+func (this *pathParser) segment(command byte) error {
+	current := this.path.CurrentPoint()
+	var origin geom.Point //what relative coordinates are relative to
+	if command >= 'a' {
+		origin = current
+	}
+	var args [7]float64
+	for n := 0; n < argCounts[command&^0x20]; n++ {
+		var err error
+		if command&^0x20 == 'A' && (n == 3 || n == 4) {
+			var flag bool
+			flag, err = this.flag()
+			if flag {
+				args[n] = 1
+			}
+		} else {
+			args[n], err = this.number()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	pt := func(n int) geom.Point {
+		return geom.Pt(origin.X+args[n], origin.Y+args[n+1])
+	}
+
+	//the control point reflected for smooth curves, if the last curve is of the same kind
+	reflected := current
+	last := this.command &^ 0x20
+	switch command &^ 0x20 {
+	case 'S':
+		if last == 'C' || last == 'S' {
+			reflected = current.Mul(2).Sub(this.control)
+		}
+	case 'T':
+		if last == 'Q' || last == 'T' {
+			reflected = current.Mul(2).Sub(this.control)
+		}
+	}
+
+	switch command &^ 0x20 {
+	case 'M':
+		p := pt(0)
+		this.path.MoveTo(p.X, p.Y)
+	case 'Z':
+		this.path.CloseFigure()
+	case 'L':
+		p := pt(0)
+		this.lineTo(p)
+	case 'H':
+		this.lineTo(geom.Pt(origin.X+args[0], current.Y))
+	case 'V':
+		this.lineTo(geom.Pt(current.X, origin.Y+args[0]))
+	case 'C':
+		c1, c2, p := pt(0), pt(2), pt(4)
+		this.bezierTo(c1, c2, p)
+		this.control = c2
+	case 'S':
+		c2, p := pt(0), pt(2)
+		this.bezierTo(reflected, c2, p)
+		this.control = c2
+	case 'Q':
+		c, p := pt(0), pt(2)
+		this.quadTo(c, p)
+		this.control = c
+	case 'T':
+		p := pt(0)
+		this.quadTo(reflected, p)
+		this.control = reflected
+	case 'A':
+		this.arcTo(args[0], args[1], args[2], args[3] != 0, args[4] != 0, pt(5))
+	}
+	this.command = command
+	return nil
+}
+
+func (this *pathParser) lineTo(p geom.Point) {
+	this.path.LineTo(p.X, p.Y)
+}
+
+func (this *pathParser) bezierTo(c1, c2, p geom.Point) {
+	this.path.BezierTo(c1.X, c1.Y, c2.X, c2.Y, p.X, p.Y)
+}
+
+func (this *pathParser) quadTo(c, p geom.Point) {
+	this.path.QuadTo(c.X, c.Y, p.X, p.Y)
+}
+
+// arcTo adds an elliptical arc as cubic Bezier curves, converting the endpoint
+// parameterization of SVG into the center one, as in the SVG implementation notes.
+func (this *pathParser) arcTo(rx, ry, angle float64, large, sweep bool, p geom.Point) {
+	p0 := this.path.CurrentPoint()
+	if p0 == p {
+		return
+	}
+	rx, ry = math.Abs(rx), math.Abs(ry)
+	if rx == 0 || ry == 0 {
+		this.lineTo(p)
+		return
+	}
+	sin, cos := math.Sincos(angle * math.Pi / 180)
+	//the start point in the coordinates of the ellipse, centered between the end points
+	dx, dy := (p0.X-p.X)/2, (p0.Y-p.Y)/2
+	x1 := cos*dx + sin*dy
+	y1 := -sin*dx + cos*dy
+	//scale radii too small to reach the end point
+	if lambda := x1*x1/(rx*rx) + y1*y1/(ry*ry); lambda > 1 {
+		rx *= math.Sqrt(lambda)
+		ry *= math.Sqrt(lambda)
+	}
+	num := rx*rx*ry*ry - rx*rx*y1*y1 - ry*ry*x1*x1
+	den := rx*rx*y1*y1 + ry*ry*x1*x1
+	k := math.Sqrt(math.Max(0, num/den))
+	if large == sweep {
+		k = -k
+	}
+	cx1, cy1 := k*rx*y1/ry, -k*ry*x1/rx
+	cx := cos*cx1 - sin*cy1 + (p0.X+p.X)/2
+	cy := sin*cx1 + cos*cy1 + (p0.Y+p.Y)/2
+
+	theta := math.Atan2((y1-cy1)/ry, (x1-cx1)/rx)
+	delta := math.Atan2((-y1-cy1)/ry, (-x1-cx1)/rx) - theta
+	if sweep && delta < 0 {
+		delta += 2 * math.Pi
+	} else if !sweep && delta > 0 {
+		delta -= 2 * math.Pi
+	}
+
+	//at most quarter turns per curve, with the control points of the unit circle
+	segments := int(math.Ceil(math.Abs(delta)/(math.Pi/2) - 1e-9))
+	step := delta / float64(segments)
+	t := 4.0 / 3 * math.Tan(step/4)
+	mapping := func(x, y float64) geom.Point {
+		return geom.Pt(cx+cos*rx*x-sin*ry*y, cy+sin*rx*x+cos*ry*y)
+	}
+	for n := 0; n < segments; n++ {
+		sin0, cos0 := math.Sincos(theta + float64(n)*step)
+		sin1, cos1 := math.Sincos(theta + float64(n+1)*step)
+		c1 := mapping(cos0-t*sin0, sin0+t*cos0)
+		c2 := mapping(cos1+t*sin1, sin1-t*cos1)
+		end := p
+		if n < segments-1 {
+			end = mapping(cos1, sin1)
+		}
+		this.bezierTo(c1, c2, end)
+	}
+}
@@ -0,0 +1,235 @@
+package svg
+
+import (
+	"math"
+	"testing"
+
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// segment is a segment of a path, as reported by Path.Iterate.
+type segment struct {
+	op     geom.PathOp
+	points []geom.Point
+}
+
+func segmentsOf(path *geom.Path) []segment {
+	var segments []segment
+	path.Iterate(func(op geom.PathOp, points []geom.Point) {
+		segments = append(segments, segment{op, append([]geom.Point{}, points...)})
+	})
+	return segments
+}
+
+func nearPoint(a, b geom.Point) bool {
+	return math.Abs(a.X-b.X) < 1e-9 && math.Abs(a.Y-b.Y) < 1e-9
+}
+
+func equalSegments(a, b []segment) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for n := range a {
+		if a[n].op != b[n].op || len(a[n].points) != len(b[n].points) {
+			return false
+		}
+		for i := range a[n].points {
+			if !nearPoint(a[n].points[i], b[n].points[i]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func mustParse(t *testing.T, d string) *geom.Path {
+	t.Helper()
+	path, err := ParsePath(d)
+	if err != nil {
+		t.Fatalf("ParsePath(%q): %v", d, err)
+	}
+	return path
+}
+
+func TestParsePathLines(t *testing.T) {
+	want := geom.NewPath()
+	want.MoveTo(10, 20)
+	want.LineTo(30, 20)
+	want.LineTo(30, 40)
+	want.LineTo(5, 5)
+	want.CloseFigure()
+	for _, d := range []string{
+		"M10 20 H30 V40 L5 5 Z",
+		"M 10,20 h 20 v 20 l -25 -35 z",
+		"m10 20h20v20l-25-35z",
+		"M10,20,30,20,30,40,5,5Z",
+		"m10 20 20 0 0 20 -25 -35Z",
+		"  M10 20\n\tL30 20 L30 40 L5 5 Z  ",
+	} {
+		if got := mustParse(t, d); !equalSegments(segmentsOf(got), segmentsOf(want)) {
+			t.Errorf("%q: %v, want %v", d, segmentsOf(got), segmentsOf(want))
+		}
+	}
+}
+
+func TestParsePathNumbers(t *testing.T) {
+	tests := []struct {
+		d    string
+		want []geom.Point
+	}{
+		{"M.5.5L-1-2", []geom.Point{geom.Pt(0.5, 0.5), geom.Pt(-1, -2)}},
+		{"M1e1 2E-1L+3 4.", []geom.Point{geom.Pt(10, 0.2), geom.Pt(3, 4)}},
+		{"M0,0L1.5e+1,-.25", []geom.Point{geom.Pt(0, 0), geom.Pt(15, -0.25)}},
+	}
+	for _, test := range tests {
+		path := mustParse(t, test.d)
+		if len(path.Points) != len(test.want) {
+			t.Errorf("%q: points %v, want %v", test.d, path.Points, test.want)
+			continue
+		}
+		for n, pt := range test.want {
+			if !nearPoint(path.Points[n], pt) {
+				t.Errorf("%q: points %v, want %v", test.d, path.Points, test.want)
+				break
+			}
+		}
+	}
+}
+
+func TestParsePathCurves(t *testing.T) {
+	want := geom.NewPath()
+	want.MoveTo(0, 0)
+	want.BezierTo(10, 0, 20, 10, 20, 20)
+	want.BezierTo(20, 30, 30, 40, 40, 40) //the first control point reflected
+	want.QuadTo(50, 40, 50, 50)
+	want.QuadTo(50, 60, 60, 60) //the control point reflected
+	for _, d := range []string{
+		"M0 0 C10 0 20 10 20 20 S30 40 40 40 Q50 40 50 50 T60 60",
+		"m0 0 c10 0 20 10 20 20 s10 20 20 20 q10 0 10 10 t10 10",
+	} {
+		if got := mustParse(t, d); !equalSegments(segmentsOf(got), segmentsOf(want)) {
+			t.Errorf("%q: %v, want %v", d, segmentsOf(got), segmentsOf(want))
+		}
+	}
+
+	//without a previous curve of the same kind, the control point is the current point
+	got := mustParse(t, "M0 0 L10 0 S20 10 20 20")
+	want = geom.NewPath()
+	want.MoveTo(0, 0)
+	want.LineTo(10, 0)
+	want.BezierTo(10, 0, 20, 10, 20, 20)
+	if !equalSegments(segmentsOf(got), segmentsOf(want)) {
+		t.Errorf("S after L: %v, want %v", segmentsOf(got), segmentsOf(want))
+	}
+	got = mustParse(t, "M0 0 C0 5 5 5 10 0 T20 0")
+	want = geom.NewPath()
+	want.MoveTo(0, 0)
+	want.BezierTo(0, 5, 5, 5, 10, 0)
+	want.QuadTo(10, 0, 20, 0)
+	if !equalSegments(segmentsOf(got), segmentsOf(want)) {
+		t.Errorf("T after C: %v, want %v", segmentsOf(got), segmentsOf(want))
+	}
+}
+
+func TestParsePathClose(t *testing.T) {
+	//after a closepath, the current point is the start of the figure
+	got := mustParse(t, "M10 10 L20 10 L20 20 z l5 5 m1 1 l1 0")
+	want := geom.NewPath()
+	want.MoveTo(10, 10)
+	want.LineTo(20, 10)
+	want.LineTo(20, 20)
+	want.CloseFigure()
+	want.LineTo(15, 15)
+	want.MoveTo(16, 16)
+	want.LineTo(17, 16)
+	if !equalSegments(segmentsOf(got), segmentsOf(want)) {
+		t.Errorf("%v, want %v", segmentsOf(got), segmentsOf(want))
+	}
+}
+
+// arcPoints returns points of the curves of an arc, to check they are on an ellipse.
+func arcPoints(path *geom.Path) []geom.Point {
+	var points []geom.Point
+	for _, line := range path.Flatten(geom.Identity(), 0.001) {
+		points = append(points, line.Points...)
+	}
+	return points
+}
+
+func TestParsePathArc(t *testing.T) {
+	tests := []struct {
+		d       string
+		center  geom.Point
+		rx, ry  float64
+		angle   float64
+		through geom.Point //a point the arc passes through
+	}{
+		//half circles from (0, 0) to (20, 0), above or below as the sweep flag says
+		{"M0 0 A10 10 0 0 1 20 0", geom.Pt(10, 0), 10, 10, 0, geom.Pt(10, -10)},
+		{"M0 0 A10 10 0 0 0 20 0", geom.Pt(10, 0), 10, 10, 0, geom.Pt(10, 10)},
+		//radii too small are scaled up
+		{"M0 0 A1 1 0 0 1 20 0", geom.Pt(10, 0), 10, 10, 0, geom.Pt(10, -10)},
+		//a quarter of an ellipse, and the three quarters of the large arc
+		{"M0 0 a20 10 0 0 1 20 10", geom.Pt(0, 10), 20, 10, 0, geom.Pt(20*math.Sqrt(0.5), 10-10*math.Sqrt(0.5))},
+		{"M0 0 A20 10 0 1 0 20 10", geom.Pt(0, 10), 20, 10, 0, geom.Pt(-20, 10)},
+		//a rotated ellipse, with flags not separated from the numbers
+		{"M0 0A20 10 90 0110 20", geom.Pt(0, 20), 20, 10, 90, geom.Pt(10, 20)},
+	}
+	for _, test := range tests {
+		path := mustParse(t, test.d)
+		sin, cos := math.Sincos(-test.angle * math.Pi / 180)
+		for _, pt := range arcPoints(path) {
+			//in the coordinates of the unrotated ellipse, the points are on the unit circle
+			d := pt.Sub(test.center)
+			x, y := (cos*d.X-sin*d.Y)/test.rx, (sin*d.X+cos*d.Y)/test.ry
+			if r := math.Hypot(x, y); math.Abs(r-1) > 1e-3 {
+				t.Errorf("%q: %v off the ellipse (%v)", test.d, pt, r)
+				break
+			}
+		}
+		if path.Distance(test.through, 0.001) > 0.01 {
+			t.Errorf("%q: the arc doesn't pass through %v", test.d, test.through)
+		}
+	}
+}
+
+func TestParsePathDegenerateArcs(t *testing.T) {
+	//zero radii draw lines, and arcs to the current point are omitted
+	got := mustParse(t, "M0 0 A0 10 0 0 1 20 0 A10 10 0 0 1 20 0")
+	want := geom.NewPath()
+	want.MoveTo(0, 0)
+	want.LineTo(20, 0)
+	if !equalSegments(segmentsOf(got), segmentsOf(want)) {
+		t.Errorf("%v, want %v", segmentsOf(got), segmentsOf(want))
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	tests := []struct {
+		d      string
+		points int //the points parsed before the error
+	}{
+		{"L10 10", 0},
+		{"10 10", 0},
+		{"M10 10 L20", 1},
+		{"M10 10 L20 20 X", 2},
+		{"M10 10 Z 5 5", 1},
+		{"M0 0 A10 10 0 2 1 20 0", 1},
+		{"M0 0 L1 1 L..5 2", 2},
+	}
+	for _, test := range tests {
+		path, err := ParsePath(test.d)
+		if err == nil {
+			t.Errorf("%q: no error", test.d)
+			continue
+		}
+		if len(path.Points) != test.points {
+			t.Errorf("%q: %d points before the error, want %d", test.d, len(path.Points), test.points)
+		}
+	}
+	for _, d := range []string{"", "   ", "M0 0"} {
+		if _, err := ParsePath(d); err != nil {
+			t.Errorf("%q: %v", d, err)
+		}
+	}
+}
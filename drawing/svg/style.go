@@ -0,0 +1,383 @@
+package svg
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// paint is the value of a fill or stroke property.
+type paint struct {
+	none    bool
+	current bool //currentColor, resolved when used
+	color   color.NRGBA
+}
+
+// style is the computed value of the properties of an element.
+type style struct {
+	color         color.NRGBA
+	fill          paint
+	stroke        paint
+	fillOpacity   float64
+	strokeOpacity float64
+	opacity       float64 //the product of the opacities of the element and its ancestors
+	fillRule      geom.FillMode
+	strokeWidth   float64
+	lineCap       geom.LineCap
+	lineJoin      geom.LineJoin
+	miterLimit    float64
+	dashes        []float64
+	dashOffset    float64
+	visible       bool
+}
+
+// newStyle returns the initial values of the properties.
+func newStyle(c color.NRGBA) *style {
+	return &style{
+		color:         c,
+		fill:          paint{color: color.NRGBA{A: 0xff}},
+		stroke:        paint{none: true},
+		fillOpacity:   1,
+		strokeOpacity: 1,
+		opacity:       1,
+		fillRule:      geom.FillWinding,
+		strokeWidth:   1,
+		lineCap:       geom.LineCapFlat,
+		lineJoin:      geom.LineJoinMiter,
+		miterLimit:    4,
+		visible:       true,
+	}
+}
+
+// inherit returns the style of an element with attributes, whose parent has this style.
+// Invalid values and inherit keep the value of the parent.
+func (this *style) inherit(attrs map[string]string, viewBox geom.Rect) *style {
+	s := *this
+	diagonal := math.Hypot(viewBox.Width, viewBox.Height) / math.Sqrt2
+	for name, value := range attrs {
+		if value == "inherit" {
+			continue
+		}
+		switch name {
+		case "color":
+			if c, ok := parseColor(value); ok {
+				s.color = c
+			}
+		case "fill":
+			s.fill = parsePaint(value, s.fill)
+		case "stroke":
+			s.stroke = parsePaint(value, s.stroke)
+		case "fill-opacity":
+			s.fillOpacity = parseOpacity(value, s.fillOpacity)
+		case "stroke-opacity":
+			s.strokeOpacity = parseOpacity(value, s.strokeOpacity)
+		case "opacity":
+			s.opacity *= parseOpacity(value, 1)
+		case "fill-rule":
+			switch value {
+			case "nonzero":
+				s.fillRule = geom.FillWinding
+			case "evenodd":
+				s.fillRule = geom.FillAlternate
+			}
+		case "stroke-width":
+			if width, ok := parseLengthOk(value, diagonal); ok && width >= 0 {
+				s.strokeWidth = width
+			}
+		case "stroke-linecap":
+			switch value {
+			case "butt":
+				s.lineCap = geom.LineCapFlat
+			case "round":
+				s.lineCap = geom.LineCapRound
+			case "square":
+				s.lineCap = geom.LineCapSquare
+			}
+		case "stroke-linejoin":
+			switch value {
+			case "miter", "arcs":
+				s.lineJoin = geom.LineJoinMiter
+			case "miter-clip":
+				s.lineJoin = geom.LineJoinMiterClipped
+			case "round":
+				s.lineJoin = geom.LineJoinRound
+			case "bevel":
+				s.lineJoin = geom.LineJoinBevel
+			}
+		case "stroke-miterlimit":
+			if limit, err := strconv.ParseFloat(value, 64); err == nil && limit >= 1 {
+				s.miterLimit = limit
+			}
+		case "stroke-dasharray":
+			s.dashes = parseDashes(value, diagonal)
+		case "stroke-dashoffset":
+			if offset, ok := parseLengthOk(value, diagonal); ok {
+				s.dashOffset = offset
+			}
+		case "visibility":
+			s.visible = value == "visible"
+		}
+	}
+	return &s
+}
+
+// resolve returns the color of a paint, with an opacity.
+func (this *style) resolve(p paint, opacity float64) color.NRGBA {
+	c := p.color
+	if p.current {
+		c = this.color
+	}
+	c.A = uint8(math.Round(float64(c.A) * opacity * this.opacity))
+	return c
+}
+
+// fillBrush returns the brush filling shapes, and false if they aren't filled.
+func (this *style) fillBrush() (canvas.Brush, bool) {
+	if this.fill.none {
+		return nil, false
+	}
+	c := this.resolve(this.fill, this.fillOpacity)
+	return canvas.SolidBrush{Color: c}, c.A > 0
+}
+
+// pen returns the pen stroking shapes, and false if they aren't stroked.
+func (this *style) pen() (*canvas.Pen, bool) {
+	if this.stroke.none || this.strokeWidth <= 0 {
+		return nil, false
+	}
+	c := this.resolve(this.stroke, this.strokeOpacity)
+	pen := &canvas.Pen{
+		Color:      c,
+		Width:      this.strokeWidth,
+		StartCap:   this.lineCap,
+		EndCap:     this.lineCap,
+		Join:       this.lineJoin,
+		MiterLimit: this.miterLimit,
+		DashCap:    this.lineCap,
+	}
+	if len(this.dashes) > 0 {
+		pen.DashStyle = canvas.DashStyleCustom
+		for _, dash := range this.dashes {
+			pen.DashPattern = append(pen.DashPattern, dash/this.strokeWidth)
+		}
+		pen.DashOffset = this.dashOffset / this.strokeWidth
+	}
+	return pen, c.A > 0
+}
+
+// parsePaint parses a fill or stroke value. Paint servers are replaced by their fallback color.
+func parsePaint(value string, inherited paint) paint {
+	switch value {
+	case "none":
+		return paint{none: true}
+	case "currentColor":
+		return paint{current: true}
+	}
+	if strings.HasPrefix(value, "url(") {
+		_, fallback, _ := strings.Cut(value, ")")
+		fallback = strings.TrimSpace(fallback)
+		if fallback == "" {
+			return paint{none: true}
+		}
+		return parsePaint(fallback, paint{none: true})
+	}
+	if c, ok := parseColor(value); ok {
+		return paint{color: c}
+	}
+	return inherited
+}
+
+// parseOpacity parses a number or percentage clamped to [0, 1].
+func parseOpacity(value string, inherited float64) float64 {
+	percent := strings.HasSuffix(value, "%")
+	opacity, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+	if err != nil {
+		return inherited
+	}
+	if percent {
+		opacity /= 100
+	}
+	return math.Max(0, math.Min(1, opacity))
+}
+
+// parseDashes parses a stroke-dasharray, returning nil for solid lines.
+func parseDashes(value string, reference float64) []float64 {
+	if value == "none" {
+		return nil
+	}
+	var dashes []float64
+	total := 0.0
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || isSpace(byte(r))
+	}) {
+		dash, ok := parseLengthOk(field, reference)
+		if !ok || dash < 0 {
+			return nil
+		}
+		dashes = append(dashes, dash)
+		total += dash
+	}
+	if total == 0 {
+		return nil
+	}
+	if len(dashes)%2 == 1 {
+		dashes = append(dashes, dashes...)
+	}
+	return dashes
+}
+
+// unitLengths are the lengths of units in user units, as pixels at 96 DPI.
+var unitLengths = map[string]float64{
+	"":   1,
+	"px": 1,
+	"pt": 96.0 / 72,
+	"pc": 16,
+	"mm": 96 / 25.4,
+	"cm": 96 / 2.54,
+	"in": 96,
+	"em": 16, //of the default font size
+	"ex": 8,
+}
+
+// parseLength parses a length in user units, percentages being of reference,
+// and returns 0 if it is invalid.
+func parseLength(value string, reference float64) float64 {
+	length, _ := parseLengthOk(value, reference)
+	return length
+}
+
+func parseLengthOk(value string, reference float64) (float64, bool) {
+	s := &scanner{s: value}
+	number, err := s.number()
+	if err != nil {
+		return 0, false
+	}
+	unit := strings.TrimSpace(value[s.pos:])
+	if unit == "%" {
+		return number * reference / 100, true
+	}
+	if k, ok := unitLengths[unit]; ok {
+		return number * k, true
+	}
+	return 0, false
+}
+
+// parseTransform parses a transform attribute, returning the identity if it is invalid.
+func parseTransform(value string) geom.Affine {
+	m := geom.Identity()
+	s := &scanner{s: value}
+	for !s.done() {
+		start := s.pos
+		for s.pos < len(s.s) && (s.s[s.pos] >= 'a' && s.s[s.pos] <= 'z' || s.s[s.pos] >= 'A' && s.s[s.pos] <= 'Z') {
+			s.pos++
+		}
+		name := s.s[start:s.pos]
+		s.skipSpace()
+		if s.pos == len(s.s) || s.s[s.pos] != '(' {
+			return geom.Identity()
+		}
+		s.pos++
+		args, err := s.numbers()
+		s.skipSpace()
+		if err != nil || s.pos == len(s.s) || s.s[s.pos] != ')' {
+			return geom.Identity()
+		}
+		s.pos++
+		s.skipSeparator()
+
+		//each transform applies before those on its left
+		switch {
+		case name == "matrix" && len(args) == 6:
+			m = geom.Affine{M11: args[0], M12: args[1], M21: args[2], M22: args[3],
+				DX: args[4], DY: args[5]}.Multiply(m)
+		case name == "translate" && len(args) == 1:
+			m = m.Translate(args[0], 0)
+		case name == "translate" && len(args) == 2:
+			m = m.Translate(args[0], args[1])
+		case name == "scale" && len(args) == 1:
+			m = m.Scale(args[0], args[0])
+		case name == "scale" && len(args) == 2:
+			m = m.Scale(args[0], args[1])
+		case name == "rotate" && len(args) == 1:
+			m = m.Rotate(args[0])
+		case name == "rotate" && len(args) == 3:
+			m = m.RotateAt(args[0], geom.Pt(args[1], args[2]))
+		case name == "skewX" && len(args) == 1:
+			m = m.Shear(math.Tan(args[0]*math.Pi/180), 0)
+		case name == "skewY" && len(args) == 1:
+			m = m.Shear(0, math.Tan(args[0]*math.Pi/180))
+		default:
+			return geom.Identity()
+		}
+	}
+	return m
+}
+
+// parseColor parses a color as a hex notation, an rgb or rgba function or a keyword.
+func parseColor(value string) (color.NRGBA, bool) {
+	value = strings.TrimSpace(value)
+	if strings.HasPrefix(value, "#") {
+		return parseHexColor(value[1:])
+	}
+	lower := strings.ToLower(value)
+	if lower == "transparent" {
+		return color.NRGBA{}, true
+	}
+	if c, ok := namedColors[lower]; ok {
+		return c, true
+	}
+	name, args, ok := strings.Cut(lower, "(")
+	if !ok || (name != "rgb" && name != "rgba") || !strings.HasSuffix(args, ")") {
+		return color.NRGBA{}, false
+	}
+	fields := strings.FieldsFunc(strings.TrimSuffix(args, ")"), func(r rune) bool {
+		return r == ',' || r == '/' || isSpace(byte(r))
+	})
+	if len(fields) != 3 && len(fields) != 4 {
+		return color.NRGBA{}, false
+	}
+	var channels [4]uint8
+	channels[3] = 0xff
+	for n, field := range fields {
+		percent := strings.HasSuffix(field, "%")
+		v, err := strconv.ParseFloat(strings.TrimSuffix(field, "%"), 64)
+		if err != nil {
+			return color.NRGBA{}, false
+		}
+		switch {
+		case percent:
+			v = v * 255 / 100
+		case n == 3:
+			v *= 255
+		}
+		channels[n] = uint8(math.Round(math.Max(0, math.Min(255, v))))
+	}
+	return color.NRGBA{channels[0], channels[1], channels[2], channels[3]}, true
+}
+
+// parseHexColor parses the digits of #rgb, #rgba, #rrggbb and #rrggbbaa.
+func parseHexColor(digits string) (color.NRGBA, bool) {
+	v, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return color.NRGBA{}, false
+	}
+	switch len(digits) {
+	case 3:
+		v = v<<4 | 0xf
+		fallthrough
+	case 4:
+		//duplicate each digit
+		r, g, b, a := uint8(v>>12&0xf), uint8(v>>8&0xf), uint8(v>>4&0xf), uint8(v&0xf)
+		return color.NRGBA{r * 0x11, g * 0x11, b * 0x11, a * 0x11}, true
+	case 6:
+		v = v<<8 | 0xff
+		fallthrough
+	case 8:
+		return color.NRGBA{uint8(v >> 24), uint8(v >> 16), uint8(v >> 8), uint8(v)}, true
+	}
+	return color.NRGBA{}, false
+}
@@ -0,0 +1,290 @@
+// Package svg parses SVG path data and renders simple SVG documents, such as icons,
+// in pure Go.
+//
+// Documents draw onto any canvas.Canvas, and Render rasterizes them with the raster package,
+// so that vector icons can be rendered at the size of each DPI.
+// The renderer supports the svg, g, path, rect, circle, ellipse, line, polyline and polygon
+// elements, viewBox and preserveAspectRatio, transforms, and solid fills and strokes set
+// by presentation attributes or style attributes. Other elements, paint servers
+// such as gradients, text and style sheets are ignored, and the opacity of groups
+// multiplies that of their shapes instead of compositing the group.
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"image"
+	"image/color"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+	"github.com/zzl/goforms/drawing/raster"
+)
+
+// ErrFormat is returned for data that isn't an SVG document.
+var ErrFormat = errors.New("svg: not an SVG document")
+
+// element is an element of a document, with its attributes and the declarations
+// of its style attribute, the latter taking precedence.
+type element struct {
+	name     string
+	attrs    map[string]string
+	children []*element
+}
+
+// Document is a parsed SVG document.
+type Document struct {
+	Width   float64   //the intrinsic width, that of the view box if unspecified
+	Height  float64   //the intrinsic height, that of the view box if unspecified
+	ViewBox geom.Rect //the area of the user coordinates drawn
+
+	// Color is the value of currentColor, black unless set by the color of the root element.
+	// It can be changed to tint monochrome icons.
+	Color color.NRGBA
+
+	root *element
+}
+
+// Parse parses an SVG document.
+func Parse(data []byte) (*Document, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	var stack []*element
+	var root *element
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			e := &element{name: token.Name.Local, attrs: make(map[string]string)}
+			for _, attr := range token.Attr {
+				if attr.Name.Space == "" || attr.Name.Space == "http://www.w3.org/2000/svg" {
+					e.attrs[attr.Name.Local] = strings.TrimSpace(attr.Value)
+				}
+			}
+			for _, declaration := range strings.Split(e.attrs["style"], ";") {
+				if name, value, ok := strings.Cut(declaration, ":"); ok {
+					e.attrs[strings.TrimSpace(name)] = strings.TrimSpace(value)
+				}
+			}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			} else if root == nil {
+				root = e
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+	if root == nil || root.name != "svg" {
+		return nil, ErrFormat
+	}
+
+	doc := &Document{Color: color.NRGBA{A: 0xff}, root: root}
+	if c, ok := parseColor(root.attrs["color"]); ok {
+		doc.Color = c
+	}
+	if values, err := (&scanner{s: root.attrs["viewBox"]}).numbers(); err == nil &&
+		len(values) == 4 && values[2] > 0 && values[3] > 0 {
+		doc.ViewBox = geom.Rc(values[0], values[1], values[2], values[3])
+	}
+	doc.Width = parseLength(root.attrs["width"], doc.ViewBox.Width)
+	doc.Height = parseLength(root.attrs["height"], doc.ViewBox.Height)
+	if doc.Width <= 0 || doc.Height <= 0 {
+		//as the aspect ratio of the view box, or the default size of replaced elements
+		switch {
+		case doc.ViewBox.IsEmpty():
+			doc.Width, doc.Height = 300, 150
+		case doc.Width > 0:
+			doc.Height = doc.Width * doc.ViewBox.Height / doc.ViewBox.Width
+		case doc.Height > 0:
+			doc.Width = doc.Height * doc.ViewBox.Width / doc.ViewBox.Height
+		default:
+			doc.Width, doc.Height = doc.ViewBox.Width, doc.ViewBox.Height
+		}
+	}
+	if doc.ViewBox.IsEmpty() {
+		doc.ViewBox = geom.Rc(0, 0, doc.Width, doc.Height)
+	}
+	return doc, nil
+}
+
+// ParseFile parses the SVG document of a file.
+func ParseFile(name string) (*Document, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Size returns the intrinsic size of the document.
+func (this *Document) Size() geom.Size {
+	return geom.Size{Width: this.Width, Height: this.Height}
+}
+
+// Draw draws the document into a rect of a canvas, fitting the view box
+// as its preserveAspectRatio says, and clipped to the rect.
+func (this *Document) Draw(c canvas.Canvas, rect geom.Rect) {
+	c.Save()
+	defer c.Restore()
+	c.ClipRect(rect)
+	m := this.viewBoxTransform(rect).Multiply(c.Transform())
+	style := newStyle(this.Color).inherit(this.root.attrs, this.ViewBox)
+	for _, child := range this.root.children {
+		this.drawElement(c, child, m, style)
+	}
+}
+
+// Render rasterizes the document at a size, stretched as its preserveAspectRatio says.
+func (this *Document) Render(width, height int) *image.RGBA {
+	c := raster.NewRGBA(width, height)
+	this.Draw(c, geom.Rc(0, 0, float64(width), float64(height)))
+	return c.Image()
+}
+
+// viewBoxTransform returns the mapping of the view box into a rect.
+func (this *Document) viewBoxTransform(rect geom.Rect) geom.Affine {
+	vb := this.ViewBox
+	sx, sy := rect.Width/vb.Width, rect.Height/vb.Height
+	fields := strings.Fields(this.root.attrs["preserveAspectRatio"])
+	align := "xMidYMid"
+	if len(fields) > 0 {
+		align = fields[0]
+	}
+	if align == "none" {
+		return geom.Translation(-vb.X, -vb.Y).Multiply(geom.Scaling(sx, sy)).
+			Multiply(geom.Translation(rect.X, rect.Y))
+	}
+	scale := math.Min(sx, sy)
+	if len(fields) > 1 && fields[1] == "slice" {
+		scale = math.Max(sx, sy)
+	}
+	//the alignment factors of the x and y axes, 0 for min, 0.5 for mid and 1 for max
+	factor := func(axis string) float64 {
+		switch {
+		case strings.Contains(align, axis+"Min"):
+			return 0
+		case strings.Contains(align, axis+"Max"):
+			return 1
+		}
+		return 0.5
+	}
+	dx := rect.X + (rect.Width-vb.Width*scale)*factor("x")
+	dy := rect.Y + (rect.Height-vb.Height*scale)*factor("Y")
+	return geom.Translation(-vb.X, -vb.Y).Multiply(geom.Scaling(scale, scale)).
+		Multiply(geom.Translation(dx, dy))
+}
+
+// drawElement draws an element with the transform and style of its parent.
+func (this *Document) drawElement(c canvas.Canvas, e *element, m geom.Affine, parent *style) {
+	if e.attrs["display"] == "none" {
+		return
+	}
+	container := false
+	switch e.name {
+	case "g", "svg", "a", "switch":
+		container = true
+	case "path", "rect", "circle", "ellipse", "line", "polyline", "polygon":
+	default:
+		return //definitions, paint servers, text and unknown elements
+	}
+	m = parseTransform(e.attrs["transform"]).Multiply(m)
+	style := parent.inherit(e.attrs, this.ViewBox)
+	if e.name == "svg" {
+		//nested viewports are drawn as groups at their location
+		m = geom.Translation(parseLength(e.attrs["x"], this.ViewBox.Width),
+			parseLength(e.attrs["y"], this.ViewBox.Height)).Multiply(m)
+	}
+	if container {
+		for _, child := range e.children {
+			this.drawElement(c, child, m, style)
+		}
+		return
+	}
+	path := this.shapePath(e)
+	if path == nil || path.IsEmpty() || !style.visible {
+		return
+	}
+	c.SetTransform(m)
+	if brush, ok := style.fillBrush(); ok {
+		path.FillMode = style.fillRule
+		c.FillPath(path, brush)
+	}
+	if pen, ok := style.pen(); ok {
+		c.StrokePath(path, pen)
+	}
+}
+
+// shapePath returns the outline of a basic shape or path element, in user coordinates.
+func (this *Document) shapePath(e *element) *geom.Path {
+	vb := this.ViewBox
+	diagonal := math.Hypot(vb.Width, vb.Height) / math.Sqrt2
+	length := func(name string, reference float64) float64 {
+		return parseLength(e.attrs[name], reference)
+	}
+	path := geom.NewPath()
+	switch e.name {
+	case "path":
+		//an erroneous path renders up to the error
+		path, _ = ParsePath(e.attrs["d"])
+	case "rect":
+		rect := geom.Rc(length("x", vb.Width), length("y", vb.Height),
+			length("width", vb.Width), length("height", vb.Height))
+		if rect.Width <= 0 || rect.Height <= 0 {
+			return nil
+		}
+		rx, rxOk := e.attrs["rx"]
+		ry, ryOk := e.attrs["ry"]
+		if !rxOk || rx == "auto" {
+			rx = ry
+		}
+		if !ryOk || ry == "auto" {
+			ry = rx
+		}
+		path.AddRoundedRectangle(rect, parseLength(rx, vb.Width), parseLength(ry, vb.Height))
+	case "circle":
+		r := length("r", diagonal)
+		if r <= 0 {
+			return nil
+		}
+		cx, cy := length("cx", vb.Width), length("cy", vb.Height)
+		path.AddEllipse(geom.Rc(cx-r, cy-r, 2*r, 2*r))
+	case "ellipse":
+		rx, ry := length("rx", vb.Width), length("ry", vb.Height)
+		if rx <= 0 || ry <= 0 {
+			return nil
+		}
+		cx, cy := length("cx", vb.Width), length("cy", vb.Height)
+		path.AddEllipse(geom.Rc(cx-rx, cy-ry, 2*rx, 2*ry))
+	case "line":
+		path.AddLine(geom.Pt(length("x1", vb.Width), length("y1", vb.Height)),
+			geom.Pt(length("x2", vb.Width), length("y2", vb.Height)))
+	case "polyline", "polygon":
+		//an odd number of coordinates ignores the last one
+		values, _ := (&scanner{s: e.attrs["points"]}).numbers()
+		var points []geom.Point
+		for n := 0; n+1 < len(values); n += 2 {
+			points = append(points, geom.Pt(values[n], values[n+1]))
+		}
+		path.AddLines(points)
+		if e.name == "polygon" {
+			path.CloseFigure()
+		}
+	}
+	return path
+}
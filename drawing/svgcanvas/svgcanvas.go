@@ -0,0 +1,442 @@
+// Package svgcanvas implements canvas.Canvas by recording the drawing as an SVG document.
+//
+// The output is deterministic: numbers are printed with at most 4 decimals,
+// and definitions are numbered in order of first use and shared by identical uses,
+// so that drawings can be compared with golden files.
+//
+// Features without an SVG equivalent degrade: different start and end caps use the start cap,
+// triangle caps are drawn round, clipped miters are drawn as miters,
+// and Clear only clears the whole drawing when nothing is clipped.
+package svgcanvas
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+type state struct {
+	transform  geom.Affine
+	clipID     string    //the id of the clipPath of the clip, "" if not clipped
+	clipBounds geom.Rect //in device coordinates
+}
+
+// Canvas is a canvas.Canvas recording an SVG document.
+type Canvas struct {
+	width, height float64
+	state         state
+	stack         []state
+
+	defs     bytes.Buffer
+	body     bytes.Buffer
+	defIDs   map[string]string //definition content to id
+	defCount int
+
+	// Faces provides the faces used to lay out text. If nil or returning nil,
+	// canvas.FallbackFace is used. The text itself is written as SVG text.
+	Faces canvas.FaceSource
+}
+
+var _ canvas.Canvas = (*Canvas)(nil)
+
+// New creates a canvas recording a drawing of a size.
+func New(width, height float64) *Canvas {
+	return &Canvas{
+		width:  width,
+		height: height,
+		state: state{transform: geom.Identity(),
+			clipBounds: geom.Rc(0, 0, width, height)},
+		defIDs: make(map[string]string),
+	}
+}
+
+// WriteTo writes the SVG document.
+func (this *Canvas) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" `+
+		`xmlns:xlink="http://www.w3.org/1999/xlink" `+
+		`width="%s" height="%s" viewBox="0 0 %[1]s %[2]s">`+"\n",
+		num(this.width), num(this.height))
+	if this.defs.Len() > 0 {
+		buf.WriteString("<defs>\n")
+		buf.Write(this.defs.Bytes())
+		buf.WriteString("</defs>\n")
+	}
+	buf.Write(this.body.Bytes())
+	buf.WriteString("</svg>\n")
+	return buf.WriteTo(w)
+}
+
+// Bytes returns the SVG document.
+func (this *Canvas) Bytes() []byte {
+	var buf bytes.Buffer
+	this.WriteTo(&buf)
+	return buf.Bytes()
+}
+
+func (this *Canvas) Bounds() geom.Rect {
+	return geom.Rc(0, 0, this.width, this.height)
+}
+
+func (this *Canvas) Save() {
+	this.stack = append(this.stack, this.state)
+}
+
+func (this *Canvas) Restore() {
+	if len(this.stack) == 0 {
+		return
+	}
+	this.state = this.stack[len(this.stack)-1]
+	this.stack = this.stack[:len(this.stack)-1]
+}
+
+func (this *Canvas) Transform() geom.Affine {
+	return this.state.transform
+}
+
+func (this *Canvas) SetTransform(m geom.Affine) {
+	this.state.transform = m
+}
+
+func (this *Canvas) ClipRect(rect geom.Rect) {
+	path := geom.NewPath()
+	path.AddRectangle(rect)
+	this.ClipPath(path)
+}
+
+func (this *Canvas) ClipPath(path *geom.Path) {
+	m := this.state.transform
+	var def strings.Builder
+	def.WriteString(`<clipPath id="%s" clipPathUnits="userSpaceOnUse"`)
+	if this.state.clipID != "" {
+		fmt.Fprintf(&def, ` clip-path="url(#%s)"`, this.state.clipID)
+	}
+	fmt.Fprintf(&def, `><path d="%s"%s%s/></clipPath>`,
+		pathData(path), fillRule(path, "clip-rule"), transformAttr(m))
+	this.state.clipID = this.define(def.String())
+
+	this.state.clipBounds = this.state.clipBounds.Intersect(m.TransformRect(path.ControlBounds()))
+}
+
+func (this *Canvas) ClipBounds() geom.Rect {
+	inverse, ok := this.state.transform.Invert()
+	if !ok {
+		return geom.Rect{}
+	}
+	return inverse.TransformRect(this.state.clipBounds)
+}
+
+func (this *Canvas) Clear(c color.NRGBA) {
+	if this.state.clipID == "" {
+		this.body.Reset()
+	}
+	if c.A == 0 {
+		return
+	}
+	this.element(fmt.Sprintf(`<rect width="%s" height="%s"%s/>`,
+		num(this.width), num(this.height), this.paintAttrs("fill", canvas.SolidBrush{Color: c}, geom.Identity())))
+}
+
+// element writes an element in device coordinates, within the current clip.
+func (this *Canvas) element(content string) {
+	if this.state.clipID != "" {
+		fmt.Fprintf(&this.body, `<g clip-path="url(#%s)">%s</g>`+"\n", this.state.clipID, content)
+		return
+	}
+	this.body.WriteString(content)
+	this.body.WriteByte('\n')
+}
+
+// define adds a definition, whose content has a %s placeholder for its id,
+// and returns the id. Identical definitions share their id.
+func (this *Canvas) define(content string) string {
+	if id, ok := this.defIDs[content]; ok {
+		return id
+	}
+	this.defCount++
+	id := "d" + strconv.Itoa(this.defCount)
+	this.defIDs[content] = id
+	this.defs.WriteString(strings.Replace(content, "%s", id, 1))
+	this.defs.WriteByte('\n')
+	return id
+}
+
+func (this *Canvas) FillPath(path *geom.Path, brush canvas.Brush) {
+	if path.IsEmpty() {
+		return
+	}
+	m := this.state.transform
+	this.element(fmt.Sprintf(`<path d="%s"%s%s%s/>`, pathData(path),
+		this.paintAttrs("fill", brush, m), fillRule(path, "fill-rule"), transformAttr(m)))
+}
+
+func (this *Canvas) StrokePath(path *geom.Path, pen *canvas.Pen) {
+	if path.IsEmpty() {
+		return
+	}
+	m := this.state.transform
+	var attrs strings.Builder
+	attrs.WriteString(` fill="none"`)
+	attrs.WriteString(this.paintAttrs("stroke", pen.GetBrush(), m))
+	width := pen.Width
+	if width <= 0 {
+		width = 1
+		attrs.WriteString(` stroke-width="1" vector-effect="non-scaling-stroke"`)
+	} else if width != 1 {
+		fmt.Fprintf(&attrs, ` stroke-width="%s"`, num(width))
+	}
+	dashes := pen.GetDashPattern()
+	lineCap := pen.StartCap
+	if dashes != nil {
+		lineCap = pen.DashCap
+	}
+	switch lineCap {
+	case canvas.LineCapSquare:
+		attrs.WriteString(` stroke-linecap="square"`)
+	case canvas.LineCapRound, canvas.LineCapTriangle:
+		attrs.WriteString(` stroke-linecap="round"`)
+	}
+	switch pen.Join {
+	case canvas.LineJoinMiter, canvas.LineJoinMiterClipped:
+		if limit := pen.GetMiterLimit(); limit != 4 {
+			fmt.Fprintf(&attrs, ` stroke-miterlimit="%s"`, num(math.Max(limit, 1)))
+		}
+	case canvas.LineJoinBevel:
+		attrs.WriteString(` stroke-linejoin="bevel"`)
+	case canvas.LineJoinRound:
+		attrs.WriteString(` stroke-linejoin="round"`)
+	}
+	if dashes != nil {
+		values := make([]string, len(dashes))
+		for n, dash := range dashes {
+			values[n] = num(dash * width)
+		}
+		fmt.Fprintf(&attrs, ` stroke-dasharray="%s"`, strings.Join(values, " "))
+		if pen.DashOffset != 0 {
+			fmt.Fprintf(&attrs, ` stroke-dashoffset="%s"`, num(pen.DashOffset*width))
+		}
+	}
+	this.element(fmt.Sprintf(`<path d="%s"%s%s/>`, pathData(path), attrs.String(), transformAttr(m)))
+}
+
+func (this *Canvas) DrawImage(img image.Image, src image.Rectangle, dst geom.Rect) {
+	src = src.Intersect(img.Bounds())
+	if src.Empty() || dst.IsEmpty() {
+		return
+	}
+	sub := image.NewNRGBA(image.Rect(0, 0, src.Dx(), src.Dy()))
+	for y := 0; y < src.Dy(); y++ {
+		for x := 0; x < src.Dx(); x++ {
+			sub.Set(x, y, img.At(src.Min.X+x, src.Min.Y+y))
+		}
+	}
+	var data bytes.Buffer
+	if err := png.Encode(&data, sub); err != nil {
+		return
+	}
+	this.element(fmt.Sprintf(`<image x="%s" y="%s" width="%s" height="%s" `+
+		`preserveAspectRatio="none" xlink:href="data:image/png;base64,%s"%s/>`,
+		num(dst.X), num(dst.Y), num(dst.Width), num(dst.Height),
+		base64.StdEncoding.EncodeToString(data.Bytes()), transformAttr(this.state.transform)))
+}
+
+// face returns the face of a font.
+func (this *Canvas) face(font *canvas.Font) canvas.Face {
+	if this.Faces != nil {
+		if face := this.Faces.Face(font); face != nil {
+			return face
+		}
+	}
+	return canvas.FallbackFace
+}
+
+func (this *Canvas) DrawString(text string, font *canvas.Font, brush canvas.Brush,
+	layout geom.Rect, format *canvas.StringFormat) {
+	m := this.state.transform
+	var attrs strings.Builder
+	fmt.Fprintf(&attrs, ` font-family="%s" font-size="%s"`, escape(font.Family), num(font.Size))
+	if font.Style&canvas.FontStyleBold != 0 {
+		attrs.WriteString(` font-weight="bold"`)
+	}
+	if font.Style&canvas.FontStyleItalic != 0 {
+		attrs.WriteString(` font-style="italic"`)
+	}
+	var decorations []string
+	if font.Style&canvas.FontStyleUnderline != 0 {
+		decorations = append(decorations, "underline")
+	}
+	if font.Style&canvas.FontStyleStrikeout != 0 {
+		decorations = append(decorations, "line-through")
+	}
+	if decorations != nil {
+		fmt.Fprintf(&attrs, ` text-decoration="%s"`, strings.Join(decorations, " "))
+	}
+	anchor := layout.X
+	if format != nil {
+		switch format.Alignment {
+		case canvas.StringAlignmentCenter:
+			attrs.WriteString(` text-anchor="middle"`)
+			anchor += layout.Width / 2
+		case canvas.StringAlignmentFar:
+			attrs.WriteString(` text-anchor="end"`)
+			anchor += layout.Width
+		}
+	}
+	attrs.WriteString(this.paintAttrs("fill", brush, m))
+	attrs.WriteString(transformAttr(m))
+
+	textLayout := canvas.LayoutText(text, this.face(font), font, layout, format)
+	var content strings.Builder
+	fmt.Fprintf(&content, `<text xml:space="preserve"%s>`, attrs.String())
+	for _, line := range textLayout.Lines {
+		fmt.Fprintf(&content, `<tspan x="%s" y="%s">%s</tspan>`,
+			num(anchor), num(line.Origin.Y), escape(line.Text))
+	}
+	content.WriteString("</text>")
+	this.element(content.String())
+}
+
+func (this *Canvas) MeasureString(text string, font *canvas.Font, width float64,
+	format *canvas.StringFormat) geom.Size {
+	return canvas.MeasureText(text, this.face(font), font, width, format)
+}
+
+// paintAttrs returns the attributes painting the fill or stroke of an element
+// with a brush, the element being drawn with the transform m.
+func (this *Canvas) paintAttrs(name string, brush canvas.Brush, m geom.Affine) string {
+	switch b := brush.(type) {
+	case *canvas.SolidBrush:
+		return this.paintAttrs(name, *b, m)
+	case *canvas.HatchBrush:
+		return this.paintAttrs(name, *b, m)
+	case *canvas.LinearGradientBrush:
+		return this.paintAttrs(name, *b, m)
+	case canvas.SolidBrush:
+		return colorAttrs(name, b.Color)
+	case canvas.HatchBrush:
+		return fmt.Sprintf(` %s="url(#%s)"`, name, this.defineHatch(b, m))
+	case canvas.LinearGradientBrush:
+		stops := b.SortedStops()
+		if len(stops) == 0 || b.Start == b.End {
+			return colorAttrs(name, b.ColorAt(0))
+		}
+		var def strings.Builder
+		fmt.Fprintf(&def, `<linearGradient id="%%s" gradientUnits="userSpaceOnUse" `+
+			`x1="%s" y1="%s" x2="%s" y2="%s">`,
+			num(b.Start.X), num(b.Start.Y), num(b.End.X), num(b.End.Y))
+		for _, stop := range stops {
+			fmt.Fprintf(&def, `<stop offset="%s"%s/>`, num(stop.Offset), colorAttrs("stop-color", stop.Color))
+		}
+		def.WriteString("</linearGradient>")
+		return fmt.Sprintf(` %s="url(#%s)"`, name, this.define(def.String()))
+	}
+	return fmt.Sprintf(` %s="none"`, name)
+}
+
+// defineHatch defines the pattern of a hatch brush, aligned with device pixels.
+func (this *Canvas) defineHatch(brush canvas.HatchBrush, m geom.Affine) string {
+	var def strings.Builder
+	def.WriteString(`<pattern id="%s" patternUnits="userSpaceOnUse" width="8" height="8"`)
+	if inverse, ok := m.Invert(); ok && !inverse.IsIdentity() {
+		def.WriteString(strings.Replace(transformAttr(inverse), "transform", "patternTransform", 1))
+	}
+	def.WriteString(">")
+	if brush.BackColor.A != 0 {
+		fmt.Fprintf(&def, `<rect width="8" height="8"%s/>`, colorAttrs("fill", brush.BackColor))
+	}
+	pattern := canvas.HatchPattern(brush.Style)
+	var d strings.Builder
+	for y, bits := range pattern {
+		for x := 0; x < 8; x++ {
+			if bits&(0x80>>x) != 0 {
+				fmt.Fprintf(&d, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	fmt.Fprintf(&def, `<path d="%s"%s/></pattern>`, d.String(), colorAttrs("fill", brush.ForeColor))
+	return this.define(def.String())
+}
+
+func colorAttrs(name string, c color.NRGBA) string {
+	attrs := fmt.Sprintf(` %s="#%02x%02x%02x"`, name, c.R, c.G, c.B)
+	if c.A != 255 {
+		if name == "stop-color" {
+			name = "stop"
+		}
+		attrs += fmt.Sprintf(` %s-opacity="%s"`, name, num(float64(c.A)/255))
+	}
+	return attrs
+}
+
+func fillRule(path *geom.Path, name string) string {
+	if path.FillMode == geom.FillWinding {
+		return ""
+	}
+	return fmt.Sprintf(` %s="evenodd"`, name)
+}
+
+func transformAttr(m geom.Affine) string {
+	if m.IsIdentity() {
+		return ""
+	}
+	return fmt.Sprintf(` transform="matrix(%s %s %s %s %s %s)"`,
+		num(m.M11), num(m.M12), num(m.M21), num(m.M22), num(m.DX), num(m.DY))
+}
+
+// pathData returns the d attribute of a path.
+func pathData(path *geom.Path) string {
+	var d strings.Builder
+	path.Iterate(func(op geom.PathOp, points []geom.Point) {
+		if d.Len() > 0 {
+			d.WriteByte(' ')
+		}
+		switch op {
+		case geom.PathMoveTo:
+			d.WriteByte('M')
+		case geom.PathLineTo:
+			d.WriteByte('L')
+		case geom.PathCubicTo:
+			d.WriteByte('C')
+		case geom.PathClose:
+			d.WriteByte('Z')
+			return
+		}
+		for n, pt := range points {
+			if n > 0 {
+				d.WriteByte(' ')
+			}
+			d.WriteString(num(pt.X))
+			d.WriteByte(' ')
+			d.WriteString(num(pt.Y))
+		}
+	})
+	return d.String()
+}
+
+// num formats a number with at most 4 decimals.
+func num(v float64) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "0"
+	}
+	v = math.Round(v*1e4) / 1e4
+	if v == 0 {
+		return "0" //no -0
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+
+func escape(s string) string {
+	return escaper.Replace(s)
+}
@@ -0,0 +1,205 @@
+package svgcanvas
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+var (
+	black = color.NRGBA{A: 255}
+	red   = color.NRGBA{R: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+	faint = color.NRGBA{R: 0x20, G: 0x40, B: 0x60, A: 0x80}
+)
+
+// scenes are the drawings compared with the golden files testdata/<name>.svg.
+var scenes = []struct {
+	name string
+	draw func(c *Canvas)
+}{
+	{"strokes", func(c *Canvas) {
+		caps := []canvas.LineCap{canvas.LineCapFlat, canvas.LineCapSquare,
+			canvas.LineCapRound, canvas.LineCapTriangle}
+		for n, lineCap := range caps {
+			pen := canvas.NewPen(black, 4)
+			pen.StartCap, pen.EndCap = lineCap, lineCap
+			y := 10 + float64(n)*10
+			canvas.DrawLine(c, pen, geom.Pt(10, y), geom.Pt(90, y))
+		}
+		styles := []canvas.DashStyle{canvas.DashStyleDash, canvas.DashStyleDot,
+			canvas.DashStyleDashDot, canvas.DashStyleDashDotDot}
+		for n, style := range styles {
+			pen := canvas.NewPen(blue, 2)
+			pen.DashStyle = style
+			pen.DashCap = canvas.LineCapRound
+			y := 60 + float64(n)*10
+			canvas.DrawLine(c, pen, geom.Pt(10, y), geom.Pt(90, y))
+		}
+		custom := canvas.NewPen(red, 1.5)
+		custom.DashStyle = canvas.DashStyleCustom
+		custom.DashPattern = []float64{2, 1, 0.5, 1}
+		custom.DashOffset = 1
+		canvas.DrawLine(c, custom, geom.Pt(10, 105), geom.Pt(90, 105))
+
+		joins := []canvas.LineJoin{canvas.LineJoinMiter, canvas.LineJoinBevel,
+			canvas.LineJoinRound, canvas.LineJoinMiterClipped}
+		for n, join := range joins {
+			pen := canvas.NewPen(black, 3)
+			pen.Join = join
+			pen.MiterLimit = 2
+			x := 110 + float64(n)*20
+			canvas.DrawLines(c, pen, []geom.Point{geom.Pt(x, 30), geom.Pt(x+7, 10), geom.Pt(x+14, 30)})
+		}
+		//hairline, unaffected by the scale
+		canvas.Scale(c, 2, 2)
+		canvas.DrawRectangle(c, canvas.NewPen(faint, 0), geom.Rc(55, 25, 20, 10))
+	}},
+	{"gradients", func(c *Canvas) {
+		gradient := canvas.LinearGradientBrush{Start: geom.Pt(0, 0), End: geom.Pt(100, 0),
+			Stops: []canvas.GradientStop{{Offset: 1, Color: blue}, {Offset: 0, Color: red},
+				{Offset: 0.5, Color: faint}}}
+		canvas.FillRectangle(c, gradient, geom.Rc(0, 0, 100, 40))
+		//an identical gradient shares the definition
+		canvas.FillEllipse(c, &gradient, geom.Rc(0, 50, 100, 40))
+		//degenerate gradients are solid
+		canvas.FillRectangle(c, canvas.LinearGradientBrush{Start: geom.Pt(5, 5), End: geom.Pt(5, 5),
+			Stops: []canvas.GradientStop{{Offset: 0, Color: blue}}}, geom.Rc(110, 0, 20, 20))
+		pen := canvas.NewPen(black, 6)
+		pen.Brush = gradient
+		canvas.DrawLine(c, pen, geom.Pt(110, 60), geom.Pt(190, 60))
+
+		hatch := canvas.HatchBrush{Style: canvas.HatchStyleCross, ForeColor: black, BackColor: faint}
+		canvas.FillRectangle(c, hatch, geom.Rc(110, 70, 30, 30))
+		c.Save()
+		canvas.Translate(c, 40, 0)
+		canvas.FillRectangle(c, hatch, geom.Rc(110, 70, 30, 30))
+		c.Restore()
+		canvas.FillPolygon(c, canvas.SolidBrush{Color: faint}, []geom.Point{
+			geom.Pt(10, 100), geom.Pt(60, 100), geom.Pt(20, 130), geom.Pt(35, 90), geom.Pt(50, 130),
+		}, geom.FillAlternate)
+	}},
+	{"clipping", func(c *Canvas) {
+		canvas.FillRectangle(c, canvas.SolidBrush{Color: faint}, geom.Rc(0, 0, 200, 150))
+		c.Save()
+		c.ClipRect(geom.Rc(10, 10, 100, 100))
+		path := geom.NewPath()
+		path.AddEllipse(geom.Rc(50, 50, 100, 80))
+		c.ClipPath(path)
+		canvas.FillRectangle(c, canvas.SolidBrush{Color: red}, geom.Rc(0, 0, 200, 150))
+		//a clear within a clip keeps what was drawn
+		c.Clear(color.NRGBA{})
+		c.Restore()
+
+		c.Save()
+		canvas.Rotate(c, 30)
+		c.ClipRect(geom.Rc(120, 0, 40, 40))
+		canvas.DrawEllipse(c, canvas.NewPen(blue, 2), geom.Rc(110, -10, 60, 60))
+		c.Restore()
+		//the same clip again shares the definition
+		c.Save()
+		c.ClipRect(geom.Rc(10, 10, 100, 100))
+		canvas.DrawLine(c, canvas.NewPen(black, 1), geom.Pt(0, 0), geom.Pt(200, 150))
+		c.Restore()
+	}},
+	{"text", func(c *Canvas) {
+		font := canvas.NewFont("Sans & <Serif>", 10, canvas.FontStyleRegular)
+		brush := canvas.SolidBrush{Color: black}
+		layout := geom.Rc(10, 10, 120, 40)
+		canvas.DrawRectangle(c, canvas.NewPen(faint, 0), layout)
+		c.DrawString("near aligned text wrapping", font, brush, layout, nil)
+		layout.Y += 50
+		c.DrawString("centered\nlines", font, brush, layout,
+			&canvas.StringFormat{Alignment: canvas.StringAlignmentCenter,
+				LineAlignment: canvas.StringAlignmentCenter})
+		layout.Y += 50
+		styled := canvas.NewFont("Mono", 8, canvas.FontStyleBold|canvas.FontStyleItalic|
+			canvas.FontStyleUnderline|canvas.FontStyleStrikeout)
+		c.DrawString(`far "quoted" <b>`, styled, canvas.SolidBrush{Color: faint}, layout,
+			&canvas.StringFormat{Alignment: canvas.StringAlignmentFar,
+				LineAlignment: canvas.StringAlignmentFar, NoWrap: true})
+		canvas.Translate(c, 150, 20)
+		canvas.Rotate(c, 90)
+		c.DrawString("rotated", font, brush, geom.Rect{}, nil)
+	}},
+	{"image", func(c *Canvas) {
+		img := image.NewNRGBA(image.Rect(0, 0, 4, 4))
+		for n := range img.Pix {
+			img.Pix[n] = byte(n * 16)
+		}
+		c.DrawImage(img, image.Rect(1, 1, 3, 3), geom.Rc(10, 10, 20, 20))
+		c.Clear(faint)
+		canvas.DrawImageAt(c, img, 40.5, 10)
+	}},
+}
+
+func render(draw func(c *Canvas)) []byte {
+	c := New(200, 150)
+	draw(c)
+	return c.Bytes()
+}
+
+func TestGolden(t *testing.T) {
+	for _, scene := range scenes {
+		t.Run(scene.name, func(t *testing.T) {
+			got := render(scene.draw)
+			if again := render(scene.draw); !bytes.Equal(got, again) {
+				t.Fatal("rendering twice gave different output")
+			}
+			golden := filepath.Join("testdata", scene.name+".svg")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("output differs from %s:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestNum(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"},
+		{-0.00001, "0"},
+		{1.5, "1.5"},
+		{1.23456, "1.2346"},
+		{-2, "-2"},
+		{1e6, "1000000"},
+	}
+	for _, test := range tests {
+		if got := num(test.v); got != test.want {
+			t.Errorf("num(%v) = %q, want %q", test.v, got, test.want)
+		}
+	}
+}
+
+func TestClipBounds(t *testing.T) {
+	c := New(200, 150)
+	c.ClipRect(geom.Rc(10, 20, 100, 200))
+	if got := c.ClipBounds(); got != geom.Rc(10, 20, 100, 130) {
+		t.Errorf("clip bounds %v, want the clip within the drawing", got)
+	}
+	canvas.Scale(c, 2, 2)
+	if got := c.ClipBounds(); got != geom.Rc(5, 10, 50, 65) {
+		t.Errorf("clip bounds %v in scaled coordinates", got)
+	}
+}
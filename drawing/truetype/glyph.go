@@ -0,0 +1,274 @@
+package truetype
+
+import (
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// composite glyph component flags
+const (
+	argsAreWords     = 0x0001
+	argsAreXY        = 0x0002
+	haveScale        = 0x0008
+	moreComponents   = 0x0020
+	haveXYScale      = 0x0040
+	haveTwoByTwo     = 0x0080
+	haveInstructions = 0x0100
+)
+
+// maxCompositeDepth bounds the nesting of composite glyphs, against cycles.
+const maxCompositeDepth = 8
+
+// glyphData returns the glyf data of a glyph, nil for empty glyphs.
+func (this *Font) glyphData(glyph uint16) []byte {
+	if int(glyph) >= this.numGlyphs {
+		return nil
+	}
+	loca, glyf := this.tables["loca"], this.tables["glyf"]
+	var start, end int
+	n := int(glyph)
+	if this.locaLong {
+		if 4*n+8 > len(loca) {
+			return nil
+		}
+		start, end = int(u32(loca, 4*n)), int(u32(loca, 4*n+4))
+	} else {
+		if 2*n+4 > len(loca) {
+			return nil
+		}
+		start, end = 2*int(u16(loca, 2*n)), 2*int(u16(loca, 2*n+2))
+	}
+	if start >= end || end > len(glyf) || end-start < 10 {
+		return nil
+	}
+	return glyf[start:end]
+}
+
+// GlyphPath returns the outline of a glyph in font units with the y axis pointing up,
+// or nil if the glyph has no outline.
+func (this *Font) GlyphPath(glyph uint16) *geom.Path {
+	path := geom.NewPath()
+	path.FillMode = geom.FillWinding
+	this.appendGlyph(path, glyph, geom.Identity(), 0)
+	if path.IsEmpty() {
+		return nil
+	}
+	return path
+}
+
+func (this *Font) appendGlyph(path *geom.Path, glyph uint16, m geom.Affine, depth int) {
+	data := this.glyphData(glyph)
+	if data == nil || depth > maxCompositeDepth {
+		return
+	}
+	if contours := i16(data, 0); contours >= 0 {
+		appendSimpleGlyph(path, data, contours, m)
+		return
+	}
+	this.forEachComponent(data, func(component uint16, cm geom.Affine) {
+		this.appendGlyph(path, component, cm.Multiply(m), depth+1)
+	})
+}
+
+// forEachComponent calls fn with the glyph and transform of each component of a composite glyph.
+func (this *Font) forEachComponent(data []byte, fn func(glyph uint16, m geom.Affine)) {
+	offset := 10
+	for {
+		if offset+4 > len(data) {
+			return
+		}
+		flags := u16(data, offset)
+		glyph := u16(data, offset+2)
+		offset += 4
+		var dx, dy float64
+		if flags&argsAreWords != 0 {
+			if offset+4 > len(data) {
+				return
+			}
+			dx, dy = float64(i16(data, offset)), float64(i16(data, offset+2))
+			offset += 4
+		} else {
+			if offset+2 > len(data) {
+				return
+			}
+			dx, dy = float64(int8(data[offset])), float64(int8(data[offset+1]))
+			offset += 2
+		}
+		if flags&argsAreXY == 0 {
+			dx, dy = 0, 0 //point matching isn't supported
+		}
+		m := geom.Identity()
+		f2dot14 := func(at int) float64 {
+			return float64(i16(data, at)) / 16384
+		}
+		switch {
+		case flags&haveScale != 0 && offset+2 <= len(data):
+			s := f2dot14(offset)
+			m.M11, m.M22 = s, s
+			offset += 2
+		case flags&haveXYScale != 0 && offset+4 <= len(data):
+			m.M11, m.M22 = f2dot14(offset), f2dot14(offset+2)
+			offset += 4
+		case flags&haveTwoByTwo != 0 && offset+8 <= len(data):
+			m.M11, m.M12, m.M21, m.M22 = f2dot14(offset), f2dot14(offset+2),
+				f2dot14(offset+4), f2dot14(offset+6)
+			offset += 8
+		}
+		m.DX, m.DY = dx, dy
+		fn(glyph, m)
+		if flags&moreComponents == 0 {
+			return
+		}
+	}
+}
+
+// componentGlyphs returns the glyphs used by a composite glyph, recursively.
+func (this *Font) componentGlyphs(glyph uint16, result map[uint16]bool, depth int) {
+	data := this.glyphData(glyph)
+	if data == nil || i16(data, 0) >= 0 || depth > maxCompositeDepth {
+		return
+	}
+	this.forEachComponent(data, func(component uint16, m geom.Affine) {
+		if !result[component] && int(component) < this.numGlyphs {
+			result[component] = true
+			this.componentGlyphs(component, result, depth+1)
+		}
+	})
+}
+
+type glyphPoint struct {
+	x, y    float64
+	onCurve bool
+}
+
+func appendSimpleGlyph(path *geom.Path, data []byte, contours int, m geom.Affine) {
+	offset := 10
+	if offset+2*contours+2 > len(data) {
+		return
+	}
+	ends := make([]int, contours)
+	for n := range ends {
+		ends[n] = int(u16(data, offset+2*n))
+	}
+	offset += 2 * contours
+	if contours == 0 {
+		return
+	}
+	count := ends[contours-1] + 1
+	offset += 2 + int(u16(data, offset)) //skip the instructions
+
+	flags := make([]byte, 0, count)
+	for len(flags) < count {
+		if offset >= len(data) {
+			return
+		}
+		flag := data[offset]
+		offset++
+		flags = append(flags, flag)
+		if flag&0x08 != 0 { //repeat
+			if offset >= len(data) {
+				return
+			}
+			for repeat := int(data[offset]); repeat > 0 && len(flags) < count; repeat-- {
+				flags = append(flags, flag)
+			}
+			offset++
+		}
+	}
+	points := make([]glyphPoint, count)
+	readCoords := func(shortFlag, sameFlag byte, set func(p *glyphPoint, v float64)) bool {
+		v := 0
+		for n, flag := range flags {
+			if flag&shortFlag != 0 {
+				if offset >= len(data) {
+					return false
+				}
+				d := int(data[offset])
+				offset++
+				if flag&sameFlag == 0 {
+					d = -d
+				}
+				v += d
+			} else if flag&sameFlag == 0 {
+				if offset+2 > len(data) {
+					return false
+				}
+				v += i16(data, offset)
+				offset += 2
+			}
+			set(&points[n], float64(v))
+		}
+		return true
+	}
+	if !readCoords(0x02, 0x10, func(p *glyphPoint, v float64) { p.x = v }) ||
+		!readCoords(0x04, 0x20, func(p *glyphPoint, v float64) { p.y = v }) {
+		return
+	}
+	for n := range points {
+		points[n].onCurve = flags[n]&0x01 != 0
+	}
+	start := 0
+	for _, end := range ends {
+		if end < start || end >= count {
+			return
+		}
+		appendContour(path, points[start:end+1], m)
+		start = end + 1
+	}
+}
+
+// appendContour adds a closed contour of quadratic curves.
+func appendContour(path *geom.Path, points []glyphPoint, m geom.Affine) {
+	count := len(points)
+	if count == 0 {
+		return
+	}
+	at := func(p glyphPoint) geom.Point {
+		return m.Transform(geom.Pt(p.x, p.y))
+	}
+	mid := func(a, b glyphPoint) glyphPoint {
+		return glyphPoint{(a.x + b.x) / 2, (a.y + b.y) / 2, true}
+	}
+	//start on an on-curve point, or between the first two off-curve points,
+	//and end back on it
+	var start glyphPoint
+	var sequence []glyphPoint
+	first := -1
+	for n, p := range points {
+		if p.onCurve {
+			first = n
+			break
+		}
+	}
+	if first >= 0 {
+		start = points[first]
+		sequence = append(append(sequence, points[first+1:]...), points[:first]...)
+	} else {
+		start = mid(points[0], points[1%count])
+		sequence = append(append(sequence, points[1:]...), points[0])
+	}
+	sequence = append(sequence, start)
+
+	path.StartFigure()
+	pt := at(start)
+	path.MoveTo(pt.X, pt.Y)
+	var control *glyphPoint
+	for n := range sequence {
+		p := sequence[n]
+		switch {
+		case p.onCurve && control == nil:
+			e := at(p)
+			path.LineTo(e.X, e.Y)
+		case p.onCurve:
+			c, e := at(*control), at(p)
+			path.QuadTo(c.X, c.Y, e.X, e.Y)
+			control = nil
+		default:
+			if control != nil {
+				c, e := at(*control), at(mid(*control, p))
+				path.QuadTo(c.X, c.Y, e.X, e.Y)
+			}
+			control = &sequence[n]
+		}
+	}
+	path.CloseFigure()
+}
@@ -0,0 +1,122 @@
+package truetype
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+// subsetTables are the tables kept by Subset: those needed to render glyphs by index.
+var subsetTables = []string{"cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "prep"}
+
+// Subset returns a font with only the outlines of the given glyphs,
+// the components of composite ones and the missing glyph 0.
+//
+// Glyph indexes are kept, unused glyphs are empty. The subset has no cmap,
+// as when embedded in documents referring to glyphs by index.
+func (this *Font) Subset(glyphs []uint16) []byte {
+	used := map[uint16]bool{0: true}
+	for _, g := range glyphs {
+		if int(g) < this.numGlyphs {
+			used[g] = true
+		}
+	}
+	for g := range used {
+		this.componentGlyphs(g, used, 0)
+	}
+
+	//glyf and loca, in the long format
+	var glyf []byte
+	loca := make([]byte, 4*(this.numGlyphs+1))
+	for g := 0; g < this.numGlyphs; g++ {
+		binary.BigEndian.PutUint32(loca[4*g:], uint32(len(glyf)))
+		if used[uint16(g)] {
+			glyf = append(glyf, this.glyphData(uint16(g))...)
+			for len(glyf)%4 != 0 {
+				glyf = append(glyf, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*this.numGlyphs:], uint32(len(glyf)))
+
+	//hmtx, with the metrics of unused glyphs zeroed
+	hmtx := append([]byte(nil), this.tables["hmtx"]...)
+	for n := 0; n < this.numHMetrics; n++ {
+		if !used[uint16(n)] && 4*n+4 <= len(hmtx) {
+			copy(hmtx[4*n:4*n+4], []byte{0, 0, 0, 0})
+		}
+	}
+
+	head := append([]byte(nil), this.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0) //checksumAdjustment, set below
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	tables := map[string][]byte{"glyf": glyf, "loca": loca, "hmtx": hmtx, "head": head}
+	for _, tag := range subsetTables {
+		if tables[tag] == nil && this.tables[tag] != nil {
+			tables[tag] = this.tables[tag]
+		}
+	}
+	data := writeFont(tables)
+
+	//the font checksum adjustment, from the checksum of the whole font
+	offset := int(u32(data, 12+16*tableIndex(tables, "head")+8))
+	binary.BigEndian.PutUint32(data[offset+8:], 0xb1b0afba-checksum(data))
+	return data
+}
+
+// tableIndex returns the index of a table in the sorted table directory.
+func tableIndex(tables map[string][]byte, tag string) int {
+	n := 0
+	for t := range tables {
+		if t < tag {
+			n++
+		}
+	}
+	return n
+}
+
+// writeFont writes an sfnt font with the given tables.
+func writeFont(tables map[string][]byte) []byte {
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	numTables := len(tags)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= numTables {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	data := make([]byte, 12+16*numTables)
+	binary.BigEndian.PutUint32(data, 0x00010000)
+	binary.BigEndian.PutUint16(data[4:], uint16(numTables))
+	binary.BigEndian.PutUint16(data[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(data[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(data[10:], uint16(16*numTables-searchRange))
+	for n, tag := range tags {
+		table := tables[tag]
+		record := data[12+16*n:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], checksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(data)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+		data = append(data, table...)
+		for len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+	}
+	return data
+}
+
+// checksum returns the sum of the big endian uint32 words of data, zero padded.
+func checksum(data []byte) uint32 {
+	var sum uint32
+	for n := 0; n < len(data); n += 4 {
+		var word [4]byte
+		copy(word[:], data[n:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
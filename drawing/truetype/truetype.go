@@ -0,0 +1,382 @@
+// Package truetype parses TrueType fonts in pure Go, for the backends
+// rendering text themselves: a Font is a canvas.Face giving glyph outlines
+// and metrics, and can be subset for embedding in documents.
+//
+// Fonts with PostScript outlines (OpenType CFF) are not supported.
+package truetype
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/drawing/geom"
+)
+
+// ErrFormat is returned for data that isn't a supported TrueType font.
+var ErrFormat = errors.New("truetype: unsupported or invalid font data")
+
+// Font is a parsed TrueType font.
+type Font struct {
+	data   []byte
+	tables map[string][]byte
+
+	unitsPerEm  int
+	locaLong    bool
+	numGlyphs   int
+	numHMetrics int
+	ascent      int
+	descent     int //positive, below the baseline
+	lineGap     int
+	bbox        [4]int //xMin, yMin, xMax, yMax
+	capHeight   int
+	italicAngle float64
+	fixedPitch  bool
+	weight      int
+	name        string
+
+	cmap func(r rune) uint16
+}
+
+var _ canvas.Face = (*Font)(nil)
+
+// Parse parses a TrueType font. The data must not be modified afterwards.
+func Parse(data []byte) (*Font, error) {
+	if len(data) < 12 {
+		return nil, ErrFormat
+	}
+	switch binary.BigEndian.Uint32(data) {
+	case 0x00010000, 0x74727565: //1.0, 'true'
+	default:
+		return nil, ErrFormat
+	}
+	numTables := int(u16(data, 4))
+	if len(data) < 12+16*numTables {
+		return nil, ErrFormat
+	}
+	f := &Font{data: data, tables: make(map[string][]byte)}
+	for n := 0; n < numTables; n++ {
+		record := data[12+16*n:]
+		tag := string(record[:4])
+		offset, length := int(u32(record, 8)), int(u32(record, 12))
+		if offset < 0 || length < 0 || offset+length > len(data) || offset+length < offset {
+			return nil, fmt.Errorf("truetype: table %q out of bounds", tag)
+		}
+		f.tables[tag] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf", "cmap"} {
+		if f.tables[tag] == nil {
+			return nil, fmt.Errorf("truetype: missing %q table", tag)
+		}
+	}
+	if err := f.parseMetrics(); err != nil {
+		return nil, err
+	}
+	if err := f.parseCmap(); err != nil {
+		return nil, err
+	}
+	f.name = f.parseName()
+	return f, nil
+}
+
+// ParseFile reads and parses a TrueType font file.
+func ParseFile(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func u16(b []byte, offset int) uint16 {
+	return binary.BigEndian.Uint16(b[offset:])
+}
+
+func i16(b []byte, offset int) int {
+	return int(int16(binary.BigEndian.Uint16(b[offset:])))
+}
+
+func u32(b []byte, offset int) uint32 {
+	return binary.BigEndian.Uint32(b[offset:])
+}
+
+func (this *Font) parseMetrics() error {
+	head, hhea, maxp := this.tables["head"], this.tables["hhea"], this.tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return ErrFormat
+	}
+	this.unitsPerEm = int(u16(head, 18))
+	if this.unitsPerEm == 0 {
+		return ErrFormat
+	}
+	this.bbox = [4]int{i16(head, 36), i16(head, 38), i16(head, 40), i16(head, 42)}
+	this.locaLong = i16(head, 50) == 1
+	this.ascent = i16(hhea, 4)
+	this.descent = -i16(hhea, 6)
+	this.lineGap = i16(hhea, 8)
+	this.numHMetrics = int(u16(hhea, 34))
+	this.numGlyphs = int(u16(maxp, 4))
+	if this.numHMetrics == 0 || len(this.tables["hmtx"]) < 4*this.numHMetrics {
+		return ErrFormat
+	}
+	this.capHeight = this.ascent
+	this.weight = 400
+	if os2 := this.tables["OS/2"]; len(os2) >= 78 {
+		this.weight = int(u16(os2, 4))
+		//prefer the typographic metrics if USE_TYPO_METRICS is set
+		if u16(os2, 62)&0x80 != 0 {
+			this.ascent, this.descent, this.lineGap = i16(os2, 68), -i16(os2, 70), i16(os2, 72)
+		}
+		if u16(os2, 0) >= 2 && len(os2) >= 90 {
+			this.capHeight = i16(os2, 88)
+		}
+	}
+	if post := this.tables["post"]; len(post) >= 16 {
+		this.italicAngle = float64(int32(u32(post, 4))) / 65536
+		this.fixedPitch = u32(post, 12) != 0
+	}
+	return nil
+}
+
+func (this *Font) parseCmap() error {
+	cmap := this.tables["cmap"]
+	if len(cmap) < 4 {
+		return ErrFormat
+	}
+	//prefer full unicode subtables
+	best, bestRank := -1, 0
+	for n := 0; n < int(u16(cmap, 2)); n++ {
+		if 4+8*n+8 > len(cmap) {
+			return ErrFormat
+		}
+		platform, encoding := u16(cmap, 4+8*n), u16(cmap, 4+8*n+2)
+		offset := int(u32(cmap, 4+8*n+4))
+		if offset+4 > len(cmap) {
+			continue
+		}
+		format := u16(cmap, offset)
+		rank := 0
+		switch {
+		case format == 12 && (platform == 3 && encoding == 10 || platform == 0):
+			rank = 3
+		case format == 4 && (platform == 3 && encoding == 1 || platform == 0):
+			rank = 2
+		case format == 4 && platform == 3 && encoding == 0: //symbol
+			rank = 1
+		}
+		if rank > bestRank {
+			best, bestRank = offset, rank
+		}
+	}
+	if best < 0 {
+		return errors.New("truetype: no supported cmap subtable")
+	}
+	sub := cmap[best:]
+	if u16(sub, 0) == 12 {
+		return this.parseCmap12(sub)
+	}
+	return this.parseCmap4(sub, bestRank == 1)
+}
+
+func (this *Font) parseCmap4(sub []byte, symbol bool) error {
+	if len(sub) < 14 {
+		return ErrFormat
+	}
+	segCount := int(u16(sub, 6)) / 2
+	if len(sub) < 16+8*segCount {
+		return ErrFormat
+	}
+	ends, starts := sub[14:], sub[16+2*segCount:]
+	deltas, rangeOffsets := sub[16+4*segCount:], sub[16+6*segCount:]
+	lookup := func(r rune) uint16 {
+		if r > 0xffff {
+			return 0
+		}
+		c := uint16(r)
+		n := sort.Search(segCount, func(n int) bool { return u16(ends, 2*n) >= c })
+		if n == segCount || u16(starts, 2*n) > c {
+			return 0
+		}
+		delta, rangeOffset := u16(deltas, 2*n), int(u16(rangeOffsets, 2*n))
+		if rangeOffset == 0 {
+			return c + delta
+		}
+		offset := 16 + 6*segCount + 2*n + rangeOffset + 2*int(c-u16(starts, 2*n))
+		if offset+2 > len(sub) {
+			return 0
+		}
+		if g := u16(sub, offset); g != 0 {
+			return g + delta
+		}
+		return 0
+	}
+	if symbol {
+		//symbol fonts map their codes to U+F000-U+F0FF
+		this.cmap = func(r rune) uint16 {
+			if g := lookup(r); g != 0 || r > 0xff {
+				return g
+			}
+			return lookup(0xf000 + r)
+		}
+	} else {
+		this.cmap = lookup
+	}
+	return nil
+}
+
+func (this *Font) parseCmap12(sub []byte) error {
+	if len(sub) < 16 {
+		return ErrFormat
+	}
+	count := int(u32(sub, 12))
+	if count < 0 || len(sub) < 16+12*count {
+		return ErrFormat
+	}
+	groups := sub[16:]
+	this.cmap = func(r rune) uint16 {
+		c := uint32(r)
+		n := sort.Search(count, func(n int) bool { return u32(groups, 12*n+4) >= c })
+		if n == count || u32(groups, 12*n) > c {
+			return 0
+		}
+		return uint16(u32(groups, 12*n+8) + c - u32(groups, 12*n))
+	}
+	return nil
+}
+
+// parseName returns the PostScript name of the font, or its family name without spaces.
+func (this *Font) parseName() string {
+	table := this.tables["name"]
+	if len(table) < 6 {
+		return "Font"
+	}
+	count, stringOffset := int(u16(table, 2)), int(u16(table, 4))
+	var family string
+	for n := 0; n < count && 6+12*n+12 <= len(table); n++ {
+		record := table[6+12*n:]
+		platform, nameID := u16(record, 0), u16(record, 6)
+		length, offset := int(u16(record, 8)), int(u16(record, 10))
+		start := stringOffset + offset
+		if start+length > len(table) || (nameID != 6 && nameID != 1) {
+			continue
+		}
+		raw := table[start : start+length]
+		var value string
+		if platform == 3 || platform == 0 {
+			units := make([]uint16, len(raw)/2)
+			for i := range units {
+				units[i] = u16(raw, 2*i)
+			}
+			value = string(utf16.Decode(units))
+		} else {
+			value = string(raw)
+		}
+		if nameID == 6 && value != "" {
+			return sanitizeName(value)
+		}
+		if family == "" {
+			family = value
+		}
+	}
+	if family != "" {
+		return sanitizeName(family)
+	}
+	return "Font"
+}
+
+// sanitizeName keeps the characters allowed in PostScript names.
+func sanitizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune("[](){}<>/%", r) {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// Name returns the PostScript name of the font.
+func (this *Font) Name() string {
+	return this.name
+}
+
+// UnitsPerEm returns the number of font units per em.
+func (this *Font) UnitsPerEm() int {
+	return this.unitsPerEm
+}
+
+// NumGlyphs returns the number of glyphs of the font.
+func (this *Font) NumGlyphs() int {
+	return this.numGlyphs
+}
+
+// GlyphIndex returns the glyph of a rune, or 0, the missing glyph, if the font has none.
+func (this *Font) GlyphIndex(r rune) uint16 {
+	g := this.cmap(r)
+	if int(g) >= this.numGlyphs {
+		return 0
+	}
+	return g
+}
+
+// GlyphAdvance returns the advance width of a glyph in font units.
+func (this *Font) GlyphAdvance(glyph uint16) int {
+	hmtx := this.tables["hmtx"]
+	n := int(glyph)
+	if n >= this.numHMetrics {
+		n = this.numHMetrics - 1
+	}
+	return int(u16(hmtx, 4*n))
+}
+
+// FontMetrics are the metrics of a font in font units, as used in font descriptors.
+type FontMetrics struct {
+	Ascent, Descent, LineGap int //descent is positive
+	CapHeight                int
+	BBox                     [4]int //xMin, yMin, xMax, yMax
+	ItalicAngle              float64
+	FixedPitch               bool
+	Weight                   int //100 to 900
+}
+
+// FontMetrics returns the metrics of the font in font units.
+func (this *Font) FontMetrics() FontMetrics {
+	return FontMetrics{
+		Ascent: this.ascent, Descent: this.descent, LineGap: this.lineGap,
+		CapHeight: this.capHeight, BBox: this.bbox,
+		ItalicAngle: this.italicAngle, FixedPitch: this.fixedPitch, Weight: this.weight,
+	}
+}
+
+// Metrics implements canvas.Face.
+func (this *Font) Metrics() canvas.FaceMetrics {
+	em := float64(this.unitsPerEm)
+	return canvas.FaceMetrics{
+		Ascent:  float64(this.ascent) / em,
+		Descent: float64(this.descent) / em,
+		LineGap: float64(this.lineGap) / em,
+	}
+}
+
+// Advance implements canvas.Face.
+func (this *Font) Advance(r rune) float64 {
+	if r == '\n' || r == '\r' {
+		return 0
+	}
+	return float64(this.GlyphAdvance(this.GlyphIndex(r))) / float64(this.unitsPerEm)
+}
+
+// Glyph implements canvas.Face.
+func (this *Font) Glyph(r rune) *geom.Path {
+	path := this.GlyphPath(this.GlyphIndex(r))
+	if path == nil {
+		return nil
+	}
+	s := 1 / float64(this.unitsPerEm)
+	path.Transform(geom.Scaling(s, -s))
+	return path
+}
@@ -2,7 +2,9 @@ package drawing
 
 import (
 	"github.com/zzl/go-gdiplus/gdip"
+	"github.com/zzl/goforms/framework/leaks"
 	"log"
+	"runtime"
 )
 
 func toRectF(rect Rect) RectF {
@@ -14,14 +16,48 @@ func toRectF(rect Rect) RectF {
 	}
 }
 
-func ensureOk(status gdip.Status) {
-	if status != gdip.Ok {
-		log.Fatalln("gdip status not ok:", status)
+// ErrorHandler handles the errors of GDI+ calls that can not be returned to a caller.
+type ErrorHandler func(err error)
+
+var errorHandler ErrorHandler = logError
+
+// SetErrorHandler sets the error handler and returns the previous one.
+// A nil handler restores the default handler, which logs the errors.
+// The forms package sets it to forms.ReportError.
+func SetErrorHandler(handler ErrorHandler) ErrorHandler {
+	oriHandler := errorHandler
+	if handler == nil {
+		handler = logError
+	}
+	errorHandler = handler
+	return oriHandler
+}
+
+// ReportError passes the error to the error handler. A nil error is ignored.
+func ReportError(err error) {
+	if err != nil {
+		errorHandler(err)
 	}
 }
 
+func logError(err error) {
+	log.Println("error:", err)
+}
+
 func checkStatus(status gdip.Status) {
 	if status != gdip.Ok {
-		println("gdip status not ok:", status)
+		ReportError(GdipError(status))
 	}
 }
+
+// setFinalizer makes the garbage collector dispose the object,
+// reporting it first when leak tracking is on.
+func setFinalizer[T any, PT interface {
+	*T
+	Dispose()
+}](obj PT) {
+	runtime.SetFinalizer(obj, func(obj PT) {
+		leaks.Finalized(obj)
+		obj.Dispose()
+	})
+}
@@ -54,6 +54,7 @@ type CatInterface interface {
 
 type CatObject struct {
 	AnimalObject
+	super *AnimalObject
 }
 
 func (this *CatObject) GetVoice() string {
@@ -81,6 +82,7 @@ type DogInterface interface {
 
 type DogObject struct {
 	AnimalObject
+	super *AnimalObject
 }
 
 func (this *DogObject) Say(something string) {
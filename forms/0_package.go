@@ -3,6 +3,7 @@ package forms
 import (
 	"github.com/zzl/go-win32api/v2/win32"
 	"github.com/zzl/goforms/forms/internal"
+	"github.com/zzl/goforms/framework/bus"
 	"log"
 	"runtime"
 	"unsafe"
@@ -28,7 +29,10 @@ func init() {
 	runtime.LockOSThread()
 	HInstance, _ = win32.GetModuleHandle(nil)
 
-	internal.ActivateCommonControlsV6IfNeeded()
+	if op, code := internal.ActivateCommonControlsV6IfNeeded(); op != "" {
+		ReportError(NewWin32Error(op, code))
+	}
+	bus.Default.SetDispatcher(Dispatcher)
 
 	hr := win32.CoInitializeEx(unsafe.Pointer(nil), win32.COINIT_APARTMENTTHREADED)
 	win32.ASSERT_SUCCEEDED(hr)
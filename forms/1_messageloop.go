@@ -2,19 +2,22 @@ package forms
 
 import (
 	"fmt"
+	"sync"
+
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/leaks"
 	"github.com/zzl/goforms/framework/utils"
-	"log"
 )
 
 type dispatcherImpl struct {
 	threadId uint32
-	chAction chan Action
+
+	mutex   sync.Mutex
+	actions []Action //unbounded, so that queuing never blocks the UI thread itself
 }
 
 func newDispatcherImpl() *dispatcherImpl {
 	d := &dispatcherImpl{}
-	d.chAction = make(chan Action, 256)
 	d.threadId = win32.GetCurrentThreadId()
 	return d
 }
@@ -22,14 +25,18 @@ func newDispatcherImpl() *dispatcherImpl {
 // Dispatcher is used to dispatch actions to be executed on the UI thread
 var Dispatcher = newDispatcherImpl()
 
-// Invoke executes the action on the UI thread
-func (this *dispatcherImpl) Invoke(action Action, optSync ...bool) {
+// Invoke executes the action on the UI thread.
+// Queuing the action never blocks, so it may be called on the UI thread too.
+// It returns a *Win32Error if the UI thread can not be notified,
+// in which case the action stays queued until the next successful Invoke
+// and a synchronous Invoke does not wait for it.
+func (this *dispatcherImpl) Invoke(action Action, optSync ...bool) error {
 	syncWait := utils.OptionalArgByVal(optSync)
 	var chWait chan struct{}
 	if syncWait {
 		if this.threadId == win32.GetCurrentThreadId() {
 			action()
-			return //
+			return nil
 		}
 		action0 := action
 		chWait = make(chan struct{})
@@ -38,24 +45,37 @@ func (this *dispatcherImpl) Invoke(action Action, optSync ...bool) {
 			close(chWait)
 		}
 	}
-	this.chAction <- action
+	this.mutex.Lock()
+	this.actions = append(this.actions, action)
+	this.mutex.Unlock()
 	ok, errno := win32.PostThreadMessage(this.threadId, WM_APP_DISPATCH, 0, 0)
 	if ok != win32.TRUE {
-		log.Panic(errno)
+		return NewWin32Error("PostThreadMessage", errno)
 	}
 	if chWait != nil {
 		<-chWait
 	}
+	return nil
+}
+
+// Post implements tasks.Dispatcher.Post.
+// Errors posting the action are passed to ReportError.
+func (this *dispatcherImpl) Post(action func()) {
+	ReportError(this.Invoke(action))
 }
 
 func (this *dispatcherImpl) check() {
 	for {
-		select {
-		case action := <-this.chAction:
-			action()
-		default:
+		this.mutex.Lock()
+		if len(this.actions) == 0 {
+			this.mutex.Unlock()
 			return
 		}
+		action := this.actions[0]
+		this.actions[0] = nil
+		this.actions = this.actions[1:]
+		this.mutex.Unlock()
+		action()
 	}
 }
 
@@ -99,20 +119,74 @@ func (this *msgPreprocessorsImpl) PreprocessMsg(msg *win32.MSG) bool {
 // MsgPreprocessors is a global registry to add or remove MsgPreprocessor implementations
 var MsgPreprocessors msgPreprocessorsImpl
 
+var lastIdleMsgPos uint32
+var lastIdleMsgType uint32
+
+// isIdleMessage reports whether processing the message should
+// trigger a new round of idle handling.
+func isIdleMessage(msg *win32.MSG) bool {
+	switch msg.Message {
+	case win32.WM_MOUSEMOVE, win32.WM_NCMOUSEMOVE:
+		pos := win32.GetMessagePos()
+		if msg.Message == lastIdleMsgType && pos == lastIdleMsgPos {
+			return false
+		}
+		lastIdleMsgType, lastIdleMsgPos = msg.Message, pos
+		return true
+	case win32.WM_PAINT, WM_SYSTIMER:
+		return false
+	}
+	return true
+}
+
+func hasPendingMessage() bool {
+	var msg win32.MSG
+	return win32.PeekMessage(&msg, 0, 0, 0, win32.PM_NOREMOVE) != win32.FALSE
+}
+
+// messageLoopDepth is the nesting level of running message loops.
+var messageLoopDepth int
+
+// endModalQuitCode is the WM_QUIT exit code ending the message loop of a modal window,
+// 0x454E444D being "ENDM" in ASCII. Any other exit code ending a modal loop is posted again
+// to quit the outer loops too, so App.Quit should not be called with this one.
+const endModalQuitCode = 0x454E444D
+
 // MessageLoop is the function that's typically called at the end of the main function
 // to pump windows messages to their desired target and bring the UI to life.
+// App.Run can be called instead, to fire the application events.
 func MessageLoop() {
+	runMessageLoop()
+	if messageLoopDepth == 0 && !App.running {
+		leaks.ReportLive("application exit")
+	}
+}
+
+// runMessageLoop runs a message loop until WM_QUIT, and returns its exit code.
+func runMessageLoop() int {
+	messageLoopDepth += 1
+	defer func() {
+		messageLoopDepth -= 1
+	}()
 	var msg win32.MSG
+	idle := true
 	for {
+		if idle && !hasPendingMessage() {
+			App.fireIdle()
+			idle = false
+		}
 		bRet, _ := win32.GetMessage(&msg, 0, 0, 0)
 		if bRet == 0 { //WM_QUIT
-			break
+			return int(int32(msg.WParam))
 		}
 		if bRet == -1 {
 			fmt.Println("??")
-			break
+			return -1
 		}
 		processMsg(&msg)
+		if isIdleMessage(&msg) {
+			idle = true
+		}
 	}
 }
 
@@ -123,7 +197,10 @@ func processMsg(msg *win32.MSG) {
 	}
 	toDispatch := true
 	if HWndActive != 0 {
-		if hAccelActive != 0 {
+		if accelTable := getActiveAccelTable(); accelTable != nil &&
+			accelTable.processChordKey(msg) {
+			toDispatch = false
+		} else if hAccelActive != 0 {
 			translated, _ := win32.TranslateAccelerator(
 				HWndActive, hAccelActive, msg)
 			if translated != 0 {
@@ -159,6 +236,19 @@ func processMsg(msg *win32.MSG) {
 	}
 }
 
+// getActiveAccelTable returns the accelerator table of the active top window
+func getActiveAccelTable() *AcceleratorTable {
+	win, ok := windowMap[HWndActive]
+	if !ok {
+		return nil
+	}
+	topWin, ok := win.(TopWindow)
+	if !ok {
+		return nil
+	}
+	return topWin.AsTopWindowObject().accelTable
+}
+
 // DoEvents processes all Windows messages currently in the message queue.
 func DoEvents() {
 	var msg win32.MSG
@@ -2,7 +2,6 @@ package forms
 
 import (
 	"github.com/zzl/go-win32api/v2/win32"
-	"log"
 	"syscall"
 )
 
@@ -15,9 +14,17 @@ type WinProcProvider interface {
 	WinProc(win *WindowObject, m *Message) error
 }
 
-// WndProc is the default window procedure function in GoForms
+// WndProc is the default window procedure function in GoForms.
+// Panics while processing messages are recovered and passed to App.PanicHandler,
+// unless in strict mode.
 func WndProc(hWnd HWND, uMsg uint32,
-	wParam WPARAM, lParam LPARAM) win32.LRESULT {
+	wParam WPARAM, lParam LPARAM) (result win32.LRESULT) {
+	defer func() {
+		if value := recover(); value != nil {
+			App.recoverPanic(value, hWnd, uMsg)
+			result = 0
+		}
+	}()
 
 	switch uMsg {
 	case win32.WM_SETFOCUS:
@@ -38,11 +45,11 @@ func WndProc(hWnd HWND, uMsg uint32,
 	if ok {
 		winObj = win.AsWindowObject()
 	} else {
-		// return win32.DefWindowProc(hWnd, uMsg, wParam, lParam)
-		winObj = creatingWindows[len(creatingWindows)-1]
-		if winObj.Handle != 0 {
-			log.Panic("something wrong")
+		if len(creatingWindows) == 0 || creatingWindows[len(creatingWindows)-1].Handle != 0 {
+			ReportError(ErrUnknownWindow)
+			return win32.DefWindowProc(hWnd, uMsg, wParam, lParam)
 		}
+		winObj = creatingWindows[len(creatingWindows)-1]
 		winObj.Handle = hWnd
 		windowMap[hWnd] = winObj
 	}
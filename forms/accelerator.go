@@ -1,7 +1,9 @@
 package forms
 
 import (
-	"log"
+	"fmt"
+
+	"github.com/zzl/goforms/framework/keys"
 
 	"github.com/zzl/go-win32api/v2/win32"
 )
@@ -14,6 +16,8 @@ type Accelerator struct {
 	Shift bool
 	Key   byte
 
+	Chord KeyChord //multi-stroke key sequence, overrides the keys above if set
+
 	Command *Command
 	Action  Action
 }
@@ -24,6 +28,8 @@ type AcceleratorTable struct {
 
 	idGen          UidGen
 	OnHandleChange SimpleEvent
+
+	chordMatcher keys.ChordMatcher[*Accelerator]
 }
 
 func NewAcceleratorTable() *AcceleratorTable {
@@ -56,10 +62,16 @@ func (this *AcceleratorTable) ReCreate() error {
 func (this *AcceleratorTable) Create() error {
 	var accels []win32.ACCEL
 
+	this.chordMatcher.ClearBindings()
+	this.chordMatcher.Reset()
 	for _, item := range this.Items {
 		if item.CmdId == 0 {
 			item.CmdId = uint16(this.idGen.Gen())
 		}
+		if len(item.Chord) > 1 {
+			this.chordMatcher.Bind(item.Chord, item)
+			continue
+		}
 		accel := win32.ACCEL{}
 
 		ctrl := item.Ctrl
@@ -92,20 +104,50 @@ func (this *AcceleratorTable) Create() error {
 			accel.Key = uint16(key)
 		} else {
 			//accel.Key = uint16(item.Char)
-			log.Fatal("key undefined")
+			return fmt.Errorf("accelerator for command id %d: key undefined", item.CmdId)
 		}
 		accel.Cmd = item.CmdId
 		accels = append(accels, accel)
 	}
+	if len(accels) == 0 {
+		this.Handle = 0
+		return nil
+	}
 
 	hAccel, errno := win32.CreateAcceleratorTable(&accels[0], int32(len(accels)))
 	if hAccel == 0 {
-		log.Fatal(errno)
+		return NewWin32Error("CreateAcceleratorTable", errno)
 	}
 	this.Handle = hAccel
 	return nil
 }
 
+// processChordKey feeds a key message to the multi-stroke accelerators.
+// It returns true if the message is consumed.
+func (this *AcceleratorTable) processChordKey(msg *win32.MSG) bool {
+	if !this.chordMatcher.HasBindings() {
+		return false
+	}
+	ks, ok := keyStrokeFromMsg(msg)
+	if !ok {
+		return false
+	}
+	result, accel := this.chordMatcher.Feed(ks)
+	switch result {
+	case keys.ChordMatched:
+		this.execute(accel)
+		return true
+	case keys.ChordPending, keys.ChordCanceled:
+		return true
+	}
+	return false
+}
+
+// GetPendingChord returns the key strokes of a partially entered chord.
+func (this *AcceleratorTable) GetPendingChord() KeyChord {
+	return this.chordMatcher.Pending()
+}
+
 func (this *AcceleratorTable) FindById(id uint16) *Accelerator {
 	for _, it := range this.Items {
 		if it.CmdId == id {
@@ -139,7 +181,10 @@ func (this *AcceleratorTable) onReflectCommand(msg *CommandMessage) {
 	if accel == nil {
 		return
 	}
+	this.execute(accel)
+}
 
+func (this *AcceleratorTable) execute(accel *Accelerator) {
 	command := accel.Command
 	if command != nil {
 		command.NotifyExecute()
@@ -153,6 +198,24 @@ func (this *AcceleratorTable) ClearItems() {
 	this.Items = nil
 }
 
+// syncCommands replaces the accelerators of the commands with their current shortcuts,
+// and recreates the table.
+func (this *AcceleratorTable) syncCommands(commands []*Command) error {
+	synced := make(map[*Command]bool, len(commands))
+	for _, command := range commands {
+		synced[command] = true
+	}
+	var items []*Accelerator
+	for _, item := range this.Items {
+		if item.Command == nil || !synced[item.Command] {
+			items = append(items, item)
+		}
+	}
+	this.Items = items
+	this.AddItemsFromCommand(commands)
+	return this.ReCreate()
+}
+
 func (this *AcceleratorTable) AddItemsFromCommand(commands []*Command) {
 	for _, cmd := range commands {
 		for _, sk := range cmd.ShortcutKeys {
@@ -166,5 +229,17 @@ func (this *AcceleratorTable) AddItemsFromCommand(commands []*Command) {
 			}
 			this.Items = append(this.Items, item)
 		}
+		for _, chord := range cmd.ShortcutChords {
+			item := &Accelerator{
+				CmdId:   uint16(cmd.Id),
+				Chord:   chord,
+				Command: cmd,
+			}
+			if len(chord) == 1 {
+				item.Ctrl, item.Alt, item.Shift, item.Key =
+					chord[0].Ctrl, chord[0].Alt, chord[0].Shift, chord[0].Key
+			}
+			this.Items = append(this.Items, item)
+		}
 	}
 }
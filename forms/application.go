@@ -0,0 +1,132 @@
+package forms
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/leaks"
+)
+
+// QuitPolicy decides when the application quits.
+type QuitPolicy int
+
+const (
+	QuitOnLastWindowClosed QuitPolicy = iota //quit when the last top window is destroyed
+	QuitOnMainWindowClosed                   //quit when the main window is destroyed
+	QuitExplicitly                           //quit only when Application.Quit is called
+)
+
+// PanicError is the error of a panic recovered in WndProc.
+type PanicError struct {
+	Value any
+	Stack []byte
+	HWnd  HWND   //the window processing the message
+	Msg   uint32 //the message being processed
+}
+
+func (this *PanicError) Error() string {
+	return fmt.Sprintf("panic processing message 0x%04x: %v", this.Msg, this.Value)
+}
+
+// Application manages the lifecycle of the application.
+// Calling App.Run is an alternative to calling MessageLoop directly.
+type Application struct {
+	MainWindow TopWindow
+	QuitPolicy QuitPolicy
+
+	// PanicHandler is called with the panics recovered in WndProc.
+	// If nil, the panics are passed to ReportError.
+	// In strict mode, the panics are not recovered.
+	PanicHandler func(err *PanicError)
+
+	OnStartup  SimpleEvent //fired before the message loop starts
+	OnShutdown SimpleEvent //fired after the message loop ends
+	OnIdle     SimpleEvent //fired when the message queue becomes empty, requerying the commands
+
+	running  bool
+	exitCode int
+}
+
+// App is the application.
+var App = &Application{}
+
+// Run shows the main window, if specified or set, and runs the message loop
+// until the application quits. It returns the exit code passed to Quit.
+func (this *Application) Run(mainWindow ...TopWindow) int {
+	if len(mainWindow) > 0 {
+		this.MainWindow = mainWindow[0]
+	}
+	this.running = true
+	this.OnStartup.Fire(this, &SimpleEventInfo{})
+	if this.MainWindow != nil && !this.MainWindow.IsVisible() {
+		this.MainWindow.Show()
+	}
+	this.exitCode = runMessageLoop()
+	this.OnShutdown.Fire(this, &SimpleEventInfo{})
+	this.running = false
+	if messageLoopDepth == 0 {
+		leaks.ReportLive("application exit")
+	}
+	return this.exitCode
+}
+
+// IsRunning tells whether Run is in progress.
+func (this *Application) IsRunning() bool {
+	return this.running
+}
+
+// Quit ends the message loop with the exit code.
+func (this *Application) Quit(exitCode int) {
+	win32.PostQuitMessage(int32(exitCode))
+}
+
+// DoEvents processes the messages currently in the message queue.
+func (this *Application) DoEvents() {
+	DoEvents()
+}
+
+// TopWindows returns the top windows of the UI thread.
+func (this *Application) TopWindows() []Window {
+	return GetTopWindows()
+}
+
+// ActiveWindow returns the active top window, or nil.
+func (this *Application) ActiveWindow() Window {
+	return GetActiveWin()
+}
+
+func (this *Application) fireIdle() {
+	this.OnIdle.Fire(this, &SimpleEventInfo{})
+}
+
+// onTopWindowDestroy applies the quit policy when a top window is being destroyed.
+func (this *Application) onTopWindowDestroy(win TopWindow) {
+	switch this.QuitPolicy {
+	case QuitOnMainWindowClosed:
+		if this.MainWindow != nil {
+			if this.MainWindow.GetHandle() == win.GetHandle() {
+				this.Quit(0)
+			}
+			return
+		}
+		fallthrough
+	case QuitOnLastWindowClosed:
+		if len(GetTopWindows()) == 1 {
+			this.Quit(0)
+		}
+	}
+}
+
+// recoverPanic handles a panic recovered in WndProc.
+func (this *Application) recoverPanic(value any, hWnd HWND, uMsg uint32) {
+	if StrictMode {
+		panic(value)
+	}
+	err := &PanicError{Value: value, Stack: debug.Stack(), HWnd: hWnd, Msg: uMsg}
+	if this.PanicHandler != nil {
+		this.PanicHandler(err)
+	} else {
+		ReportError(err)
+	}
+}
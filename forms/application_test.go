@@ -0,0 +1,75 @@
+package forms
+
+import (
+	"testing"
+
+	"github.com/zzl/go-win32api/v2/win32"
+)
+
+func TestRunExitCode(t *testing.T) {
+	var log []string
+	pStartup := App.OnStartup.AddListener(func(ei *SimpleEventInfo) {
+		log = append(log, "startup")
+		if !App.IsRunning() {
+			t.Error("not running on startup")
+		}
+	})
+	//the idle event fires once the startup messages are processed
+	pIdle := App.OnIdle.AddListener(func(ei *SimpleEventInfo) {
+		log = append(log, "idle")
+		App.Quit(3)
+	})
+	pShutdown := App.OnShutdown.AddListener(func(ei *SimpleEventInfo) {
+		log = append(log, "shutdown")
+	})
+	defer func() {
+		App.OnStartup.RemoveListener(pStartup)
+		App.OnIdle.RemoveListener(pIdle)
+		App.OnShutdown.RemoveListener(pShutdown)
+	}()
+
+	if exitCode := App.Run(); exitCode != 3 {
+		t.Errorf("exit code %d, want 3", exitCode)
+	}
+	if len(log) != 3 || log[0] != "startup" || log[1] != "idle" || log[2] != "shutdown" {
+		t.Errorf("events %v, want [startup idle shutdown]", log)
+	}
+	if App.IsRunning() {
+		t.Error("running after Run returned")
+	}
+}
+
+func TestEndModalQuitCode(t *testing.T) {
+	//the modal quit code survives the round trip through WM_QUIT
+	win32.PostQuitMessage(endModalQuitCode)
+	if exitCode := runMessageLoop(); exitCode != endModalQuitCode {
+		t.Errorf("exit code 0x%X, want 0x%X", exitCode, endModalQuitCode)
+	}
+}
+
+func TestRecoverPanic(t *testing.T) {
+	var got *PanicError
+	App.PanicHandler = func(err *PanicError) {
+		got = err
+	}
+	defer func() {
+		App.PanicHandler = nil
+	}()
+	App.recoverPanic("boom", 0, 0x0201)
+	if got == nil || got.Value != "boom" || got.Msg != 0x0201 || len(got.Stack) == 0 {
+		t.Fatalf("panic error %+v", got)
+	}
+	if got.Error() != "panic processing message 0x0201: boom" {
+		t.Errorf("Error() = %q", got.Error())
+	}
+
+	StrictMode = true
+	defer func() {
+		StrictMode = false
+		if value := recover(); value != "strict" {
+			t.Errorf("recovered %v, want the panic rethrown in strict mode", value)
+		}
+	}()
+	App.recoverPanic("strict", 0, 0)
+	t.Error("the panic was not rethrown in strict mode")
+}
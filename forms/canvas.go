@@ -0,0 +1,27 @@
+package forms
+
+import (
+	"github.com/zzl/go-gdiplus/gdip"
+	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing"
+	"github.com/zzl/goforms/drawing/canvas"
+	"github.com/zzl/goforms/framework/scope"
+)
+
+// PaintCanvas calls paint with an anti-aliased canvas drawing on a device context,
+// typically the one passed to CustomWindowSpi.OnPaint.
+// Painting code written against canvas.Canvas can then be rendered
+// to images by drawing/raster, e.g. in tests.
+func PaintCanvas(hdc win32.HDC, paint func(c canvas.Canvas)) error {
+	s := scope.NewScope()
+	defer s.Leave()
+	g, err := drawing.NewGraphicsFromHdc(s, hdc)
+	if err != nil {
+		return err
+	}
+	g.SetSmoothingMode(gdip.SmoothingModeAntiAlias)
+	g.SetPixelOffsetMode(gdip.PixelOffsetModeHalf)
+	g.SetTextRenderingHint(gdip.TextRenderingHintAntiAlias)
+	paint(drawing.NewGraphicsCanvas(s, g))
+	return nil
+}
@@ -5,7 +5,6 @@ import (
 	"github.com/zzl/goforms/framework/types"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"syscall"
 	"unsafe"
 
@@ -48,6 +47,7 @@ type ComboBoxInterface interface {
 
 type ComboBoxObject struct {
 	ControlObject
+	super *ControlObject
 
 	ForeColorAwareSupport
 
@@ -106,7 +106,8 @@ func (this *ComboBoxObject) ComboBoxObj() *ComboBoxObject {
 func (this *ComboBoxObject) GetItemCount() int {
 	retVal, errono := SendMessage(this.Handle, win32.CB_GETCOUNT, 0, 0)
 	if int32(retVal) == win32.CB_ERR {
-		log.Fatal(errono)
+		reportWin32Error("CB_GETCOUNT", errono)
+		return 0
 	}
 	return int(retVal)
 }
@@ -121,7 +122,8 @@ func (this *ComboBoxObject) AddStringItem(item string) {
 	index, errno := SendMessage(this.Handle, win32.CB_ADDSTRING,
 		0, unsafe.Pointer(pwszText))
 	if int32(index) == win32.CB_ERR {
-		log.Fatal(errno)
+		reportWin32Error("CB_ADDSTRING", errno)
+		return
 	}
 	this.values = append(this.values, consts.Null)
 }
@@ -138,7 +140,8 @@ func (this *ComboBoxObject) AddItem(item types.ValueText) {
 	index, errno := SendMessage(this.Handle, win32.CB_ADDSTRING,
 		0, unsafe.Pointer(pwszText))
 	if int32(index) == win32.CB_ERR {
-		log.Fatal(errno)
+		reportWin32Error("CB_ADDSTRING", errno)
+		return
 	}
 	this.values = append(this.values, item.Value)
 	//_, _ = win32.SendMessage(this.Handle, win32.CB_SETITEMDATA,
@@ -262,7 +265,7 @@ func (this *ComboBoxObject) SetText(text string) {
 	if int32(index) == win32.CB_ERR {
 		bOk, errno := SetWindowText(this.Handle, text)
 		if !bOk {
-			log.Fatal(errno)
+			reportWin32Error("SetWindowText", errno)
 		}
 	} else {
 		this.SetSelectedIndex(int(index))
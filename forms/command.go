@@ -1,5 +1,10 @@
 package forms
 
+import (
+	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/keys"
+)
+
 type Command struct {
 	Id       int
 	Name     string
@@ -14,10 +19,16 @@ type Command struct {
 	RadioGroup string
 	Checked    bool //u
 
-	ShortcutKeys []KeyStroke
+	ShortcutKeys   []KeyStroke
+	ShortcutChords []KeyChord //multi-stroke shortcuts, such as Ctrl+K, Ctrl+C
+
+	CanExecute func() bool //if set, Disabled is kept in sync with !CanExecute() on requery
+	IsChecked  func() bool //if set, Checked is kept in sync with IsChecked() on requery
 
 	OnChange  SimpleEvent
 	OnExecute SimpleEvent
+
+	manager *CommandManager
 }
 
 func NewCommand(text string, action Action) *Command {
@@ -32,7 +43,13 @@ type CommandAware interface {
 type CommandManager struct {
 	Items []*Command
 
-	idItemMap map[int]*Command
+	idItemMap    map[int]*Command
+	requerying   bool
+	idleListener *SimpleEventListener
+	accelTables  []*AcceleratorTable
+	chordMatcher keys.ChordMatcher[*Command]
+
+	defaultKeymap Keymap
 }
 
 func NewCommandManager() *CommandManager {
@@ -59,6 +76,27 @@ func (this *Command) GetNoPrefixText() string {
 	return string(bts)
 }
 
+// GetShortcuts returns all the shortcuts of the command as chords,
+// single key strokes first.
+func (this *Command) GetShortcuts() []KeyChord {
+	var chords []KeyChord
+	for _, ks := range this.ShortcutKeys {
+		chords = append(chords, KeyChord{ks})
+	}
+	return append(chords, this.ShortcutChords...)
+}
+
+// ShortcutText returns the display text of the first shortcut, or "" if none.
+func (this *Command) ShortcutText() string {
+	if len(this.ShortcutKeys) > 0 {
+		return this.ShortcutKeys[0].DisplayString()
+	}
+	if len(this.ShortcutChords) > 0 {
+		return this.ShortcutChords[0].DisplayString()
+	}
+	return ""
+}
+
 func (this *Command) SetState(disabled bool, checked bool) {
 	changed := false
 	if disabled != this.Disabled {
@@ -68,41 +106,135 @@ func (this *Command) SetState(disabled bool, checked bool) {
 	if checked != this.Checked {
 		this.Checked = checked
 		changed = true
+		if checked && this.manager != nil {
+			this.manager.uncheckRadioSiblings(this)
+		}
 	}
 	if changed {
 		this.NotifyChange()
 	}
 }
 
+// Requery re-evaluates the CanExecute and IsChecked predicates
+// and updates the command state accordingly.
+func (this *Command) Requery() {
+	disabled, checked := this.queryState()
+	this.SetState(disabled, checked)
+}
+
+func (this *Command) queryState() (disabled bool, checked bool) {
+	disabled, checked = this.Disabled, this.Checked
+	if this.CanExecute != nil {
+		disabled = !this.CanExecute()
+	}
+	if this.IsChecked != nil {
+		checked = this.IsChecked()
+	}
+	return
+}
+
 func (this *Command) NotifyChange() {
 	this.OnChange.Fire(this, &SimpleEventInfo{})
 }
 
 func (this *Command) NotifyExecute() {
+	if this.CanExecute != nil && !this.CanExecute() {
+		return
+	}
 	if this.Action != nil {
 		this.Action()
 	}
 	this.OnExecute.Fire(this, &SimpleEventInfo{})
+	if this.manager != nil {
+		this.manager.Requery()
+	}
 }
 
-//
 func (this *CommandManager) Init() {
 	this.idItemMap = make(map[int]*Command)
+	this.idleListener = App.OnIdle.AddListener(func(ei *SimpleEventInfo) {
+		this.Requery()
+	})
+}
+
+func (this *CommandManager) Dispose() {
+	App.OnIdle.RemoveListener(this.idleListener)
 }
 
 func (this *CommandManager) AddItems(items []*Command) {
 	this.Items = append(this.Items, items...)
 	for _, item := range items {
+		item.manager = this
 		if item.Id != 0 {
 			this.idItemMap[item.Id] = item
 		}
 	}
+	this.addDefaultShortcuts(items)
+	this.RebindShortcuts()
 }
 
 func (this *CommandManager) Item(id int) *Command {
 	return this.idItemMap[id]
 }
 
+// Requery re-evaluates the state predicates of all commands.
+// It is called on App.OnIdle and after a command executes.
+// Within a radio group, a command checked by its IsChecked predicate
+// takes over one only checked by its Checked field; otherwise the currently
+// checked command is kept if it still qualifies, or the first qualifying one wins.
+func (this *CommandManager) Requery() {
+	if this.requerying {
+		return
+	}
+	this.requerying = true
+	defer func() {
+		this.requerying = false
+	}()
+
+	count := len(this.Items)
+	disabledStates := make([]bool, count)
+	checkedStates := make([]bool, count)
+	groupWinners := make(map[string]*Command)
+	for n, item := range this.Items {
+		disabledStates[n], checkedStates[n] = item.queryState()
+		if item.RadioGroup == "" || !checkedStates[n] {
+			continue
+		}
+		winner := groupWinners[item.RadioGroup]
+		if winner == nil || radioPrecedes(item, winner) {
+			groupWinners[item.RadioGroup] = item
+		}
+	}
+	for n, item := range this.Items {
+		checked := checkedStates[n]
+		if checked && item.RadioGroup != "" {
+			checked = groupWinners[item.RadioGroup] == item
+		}
+		item.SetState(disabledStates[n], checked)
+	}
+}
+
+// radioPrecedes tells whether a command reporting itself checked
+// wins its radio group over the command winning it so far.
+func radioPrecedes(item, winner *Command) bool {
+	if (item.IsChecked != nil) != (winner.IsChecked != nil) {
+		return item.IsChecked != nil
+	}
+	return item.Checked && !winner.Checked
+}
+
+func (this *CommandManager) uncheckRadioSiblings(command *Command) {
+	if command.RadioGroup == "" {
+		return
+	}
+	for _, item := range this.Items {
+		if item != command && item.Checked && item.RadioGroup == command.RadioGroup {
+			item.Checked = false
+			item.NotifyChange()
+		}
+	}
+}
+
 func shortcutKeysChanged(keys1 []KeyStroke, keys2 []KeyStroke) bool {
 	count := len(keys1)
 	if count != len(keys2) {
@@ -124,4 +256,54 @@ func (this *CommandManager) SetShortcuts(shortcuts map[int][]KeyStroke) {
 			item.NotifyChange()
 		}
 	}
+	this.RebindShortcuts()
+}
+
+// RebindShortcuts rebuilds the bindings PreprocessMsg matches key strokes against,
+// and the accelerator tables bound by BindAcceleratorTable.
+// AddItems, SetShortcuts and ApplyKeymap call it; call it after changing
+// the ShortcutKeys or ShortcutChords of commands directly.
+func (this *CommandManager) RebindShortcuts() {
+	this.chordMatcher.ClearBindings()
+	for _, item := range this.Items {
+		for _, chord := range item.GetShortcuts() {
+			this.chordMatcher.Bind(chord, item)
+		}
+	}
+	for _, table := range this.accelTables {
+		ReportError(table.syncCommands(this.Items))
+	}
+}
+
+// BindAcceleratorTable adds accelerators for the shortcuts of the commands to a table
+// and recreates it, then keeps them in sync when the shortcuts change.
+func (this *CommandManager) BindAcceleratorTable(table *AcceleratorTable) error {
+	this.accelTables = append(this.accelTables, table)
+	return table.syncCommands(this.Items)
+}
+
+// PreprocessMsg implements MsgPreprocessor.PreprocessMsg.
+// Add the manager to MsgPreprocessors to execute commands by their shortcuts,
+// including multi-stroke chords, without an AcceleratorTable.
+func (this *CommandManager) PreprocessMsg(msg *win32.MSG) bool {
+	ks, ok := keyStrokeFromMsg(msg)
+	if !ok {
+		return false
+	}
+	result, command := this.chordMatcher.Feed(ks)
+	switch result {
+	case keys.ChordMatched:
+		if !command.Disabled {
+			command.NotifyExecute()
+		}
+		return true
+	case keys.ChordPending, keys.ChordCanceled:
+		return true
+	}
+	return false
+}
+
+// GetPendingChord returns the key strokes of a partially entered chord.
+func (this *CommandManager) GetPendingChord() KeyChord {
+	return this.chordMatcher.Pending()
 }
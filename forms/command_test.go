@@ -0,0 +1,115 @@
+package forms
+
+import "testing"
+
+func TestRequeryPredicates(t *testing.T) {
+	canExecute, isChecked := false, true
+	command := &Command{
+		CanExecute: func() bool { return canExecute },
+		IsChecked:  func() bool { return isChecked },
+	}
+	changes := 0
+	command.OnChange.AddListener(func(ei *SimpleEventInfo) {
+		changes += 1
+	})
+	manager := NewCommandManager()
+	defer manager.Dispose()
+	manager.AddItems([]*Command{command})
+
+	manager.Requery()
+	if !command.Disabled || !command.Checked || changes != 1 {
+		t.Fatalf("disabled %v, checked %v, %d changes, want true, true, 1",
+			command.Disabled, command.Checked, changes)
+	}
+	manager.Requery()
+	if changes != 1 {
+		t.Errorf("%d changes requerying an unchanged state, want 1", changes)
+	}
+	canExecute, isChecked = true, false
+	App.fireIdle()
+	if command.Disabled || command.Checked || changes != 2 {
+		t.Errorf("after idle: disabled %v, checked %v, %d changes, want false, false, 2",
+			command.Disabled, command.Checked, changes)
+	}
+
+	//commands without predicates keep their state
+	plain := &Command{Disabled: true, Checked: true}
+	manager.AddItems([]*Command{plain})
+	manager.Requery()
+	if !plain.Disabled || !plain.Checked {
+		t.Errorf("plain command: disabled %v, checked %v, want true, true", plain.Disabled, plain.Checked)
+	}
+}
+
+func TestExecuteRequeries(t *testing.T) {
+	count := 0
+	increment := &Command{Action: func() { count += 1 }}
+	atTwo := &Command{IsChecked: func() bool { return count == 2 }}
+	manager := NewCommandManager()
+	defer manager.Dispose()
+	manager.AddItems([]*Command{increment, atTwo})
+
+	increment.NotifyExecute()
+	increment.NotifyExecute()
+	if !atTwo.Checked {
+		t.Error("executing a command didn't requery the others")
+	}
+	increment.CanExecute = func() bool { return false }
+	increment.NotifyExecute()
+	if count != 2 {
+		t.Errorf("count = %d, a command that can't execute ran", count)
+	}
+}
+
+// checkedNames returns the names of the checked commands.
+func checkedNames(commands []*Command) []string {
+	var names []string
+	for _, command := range commands {
+		if command.Checked {
+			names = append(names, command.Name)
+		}
+	}
+	return names
+}
+
+func TestRadioGroup(t *testing.T) {
+	mode := "b"
+	radio := func(name string) *Command {
+		return &Command{Name: name, RadioGroup: "mode",
+			IsChecked: func() bool { return mode == name || mode == "all" }}
+	}
+	a, b, c := radio("a"), radio("b"), radio("c")
+	manual := &Command{Name: "manual", RadioGroup: "mode"}
+	other := &Command{Name: "other", RadioGroup: "other", Checked: true}
+	commands := []*Command{a, b, c, manual, other}
+	manager := NewCommandManager()
+	defer manager.Dispose()
+	manager.AddItems(commands)
+
+	tests := []struct {
+		name  string
+		setup func()
+		want  []string
+	}{
+		{"predicate", func() {}, []string{"b", "other"}},
+		{"predicate moves", func() { mode = "c" }, []string{"c", "other"}},
+		{"current kept", func() { mode = "all" }, []string{"c", "other"}},
+		{"first qualifying", func() { c.IsChecked = func() bool { return false } }, []string{"a", "other"}},
+		{"checked by hand", func() { mode = ""; manual.SetState(false, true) }, []string{"manual", "other"}},
+		//a predicate says more than a Checked field set by hand
+		{"predicate over manual", func() { mode = "b" }, []string{"b", "other"}},
+		{"set state unchecks siblings", func() {
+			mode = ""
+			manager.Requery()
+			manual.SetState(false, true)
+		}, []string{"manual", "other"}},
+	}
+	for _, test := range tests {
+		test.setup()
+		manager.Requery()
+		got := checkedNames(commands)
+		if len(got) != len(test.want) || got[0] != test.want[0] || got[1] != test.want[1] {
+			t.Errorf("%s: checked %v, want %v", test.name, got, test.want)
+		}
+	}
+}
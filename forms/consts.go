@@ -15,6 +15,9 @@ const WM_REFLECT_DRAWITEM = win32.WM_APP + 67
 
 const WM_APP_DISPATCH = win32.WM_APP + 88
 
+// WM_SYSTIMER is the undocumented message used for caret blinking
+const WM_SYSTIMER = 0x0118
+
 const WM_CHILD_SETFOCUS = win32.WM_APP + 100
 const WM_CHILD_KILLFOCUS = win32.WM_APP + 101
 
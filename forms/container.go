@@ -2,7 +2,6 @@ package forms
 
 import (
 	"github.com/zzl/goforms/drawing/colors"
-	"log"
 	"syscall"
 
 	"github.com/zzl/go-win32api/v2/win32"
@@ -146,7 +145,8 @@ func (this *ContainerObject) EnsureClassRegistered() {
 		BackgroundBrush: 0,
 	})
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 	_containerClassRegstered = true
 }
@@ -269,9 +269,10 @@ func (this *ContainerObject) GetLayout() Layout {
 }
 
 // SetLayout implements Container.SetLayout.
+// Errors resolving the layout items are passed to ReportError.
 func (this *ContainerObject) SetLayout(layout Layout) {
 	this.Layout = layout
-	layout.SetContainer(LayoutContainer{this})
+	ReportError(layout.SetContainer(LayoutContainer{this}))
 }
 
 // UpdateLayout implements Container.UpdateLayout.
@@ -300,8 +301,9 @@ func (this *ContainerObject) Add(controls ...Control) {
 		hWndSet[c.GetHandle()] = true
 	}
 	for _, c := range controls {
-		if c.GetHandle() == 0 { //?
-			log.Panic("????")
+		if c.GetHandle() == 0 {
+			ReportError(ErrNotCreated)
+			continue
 		}
 		if hWndSet[c.GetHandle()] { //already exist
 			continue
@@ -13,6 +13,7 @@ type ContainerControl interface {
 
 type ContainerControlObject struct {
 	ContainerObject
+	super *ContainerObject
 
 	NameAwareSupport
 }
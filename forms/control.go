@@ -122,9 +122,7 @@ func createControlIn(parentWin Window, controlWin Window,
 	opts := utils.OptionalArg(extraOpts)
 	opts.ParentHandle = resolveParentHandle(parentWin)
 	err := controlWin.Create(*opts)
-	if err != nil {
-		log.Fatal(err)
-	}
+	assertNoErr(err)
 	return controlWin.(Control)
 }
 
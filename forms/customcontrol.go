@@ -89,9 +89,7 @@ func (this *CustomControlObject) EnsureClassRegistered() {
 	_, err := RegisterClass(customControlClass, nil, ClassOptions{
 		BackgroundBrush: 0,
 	})
-	if err != nil {
-		log.Fatal(err)
-	}
+	ReportError(err)
 }
 
 func (this *CustomControlObject) WinProc(win *WindowObject, m *Message) error {
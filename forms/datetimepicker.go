@@ -33,7 +33,8 @@ func (this *DateTimePickerObject) GetValue() any {
 	ret, errno := SendMessage(this.Handle, win32.DTM_GETSYSTEMTIME,
 		0, unsafe.Pointer(&st))
 	if int32(ret) == win32.GDT_ERROR {
-		log.Fatal(errno)
+		reportWin32Error("DTM_GETSYSTEMTIME", errno)
+		return nil
 	}
 	if win32.NMDATETIMECHANGE_FLAGS(ret) == win32.GDT_NONE {
 		return nil
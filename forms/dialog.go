@@ -3,7 +3,6 @@ package forms
 import (
 	"github.com/zzl/go-win32api/v2/win32"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"syscall"
 	"unsafe"
 )
@@ -14,6 +13,7 @@ type Dialog interface {
 
 type DialogObject struct {
 	ContainerObject
+	super *ContainerObject
 }
 
 func NewDialogObject() *DialogObject {
@@ -40,7 +40,9 @@ func (this *DialogObject) Show() {
 		(*win32.DLGTEMPLATE)(unsafe.Pointer(hTemplate)),
 		HWndActive, dialogProcCallback, 0)
 	if hWnd == 0 {
-		log.Fatal(errno)
+		win32.GlobalFree(hTemplate)
+		reportWin32Error("CreateDialogIndirectParam", errno)
+		return
 	}
 	win32.GlobalFree(hTemplate)
 	win32.ShowWindow(hWnd, win32.SW_SHOW)
@@ -52,7 +54,9 @@ func (this *DialogObject) ShowModal() {
 		(*win32.DLGTEMPLATE)(unsafe.Pointer(hTemplate)),
 		HWndActive, dialogProcCallback, 0)
 	if ret == NegativeOne {
-		log.Fatal(errno)
+		win32.GlobalFree(hTemplate)
+		reportWin32Error("DialogBoxIndirectParam", errno)
+		return
 	}
 	win32.GlobalFree(hTemplate)
 }
@@ -67,7 +71,8 @@ func (this *DialogObject) createTemplate() win32.HGLOBAL {
 
 	hGlobal, errno := win32.GlobalAlloc(win32.GMEM_ZEROINIT, 1024)
 	if hGlobal == 0 {
-		log.Fatal(errno)
+		reportWin32Error("GlobalAlloc", errno)
+		return 0
 	}
 	pData, errno := win32.GlobalLock(hGlobal)
 	*(*win32.DLGTEMPLATE)(pData) = templ
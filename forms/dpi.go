@@ -34,3 +34,12 @@ func DpiUnscale(value int) int {
 	}
 	return value
 }
+
+// WindowDpi returns the DPI of the device context of a window,
+// which graphics created on it are scaled to.
+func WindowDpi(hWnd win32.HWND) int32 {
+	hDc := win32.GetDC(hWnd)
+	dpi := win32.GetDeviceCaps(hDc, win32.LOGPIXELSX)
+	win32.ReleaseDC(hWnd, hDc)
+	return dpi
+}
@@ -0,0 +1,94 @@
+package forms
+
+import (
+	"errors"
+	"log"
+	"syscall"
+
+	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing"
+)
+
+var (
+	// ErrNotCreated is reported when an operation requires a window handle
+	// but the window has not been created yet.
+	ErrNotCreated = errors.New("window not created")
+
+	// ErrNotRealized is reported when a virtual object is used without being realized.
+	ErrNotRealized = errors.New("object not realized")
+
+	// ErrUnknownWindow is reported when the window procedure receives a message
+	// for a window neither mapped nor being created.
+	ErrUnknownWindow = errors.New("message for an unknown window")
+)
+
+// Win32Error is the error of a failed Win32 call.
+// It unwraps to the syscall.Errno of the error code,
+// so errors.Is can be used to test for specific codes.
+type Win32Error struct {
+	Op   string            //the failed call, e.g. "SetTimer"
+	Code win32.WIN32_ERROR //the last error code, may be NO_ERROR
+}
+
+// NewWin32Error creates a Win32Error.
+func NewWin32Error(op string, code win32.WIN32_ERROR) *Win32Error {
+	return &Win32Error{Op: op, Code: code}
+}
+
+func (this *Win32Error) Error() string {
+	if this.Code == win32.NO_ERROR {
+		return this.Op + " failed"
+	}
+	return this.Op + ": " + this.Code.Error()
+}
+
+func (this *Win32Error) Unwrap() error {
+	return syscall.Errno(this.Code)
+}
+
+// ErrorHandler handles the errors that can not be returned to a caller,
+// such as those occurred in message handlers and event callbacks.
+type ErrorHandler func(err error)
+
+var errorHandler ErrorHandler = logError
+
+// StrictMode makes ReportError panic instead of calling the error handler,
+// so that errors are not overlooked during development.
+var StrictMode bool
+
+// SetErrorHandler sets the error handler and returns the previous one.
+// A nil handler restores the default handler, which logs the errors.
+func SetErrorHandler(handler ErrorHandler) ErrorHandler {
+	oriHandler := errorHandler
+	if handler == nil {
+		handler = logError
+	}
+	errorHandler = handler
+	return oriHandler
+}
+
+// ReportError passes the error to the error handler,
+// or panics with it in strict mode. A nil error is ignored.
+func ReportError(err error) {
+	if err == nil {
+		return
+	}
+	if StrictMode {
+		panic(err)
+	}
+	errorHandler(err)
+}
+
+func init() {
+	//the errors of drawing calls that can not be returned go the same way
+	drawing.SetErrorHandler(ReportError)
+}
+
+func logError(err error) {
+	log.Println("error:", err)
+}
+
+// reportWin32Error reports a failed Win32 call.
+func reportWin32Error(op string, code win32.WIN32_ERROR) {
+	ReportError(NewWin32Error(op, code))
+}
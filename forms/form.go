@@ -5,7 +5,6 @@ import (
 	"github.com/zzl/goforms/drawing/colors"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 
 	"github.com/zzl/go-win32api/v2/win32"
 )
@@ -62,7 +61,8 @@ func (this *FormObject) EnsureClassRegistered() {
 		Style:           win32.CS_HREDRAW | win32.CS_VREDRAW,
 	})
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 	_form_class_registerd = true
 }
@@ -1,7 +1,6 @@
 package forms
 
 import (
-	"log"
 	"syscall"
 	"unsafe"
 
@@ -70,7 +69,7 @@ func (this *HeaderControlObject) _addItem(text string, width int, align int) {
 	index, errno := SendMessage(this.Handle, win32.HDM_INSERTITEM,
 		count, unsafe.Pointer(&hdi))
 	if index == NegativeOne {
-		log.Fatal(errno)
+		reportWin32Error("HDM_INSERTITEM", errno)
 	}
 }
 
@@ -2,12 +2,14 @@ package forms
 
 import (
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"unsafe"
 
 	"github.com/zzl/go-win32api/v2/win32"
 	"github.com/zzl/goforms/drawing"
+	"github.com/zzl/goforms/drawing/svg"
 )
 
 type ImageList struct {
@@ -43,9 +45,7 @@ func NewImageList(size int, create bool) *ImageList {
 	iml.Init()
 	if create {
 		err := iml.Create()
-		if err != nil {
-			log.Panic(err)
-		}
+		assertNoErr(err)
 	}
 	return iml
 }
@@ -77,7 +77,8 @@ func GetSysImageList(small bool) *ImageList {
 	var hIml win32.HIMAGELIST
 	hr := win32.SHGetImageList(iImageList, &win32.IID_IImageList, unsafe.Pointer(&hIml))
 	if win32.FAILED(hr) {
-		log.Fatal("?")
+		ReportError(fmt.Errorf("SHGetImageList: %s", win32.HRESULT_ToString(hr)))
+		return nil
 	}
 
 	return &ImageList{
@@ -204,6 +205,18 @@ func (this *ImageList) AddImageData(imageData []byte) (int, error) {
 	return ret, errno
 }
 
+// AddSvg adds an SVG document rendered at the size of the images.
+func (this *ImageList) AddSvg(doc *svg.Document) (int, error) {
+	bitmap := drawing.NewBitmapFromSvg(nil, doc, int32(this.Cx), int32(this.Cy))
+	defer bitmap.Dispose()
+
+	hBitmap := bitmap.GetHBitmap()
+	ret, err := this.Add(hBitmap)
+	win32.DeleteObject(hBitmap)
+
+	return ret, err
+}
+
 func (this *ImageList) AddImageFile(filePath string) (int, error) {
 	bts, err := ioutil.ReadFile(filePath)
 	if err != nil {
@@ -2,7 +2,6 @@ package internal
 
 import (
 	"github.com/zzl/go-win32api/v2/win32"
-	"log"
 	"os"
 	"syscall"
 	"unsafe"
@@ -25,17 +24,20 @@ func enumResName(hModule win32.HINSTANCE, lpType win32.PWSTR,
 	return 1
 }
 
-func ActivateCommonControlsV6IfNeeded() {
+// ActivateCommonControlsV6IfNeeded activates the common controls v6 for the process
+// if the executable has no manifest. It returns the failed call and its error code,
+// or an empty op on success.
+func ActivateCommonControlsV6IfNeeded() (op string, code win32.WIN32_ERROR) {
 
 	pEnumResName := syscall.NewCallback(enumResName)
 	ok := win32.EnumResourceNames(0, win32.MAKEINTRESOURCE(uint16(win32.RT_MANIFEST)),
 		pEnumResName, 0)
 	if ok == win32.TRUE {
-		return
+		return "", win32.NO_ERROR
 	}
 	exePath, _ := os.Executable()
 	if _, err := os.Stat(exePath + ".manifest"); err == nil {
-		return
+		return "", win32.NO_ERROR
 	}
 
 	system32 := make([]uint16, win32.MAX_PATH)
@@ -55,13 +57,22 @@ func ActivateCommonControlsV6IfNeeded() {
 	procCreateActCtx := libKernel32.NewProc("CreateActCtxW")
 	procActivateActCtx := libKernel32.NewProc("ActivateActCtx")
 
-	hActCtx, _, errno := procCreateActCtx.Call(uintptr(unsafe.Pointer(&actctx)))
+	hActCtx, _, err := procCreateActCtx.Call(uintptr(unsafe.Pointer(&actctx)))
 	if hActCtx == win32.INVALID_HANDLE_VALUE {
-		log.Panic(errno)
+		return "CreateActCtx", errnoCode(err)
 	}
 	var cookie uintptr
-	ret, _, errno := procActivateActCtx.Call(hActCtx, uintptr(unsafe.Pointer(&cookie)))
+	ret, _, err := procActivateActCtx.Call(hActCtx, uintptr(unsafe.Pointer(&cookie)))
 	if win32.BOOL(ret) == win32.FALSE {
-		log.Panic(errno)
+		return "ActivateActCtx", errnoCode(err)
 	}
+	return "", win32.NO_ERROR
+}
+
+// errnoCode returns the error code of the error of a proc call.
+func errnoCode(err error) win32.WIN32_ERROR {
+	if errno, ok := err.(syscall.Errno); ok {
+		return win32.WIN32_ERROR(errno)
+	}
+	return win32.NO_ERROR
 }
@@ -0,0 +1,141 @@
+package forms
+
+import (
+	"github.com/zzl/goforms/framework/keys"
+)
+
+type Keymap = keys.Keymap
+type KeymapConflict = keys.Conflict
+
+// GetKeymap returns the current shortcuts of the named commands.
+func (this *CommandManager) GetKeymap() Keymap {
+	keymap := make(Keymap)
+	for _, item := range this.Items {
+		if item.Name == "" {
+			continue
+		}
+		keymap[item.Name] = item.GetShortcuts()
+	}
+	return keymap
+}
+
+// GetDefaultKeymap returns a copy of the default shortcuts of the named commands,
+// i.e. those they had when added to the manager.
+func (this *CommandManager) GetDefaultKeymap() Keymap {
+	return this.defaultKeymap.Clone()
+}
+
+// addDefaultShortcuts records the shortcuts of added commands as their defaults.
+func (this *CommandManager) addDefaultShortcuts(items []*Command) {
+	if this.defaultKeymap == nil {
+		this.defaultKeymap = make(Keymap)
+	}
+	keymap := this.defaultKeymap
+	for _, item := range items {
+		if item.Name == "" {
+			continue
+		}
+		if _, ok := keymap[item.Name]; !ok {
+			keymap[item.Name] = item.GetShortcuts()
+		}
+	}
+}
+
+// SetDefaultKeymap replaces the default keymap.
+func (this *CommandManager) SetDefaultKeymap(keymap Keymap) {
+	this.defaultKeymap = keymap.Clone()
+}
+
+// ApplyKeymap updates the shortcuts of the commands mentioned in the keymap.
+func (this *CommandManager) ApplyKeymap(keymap Keymap) {
+	for _, item := range this.Items {
+		if item.Name == "" {
+			continue
+		}
+		chords, ok := keymap[item.Name]
+		if !ok {
+			continue
+		}
+		var shortcutKeys []KeyStroke
+		var shortcutChords []KeyChord
+		for _, chord := range chords {
+			if len(chord) == 1 {
+				shortcutKeys = append(shortcutKeys, chord[0])
+			} else if len(chord) > 1 {
+				shortcutChords = append(shortcutChords, chord)
+			}
+		}
+		if shortcutKeysChanged(item.ShortcutKeys, shortcutKeys) ||
+			shortcutChordsChanged(item.ShortcutChords, shortcutChords) {
+			item.ShortcutKeys = shortcutKeys
+			item.ShortcutChords = shortcutChords
+			item.NotifyChange()
+		}
+	}
+	this.RebindShortcuts()
+}
+
+// ResetKeymap restores the default shortcuts.
+func (this *CommandManager) ResetKeymap() {
+	this.ApplyKeymap(this.GetDefaultKeymap())
+}
+
+// LoadKeymapFile applies the user overrides in a JSON keymap file
+// on top of the default keymap. A missing file is not an error.
+func (this *CommandManager) LoadKeymapFile(path string) error {
+	userKeymap, err := keys.LoadKeymapFile(path)
+	if err != nil {
+		return err
+	}
+	this.ApplyKeymap(keys.MergeKeymaps(this.GetDefaultKeymap(), userKeymap))
+	return nil
+}
+
+// SaveKeymapFile saves the shortcuts that differ from the default keymap
+// to a JSON file.
+func (this *CommandManager) SaveKeymapFile(path string) error {
+	overrides := keys.DiffKeymaps(this.GetDefaultKeymap(), this.GetKeymap())
+	return overrides.SaveFile(path)
+}
+
+func (this *CommandManager) conflictOptions() keys.ConflictOptions {
+	mnemonics := make(map[byte][]string)
+	for _, item := range this.Items {
+		if item.Name == "" {
+			continue
+		}
+		if key := keys.Mnemonic(item.Text); key != 0 {
+			mnemonics[key] = append(mnemonics[key], item.Name)
+		}
+	}
+	return keys.ConflictOptions{Mnemonics: mnemonics}
+}
+
+// FindShortcutConflicts reports duplicate shortcuts, unreachable chord prefixes,
+// Alt shortcuts shadowing command text mnemonics and reserved system keys.
+func (this *CommandManager) FindShortcutConflicts() []KeymapConflict {
+	return keys.FindConflicts(this.GetKeymap(), this.conflictOptions())
+}
+
+// SuggestShortcuts returns up to max free key strokes for the command,
+// preferring letters of its text.
+func (this *CommandManager) SuggestShortcuts(command *Command, max int) []KeyStroke {
+	hint := command.GetNoPrefixText()
+	if mnemonic := keys.Mnemonic(command.Text); mnemonic != 0 {
+		hint = string(rune(mnemonic)) + hint
+	}
+	return keys.SuggestKeyStrokes(hint, this.GetKeymap(), this.conflictOptions(), max)
+}
+
+func shortcutChordsChanged(chords1 []KeyChord, chords2 []KeyChord) bool {
+	count := len(chords1)
+	if count != len(chords2) {
+		return true
+	}
+	for n := 0; n < count; n++ {
+		if !chords1[n].Equals(chords2[n]) {
+			return true
+		}
+	}
+	return false
+}
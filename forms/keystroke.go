@@ -0,0 +1,59 @@
+package forms
+
+import (
+	"syscall"
+
+	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/keys"
+)
+
+type KeyStroke = keys.KeyStroke
+type KeyChord = keys.KeyChord
+
+// ParseKeyStroke parses a key stroke such as "Ctrl+Shift+S".
+func ParseKeyStroke(s string) (KeyStroke, error) {
+	return keys.ParseKeyStroke(s)
+}
+
+// ParseKeyChord parses a key stroke sequence such as "Ctrl+K, Ctrl+C".
+func ParseKeyChord(s string) (KeyChord, error) {
+	return keys.ParseKeyChord(s)
+}
+
+func init() {
+	keys.SetLocale(&keys.Locale{KeyName: GetSystemKeyName})
+}
+
+// GetSystemKeyName returns the localized name of a virtual key
+// as reported by the keyboard layout, or "" if there is none.
+func GetSystemKeyName(key byte) string {
+	scan := win32.MapVirtualKey(uint32(key), win32.MAPVK_VK_TO_VSC)
+	if scan == 0 {
+		return ""
+	}
+	var lParam int32
+	lParam = int32(scan) << 16
+	if keys.IsExtendedKey(key) {
+		lParam |= 0x1000000
+	}
+	buf := make([]uint16, 33)
+	ret, _ := win32.GetKeyNameText(lParam, &buf[0], 32)
+	if ret == 0 {
+		return ""
+	}
+	return syscall.UTF16ToString(buf)
+}
+
+// keyStrokeFromMsg builds a KeyStroke from a WM_KEYDOWN or WM_SYSKEYDOWN message.
+// It returns false for other messages and for modifier keys pressed alone.
+func keyStrokeFromMsg(msg *win32.MSG) (KeyStroke, bool) {
+	if msg.Message != win32.WM_KEYDOWN && msg.Message != win32.WM_SYSKEYDOWN {
+		return KeyStroke{}, false
+	}
+	key := byte(msg.WParam)
+	if keys.IsModifierKey(key) {
+		return KeyStroke{}, false
+	}
+	args := NewKeyEventArgs(msg.WParam, msg.LParam)
+	return KeyStroke{Ctrl: args.Ctrl, Alt: args.Alt, Shift: args.Shift, Key: key}, true
+}
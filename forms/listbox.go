@@ -5,7 +5,6 @@ import (
 	"github.com/zzl/goforms/framework/types"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"syscall"
 	"unsafe"
 
@@ -42,6 +41,7 @@ type ListBox interface {
 
 type ListBoxObject struct {
 	ControlObject
+	super *ControlObject
 
 	IntegralHeight bool
 	ForeColorAwareSupport
@@ -162,7 +162,8 @@ func (this *ListBoxObject) AddItem(item types.ValueText) {
 	index, errno := SendMessage(this.Handle, win32.LB_ADDSTRING,
 		0, unsafe.Pointer(pwszText))
 	if int32(index) == win32.LB_ERR {
-		log.Fatal(errno)
+		reportWin32Error("LB_ADDSTRING", errno)
+		return
 	}
 	this.values = append(this.values, item.Value)
 }
@@ -178,7 +179,8 @@ func (this *ListBoxObject) AddStringItem(item string) {
 	index, errno := SendMessage(this.Handle, win32.LB_ADDSTRING,
 		0, unsafe.Pointer(pwszText))
 	if int32(index) == win32.LB_ERR {
-		log.Fatal(errno)
+		reportWin32Error("LB_ADDSTRING", errno)
+		return
 	}
 	this.values = append(this.values, consts.Null)
 }
@@ -201,7 +203,8 @@ func (this *ListBoxObject) DeleteItem(index int) {
 func (this *ListBoxObject) GetItemCount() int {
 	retVal, errono := SendMessage(this.Handle, win32.LB_GETCOUNT, 0, 0)
 	if int32(retVal) == win32.LB_ERR {
-		log.Fatal(errono)
+		reportWin32Error("LB_GETCOUNT", errono)
+		return 0
 	}
 	return int(retVal)
 }
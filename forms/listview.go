@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"math"
 	"syscall"
 	"unsafe"
@@ -142,7 +141,8 @@ func (this *ListViewObject) SetImageList(iml *ImageList) {
 	}
 	this.iml = iml
 	if this.Handle == 0 {
-		log.Fatal("please create control first")
+		ReportError(ErrNotCreated)
+		return
 	}
 	var hIml win32.HIMAGELIST
 	if iml != nil {
@@ -151,7 +151,7 @@ func (this *ListViewObject) SetImageList(iml *ImageList) {
 	_, errno := SendMessage(this.Handle, win32.LVM_SETIMAGELIST,
 		win32.LVSIL_SMALL, hIml)
 	if errno != win32.NO_ERROR {
-		log.Fatal(errno)
+		reportWin32Error("LVM_SETIMAGELIST", errno)
 	}
 }
 
@@ -271,7 +271,8 @@ func (this *ListViewObject) onColumnClick(colIndex int) {
 	ret, errno := SendMessage(this.hWndHeader, win32.HDM_GETITEM,
 		colIndex, unsafe.Pointer(&hdi))
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("HDM_GETITEM", errno)
+		return
 	}
 	var desc bool
 	fmt := hdi.Fmt
@@ -299,7 +300,8 @@ func (this *ListViewObject) onColumnClick(colIndex int) {
 	ret, errno = SendMessage(this.hWndHeader, win32.HDM_SETITEM,
 		colIndex, unsafe.Pointer(&hdi))
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("HDM_SETITEM", errno)
+		return
 	}
 	this.lastSortColIndex = colIndex
 
@@ -314,7 +316,7 @@ func (this *ListViewObject) DeleteColumn(index int) {
 	ret, errno := SendMessage(this.Handle,
 		win32.LVM_DELETECOLUMN, index, 0)
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("LVM_DELETECOLUMN", errno)
 	}
 }
 
@@ -341,7 +343,7 @@ func (this *ListViewObject) DeleteRow(index int) {
 	ret, errno := SendMessage(this.Handle,
 		win32.LVM_DELETEITEM, index, 0)
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("LVM_DELETEITEM", errno)
 	}
 }
 
@@ -369,7 +371,9 @@ func (this *ListViewObject) AddRow(info *ListRowInfo) (int, int) {
 	iItem, errno := SendMessage(this.Handle, win32.LVM_INSERTITEM,
 		0, unsafe.Pointer(&lvi))
 	if iItem == NegativeOne {
-		log.Fatal(errno)
+		delete(this.dataMap, info.Id)
+		reportWin32Error("LVM_INSERTITEM", errno)
+		return -1, 0
 	}
 	lvi.LParam = 0
 
@@ -383,7 +387,7 @@ func (this *ListViewObject) AddRow(info *ListRowInfo) (int, int) {
 		ret, errno := SendMessage(this.Handle, win32.LVM_SETITEMTEXT,
 			iItem, unsafe.Pointer(&lvi))
 		if ret == 0 {
-			log.Fatal(errno)
+			reportWin32Error("LVM_SETITEMTEXT", errno)
 		}
 	}
 	return int(iItem), info.Id
@@ -467,7 +471,8 @@ func (this *ListViewObject) _addColumn(column *ListViewColumn) {
 		ret, errno := SendMessage(this.Handle, win32.LVM_INSERTCOLUMN,
 			0, unsafe.Pointer(&lvc))
 		if ret == NegativeOne {
-			log.Fatal(errno)
+			reportWin32Error("LVM_INSERTCOLUMN", errno)
+			return
 		}
 		dummyColumnAdded = true
 	}
@@ -500,7 +505,7 @@ func (this *ListViewObject) _addColumn(column *ListViewColumn) {
 	ret, errno := SendMessage(this.Handle, win32.LVM_INSERTCOLUMN,
 		index, unsafe.Pointer(&lvc))
 	if ret == NegativeOne {
-		log.Fatal(errno)
+		reportWin32Error("LVM_INSERTCOLUMN", errno)
 	}
 	if dummyColumnAdded {
 		_, _ = SendMessage(this.Handle, win32.LVM_DELETECOLUMN, 0, 0)
@@ -568,7 +573,8 @@ func (this *ListViewObject) IndexToId(index int) int {
 	ret, errno := SendMessage(this.Handle, win32.LVM_GETITEM,
 		0, unsafe.Pointer(&lvi))
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("LVM_GETITEM", errno)
+		return 0
 	}
 	rowId := int(lvi.LParam)
 	return rowId
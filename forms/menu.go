@@ -27,6 +27,8 @@ type MenuItem struct {
 	ownerMenuHandle win32.HMENU
 	subMenuHandle   win32.HMENU
 	hBitmap         win32.HBITMAP
+
+	commandListener *SimpleEventListener
 }
 
 type Menu struct {
@@ -88,6 +90,11 @@ func (this *Menu) disposeItem(item *MenuItem) {
 	if item.SubItems != nil {
 		this.disposeItems(item.SubItems)
 	}
+	if item.commandListener != nil {
+		item.Command.OnChange.RemoveListener(item.commandListener)
+		item.commandListener = nil
+	}
+	item.ownerMenuHandle = 0
 	if item.hBitmap != 0 {
 		win32.DeleteObject(win32.HGDIOBJ(item.hBitmap))
 		item.hBitmap = 0
@@ -109,7 +116,7 @@ func (this *Menu) populateItems(hMenu win32.HMENU, items []*MenuItem) {
 		mi.DwStyle = win32.MNS_NOTIFYBYPOS
 		bOk, errno := win32.SetMenuInfo(hMenu, &mi)
 		if bOk == win32.FALSE {
-			log.Fatal(errno)
+			reportWin32Error("SetMenuInfo", errno)
 		}
 	}
 
@@ -125,8 +132,8 @@ func (this *Menu) populateItems(hMenu win32.HMENU, items []*MenuItem) {
 		command := item.Command
 		if command != nil {
 			text = command.Text
-			if len(command.ShortcutKeys) > 0 {
-				text += "\t" + command.ShortcutKeys[0].String()
+			if shortcut := command.ShortcutText(); shortcut != "" {
+				text += "\t" + shortcut
 			}
 		}
 		if text == "-" {
@@ -134,7 +141,7 @@ func (this *Menu) populateItems(hMenu win32.HMENU, items []*MenuItem) {
 		} else {
 			mii.FType = win32.MFT_STRING
 			mii.FMask |= win32.MIIM_STRING | win32.MIIM_ID | win32.MIIM_DATA
-			if item.Radio {
+			if item.Radio || (command != nil && command.RadioGroup != "") {
 				mii.FType = win32.MFT_STRING | win32.MFT_RADIOCHECK
 			}
 			mii.WID = uint32(item.Id)
@@ -188,7 +195,7 @@ func (this *Menu) populateItems(hMenu win32.HMENU, items []*MenuItem) {
 		ok, errno := win32.InsertMenuItem(hMenu,
 			uint32(oriCount)+uint32(n), win32.TRUE, &mii)
 		if ok == win32.FALSE {
-			log.Fatal(errno)
+			reportWin32Error("InsertMenuItem", errno)
 		}
 	}
 }
@@ -219,7 +226,7 @@ func (this *Menu) SetItemText(id int, text string) {
 	mii.DwTypeData = pwszText
 	bOk, errno := win32.SetMenuItemInfo(this.Handle, uint32(id), win32.FALSE, &mii)
 	if bOk == win32.FALSE {
-		log.Fatal(errno)
+		reportWin32Error("SetMenuItemInfo", errno)
 	}
 }
 
@@ -267,9 +274,9 @@ func (this *Menu) processItems(items []*MenuItem) {
 				this.nameItemMap[item.Name] = item
 			}
 		}
-		if command != nil {
+		if command != nil && item.commandListener == nil {
 			tItem := item
-			command.OnChange.AddListener(func(info *SimpleEventInfo) {
+			item.commandListener = command.OnChange.AddListener(func(info *SimpleEventInfo) {
 				this.updateCommandItem(tItem)
 			})
 		}
@@ -290,8 +297,8 @@ func (this *Menu) updateCommandItem(item *MenuItem) {
 	mii.CbSize = uint32(unsafe.Sizeof(mii))
 	mii.FMask = win32.MIIM_STRING | win32.MIIM_STATE
 	text := command.Text
-	if len(command.ShortcutKeys) > 0 {
-		text += "\t" + command.ShortcutKeys[0].String()
+	if shortcut := command.ShortcutText(); shortcut != "" {
+		text += "\t" + shortcut
 	}
 	mii.DwTypeData, _ = syscall.UTF16PtrFromString(text)
 
@@ -303,7 +310,7 @@ func (this *Menu) updateCommandItem(item *MenuItem) {
 	if command.Image != 0 {
 		image = command.Image
 	}
-	if image != 0 {
+	if image != 0 && this.ImageList != nil {
 		if image == consts.Zero {
 			image = 0
 		}
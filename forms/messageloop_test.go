@@ -0,0 +1,23 @@
+package forms
+
+import "testing"
+
+func TestDispatcherPost(t *testing.T) {
+	//more actions than any fixed buffer, posted without running the loop
+	var order []int
+	for n := 0; n < 1000; n++ {
+		n := n
+		Dispatcher.Post(func() {
+			order = append(order, n)
+		})
+	}
+	Dispatcher.check()
+	if len(order) != 1000 {
+		t.Fatalf("%d actions run, want 1000", len(order))
+	}
+	for n, value := range order {
+		if value != n {
+			t.Fatalf("action %d run at %d", value, n)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package forms
 
 import (
-	"log"
 	"syscall"
 	"unsafe"
 
@@ -79,14 +78,17 @@ func GetComboBoxMetrics(hWndParent HWND) *comboBoxMetrics {
 		HInstance,
 		unsafe.Pointer(uintptr(0)))
 	if hWnd == 0 {
-		log.Fatal(errno)
+		reportWin32Error("CreateWindowEx", errno)
+		return &_comboBoxMetrics
 	}
 
 	var cbi win32.COMBOBOXINFO
 	cbi.CbSize = uint32(unsafe.Sizeof(cbi))
 	bOk, errno := win32.GetComboBoxInfo(hWnd, &cbi)
 	if bOk == 0 {
-		log.Fatal(errno)
+		win32.DestroyWindow(hWnd)
+		reportWin32Error("GetComboBoxInfo", errno)
+		return &_comboBoxMetrics
 	}
 	var rc win32.RECT
 	win32.GetWindowRect(hWnd, &rc)
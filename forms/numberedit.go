@@ -4,7 +4,6 @@ import (
 	"github.com/zzl/go-win32api/v2/win32"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"math"
 )
 
@@ -86,7 +85,7 @@ func (this *NumberEditObject) Create(options WindowOptions) error {
 		Height:       16,
 		ParentHandle: this.GetContainer().GetHandle(),
 	}); err != nil {
-		log.Fatal(err)
+		return err
 	}
 	this.udWin = win
 	this.updateUdPos()
@@ -4,7 +4,6 @@ import (
 	"github.com/zzl/go-win32api/v2/win32"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 )
 
 type Panel interface {
@@ -92,7 +91,8 @@ func (this *PanelObject) EnsureClassRegistered() {
 		BackgroundBrush: ToSysColorBrush(byte(win32.COLOR_3DFACE)),
 	})
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 	_panelClassRegstered = true
 }
@@ -105,7 +105,8 @@ func (this *PanelObject) ensureWClassRegistered() {
 		BackgroundBrush: ToSysColorBrush(byte(win32.COLOR_WINDOW)),
 	})
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 	_panel_w_ClassRegstered = true
 }
@@ -3,7 +3,6 @@ package forms
 import (
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"unsafe"
 
 	"github.com/zzl/go-win32api/v2/win32"
@@ -32,6 +31,7 @@ type RadioButtonInterface interface {
 
 type RadioButtonObject struct {
 	ControlObject
+	super *ControlObject
 
 	ForeColorAwareSupport
 	AutoCheck  bool
@@ -153,7 +153,8 @@ func (this *RadioButtonObject) GetPreferredSize(int, int) (cx, cy int) {
 	bOk, _ := SendMessage(this.Handle, win32.BCM_GETIDEALSIZE, 0,
 		unsafe.Pointer(&sz))
 	if bOk == 0 {
-		log.Fatal("?")
+		ReportError(NewWin32Error("BCM_GETIDEALSIZE", win32.NO_ERROR))
+		return 0, 0
 	}
 	return int(sz.Cx + 16), int(sz.Cy)
 }
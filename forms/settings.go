@@ -0,0 +1,209 @@
+package forms
+
+import (
+	"strconv"
+	"unsafe"
+
+	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/events"
+	"github.com/zzl/goforms/framework/keys"
+	"github.com/zzl/goforms/framework/settings"
+)
+
+// BindKeymap persists the shortcuts of the command manager in a settings section.
+// Only the shortcuts that differ from the default keymap are stored.
+// They are applied when the store loads, and captured when it saves.
+func BindKeymap(store *settings.Store, name string,
+	manager *CommandManager) *settings.Section[Keymap] {
+	section := settings.NewSection(store, name, Keymap{})
+	apply := func() {
+		manager.ApplyKeymap(keys.MergeKeymaps(manager.GetDefaultKeymap(), section.Get()))
+	}
+	apply()
+	store.OnLoad.AddListener(func(ei *SimpleEventInfo) {
+		apply()
+	})
+	store.OnSave.AddListener(func(ei *SimpleEventInfo) {
+		section.Set(keys.DiffKeymaps(manager.GetDefaultKeymap(), manager.GetKeymap()))
+	})
+	return section
+}
+
+// WindowPlacement is the restored position and size of a top window,
+// in screen coordinates, and whether it is maximized.
+type WindowPlacement struct {
+	Left      int  `json:"left"`
+	Top       int  `json:"top"`
+	Width     int  `json:"width"`
+	Height    int  `json:"height"`
+	Maximized bool `json:"maximized,omitempty"`
+}
+
+// IsEmpty tells whether the placement has no size, e.g. when never saved.
+func (this WindowPlacement) IsEmpty() bool {
+	return this.Width <= 0 || this.Height <= 0
+}
+
+// GetWindowPlacement returns the placement of the window.
+// For a maximized or minimized window, the position and size are those it restores to.
+func GetWindowPlacement(win Window) WindowPlacement {
+	wp := win32.WINDOWPLACEMENT{Length: uint32(unsafe.Sizeof(win32.WINDOWPLACEMENT{}))}
+	ok, errno := win32.GetWindowPlacement(win.GetHandle(), &wp)
+	if ok == win32.FALSE {
+		reportWin32Error("GetWindowPlacement", errno)
+		return WindowPlacement{}
+	}
+	rc := wp.RcNormalPosition
+	maximized := wp.ShowCmd == uint32(win32.SW_SHOWMAXIMIZED) ||
+		wp.ShowCmd == uint32(win32.SW_SHOWMINIMIZED) && wp.Flags&win32.WPF_RESTORETOMAXIMIZED != 0
+	return WindowPlacement{
+		Left:      int(rc.Left),
+		Top:       int(rc.Top),
+		Width:     int(rc.Right - rc.Left),
+		Height:    int(rc.Bottom - rc.Top),
+		Maximized: maximized,
+	}
+}
+
+// SetWindowPlacement moves the window to the placement.
+// A placement that is empty or off all the monitors is ignored.
+// A hidden window stays hidden, and is maximized when it is shown.
+func SetWindowPlacement(win Window, placement WindowPlacement) {
+	if placement.IsEmpty() {
+		return
+	}
+	rc := win32.RECT{
+		Left:   int32(placement.Left),
+		Top:    int32(placement.Top),
+		Right:  int32(placement.Left + placement.Width),
+		Bottom: int32(placement.Top + placement.Height),
+	}
+	if win32.MonitorFromRect(&rc, win32.MONITOR_DEFAULTTONULL) == 0 {
+		return
+	}
+	hWnd := win.GetHandle()
+	visible := win.IsVisible()
+	wp := win32.WINDOWPLACEMENT{
+		Length:           uint32(unsafe.Sizeof(win32.WINDOWPLACEMENT{})),
+		ShowCmd:          uint32(win32.SW_HIDE),
+		RcNormalPosition: rc,
+	}
+	if visible {
+		wp.ShowCmd = uint32(win32.SW_SHOWNORMAL)
+		if placement.Maximized {
+			wp.ShowCmd = uint32(win32.SW_SHOWMAXIMIZED)
+		}
+	}
+	ok, errno := win32.SetWindowPlacement(hWnd, &wp)
+	if ok == win32.FALSE {
+		reportWin32Error("SetWindowPlacement", errno)
+		return
+	}
+	if !visible && placement.Maximized {
+		event := win.GetEvent(win32.WM_SHOWWINDOW)
+		var pListener *events.EventListener[*Message]
+		pListener = event.AddListener(func(m *Message) {
+			if m.WParam == 0 {
+				return
+			}
+			event.RemoveListener(pListener)
+			ReportError(Dispatcher.Invoke(func() {
+				win32.ShowWindow(hWnd, win32.SW_SHOWMAXIMIZED)
+			}))
+		})
+	}
+}
+
+// BindWindowPlacement persists the placement of a top window in a settings section.
+// The placement is applied when the window is created and when the store loads,
+// and captured when the store saves and when the window is destroyed.
+func BindWindowPlacement(store *settings.Store, name string,
+	win TopWindow) *settings.Section[WindowPlacement] {
+	section := settings.NewSection(store, name, WindowPlacement{})
+	apply := func() {
+		if win.GetHandle() != 0 {
+			SetWindowPlacement(win, section.Get())
+		}
+	}
+	capture := func() {
+		if win.GetHandle() != 0 {
+			section.Set(GetWindowPlacement(win))
+		}
+	}
+	if win.GetHandle() != 0 {
+		apply()
+	} else {
+		win.GetOnCreate().AddListener(func(ei *SimpleEventInfo) {
+			apply()
+		})
+	}
+	store.OnLoad.AddListener(func(ei *SimpleEventInfo) {
+		apply()
+	})
+	store.OnSave.AddListener(func(ei *SimpleEventInfo) {
+		capture()
+	})
+	win.GetEvent(win32.WM_DESTROY).AddListener(func(m *Message) {
+		capture()
+	})
+	return section
+}
+
+// BindSplitterRatios persists the ratios of splitters in a settings section.
+// The ratios are keyed by the splitter names, or their indexes if unnamed.
+// They are captured when the store saves and when the root window of the splitters
+// is destroyed, before the panes are.
+func BindSplitterRatios(store *settings.Store, name string,
+	splitters ...Splitter) *settings.Section[map[string]float64] {
+	section := settings.NewSection(store, name, map[string]float64{})
+	key := func(n int, splitter Splitter) string {
+		if splitterName := splitter.GetName(); splitterName != "" {
+			return splitterName
+		}
+		return strconv.Itoa(n)
+	}
+	apply := func() {
+		ratios := section.Get()
+		for n, splitter := range splitters {
+			if ratio, ok := ratios[key(n, splitter)]; ok {
+				splitter.SplitterObj().SetRatio(ratio)
+			}
+		}
+	}
+	apply()
+	store.OnLoad.AddListener(func(ei *SimpleEventInfo) {
+		apply()
+	})
+	capture := func(n int, splitter Splitter) {
+		if ratio := splitter.SplitterObj().GetRatio(); ratio > 0 {
+			section.Update(func(ratios *map[string]float64) {
+				(*ratios)[key(n, splitter)] = ratio
+			})
+		}
+	}
+	store.OnSave.AddListener(func(ei *SimpleEventInfo) {
+		for n, splitter := range splitters {
+			capture(n, splitter)
+		}
+	})
+	//the root window gets WM_DESTROY before its descendants,
+	//while the panes next to a splitter may be destroyed before the splitter
+	for n, splitter := range splitters {
+		n, splitter := n, splitter
+		watchRoot := func() {
+			if root := splitter.GetRootWindow(); root != nil {
+				root.GetEvent(win32.WM_DESTROY).AddListener(func(m *Message) {
+					capture(n, splitter)
+				})
+			}
+		}
+		if splitter.GetHandle() != 0 {
+			watchRoot()
+		} else {
+			splitter.GetOnCreate().AddListener(func(ei *SimpleEventInfo) {
+				watchRoot()
+			})
+		}
+	}
+	return section
+}
@@ -1,7 +1,5 @@
 package forms
 
-import "log"
-
 type SimpleWindowObject struct {
 	WindowObject
 	super *WindowObject
@@ -17,7 +15,8 @@ func (this *SimpleWindowObject) EnsureClassRegistered() {
 		BackgroundBrush: 0,
 	})
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 	_simpleWindowClassRegstered = true
 }
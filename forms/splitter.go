@@ -4,7 +4,6 @@ import (
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
 	"github.com/zzl/goforms/layouts"
-	"log"
 	"unsafe"
 
 	"github.com/zzl/go-win32api/v2/win32"
@@ -12,6 +11,8 @@ import (
 
 type Splitter interface {
 	Control
+
+	SplitterObj() *SplitterObject
 }
 
 type SplitterObject struct {
@@ -21,6 +22,8 @@ type SplitterObject struct {
 	Width int
 
 	dragInfo *splitterDragInfo
+
+	pendingRatio float64
 }
 
 type NewSplitter struct {
@@ -77,7 +80,8 @@ func ensureSplitterClassRegistered() {
 		CursorResId:     int(uintptr(unsafe.Pointer(win32.IDC_SIZEWE))),
 	})
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 
 	_splitterClassRegistered = true
@@ -88,6 +92,10 @@ func (this *SplitterObject) Init() {
 	this.WinProcFunc = splitterWndProc
 }
 
+func (this *SplitterObject) SplitterObj() *SplitterObject {
+	return this
+}
+
 func (this *SplitterObject) EnsureClassRegistered() {
 	ensureSplitterClassRegistered()
 }
@@ -113,6 +121,14 @@ func splitterWndProc(win *WindowObject, m *Message) error {
 	case win32.WM_KEYDOWN:
 		this.onKeyDown(m.WParam, m.LParam)
 		return m.SetHandledWithResult(0)
+	case win32.WM_SIZE:
+		if this.pendingRatio > 0 {
+			ReportError(Dispatcher.Invoke(func() {
+				if this.pendingRatio > 0 {
+					this.SetRatio(this.pendingRatio)
+				}
+			}))
+		}
 	}
 	return nil
 }
@@ -228,25 +244,44 @@ func (this *SplitterObject) dragEnd(canceled bool) {
 	win32.DeleteObject(hBrush)
 	win32.ReleaseCapture()
 
+	this.moveBy(dx)
+}
+
+// siblingWidths returns the widths of the controls before and after the splitter.
+func (this *SplitterObject) siblingWidths() (prevWidth int, nextWidth int) {
+	hWndPrev, _ := win32.GetWindow(this.Handle, win32.GW_HWNDPREV)
+	hWndNext, _ := win32.GetWindow(this.Handle, win32.GW_HWNDNEXT)
+	var rc win32.RECT
+	if hWndPrev != 0 {
+		win32.GetWindowRect(hWndPrev, &rc)
+		prevWidth = int(rc.Right - rc.Left)
+	}
+	if hWndNext != 0 {
+		win32.GetWindowRect(hWndNext, &rc)
+		nextWidth = int(rc.Right - rc.Left)
+	}
+	return
+}
+
+// moveBy widens the previous control by dx and narrows the next one by dx.
+func (this *SplitterObject) moveBy(dx int) {
 	hWndPrev, _ := win32.GetWindow(this.Handle, win32.GW_HWNDPREV)
 	hWndNext, _ := win32.GetWindow(this.Handle, win32.GW_HWNDNEXT)
 
 	//layout := this.GetContainer().GetLayoutForControl(this)
-	layout := this.GetData(layouts.Data_Layout).(Layout)
+	layout, _ := this.GetData(layouts.Data_Layout).(Layout)
 	if layout != nil {
-		var rc win32.RECT
-		win32.GetWindowRect(hWndPrev, &rc)
-		prevWidth := int(rc.Right-rc.Left) + dx
+		prevWidth, nextWidth := this.siblingWidths()
 
 		prevControl := GetWindow(hWndPrev).(Control)
 		prevItem := layout.FindItemByControl(prevControl)
-		prevItem.SetWidth(prevWidth)
-
-		win32.GetWindowRect(hWndNext, &rc)
-		nextWidth := int(rc.Right-rc.Left) - dx
 		nextControl := GetWindow(hWndNext).(Control)
 		nextItem := layout.FindItemByControl(nextControl)
-		nextItem.SetWidth(nextWidth)
+		if prevItem == nil || nextItem == nil {
+			return
+		}
+		prevItem.SetWidth(prevWidth + dx)
+		nextItem.SetWidth(nextWidth - dx)
 
 		layout.Update()
 	} else {
@@ -254,6 +289,39 @@ func (this *SplitterObject) dragEnd(canceled bool) {
 	}
 }
 
+// GetRatio returns the width of the previous control
+// relative to the total width of the previous and next controls.
+func (this *SplitterObject) GetRatio() float64 {
+	if this.pendingRatio > 0 {
+		return this.pendingRatio
+	}
+	prevWidth, nextWidth := this.siblingWidths()
+	if prevWidth+nextWidth <= 0 {
+		return 0
+	}
+	return float64(prevWidth) / float64(prevWidth+nextWidth)
+}
+
+// SetRatio moves the splitter so that the previous control takes
+// the ratio of the total width of the previous and next controls.
+// If the controls are not laid out yet, the ratio is applied once they are.
+func (this *SplitterObject) SetRatio(ratio float64) {
+	if ratio <= 0 || ratio >= 1 {
+		return
+	}
+	prevWidth, nextWidth := this.siblingWidths()
+	total := prevWidth + nextWidth
+	if this.Handle == 0 || total <= 0 {
+		this.pendingRatio = ratio
+		return
+	}
+	this.pendingRatio = 0
+	dx := int(ratio*float64(total)+0.5) - prevWidth
+	if dx != 0 {
+		this.moveBy(dx)
+	}
+}
+
 func (this *SplitterObject) onMouseMove(wParam WPARAM, lParam LPARAM) {
 	if this.dragInfo == nil {
 		return
@@ -3,7 +3,6 @@ package forms
 import (
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"syscall"
 	"unsafe"
 
@@ -142,7 +141,7 @@ func (this *StatusBarObject) _setParts() {
 	ret, errno := SendMessage(this.Handle, win32.SB_SETPARTS,
 		partCount, unsafe.Pointer(&rights[0]))
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("SB_SETPARTS", errno)
 	}
 }
 
@@ -241,6 +240,6 @@ func (this *StatusBarObject) SetPartText(index int, text string) {
 		win32.MAKELONG(uint16(index), uint16(style)),
 		unsafe.Pointer(pwsz))
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("SB_SETTEXT", errno)
 	}
 }
@@ -3,7 +3,6 @@ package forms
 import (
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"syscall"
 	"unsafe"
 
@@ -131,7 +130,7 @@ func (this *TabControlObject) SetItems(items []TabItem) {
 		ret, errno := SendMessage(this.Handle, win32.TCM_INSERTITEM,
 			n, unsafe.Pointer(&ti))
 		if ret == NegativeOne {
-			log.Fatal(errno)
+			reportWin32Error("TCM_INSERTITEM", errno)
 		}
 	}
 	this.updateItemsBounds()
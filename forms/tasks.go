@@ -0,0 +1,52 @@
+package forms
+
+import (
+	"context"
+
+	"github.com/zzl/goforms/framework/tasks"
+)
+
+// RunAsync runs the work on a background goroutine and returns its future,
+// whose continuations and progress listeners run on the UI thread.
+func RunAsync[T any](ctx context.Context, work tasks.Work[T],
+	opts ...*tasks.Options) *tasks.Future[T] {
+	return tasks.Run[T](Dispatcher, ctx, work, opts...)
+}
+
+// WaitCursorBusy is a tasks.BusyHook showing the wait cursor while the task runs.
+func WaitCursorBusy(task *tasks.Task) func() {
+	wc := NewWaitCursor()
+	return wc.Restore
+}
+
+// ProgressBarBusy returns a tasks.BusyHook showing the progress of the task
+// on the progress bar. The progress bar shows a marquee while the progress is unknown.
+func ProgressBarBusy(progressBar ProgressBar) tasks.BusyHook {
+	return func(task *tasks.Task) func() {
+		bar := progressBar.ProgressBarObj()
+		bar.SetRange(0, 1000)
+		bar.SetProgress(0)
+		bar.ShowMarquee(true)
+		marquee := true
+		task.AddProgressListener(func(info tasks.ProgressInfo) {
+			if info.Fraction < 0 {
+				if !marquee {
+					bar.ShowMarquee(true)
+					marquee = true
+				}
+				return
+			}
+			if marquee {
+				bar.ShowMarquee(false)
+				marquee = false
+			}
+			bar.SetProgress(int(min(info.Fraction, 1) * 1000))
+		})
+		return func() {
+			if marquee {
+				bar.ShowMarquee(false)
+			}
+			bar.SetProgress(0)
+		}
+	}
+}
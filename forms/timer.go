@@ -1,48 +1,141 @@
 package forms
 
 import (
+	"errors"
+	"syscall"
+	"time"
+
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/framework/clock"
 	"github.com/zzl/goforms/framework/events"
-	"log"
-	"syscall"
+	"github.com/zzl/goforms/framework/timers"
 )
 
+// UIClock is a clock.Clock backed by Win32 thread timers.
+// Functions scheduled with it run on the UI thread,
+// which must be running a message loop.
+var UIClock clock.Clock = &uiClock{timers: map[uintptr]*uiTimer{}}
+
+type uiClock struct {
+	timers     map[uintptr]*uiTimer
+	pTimerProc uintptr
+	err        *Win32Error //the failure of the last AfterFunc, if any
+}
+
+type uiTimer struct {
+	clock *uiClock
+	id    uintptr
+	fn    func()
+}
+
+func (this *uiClock) Now() time.Time {
+	return time.Now()
+}
+
+// AfterFunc implements clock.Clock.AfterFunc.
+// It returns nil if the Win32 timer can not be created, keeping the error for Timer.Start.
+func (this *uiClock) AfterFunc(d time.Duration, fn func()) clock.Timer {
+	timer, err := this.afterFunc(d, fn)
+	this.err = err
+	if err != nil {
+		return nil
+	}
+	return timer
+}
+
+func (this *uiClock) afterFunc(d time.Duration, fn func()) (*uiTimer, *Win32Error) {
+	if this.pTimerProc == 0 {
+		this.pTimerProc = syscall.NewCallback(this.timerProc)
+	}
+	id, errno := win32.SetTimer(0, 0, uint32(max(d.Milliseconds(), 0)), this.pTimerProc)
+	if id == 0 {
+		return nil, NewWin32Error("SetTimer", errno)
+	}
+	timer := &uiTimer{clock: this, id: id, fn: fn}
+	this.timers[id] = timer
+	return timer, nil
+}
+
+func (this *uiClock) timerProc(hWnd win32.HWND, uMsg uint32, idEvent uintptr, dwTime win32.DWORD) uintptr {
+	timer, ok := this.timers[idEvent]
+	if !ok {
+		return 0
+	}
+	timer.Stop()
+	timer.fn()
+	return 0
+}
+
+func (this *uiTimer) Stop() bool {
+	if this.id == 0 {
+		return false
+	}
+	win32.KillTimer(0, this.id)
+	delete(this.clock.timers, this.id)
+	this.id = 0
+	return true
+}
+
+// Timer fires the OnTick event after an interval, once or repeatedly.
 type Timer struct {
 	IntervalMillis int
+	OneShot        bool        //fire once instead of repeatedly
+	Clock          clock.Clock //the clock scheduling the ticks, UIClock if nil
 	OnTick         SimpleEvent
 
-	id uintptr
+	timer *timers.Timer
 }
 
 func NewTimer() *Timer {
 	return &Timer{}
 }
 
-var _timerMap = map[uintptr]*Timer{}
+// Start starts the timer if it is not running.
+// It returns a *Win32Error if the UI clock can not create the Win32 timer.
+func (this *Timer) Start() error {
+	if this.IsRunning() {
+		return nil
+	}
+	clk := this.Clock
+	if clk == nil {
+		clk = UIClock
+	}
+	interval := time.Duration(this.IntervalMillis) * time.Millisecond
+	this.timer = timers.NewTimer(clk, interval, !this.OneShot, this.tick)
+	if !this.timer.Start() {
+		if ui, ok := clk.(*uiClock); ok && ui.err != nil {
+			return ui.err
+		}
+		return errors.New("timer could not be started")
+	}
+	return nil
+}
+
+// Stop stops the timer. It can be started again.
+func (this *Timer) Stop() {
+	if this.timer != nil {
+		this.timer.Stop()
+	}
+}
 
-var _pTimerProc uintptr
+// Restart stops the timer and starts it again,
+// so that the next tick happens a full interval from now.
+func (this *Timer) Restart() error {
+	this.Stop()
+	return this.Start()
+}
 
-func _timerProc(hWnd win32.HWND, uMsg uint32, idEvent uintptr, dwTime win32.DWORD) uintptr {
-	timer := _timerMap[idEvent]
-	timer.OnTick.Fire(timer, &SimpleEventInfo{})
-	return 0
+// IsRunning tells whether the timer is running.
+func (this *Timer) IsRunning() bool {
+	return this.timer != nil && this.timer.IsRunning()
 }
 
-func (this *Timer) Start() {
-	if _pTimerProc == 0 {
-		_pTimerProc = syscall.NewCallback(_timerProc)
-	}
-	id, errno := win32.SetTimer(0, 0, uint32(this.IntervalMillis), _pTimerProc)
-	if id == 0 {
-		log.Panic(errno)
-	}
-	this.id = id
-	_timerMap[id] = this
+func (this *Timer) tick() {
+	this.OnTick.Fire(this, &SimpleEventInfo{})
 }
 
 func (this *Timer) Dispose() {
-	win32.KillTimer(0, this.id)
-	delete(_timerMap, this.id)
+	this.Stop()
 }
 
 func CreateTimer(intervalMillis int, onTick events.SimpleEventListener) *Timer {
@@ -51,3 +144,15 @@ func CreateTimer(intervalMillis int, onTick events.SimpleEventListener) *Timer {
 	timer.OnTick.AddListener(onTick)
 	return timer
 }
+
+// NewDebouncer creates a timers.Debouncer calling fn on the UI thread,
+// once its Trigger calls have stopped for the delay.
+func NewDebouncer(delayMillis int, fn func()) *timers.Debouncer {
+	return timers.NewDebouncer(UIClock, time.Duration(delayMillis)*time.Millisecond, fn)
+}
+
+// NewThrottler creates a timers.Throttler calling fn on the UI thread,
+// at most once per interval.
+func NewThrottler(intervalMillis int, fn func()) *timers.Throttler {
+	return timers.NewThrottler(UIClock, time.Duration(intervalMillis)*time.Millisecond, fn)
+}
@@ -0,0 +1,60 @@
+package forms
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zzl/goforms/framework/clock"
+)
+
+func TestTimerTicks(t *testing.T) {
+	virtual := clock.NewVirtual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ticks := 0
+	timer := CreateTimer(100, func(ei *SimpleEventInfo) {
+		ticks += 1
+	})
+	timer.Clock = virtual
+	if err := timer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	virtual.Advance(350 * time.Millisecond)
+	if ticks != 3 || !timer.IsRunning() {
+		t.Fatalf("%d ticks, running %v, want 3 ticks and running", ticks, timer.IsRunning())
+	}
+	timer.Stop()
+	virtual.Advance(time.Second)
+	if ticks != 3 || timer.IsRunning() {
+		t.Errorf("%d ticks after Stop, running %v, want 3 and stopped", ticks, timer.IsRunning())
+	}
+
+	//re-armed after Stop
+	if err := timer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	virtual.Advance(100 * time.Millisecond)
+	if ticks != 4 {
+		t.Errorf("%d ticks after restarting, want 4", ticks)
+	}
+	timer.Dispose()
+}
+
+func TestTimerOneShot(t *testing.T) {
+	virtual := clock.NewVirtual(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ticks := 0
+	timer := CreateTimer(100, func(ei *SimpleEventInfo) {
+		ticks += 1
+	})
+	timer.Clock = virtual
+	timer.OneShot = true
+	timer.Start()
+	virtual.Advance(60 * time.Millisecond)
+	timer.Restart()
+	virtual.Advance(60 * time.Millisecond)
+	if ticks != 0 {
+		t.Fatalf("%d ticks before the restarted interval elapsed", ticks)
+	}
+	virtual.Advance(time.Second)
+	if ticks != 1 || timer.IsRunning() {
+		t.Errorf("%d ticks, running %v, want one tick and stopped", ticks, timer.IsRunning())
+	}
+}
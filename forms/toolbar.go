@@ -52,6 +52,8 @@ type ToolBarItem struct {
 
 	Disabled bool
 	Command  *Command
+
+	commandListener *SimpleEventListener
 }
 
 type TbLabelStyle byte
@@ -266,7 +268,7 @@ func (this *ToolBarObject) onDrawItem(wParam WPARAM, lParam LPARAM) win32.LRESUL
 func (this *ToolBarObject) Create(options WindowOptions) error {
 	err := this.super.Create(options)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	//
@@ -305,6 +307,7 @@ func (this *ToolBarObject) Create(options WindowOptions) error {
 }
 
 func (this *ToolBarObject) Dispose() {
+	this.unbindCommands(this.items)
 	if this.imageList != nil {
 		this.imageList.Dispose()
 	}
@@ -514,6 +517,7 @@ func (this *ToolBarObject) Clear() {
 	for n := count - 1; n >= 0; n-- {
 		SendMessage(this.Handle, win32.TB_DELETEBUTTON, n, 0)
 	}
+	this.unbindCommands(this.items)
 	this.items = nil
 }
 
@@ -550,15 +554,24 @@ func (this *ToolBarObject) processItems(items []*ToolBarItem) {
 			}
 		}
 		this.idItemMap[item.Id] = item
-		if command != nil {
+		if command != nil && item.commandListener == nil {
 			tItem := item
-			command.OnChange.AddListener(func(eventInfo *SimpleEventInfo) {
+			item.commandListener = command.OnChange.AddListener(func(eventInfo *SimpleEventInfo) {
 				this.updateCommandItem(tItem)
 			})
 		}
 	}
 }
 
+func (this *ToolBarObject) unbindCommands(items []*ToolBarItem) {
+	for _, item := range items {
+		if item.commandListener != nil {
+			item.Command.OnChange.RemoveListener(item.commandListener)
+			item.commandListener = nil
+		}
+	}
+}
+
 func (this *ToolBarObject) UpdateCommandItems() {
 	for _, item := range this.items {
 		if item.Command != nil {
@@ -573,6 +586,9 @@ func (this *ToolBarObject) updateCommandItem(item *ToolBarItem) {
 		println("??")
 		return
 	}
+	if this.Handle == 0 {
+		return
+	}
 	var tbi win32.TBBUTTONINFO
 	tbi.CbSize = uint32(unsafe.Sizeof(tbi))
 	tbi.DwMask = win32.TBIF_COMMAND | win32.TBIF_TEXT | win32.TBIF_IMAGE | win32.TBIF_STATE
@@ -690,7 +706,8 @@ func (this *ToolBarObject) BuildOverflowMenu() *PopupMenu {
 		ret, errno := SendMessage(this.Handle, win32.TB_GETBUTTON,
 			n, unsafe.Pointer(&tbb))
 		if ret == 0 {
-			log.Fatal(errno)
+			reportWin32Error("TB_GETBUTTON", errno)
+			continue
 		}
 		if tbb.FsState&uint8(win32.TBSTATE_HIDDEN) != 0 {
 			continue
@@ -699,7 +716,8 @@ func (this *ToolBarObject) BuildOverflowMenu() *PopupMenu {
 		ret, errno = SendMessage(this.Handle, win32.TB_GETITEMRECT,
 			n, unsafe.Pointer(&rcButton))
 		if ret == 0 {
-			log.Fatal(errno)
+			reportWin32Error("TB_GETITEMRECT", errno)
+			continue
 		}
 		enabled := tbb.FsState&uint8(win32.TBSTATE_ENABLED) != 0
 		if rcButton.Right <= rcClient.Right && rcButton.Bottom <= rcClient.Bottom {
@@ -1,9 +1,9 @@
 package forms
 
 import (
+	"errors"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
-	"log"
 	"syscall"
 	"unsafe"
 
@@ -73,7 +73,8 @@ func (this *TooltipObject) CreateIn(parent Window, extraOpts ...*WindowOptions)
 	opts.ParentHandle = parent.GetHandle()
 	err := this.RealObject.Create(*opts)
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 
 	var ti win32.TOOLINFO
@@ -89,7 +90,7 @@ func (this *TooltipObject) CreateIn(parent Window, extraOpts ...*WindowOptions)
 	ret, errno := SendMessage(this.Handle, win32.TTM_ADDTOOL,
 		0, unsafe.Pointer(&ti))
 	if ret == 0 {
-		log.Fatal(errno.Error())
+		reportWin32Error("TTM_ADDTOOL", errno)
 	}
 
 }
@@ -166,13 +167,15 @@ func (this *TooltipObject) AddTool(control Control, textProvider TooltipTextProv
 	ret, errno := SendMessage(this.Handle, win32.TTM_ADDTOOL,
 		0, unsafe.Pointer(&ti))
 	if ret == 0 {
-		log.Fatal(errno.Error())
+		reportWin32Error("TTM_ADDTOOL", errno)
+		return
 	}
 	if textProvider == nil {
 		var ok bool
 		textProvider, ok = control.(TooltipTextProvider)
 		if !ok {
-			log.Fatal("text provider unspecified")
+			ReportError(errors.New("tooltip text provider unspecified"))
+			return
 		}
 	}
 	this.toolsMap[control.GetHandle()] = &tooltipTool{
@@ -162,7 +162,8 @@ func (this *TopWindowObject) EnsureClassRegistered() {
 		BackgroundBrush: ToSysColorBrush(byte(win32.COLOR_WINDOW)),
 	})
 	if err != nil {
-		log.Fatal(err)
+		ReportError(err)
+		return
 	}
 	_topwindow_class_registerd = true
 }
@@ -235,7 +236,9 @@ func (this *TopWindowObject) ShowModal() {
 	if hWndOwner != 0 {
 		win32.EnableWindow(hWndOwner, win32.FALSE)
 	}
-	MessageLoop()
+	if exitCode := runMessageLoop(); exitCode != endModalQuitCode {
+		win32.PostQuitMessage(int32(exitCode)) //let the outer loop quit too
+	}
 	if hWndOwner != 0 {
 		win32.EnableWindow(hWndOwner, win32.TRUE)
 		win32.SetActiveWindow(hWndOwner) //?
@@ -421,17 +424,14 @@ func (this *TopWindowObject) WinProc(winObj *WindowObject, m *Message) error {
 		return m.SetHandledWithResult(0)
 	case win32.WM_CLOSE:
 		if win.showingModal() {
-			win32.PostQuitMessage('E' + 'N' + 'D' + 'M' + 'O' + 'D' + 'A' + 'L') //??
+			win32.PostQuitMessage(endModalQuitCode)
 		} else {
 			win32.DestroyWindow(win.GetHandle())
 		}
 		return m.SetHandledWithResult(0)
 	case win32.WM_DESTROY:
 		retVal := this.super.WinProc(winObj, m)
-		topWindows := GetTopWindows()
-		if len(topWindows) == 1 {
-			win32.PostQuitMessage(0) //?
-		}
+		App.onTopWindowDestroy(win)
 		return retVal
 	case win32.WM_NCDESTROY:
 		//?
@@ -40,21 +40,25 @@ func trayWinClassProc(hWnd HWND, uMsg uint32, wParam WPARAM, lParam LPARAM) win3
 	return win32.DefWindowProc(hWnd, uMsg, wParam, lParam)
 }
 
-func ensureTrayWinClassRegistered() {
+func ensureTrayWinClassRegistered() error {
 	if trayWinClassRegistered {
-		return
+		return nil
 	}
 	_, err := RegisterClass(trayWinClass, trayWinClassProc, ClassOptions{
 		BackgroundBrush: 0,
 	})
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	trayWinClassRegistered = true
+	return nil
 }
 
-func (this *TrayIcon) Create(visible bool) {
-	ensureTrayWinClassRegistered()
+// Create adds the icon to the notification area.
+func (this *TrayIcon) Create(visible bool) error {
+	if err := ensureTrayWinClassRegistered(); err != nil {
+		return err
+	}
 
 	trayWin := NewWindowObject()
 	err := trayWin.Create(WindowOptions{
@@ -64,7 +68,7 @@ func (this *TrayIcon) Create(visible bool) {
 	})
 
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 	trayWin.data = map[string]any{
 		"TrayIcon": this,
@@ -94,8 +98,9 @@ func (this *TrayIcon) Create(visible bool) {
 
 	bOk := win32.Shell_NotifyIcon(win32.NIM_ADD, &nid)
 	if bOk == win32.FALSE {
-		log.Fatal("?")
+		return NewWin32Error("Shell_NotifyIcon", win32.NO_ERROR)
 	}
+	return nil
 }
 
 func (this *TrayIcon) Show() {
@@ -1,6 +1,7 @@
 package forms
 
 import (
+	"fmt"
 	"github.com/zzl/goforms/framework/consts"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
@@ -520,7 +521,8 @@ func (this *TreeViewObject) AddItem(item TreeItem) int {
 	ret, errno := SendMessage(this.Handle, win32.TVM_INSERTITEM,
 		0, unsafe.Pointer(&tvis))
 	if ret == 0 {
-		log.Panic(errno)
+		reportWin32Error("TVM_INSERTITEM", errno)
+		return 0
 	}
 	hItem := ret
 	this.nodeIdHandleMap[id] = hItem
@@ -667,7 +669,8 @@ func (this *TreeViewObject) UseDefaultNodeImages() {
 	flags := win32.SHGSI_ICON | win32.SHGSI_SMALLICON
 	hr := win32.SHGetStockIconInfo(win32.SIID_FOLDER, flags, &ssii)
 	if !win32.SUCCEEDED(hr) {
-		log.Fatal("?")
+		ReportError(fmt.Errorf("SHGetStockIconInfo: %s", win32.HRESULT_ToString(hr)))
+		return
 	}
 	hIconFolder := ssii.HIcon
 	_ = win32.SHGetStockIconInfo(win32.SIID_FOLDEROPEN, flags, &ssii)
@@ -789,7 +792,8 @@ func (this *TreeViewObject) GetParentId(id int) int {
 	ret, errno := SendMessage(this.Handle,
 		win32.TVM_GETITEM, 0, unsafe.Pointer(&tvi))
 	if ret == 0 {
-		log.Fatal(errno)
+		reportWin32Error("TVM_GETITEM", errno)
+		return 0
 	}
 	parentId := int(tvi.LParam)
 	return parentId
@@ -893,7 +897,8 @@ func (this *TreeViewObject) createCheckBoxes(rightSpace int) win32.HIMAGELIST {
 	hIml := win32.ImageList_Create(
 		int32(cxSpace), int32(cy), win32.ILC_COLOR, int32(frames), int32(frames))
 	if hIml == 0 {
-		log.Fatal("?")
+		ReportError(NewWin32Error("ImageList_Create", win32.NO_ERROR))
+		return 0
 	}
 	win32.ImageList_Add(hIml, hbmCheckboxes, 0)
 	return hIml
@@ -3,8 +3,6 @@ package forms
 import (
 	"github.com/zzl/goforms/framework/events"
 	"github.com/zzl/goforms/framework/types"
-	"log"
-	"syscall"
 
 	"github.com/zzl/go-win32api/v2/win32"
 	"github.com/zzl/goforms/drawing"
@@ -315,57 +313,6 @@ func ChainActions(actions ...Action) Action {
 
 //
 
-type KeyStroke struct {
-	Ctrl  bool
-	Alt   bool
-	Shift bool
-	Key   byte
-}
-
-func isExtendedKey(vk byte) bool {
-	switch win32.VIRTUAL_KEY(vk) {
-	case win32.VK_INSERT, win32.VK_DELETE, win32.VK_HOME, win32.VK_END,
-		win32.VK_NEXT, win32.VK_PRIOR, win32.VK_LEFT,
-		win32.VK_RIGHT, win32.VK_UP, win32.VK_DOWN:
-		return true
-	}
-	return false
-}
-
-func (me KeyStroke) IsExtended() bool {
-	return isExtendedKey(me.Key)
-}
-
-func (me KeyStroke) String() string {
-	var s string
-	if me.Ctrl {
-		s += "Ctrl+"
-	}
-	if me.Shift {
-		s += "Shift+"
-	}
-	if me.Alt {
-		s += "Alt+"
-	}
-	scan := win32.MapVirtualKey(uint32(me.Key), win32.MAPVK_VK_TO_VSC)
-	if scan == 0 {
-		log.Println("?")
-	}
-	var lParam int32
-	lParam = int32(scan) << 16
-	if isExtendedKey(me.Key) {
-		lParam |= 0x1000000
-	}
-	buf := make([]uint16, 33)
-	ret, errno := win32.GetKeyNameText(lParam, &buf[0], 32)
-	if ret == 0 {
-		log.Println(errno)
-	}
-	keyName := syscall.UTF16ToString(buf)
-	s += keyName
-	return s
-}
-
 type ForeColorAwareSupport struct {
 	foreColor drawing.Color
 }
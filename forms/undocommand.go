@@ -0,0 +1,73 @@
+package forms
+
+import "github.com/zzl/goforms/framework/undo"
+
+type UndoManager = undo.Manager
+type UndoAction = undo.Action
+
+// UndoText and RedoText are the base texts of the undo/redo commands.
+// The name of the pending action is appended, as in "&Undo Rename".
+var (
+	UndoText = "&Undo"
+	RedoText = "&Redo"
+)
+
+func NewUndoManager() *UndoManager {
+	return undo.NewManager()
+}
+
+// NewUndoCommand creates a command that undoes the last action of the manager.
+// Its text and enabled state follow the manager automatically.
+func NewUndoCommand(manager *UndoManager) *Command {
+	command := &Command{
+		Name:         "Undo",
+		Category:     "Edit",
+		ShortcutKeys: []KeyStroke{{Ctrl: true, Key: 'Z'}},
+		Action:       func() { manager.Undo() },
+		CanExecute:   manager.CanUndo,
+	}
+	bindUndoCommand(command, manager, func() string {
+		return undoCommandText(UndoText, manager.UndoName())
+	})
+	return command
+}
+
+// NewRedoCommand creates a command that redoes the last undone action of the manager.
+// Its text and enabled state follow the manager automatically.
+func NewRedoCommand(manager *UndoManager) *Command {
+	command := &Command{
+		Name:         "Redo",
+		Category:     "Edit",
+		ShortcutKeys: []KeyStroke{{Ctrl: true, Key: 'Y'}},
+		Action:       func() { manager.Redo() },
+		CanExecute:   manager.CanRedo,
+	}
+	bindUndoCommand(command, manager, func() string {
+		return undoCommandText(RedoText, manager.RedoName())
+	})
+	return command
+}
+
+func undoCommandText(text string, actionName string) string {
+	if actionName == "" {
+		return text
+	}
+	return text + " " + actionName
+}
+
+func bindUndoCommand(command *Command, manager *UndoManager, textFunc func() string) {
+	update := func() {
+		text := textFunc()
+		disabled := !command.CanExecute()
+		if text == command.Text && disabled == command.Disabled {
+			return
+		}
+		command.Text = text
+		command.Disabled = disabled
+		command.NotifyChange()
+	}
+	update()
+	manager.OnChange.AddListener(func(info *SimpleEventInfo) {
+		update()
+	})
+}
@@ -2,7 +2,6 @@ package forms
 
 import (
 	"github.com/zzl/goforms/framework/consts"
-	"log"
 	"runtime"
 	"strings"
 	"syscall"
@@ -537,6 +536,18 @@ func configControlSize(control Control, size Size) {
 	control.SetSize(size.Width, size.Height)
 }
 
+// ConfigControlSize sets the size of a newly created control,
+// using its preferred size for the zero dimensions.
+func ConfigControlSize(control Control, size Size) {
+	configControlSize(control, size)
+}
+
+// ResolveParentHandle returns the handle of the parent window for a new control.
+// If parentWin is nil, the ContextContainer or the active window is used.
+func ResolveParentHandle(parentWin Window) win32.HWND {
+	return resolveParentHandle(parentWin)
+}
+
 func resolveParentHandle(parentWin Window) win32.HWND {
 	if parentWin == nil {
 		parentWin = ContextContainer
@@ -547,8 +558,8 @@ func resolveParentHandle(parentWin Window) win32.HWND {
 	return parentWin.GetHandle()
 }
 
+// assertNoErr reports an unexpected error, such as failing to create a control,
+// through ReportError.
 func assertNoErr(err error) {
-	if err != nil {
-		log.Panic(err)
-	}
+	ReportError(err)
 }
@@ -1,7 +1,9 @@
 package forms
 
 import (
+	"errors"
 	"github.com/zzl/goforms/framework/consts"
+	"github.com/zzl/goforms/framework/leaks"
 	"github.com/zzl/goforms/framework/types"
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/framework/virtual"
@@ -450,7 +452,8 @@ func (this *WindowObject) ensureSubclassed() {
 	wndProc, errno := win32.SetWindowLongPtr(this.Handle,
 		win32.GWLP_WNDPROC, wndProcCallback)
 	if wndProc == 0 {
-		log.Fatal(errno)
+		reportWin32Error("SetWindowLongPtr", errno)
+		return
 	}
 	this.oriWndProc = wndProc
 }
@@ -550,7 +553,7 @@ func (this *WindowObject) CreateHandle(
 func (this *WindowObject) Create(options WindowOptions) error {
 	win := this.RealObject
 	if win == nil {
-		log.Fatal("Object not realized")
+		return ErrNotRealized
 	}
 	win.EnsureClassRegistered()
 	win.PreCreate(&options)
@@ -590,7 +593,8 @@ func (this *WindowObject) Create(options WindowOptions) error {
 		}
 	}
 	if font != nil {
-		font.EnsureCreated()
+		ReportError(font.EnsureCreated())
+		leaks.Owned(font)
 		hFont = font.Handle
 	}
 	if hFont == 0 {
@@ -608,7 +612,7 @@ func (this *WindowObject) Create(options WindowOptions) error {
 // Attach attaches this WindowObject to an existing window handle
 func (this *WindowObject) Attach(hWnd HWND) error {
 	if this.Handle != 0 {
-		log.Panic("??")
+		return errors.New("window already attached")
 	}
 	this.Handle = hWnd
 	windowMap[hWnd] = this
@@ -623,7 +627,7 @@ func (this *WindowObject) Detach() error {
 		return nil
 	}
 	if this.oriWndProc == 0 {
-		log.Panic("??")
+		return errors.New("window not attached")
 	}
 	this.unSubclass()
 	delete(windowMap, this.Handle)
@@ -738,7 +742,11 @@ func (this *WindowObject) SetFont(font *gdi.Font) {
 	if this.Handle == 0 {
 		return
 	}
-	font.EnsureCreated()
+	if err := font.EnsureCreated(); err != nil {
+		ReportError(err)
+		return
+	}
+	leaks.Owned(font) //disposed by the window
 	SendMessage(this.Handle, win32.WM_SETFONT, font.Handle, 0)
 	this.SetData(Data_FontHandle, font.Handle)
 }
@@ -0,0 +1,579 @@
+package forms
+
+import (
+	"syscall"
+	"unsafe"
+
+	"github.com/zzl/go-win32api/v2/win32"
+	. "github.com/zzl/goforms/forms"
+	"github.com/zzl/goforms/framework/fuzzy"
+	"github.com/zzl/goforms/framework/virtual"
+)
+
+// CommandPaletteRecent is the recent list shared by command palettes
+// that do not specify their own.
+var CommandPaletteRecent = fuzzy.NewRecentList(20)
+
+type CommandPalette interface {
+	CustomControl
+
+	SetManager(manager *CommandManager)
+	Filter(pattern string)
+
+	GetSelectedCommand() *Command
+
+	CommandPaletteObj() *CommandPaletteObject
+}
+
+type CommandPaletteObject struct {
+	CustomControlObject
+	super *CustomControlObject
+
+	Manager         *CommandManager
+	Recent          *fuzzy.RecentList
+	Placeholder     string
+	MaxVisibleItems int
+
+	OnOk     SimpleEvent
+	OnCancel SimpleEvent
+
+	edit    *EditObject
+	listBox *ListBoxObject
+
+	commands   []*Command
+	candidates []fuzzy.Candidate
+	results    []fuzzy.Result
+
+	lineHeight int
+}
+
+func NewCommandPaletteObject() *CommandPaletteObject {
+	return virtual.New[CommandPaletteObject]()
+}
+
+func (this *CommandPaletteObject) CommandPaletteObj() *CommandPaletteObject {
+	return this
+}
+
+func (this *CommandPaletteObject) Init() {
+	this.super.Init()
+	this.Placeholder = "Type a command name"
+	this.MaxVisibleItems = 12
+}
+
+func (this *CommandPaletteObject) Dispose() {
+	if this.edit != nil {
+		this.edit.RemoveMessageProcessor(this)
+		this.listBox.RemoveMessageProcessor(this)
+	}
+	this.super.Dispose()
+}
+
+func (this *CommandPaletteObject) OnHandleCreated() {
+	edit := NewEditObject()
+	err := edit.Create(WindowOptions{ParentHandle: this.Handle})
+	if err != nil {
+		ReportError(err)
+		return
+	}
+	listBox := NewListBoxObject()
+	err = listBox.Create(WindowOptions{
+		ParentHandle: this.Handle,
+		StyleInclude: WINDOW_STYLE(win32.LBS_OWNERDRAWFIXED | win32.LBS_HASSTRINGS |
+			win32.LBS_NOINTEGRALHEIGHT),
+		StyleExclude: win32.WS_TABSTOP,
+	})
+	if err != nil {
+		ReportError(err)
+		return
+	}
+	this.edit, this.listBox = edit, listBox
+
+	this.edit.SetPlaceholder(this.Placeholder)
+	this.edit.OnValueChange.AddListener(func(ei *SimpleEventInfo) {
+		this.Filter(this.edit.GetText())
+	})
+	this.edit.AddMessageProcessor(this)
+	this.listBox.AddMessageProcessor(this)
+
+	this.lineHeight = this.measureLineHeight()
+	SendMessage(this.listBox.Handle, win32.LB_SETITEMHEIGHT, 0, this.getItemHeight())
+	this.Filter("")
+}
+
+func (this *CommandPaletteObject) GetControlSpecStyle() (include, exclude WINDOW_STYLE) {
+	return win32.WS_CLIPCHILDREN, win32.WS_TABSTOP
+}
+
+// SetManager sets the command manager whose commands are listed.
+func (this *CommandPaletteObject) SetManager(manager *CommandManager) {
+	this.Manager = manager
+	if this.Handle != 0 {
+		this.Reload()
+	}
+}
+
+// Reload re-collects the executable commands of the manager
+// and clears the filter text.
+func (this *CommandPaletteObject) Reload() {
+	this.commands = nil
+	this.candidates = nil
+	if this.Manager != nil {
+		this.Manager.Requery()
+		for _, command := range this.Manager.Items {
+			if command.Disabled || command.Text == "" || command.Text == "-" {
+				continue
+			}
+			key := command.Name
+			if key == "" {
+				key = command.GetNoPrefixText()
+			}
+			var extras []string
+			if command.Name != "" {
+				extras = append(extras, command.Name)
+			}
+			if command.Desc != "" {
+				extras = append(extras, command.Desc)
+			}
+			this.commands = append(this.commands, command)
+			this.candidates = append(this.candidates, fuzzy.Candidate{
+				Key:    key,
+				Text:   commandPaletteText(command),
+				Extras: extras,
+			})
+		}
+	}
+	if this.edit != nil {
+		if this.edit.GetText() != "" {
+			this.edit.SetText("") //refilters
+		} else {
+			this.Filter("")
+		}
+	}
+}
+
+func commandPaletteText(command *Command) string {
+	text := command.GetNoPrefixText()
+	if command.Category != "" {
+		text = command.Category + ": " + text
+	}
+	return text
+}
+
+func (this *CommandPaletteObject) getRecent() *fuzzy.RecentList {
+	if this.Recent != nil {
+		return this.Recent
+	}
+	return CommandPaletteRecent
+}
+
+// Filter ranks the commands against the pattern and selects the best match.
+func (this *CommandPaletteObject) Filter(pattern string) {
+	this.results = fuzzy.Rank(pattern, this.candidates, this.getRecent())
+	if this.listBox == nil {
+		return
+	}
+	SendMessage(this.listBox.Handle, win32.WM_SETREDRAW, 0, 0)
+	this.listBox.ClearItems()
+	for _, result := range this.results {
+		this.listBox.AddStringItem(this.candidates[result.Index].Text)
+	}
+	if len(this.results) != 0 {
+		this.listBox.SetSelectedIndex(0)
+	}
+	SendMessage(this.listBox.Handle, win32.WM_SETREDRAW, 1, 0)
+	this.listBox.Invalidate()
+	if container := this.getContainer(); container != nil {
+		container.UpdateSize()
+	}
+}
+
+func (this *CommandPaletteObject) getContainer() DropdownPopupContainer {
+	hWndParent := this.GetParentHandle()
+	if container, ok := GetWindow(hWndParent).(DropdownPopupContainer); ok {
+		return container
+	}
+	return nil
+}
+
+// GetSelectedCommand returns the selected command, or nil if none.
+func (this *CommandPaletteObject) GetSelectedCommand() *Command {
+	if this.listBox == nil {
+		return nil
+	}
+	index := this.listBox.GetSelectedIndex()
+	if index < 0 || index >= len(this.results) {
+		return nil
+	}
+	return this.commands[this.results[index].Index]
+}
+
+func (this *CommandPaletteObject) moveSelection(delta int) {
+	count := len(this.results)
+	if count == 0 {
+		return
+	}
+	index := this.listBox.GetSelectedIndex() + delta
+	if index < 0 {
+		index = 0
+	} else if index >= count {
+		index = count - 1
+	}
+	this.listBox.SetSelectedIndex(index)
+}
+
+func (this *CommandPaletteObject) getPageSize() int {
+	var rc win32.RECT
+	win32.GetClientRect(this.listBox.Handle, &rc)
+	pageSize := int(rc.Bottom-rc.Top) / this.getItemHeight()
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	return pageSize
+}
+
+// ProcessMessage implements MessageProcessor for the edit and the list box.
+func (this *CommandPaletteObject) ProcessMessage(m *Message) {
+	if m.UMsg == win32.WM_KEYDOWN {
+		switch win32.VIRTUAL_KEY(m.WParam) {
+		case win32.VK_UP:
+			this.moveSelection(-1)
+		case win32.VK_DOWN:
+			this.moveSelection(1)
+		case win32.VK_PRIOR:
+			this.moveSelection(-this.getPageSize())
+		case win32.VK_NEXT:
+			this.moveSelection(this.getPageSize())
+		case win32.VK_RETURN:
+			if this.GetSelectedCommand() != nil {
+				this.OnOk.Fire(this, &SimpleEventInfo{})
+			}
+		case win32.VK_ESCAPE:
+			this.OnCancel.Fire(this, &SimpleEventInfo{})
+		default:
+			return
+		}
+		m.SetHandled(true)
+	} else if m.UMsg == win32.WM_CHAR {
+		//suppress the beep of the edit control
+		if m.WParam == '\r' || m.WParam == 0x1b {
+			m.SetHandled(true)
+		}
+	} else if m.UMsg == win32.WM_LBUTTONUP && m.HWnd == this.listBox.Handle {
+		x, y, _ := ParseMouseMsgParams(m.WParam, m.LParam)
+		if this.listBox.IndexFromPoint(int(x), int(y)) != -1 {
+			this.OnOk.Fire(this, &SimpleEventInfo{})
+		}
+	}
+}
+
+func (this *CommandPaletteObject) WinProc(win *WindowObject, m *Message) error {
+	if m.UMsg == win32.WM_SIZE {
+		width, height := win32.LOWORD(uint32(m.LParam)), win32.HIWORD(uint32(m.LParam))
+		this.layout(int(width), int(height))
+	} else if m.UMsg == win32.WM_SETFOCUS && this.edit != nil {
+		this.edit.Focus()
+		return m.SetHandledWithResult(0)
+	}
+	return this.super.WinProc(win, m)
+}
+
+func (this *CommandPaletteObject) layout(width, height int) {
+	if this.edit == nil {
+		return
+	}
+	padding := DpiScale(4)
+	cyEdit := this.getEditHeight()
+	this.edit.SetBounds(padding, padding, width-2*padding, cyEdit)
+	top := cyEdit + 2*padding
+	this.listBox.SetBounds(0, top, width, height-top)
+}
+
+func (this *CommandPaletteObject) getEditHeight() int {
+	return this.lineHeight + DpiScale(8)
+}
+
+func (this *CommandPaletteObject) getItemHeight() int {
+	return 2*this.lineHeight + DpiScale(6)
+}
+
+func (this *CommandPaletteObject) measureLineHeight() int {
+	hdc := win32.GetDC(this.Handle)
+	hOriFont := win32.SelectObject(hdc, win32.HGDIOBJ(this.getFont()))
+	var tm win32.TEXTMETRIC
+	win32.GetTextMetrics(hdc, &tm)
+	win32.SelectObject(hdc, hOriFont)
+	win32.ReleaseDC(this.Handle, hdc)
+	return int(tm.TmHeight)
+}
+
+func (this *CommandPaletteObject) getFont() win32.HFONT {
+	if font := this.GetFont(); font != nil {
+		return font.Handle
+	}
+	return GetDefaultFont()
+}
+
+// GetPreferredSize returns a fixed width and the height fitting the visible items.
+func (this *CommandPaletteObject) GetPreferredSize(cxMax int, cyMax int) (int, int) {
+	count := len(this.results)
+	if count > this.MaxVisibleItems {
+		count = this.MaxVisibleItems
+	} else if count == 0 {
+		count = 1
+	}
+	cx := DpiScale(480)
+	cy := this.getEditHeight() + 2*DpiScale(4) + count*this.getItemHeight()
+	if cx > cxMax {
+		cx = cxMax
+	}
+	if cy > cyMax {
+		cy = cyMax
+	}
+	return cx, cy
+}
+
+func (this *CommandPaletteObject) OnBubbleMessage(msg *Message) {
+	if msg.UMsg == win32.WM_DRAWITEM {
+		pdis := *(**win32.DRAWITEMSTRUCT)(unsafe.Pointer(&msg.LParam))
+		if this.listBox != nil && pdis.HwndItem == this.listBox.Handle {
+			this.drawItem(pdis)
+			msg.SetHandledWithResult(1)
+			return
+		}
+	}
+	this.super.OnBubbleMessage(msg)
+}
+
+func (this *CommandPaletteObject) drawItem(pdis *win32.DRAWITEMSTRUCT) {
+	hdc := pdis.HDC
+	rc := pdis.RcItem
+
+	selected := pdis.ItemState&win32.ODS_SELECTED != 0
+	clrBg := win32.GetSysColor(win32.COLOR_WINDOW)
+	clrFg := win32.GetSysColor(win32.COLOR_WINDOWTEXT)
+	clrGray := win32.GetSysColor(win32.COLOR_GRAYTEXT)
+	clrMatch := win32.GetSysColor(win32.COLOR_HOTLIGHT)
+	if selected {
+		clrBg = win32.GetSysColor(win32.COLOR_HIGHLIGHT)
+		clrFg = win32.GetSysColor(win32.COLOR_HIGHLIGHTTEXT)
+		clrGray, clrMatch = clrFg, clrFg
+	}
+	FillSolidRect(hdc, &rc, clrBg)
+
+	index := int(int32(pdis.ItemID))
+	if index < 0 || index >= len(this.results) {
+		return
+	}
+	result := this.results[index]
+	command := this.commands[result.Index]
+
+	hOriFont := win32.SelectObject(hdc, win32.HGDIOBJ(this.getFont()))
+	win32.SetBkMode(hdc, win32.TRANSPARENT)
+
+	padding := int32(DpiScale(6))
+	rcLine := win32.RECT{Left: rc.Left + padding, Top: rc.Top + int32(DpiScale(3)),
+		Right: rc.Right - padding}
+	rcLine.Bottom = rcLine.Top + int32(this.lineHeight)
+
+	//shortcut
+	if shortcut := command.ShortcutText(); shortcut != "" {
+		wsz, _ := syscall.UTF16FromString(shortcut)
+		var size win32.SIZE
+		win32.GetTextExtentPoint32(hdc, &wsz[0], int32(len(wsz)-1), &size)
+		win32.SetTextColor(hdc, clrGray)
+		win32.DrawText(hdc, &wsz[0], int32(len(wsz)-1), &rcLine,
+			win32.DT_RIGHT|win32.DT_SINGLELINE|win32.DT_NOPREFIX)
+		rcLine.Right -= size.Cx + padding
+	}
+
+	//text, matched characters highlighted
+	runes := []rune(this.candidates[result.Index].Text)
+	matched := make([]bool, len(runes))
+	for _, pos := range result.Positions {
+		matched[pos] = true
+	}
+	x := rcLine.Left
+	for start := 0; start < len(runes) && x < rcLine.Right; {
+		end := start + 1
+		for end < len(runes) && matched[end] == matched[start] {
+			end++
+		}
+		if matched[start] {
+			win32.SetTextColor(hdc, clrMatch)
+		} else {
+			win32.SetTextColor(hdc, clrFg)
+		}
+		wsz, _ := syscall.UTF16FromString(string(runes[start:end]))
+		var size win32.SIZE
+		win32.GetTextExtentPoint32(hdc, &wsz[0], int32(len(wsz)-1), &size)
+		rcRun := win32.RECT{Left: x, Top: rcLine.Top, Right: rcLine.Right, Bottom: rcLine.Bottom}
+		win32.DrawText(hdc, &wsz[0], int32(len(wsz)-1), &rcRun,
+			win32.DT_SINGLELINE|win32.DT_NOPREFIX|win32.DT_END_ELLIPSIS)
+		x += size.Cx
+		start = end
+	}
+
+	//description
+	if command.Desc != "" {
+		rcDesc := win32.RECT{Left: rc.Left + padding, Top: rcLine.Bottom,
+			Right: rc.Right - padding, Bottom: rc.Bottom}
+		wsz, _ := syscall.UTF16FromString(command.Desc)
+		win32.SetTextColor(hdc, clrGray)
+		win32.DrawText(hdc, &wsz[0], int32(len(wsz)-1), &rcDesc,
+			win32.DT_SINGLELINE|win32.DT_NOPREFIX|win32.DT_END_ELLIPSIS)
+	}
+
+	win32.SelectObject(hdc, hOriFont)
+	if pdis.ItemState&win32.ODS_FOCUS != 0 {
+		win32.DrawFocusRect(hdc, &rc)
+	}
+}
+
+// CommandPalettePopup adapts a CommandPalette to DropdownPopup.
+type CommandPalettePopup struct {
+	Palette CommandPalette
+
+	onOk     SimpleEvent
+	onCancel SimpleEvent
+}
+
+func NewCommandPalettePopup(palette CommandPalette) *CommandPalettePopup {
+	obj := &CommandPalettePopup{Palette: palette}
+	paletteObj := palette.CommandPaletteObj()
+	paletteObj.OnOk.AddListener(func(ei *SimpleEventInfo) {
+		obj.onOk.Fire(obj, ei)
+	})
+	paletteObj.OnCancel.AddListener(func(ei *SimpleEventInfo) {
+		obj.onCancel.Fire(obj, ei)
+	})
+	return obj
+}
+
+func (this *CommandPalettePopup) GetControl() Control {
+	return this.Palette
+}
+
+func (this *CommandPalettePopup) GetOnOk() *SimpleEvent {
+	return &this.onOk
+}
+
+func (this *CommandPalettePopup) GetOnCancel() *SimpleEvent {
+	return &this.onCancel
+}
+
+func (this *CommandPalettePopup) GetValue() interface{} {
+	return this.Palette.GetSelectedCommand()
+}
+
+func (this *CommandPalettePopup) SetValue(value interface{}) {
+	//
+}
+
+func (this *CommandPalettePopup) GetText() string {
+	if command := this.Palette.GetSelectedCommand(); command != nil {
+		return command.GetNoPrefixText()
+	}
+	return ""
+}
+
+func (this *CommandPalettePopup) PreparePopup() {
+	//
+}
+
+func (this *CommandPalettePopup) GetPopupSize(width int,
+	maxWidth int, maxHeight int) (int, int) {
+	return this.Palette.GetPreferredSize(maxWidth, maxHeight)
+}
+
+func (this *CommandPalettePopup) NotifyBeforeShow() {
+	//
+}
+
+func (this *CommandPalettePopup) NotifyAfterShow() {
+	//
+}
+
+func (this *CommandPalettePopup) SetContainer(container DropdownPopupContainer) {
+	//
+}
+
+// ShowCommandPalette pops up a command palette listing the commands of the manager,
+// centered near the top of the owner window. The chosen command is executed
+// through CommandManager.NotifyExecute after the palette closes,
+// and the focus returns to the window that had it.
+func ShowCommandPalette(owner TopWindow, manager *CommandManager) error {
+	palette := NewCommandPaletteObject()
+	palette.Manager = manager
+	err := palette.Create(WindowOptions{
+		ParentHandle: owner.GetHandle(),
+		StyleExclude: win32.WS_VISIBLE,
+	})
+	if err != nil {
+		return err
+	}
+	if palette.edit == nil { //the child controls failed, as reported
+		palette.Destroy()
+		return NewWin32Error("CommandPalette.Create", win32.ERROR_CANNOT_MAKE)
+	}
+	palette.Reload()
+	hWndFocus := win32.GetFocus()
+
+	popup := NewCommandPalettePopup(palette)
+	container := NewDropdownPopupContainerObject()
+	container.Popup = popup
+	container.HasBorder = true
+	container.NoAnim = true
+
+	var rc win32.RECT
+	win32.GetClientRect(owner.GetHandle(), &rc)
+	pt := win32.POINT{X: rc.Left + (rc.Right-rc.Left)/2, Y: rc.Top}
+	win32.ClientToScreen(owner.GetHandle(), &pt)
+	cx, _ := palette.GetPreferredSize(int(rc.Right-rc.Left), 4096)
+	container.DropdownRect = Rect{
+		Left:   int(pt.X) - cx/2,
+		Top:    int(pt.Y),
+		Right:  int(pt.X) - cx/2 + cx,
+		Bottom: int(pt.Y) + DpiScale(8),
+	}
+
+	closed := false
+	closePalette := func(command *Command) {
+		if closed {
+			return
+		}
+		closed = true
+		if command != nil {
+			index := palette.listBox.GetSelectedIndex()
+			palette.getRecent().Add(palette.candidates[palette.results[index].Index].Key)
+		}
+		container.Close()
+		//the palette is closed after the message being processed, which may be its own
+		win32.PostMessage(palette.Handle, win32.WM_CLOSE, 0, 0)
+		ReportError(Dispatcher.Invoke(func() {
+			if hWndFocus != 0 && win32.IsWindow(hWndFocus) == win32.TRUE {
+				win32.SetFocus(hWndFocus)
+			}
+			if command != nil {
+				command.NotifyExecute()
+			}
+		}))
+	}
+	popup.GetOnOk().AddListener(func(ei *SimpleEventInfo) {
+		closePalette(palette.GetSelectedCommand())
+	})
+	popup.GetOnCancel().AddListener(func(ei *SimpleEventInfo) {
+		closePalette(nil)
+	})
+	container.OnDeactivate.AddListener(func(ei *SimpleEventInfo) {
+		closePalette(nil)
+	})
+
+	err = container.CreateFor(owner.GetHandle())
+	if err != nil {
+		palette.Destroy()
+		return err
+	}
+	container.Show()
+	return nil
+}
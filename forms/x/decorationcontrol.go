@@ -11,6 +11,7 @@ type DecorationControl interface {
 
 type DecorationControlObject struct {
 	ControlObject
+	super *ControlObject
 }
 
 func (this *DecorationControlObject) GetControlSpecStyle() (include, exclude WINDOW_STYLE) {
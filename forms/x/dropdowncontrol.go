@@ -2,6 +2,7 @@ package forms
 
 import (
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing"
 	. "github.com/zzl/goforms/forms"
 	"syscall"
 )
@@ -24,6 +25,8 @@ type DropdownControlObject struct {
 	super *CustomControlObject
 
 	PopupBorder bool
+	Skin        *drawing.NinePatch //drawn instead of the themed combobox border if set
+	PopupSkin   *drawing.NinePatch //drawn as the popup border if set, with PopupBorder
 
 	mouseOnButton  bool
 	droppingDown   bool
@@ -86,6 +89,7 @@ func (this *DropdownControlObject) DropDown() {
 	ppc.Popup = popup
 	popup.SetContainer(ppc)
 	ppc.HasBorder = this.PopupBorder
+	ppc.BorderSkin = this.PopupSkin
 
 	ppc.CreateFor(this.Handle)
 	ppc.OnDeactivate.AddListener(func(ei *SimpleEventInfo) {
@@ -132,11 +136,70 @@ type comboBoxMetrics struct {
 func (this *DropdownControlObject) GetPreferredSize(cxMax int, cyMax int) (int, int) {
 	if this.Handle != 0 {
 		cbm := GetComboBoxMetrics(this.Handle)
+		if this.Skin != nil {
+			cx, cy := this.measureText()
+			cx += int(cbm.RcButton.Right-cbm.RcButton.Left) + 2
+			return this.Skin.GetPreferredSize(cx, cy, this.skinDpi())
+		}
 		return 32, cbm.Height
 	}
 	return 0, 0
 }
 
+func (this *DropdownControlObject) getFont() win32.HFONT {
+	if this.GetFont() != nil {
+		return this.GetFont().Handle
+	}
+	return GetDefaultFont()
+}
+
+func (this *DropdownControlObject) measureText() (int, int) {
+	hdc := win32.GetDC(this.Handle)
+	hOriFont := win32.SelectObject(hdc, win32.HGDIOBJ(this.getFont()))
+	cx, cy := MeasureDcText(hdc, this.text)
+	win32.SelectObject(hdc, hOriFont)
+	win32.ReleaseDC(this.Handle, hdc)
+	return cx, cy
+}
+
+// skinDpi returns the DPI the skin is drawn at, that of the control's device context.
+func (this *DropdownControlObject) skinDpi() float32 {
+	return float32(WindowDpi(this.Handle))
+}
+
+// paintSkin draws the skin into a rect.
+func (this *DropdownControlObject) paintSkin(hdc win32.HDC, rc win32.RECT) {
+	s := NewScope()
+	defer s.Leave()
+	g, err := drawing.NewGraphicsFromHdc(s, hdc)
+	if err != nil {
+		return
+	}
+	this.Skin.Draw(g, drawing.Rc(rc.Left, rc.Top, rc.Right-rc.Left, rc.Bottom-rc.Top))
+}
+
+// getContentRect returns the client rect inside the padding of the skin, if set.
+func (this *DropdownControlObject) getContentRect() win32.RECT {
+	var rcClient win32.RECT
+	win32.GetClientRect(this.Handle, &rcClient)
+	if this.Skin == nil {
+		return rcClient
+	}
+	rect := drawing.Rc(rcClient.Left, rcClient.Top,
+		rcClient.Right-rcClient.Left, rcClient.Bottom-rcClient.Top)
+	content := this.Skin.ContentRect(rect, this.skinDpi())
+	return win32.RECT{Left: content.X, Top: content.Y,
+		Right: content.X + content.Width, Bottom: content.Y + content.Height}
+}
+
+// getButtonRect returns the rect of the drop-down button, at the right of the content rect.
+func (this *DropdownControlObject) getButtonRect(rcContent win32.RECT) win32.RECT {
+	cbm := GetComboBoxMetrics(this.Handle)
+	rcButton := rcContent
+	rcButton.Left = rcContent.Right - (cbm.RcButton.Right - cbm.RcButton.Left) - 2
+	return rcButton
+}
+
 func (this *DropdownControlObject) OnSetFocus() {
 	this.Invalidate()
 }
@@ -160,11 +223,8 @@ func (this *DropdownControlObject) OnMouseDown(x int32, y int32, button byte) {
 }
 
 func (this *DropdownControlObject) OnMouseMove(x int32, y int32, button byte) {
-	var rcClient win32.RECT
-	win32.GetClientRect(this.Handle, &rcClient)
-	cbm := GetComboBoxMetrics(this.Handle)
-	buttonLeft := rcClient.Right - (cbm.RcButton.Right - cbm.RcButton.Left) - 2
-	mouseOnButton := x >= buttonLeft
+	rcButton := this.getButtonRect(this.getContentRect())
+	mouseOnButton := x >= rcButton.Left
 	if mouseOnButton != this.mouseOnButton {
 		this.mouseOnButton = mouseOnButton
 		this.Invalidate()
@@ -183,31 +243,46 @@ func (this *DropdownControlObject) OnMouseLeave() {
 func (this *DropdownControlObject) OnPaint(hdc win32.HDC, prcClip *win32.RECT) {
 	var rcClient win32.RECT
 	win32.GetClientRect(this.Handle, &rcClient)
-	cbm := GetComboBoxMetrics(this.Handle)
 
 	focused := this.HasFocus()
 
+	if this.Skin != nil {
+		this.paintSkin(hdc, rcClient)
+	}
+	rcContent := this.getContentRect()
+	rcButton := this.getButtonRect(rcContent)
+
 	pwsz, _ := syscall.UTF16PtrFromString("Combobox")
 	hTheme := win32.OpenThemeData(this.Handle, pwsz)
-	var rcButton win32.RECT
 	if hTheme == 0 {
-		//?
+		//classic look without visual styles
+		rcFrame := rcButton
+		if this.Skin == nil {
+			win32.DrawEdge(hdc, &rcClient, win32.EDGE_SUNKEN, win32.BF_RECT)
+			win32.InflateRect(&rcFrame, -2, -2)
+		}
+		state := win32.DFCS_SCROLLCOMBOBOX
+		if this.droppingDown {
+			state |= win32.DFCS_PUSHED
+		} else if this.mouseOnButton {
+			state |= win32.DFCS_HOT
+		}
+		win32.DrawFrameControl(hdc, &rcFrame, win32.DFC_SCROLL, state)
 	} else {
-		//bg
-		win32.DrawThemeBackground(hTheme, hdc, 2, 0, &rcClient, prcClip)
 		var state int32
-		state = 1
-		if focused || this.droppingDown {
-			state = 3
-		} else if this.IsMouseHovering() {
-			state = 2
+		if this.Skin == nil {
+			//bg
+			win32.DrawThemeBackground(hTheme, hdc, 2, 0, &rcClient, prcClip)
+			state = 1
+			if focused || this.droppingDown {
+				state = 3
+			} else if this.IsMouseHovering() {
+				state = 2
+			}
+			//bdr
+			win32.DrawThemeBackground(hTheme, hdc, 4, state, &rcClient, prcClip)
 		}
-		//bdr
-		win32.DrawThemeBackground(hTheme, hdc, 4, state, &rcClient, prcClip)
 		//btn
-		rcButton.Right = rcClient.Right
-		rcButton.Left = rcClient.Right - (cbm.RcButton.Right - cbm.RcButton.Left) - 2
-		rcButton.Bottom = rcClient.Bottom
 		state = 1
 		if this.mouseOnButton {
 			state = 2
@@ -223,11 +298,7 @@ func (this *DropdownControlObject) OnPaint(hdc win32.HDC, prcClip *win32.RECT) {
 		win32.CloseThemeData(hTheme)
 	}
 
-	hFont := GetDefaultFont()
-	if this.GetFont() != nil {
-		hFont = this.GetFont().Handle
-	}
-	hOriFont := win32.SelectObject(hdc, win32.HGDIOBJ(hFont))
+	hOriFont := win32.SelectObject(hdc, win32.HGDIOBJ(this.getFont()))
 
 	var clrFg, clrBg win32.COLORREF
 	if this.HasFocus() {
@@ -242,10 +313,15 @@ func (this *DropdownControlObject) OnPaint(hdc win32.HDC, prcClip *win32.RECT) {
 
 	wsz, _ := syscall.UTF16FromString(text)
 	var rcText win32.RECT
-	rcText.Left = 3
-	rcText.Top = 3
-	rcText.Bottom = rcClient.Bottom - 3
-	rcText.Right = rcButton.Left - 2
+	if this.Skin != nil {
+		rcText = rcContent
+		rcText.Right = rcButton.Left
+	} else {
+		rcText.Left = 3
+		rcText.Top = 3
+		rcText.Bottom = rcClient.Bottom - 3
+		rcText.Right = rcButton.Left - 2
+	}
 
 	win32.SetBkColor(hdc, clrBg)
 	win32.SetTextColor(hdc, clrFg)
@@ -264,13 +340,15 @@ func (this *DropdownControlObject) GetControlSpecStyle() (include, exclude WINDO
 }
 
 func (this *DropdownControlObject) Create(options WindowOptions) error {
-	cbm := GetComboBoxMetrics(options.ParentHandle)
-	options.Height = cbm.Height
+	if this.Skin == nil {
+		cbm := GetComboBoxMetrics(options.ParentHandle)
+		options.Height = cbm.Height
+	}
 	return this.super.Create(options)
 }
 
 func (this *DropdownControlObject) SetBounds(left, top, width, height int) {
-	if this.Handle != 0 {
+	if this.Handle != 0 && this.Skin == nil {
 		cbm := GetComboBoxMetrics(this.Handle)
 		height = cbm.Height
 	}
@@ -2,6 +2,7 @@ package forms
 
 import (
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing"
 	. "github.com/zzl/goforms/forms"
 	"github.com/zzl/goforms/framework/virtual"
 )
@@ -16,6 +17,7 @@ type DropdownPopupBorderObject struct {
 
 	Color   win32.COLORREF
 	Visible bool
+	Skin    *drawing.NinePatch //drawn instead of the 1-pixel line if set
 }
 
 func NewDropdownPopupBorderObject() *DropdownPopupBorderObject {
@@ -35,7 +37,9 @@ func (this *DropdownPopupBorderObject) WinProc(win *WindowObject, m *Message) er
 	if m.UMsg == win32.WM_PAINT {
 		var ps win32.PAINTSTRUCT
 		win32.BeginPaint(win.Handle, &ps)
-		if this.Visible {
+		if this.Visible && this.Skin != nil {
+			this.paintSkin(ps.Hdc)
+		} else if this.Visible {
 			hdc := ps.Hdc
 			//FillSolidRect(ps.Hdc, &ps.RcPaint, win32.RGB(255, 0, 0))
 			var rc win32.RECT
@@ -60,6 +64,28 @@ func (this *DropdownPopupBorderObject) WinProc(win *WindowObject, m *Message) er
 	return win.CallOriWndProc(m)
 }
 
+// paintSkin draws the skin into the client rect.
+func (this *DropdownPopupBorderObject) paintSkin(hdc win32.HDC) {
+	s := NewScope()
+	defer s.Leave()
+	g, err := drawing.NewGraphicsFromHdc(s, hdc)
+	if err != nil {
+		return
+	}
+	var rc win32.RECT
+	win32.GetClientRect(this.Handle, &rc)
+	this.Skin.Draw(g, drawing.Rc(rc.Left, rc.Top, rc.Right-rc.Left, rc.Bottom-rc.Top))
+}
+
+// GetInsets returns the widths of the border around the popup,
+// the padding of the skin if set, or 1 pixel.
+func (this *DropdownPopupBorderObject) GetInsets() drawing.Insets {
+	if this.Skin == nil {
+		return drawing.Insets{Left: 1, Top: 1, Right: 1, Bottom: 1}
+	}
+	return this.Skin.Padding(float32(WindowDpi(this.Handle)))
+}
+
 func (this *DropdownPopupBorderObject) GetDefaultStyle() WINDOW_STYLE {
 	return win32.WS_POPUP //|win32.WS_VISIBLE
 }
@@ -73,7 +99,9 @@ func (this *DropdownPopupBorderObject) SetBounds(left, top, width, height int) {
 	cy := height
 	win32.MoveWindow(this.Handle, int32(left), int32(top), int32(cx), int32(cy), win32.TRUE)
 	hRgnA := win32.CreateRectRgn(0, 0, int32(cx), int32(cy))
-	hRgnX := win32.CreateRectRgn(1, 1, int32(cx-1), int32(cy-1))
+	insets := this.GetInsets()
+	hRgnX := win32.CreateRectRgn(insets.Left, insets.Top,
+		int32(cx)-insets.Right, int32(cy)-insets.Bottom)
 	hRgn := win32.CreateRectRgn(0, 0, 0, 0)
 	win32.CombineRgn(hRgn, hRgnA, hRgnX, win32.RGN_DIFF)
 	win32.SetWindowRgn(this.Handle, hRgn, 1)
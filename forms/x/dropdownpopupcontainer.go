@@ -2,6 +2,7 @@ package forms
 
 import (
 	"github.com/zzl/go-win32api/v2/win32"
+	"github.com/zzl/goforms/drawing"
 	. "github.com/zzl/goforms/forms"
 	"github.com/zzl/goforms/framework/virtual"
 	"unsafe"
@@ -26,9 +27,10 @@ type DropdownPopupContainerObject struct {
 	WindowObject
 	super *WindowObject
 
-	Popup     DropdownPopup
-	HasBorder bool
-	NoAnim    bool
+	Popup      DropdownPopup
+	HasBorder  bool
+	BorderSkin *drawing.NinePatch //the skin of the border if HasBorder
+	NoAnim     bool
 
 	//GetPopupBoundsCallback func() (int, int, int, int)
 	DropdownRect       Rect
@@ -173,6 +175,7 @@ func (this *DropdownPopupContainerObject) CreateFor(hWndDropdown win32.HWND) err
 
 	if this.HasBorder {
 		this.border = NewDropdownPopupBorderObject()
+		this.border.Skin = this.BorderSkin
 		err = this.border.Create(WindowOptions{
 			ParentHandle: this.Handle,
 		})
@@ -200,9 +203,10 @@ func (this *DropdownPopupContainerObject) Show() {
 	x, y, cx, cy := this.getPopupBounds()
 	xPopup, yPopup, cxPopup, cyPopup := 0, 0, cx, cy
 	if this.HasBorder {
-		xPopup, yPopup = 1, 1
-		cxPopup -= 2
-		cyPopup -= 2
+		insets := this.border.GetInsets()
+		xPopup, yPopup = int(insets.Left), int(insets.Top)
+		cxPopup -= int(insets.Left + insets.Right)
+		cyPopup -= int(insets.Top + insets.Bottom)
 	}
 
 	win32.SetWindowPos(this.hWndPopupControl, 0,
@@ -266,17 +270,18 @@ func (this *DropdownPopupContainerObject) getPopupBounds() (int, int, int, int)
 	maxHeight := int(cyScreen) - y
 
 	popupWidth, maxPopupWidth, maxPopupHeight := width, maxWidth, maxHeight
+	var cxBorder, cyBorder int
 	if this.HasBorder {
-		popupWidth -= 2
-		maxPopupWidth -= 2
-		maxPopupHeight -= 2
+		insets := this.border.GetInsets()
+		cxBorder, cyBorder = int(insets.Left+insets.Right), int(insets.Top+insets.Bottom)
+		popupWidth -= cxBorder
+		maxPopupWidth -= cxBorder
+		maxPopupHeight -= cyBorder
 	}
 
 	cx, cy := this.Popup.GetPopupSize(popupWidth, maxPopupWidth, maxPopupHeight)
-	if this.HasBorder {
-		cx += 2
-		cy += 2
-	}
+	cx += cxBorder
+	cy += cyBorder
 
 	if cx > maxWidth {
 		cx = maxWidth
@@ -305,9 +310,10 @@ func (this *DropdownPopupContainerObject) UpdateSize() {
 	x, y, cx, cy := this.getPopupBounds()
 	xPopup, yPopup, cxPopup, cyPopup := 0, 0, cx, cy
 	if this.HasBorder {
-		xPopup, yPopup = 1, 1
-		cxPopup -= 2
-		cyPopup -= 2
+		insets := this.border.GetInsets()
+		xPopup, yPopup = int(insets.Left), int(insets.Top)
+		cxPopup -= int(insets.Left + insets.Right)
+		cyPopup -= int(insets.Top + insets.Bottom)
 	}
 
 	var swpFlags = win32.SWP_NOMOVE | win32.SWP_NOZORDER |
@@ -0,0 +1,236 @@
+// Package bus is a typed publish/subscribe message bus
+// for decoupled components, e.g. windows reacting to each other's selections.
+//
+// Unlike events.Event, which belongs to its source object, a bus connects
+// publishers and subscribers that only share a Topic.
+package bus
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/zzl/goforms/framework/internal/goroutine"
+	"github.com/zzl/goforms/framework/scope"
+	"github.com/zzl/goforms/framework/tasks"
+)
+
+// Topic identifies a kind of message with payload type T.
+// Topics are compared by identity, so they are usually package level variables.
+type Topic[T any] struct {
+	name string
+}
+
+// NewTopic creates a topic. The name is informational only.
+func NewTopic[T any](name string) *Topic[T] {
+	return &Topic[T]{name: name}
+}
+
+func (this *Topic[T]) Name() string {
+	return this.name
+}
+
+// Options are the delivery options of a subscription.
+type Options struct {
+	// UIThread delivers the messages on the UI thread through the dispatcher of the bus.
+	// Delivery is always queued then. Publish fails if the bus has no dispatcher.
+	UIThread bool
+
+	// Queued delivers the messages after the outermost Publish in progress
+	// on the publishing goroutine has delivered its synchronous messages,
+	// instead of during Publish. They are delivered on that goroutine.
+	Queued bool
+}
+
+// ErrNoDispatcher is returned by Publish when a subscriber asks for
+// UI thread delivery on a bus without a dispatcher.
+var ErrNoDispatcher = errors.New("bus has no dispatcher for UI thread delivery")
+
+// Bus routes published messages to the subscribers of their topics.
+type Bus struct {
+	mutex      sync.Mutex
+	dispatcher tasks.Dispatcher
+	subs       map[any][]*Subscription
+	publishing map[uint64]*publishing //the Publish calls in progress by goroutine id
+}
+
+// publishing is the state of the Publish calls in progress on a goroutine.
+type publishing struct {
+	depth    int //the nesting level of the Publish calls
+	queue    []func()
+	draining bool
+}
+
+// New creates a bus. The dispatcher is used for UI thread delivery,
+// it can be nil if no subscriber asks for it.
+func New(dispatcher tasks.Dispatcher) *Bus {
+	return &Bus{dispatcher: dispatcher, subs: map[any][]*Subscription{},
+		publishing: map[uint64]*publishing{}}
+}
+
+// Default is the application-wide bus.
+// The forms package sets its dispatcher to forms.Dispatcher.
+var Default = New(nil)
+
+// SetDispatcher sets the dispatcher used for UI thread delivery.
+func (this *Bus) SetDispatcher(dispatcher tasks.Dispatcher) {
+	this.mutex.Lock()
+	this.dispatcher = dispatcher
+	this.mutex.Unlock()
+}
+
+// Subscription is returned by Subscribe. It implements types.Disposable.
+type Subscription struct {
+	bus      *Bus
+	topic    any
+	options  Options
+	deliver  func(message any)
+	disposed bool
+}
+
+// Dispose unsubscribes. Messages queued for the subscription are dropped.
+func (this *Subscription) Dispose() {
+	bus := this.bus
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	if this.disposed {
+		return
+	}
+	this.disposed = true
+	subs := bus.subs[this.topic]
+	for n, sub := range subs {
+		if sub == this {
+			subs = append(subs[:n:n], subs[n+1:]...)
+			break
+		}
+	}
+	if len(subs) == 0 {
+		delete(bus.subs, this.topic)
+	} else {
+		bus.subs[this.topic] = subs
+	}
+}
+
+func (this *Subscription) isDisposed() bool {
+	this.bus.mutex.Lock()
+	defer this.bus.mutex.Unlock()
+	return this.disposed
+}
+
+// Subscribe subscribes the handler to the topic.
+func Subscribe[T any](bus *Bus, topic *Topic[T], handler func(message T),
+	opts ...*Options) *Subscription {
+	sub := &Subscription{
+		bus:   bus,
+		topic: topic,
+		deliver: func(message any) {
+			handler(message.(T))
+		},
+	}
+	if len(opts) > 0 && opts[0] != nil {
+		sub.options = *opts[0]
+	}
+	bus.mutex.Lock()
+	bus.subs[topic] = append(bus.subs[topic], sub)
+	bus.mutex.Unlock()
+	return sub
+}
+
+// SubscribeScoped subscribes the handler to the topic until the scope leaves.
+func SubscribeScoped[T any](s *scope.Scope, bus *Bus, topic *Topic[T],
+	handler func(message T), opts ...*Options) *Subscription {
+	sub := Subscribe(bus, topic, handler, opts...)
+	s.Add(sub)
+	return sub
+}
+
+// Publish delivers the message to the subscribers of the topic.
+// The synchronous subscribers are called before Publish returns,
+// in the order they subscribed.
+// It returns ErrNoDispatcher if a subscriber asks for UI thread delivery
+// and the bus has no dispatcher, that subscriber not getting the message.
+func Publish[T any](bus *Bus, topic *Topic[T], message T) error {
+	gid := goroutine.ID()
+	bus.mutex.Lock()
+	p := bus.publishing[gid]
+	if p == nil {
+		p = &publishing{}
+		bus.publishing[gid] = p
+	}
+	subs := bus.subs[topic]
+	dispatcher := bus.dispatcher
+	var err error
+	var syncSubs []*Subscription
+	var posts []func()
+	for _, sub := range subs {
+		sub := sub
+		deliver := func() {
+			if !sub.isDisposed() {
+				sub.deliver(message)
+			}
+		}
+		switch {
+		case sub.options.UIThread && dispatcher == nil:
+			err = ErrNoDispatcher
+		case sub.options.UIThread:
+			posts = append(posts, deliver)
+		case sub.options.Queued:
+			p.queue = append(p.queue, deliver)
+		default:
+			syncSubs = append(syncSubs, sub)
+		}
+	}
+	p.depth += 1
+	bus.mutex.Unlock()
+
+	func() {
+		defer func() {
+			bus.mutex.Lock()
+			p.depth -= 1
+			bus.mutex.Unlock()
+		}()
+		for _, deliver := range posts {
+			dispatcher.Post(deliver)
+		}
+		for _, sub := range syncSubs {
+			if !sub.isDisposed() {
+				sub.deliver(message)
+			}
+		}
+	}()
+	bus.drain(gid, p)
+	return err
+}
+
+// drain delivers the messages queued on a goroutine once its outermost Publish
+// has delivered its synchronous messages. Messages queued meanwhile are delivered too.
+func (this *Bus) drain(gid uint64, p *publishing) {
+	this.mutex.Lock()
+	if p.depth > 0 || p.draining {
+		this.mutex.Unlock()
+		return
+	}
+	p.draining = true
+	defer func() {
+		this.mutex.Lock()
+		p.draining = false
+		if len(p.queue) == 0 {
+			delete(this.publishing, gid)
+		}
+		this.mutex.Unlock()
+	}()
+	for len(p.queue) > 0 {
+		deliver := p.queue[0]
+		p.queue = p.queue[1:]
+		this.mutex.Unlock()
+		deliver()
+		this.mutex.Lock()
+	}
+	this.mutex.Unlock()
+}
+
+// HasSubscribers tells whether the topic has any subscriber.
+func HasSubscribers[T any](bus *Bus, topic *Topic[T]) bool {
+	bus.mutex.Lock()
+	defer bus.mutex.Unlock()
+	return len(bus.subs[topic]) > 0
+}
@@ -0,0 +1,155 @@
+package bus
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zzl/goforms/framework/scope"
+	"github.com/zzl/goforms/framework/tasks"
+)
+
+func TestPublishOrder(t *testing.T) {
+	bus := New(nil)
+	outer := NewTopic[string]("outer")
+	inner := NewTopic[string]("inner")
+	var got []string
+	Subscribe(bus, outer, func(message string) {
+		got = append(got, "sync "+message)
+		Publish(bus, inner, message+"/inner")
+		got = append(got, "sync "+message+" done")
+	})
+	Subscribe(bus, inner, func(message string) {
+		got = append(got, "sync "+message)
+	})
+	Subscribe(bus, inner, func(message string) {
+		got = append(got, "queued "+message)
+	}, &Options{Queued: true})
+
+	Publish(bus, outer, "a")
+	//the queued messages of the nested Publish wait for the outer one
+	want := []string{
+		"sync a",
+		"sync a/inner",
+		"sync a done",
+		"queued a/inner",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPublishFromQueued(t *testing.T) {
+	bus := New(nil)
+	topic := NewTopic[int]("count")
+	var got []int
+	Subscribe(bus, topic, func(n int) {
+		got = append(got, n)
+		if n < 3 {
+			Publish(bus, topic, n+1)
+		}
+	}, &Options{Queued: true})
+	Subscribe(bus, topic, func(n int) {
+		got = append(got, -n)
+	})
+	Publish(bus, topic, 1)
+	want := []int{-1, 1, -2, 2, -3, 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestDispose(t *testing.T) {
+	bus := New(nil)
+	topic := NewTopic[int]("n")
+	count := 0
+	var sub *Subscription
+	sub = Subscribe(bus, topic, func(n int) {
+		count += n
+	}, &Options{Queued: true})
+	Subscribe(bus, topic, func(n int) {
+		sub.Dispose() //drops the message queued for sub
+	})
+	Publish(bus, topic, 1)
+	Publish(bus, topic, 1)
+	if count != 0 {
+		t.Errorf("count %d, want the queued messages of the disposed subscription dropped", count)
+	}
+	if !HasSubscribers(bus, topic) {
+		t.Error("no subscribers left, want the one that didn't dispose")
+	}
+}
+
+func TestSubscribeScoped(t *testing.T) {
+	bus := New(nil)
+	topic := NewTopic[int]("n")
+	count := 0
+	scope.WithScope(func(s *scope.Scope) {
+		SubscribeScoped(s, bus, topic, func(n int) {
+			count += n
+		})
+		Publish(bus, topic, 1)
+	})
+	Publish(bus, topic, 1)
+	if count != 1 || HasSubscribers(bus, topic) {
+		t.Errorf("count %d, has subscribers %v after the scope left, want 1, false",
+			count, HasSubscribers(bus, topic))
+	}
+}
+
+func TestUIThread(t *testing.T) {
+	bus := New(nil)
+	topic := NewTopic[string]("ui")
+	var got []string
+	Subscribe(bus, topic, func(message string) {
+		got = append(got, "ui "+message)
+	}, &Options{UIThread: true})
+	Subscribe(bus, topic, func(message string) {
+		got = append(got, "sync "+message)
+	})
+	if err := Publish(bus, topic, "a"); err != ErrNoDispatcher {
+		t.Errorf("Publish without a dispatcher returned %v, want ErrNoDispatcher", err)
+	}
+	if !reflect.DeepEqual(got, []string{"sync a"}) {
+		t.Errorf("got %q without a dispatcher, want only the synchronous delivery", got)
+	}
+
+	dispatcher := tasks.NewQueueDispatcher()
+	bus.SetDispatcher(dispatcher)
+	got = nil
+	if err := Publish(bus, topic, "b"); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"sync b"}) {
+		t.Errorf("got %q before the dispatcher ran, want only the synchronous delivery", got)
+	}
+	dispatcher.RunPending()
+	if !reflect.DeepEqual(got, []string{"sync b", "ui b"}) {
+		t.Errorf("got %q after the dispatcher ran", got)
+	}
+}
+
+// TestGoroutines checks that the messages queued by a Publish on a goroutine
+// are delivered on that goroutine, not by a Publish in progress on another one.
+func TestGoroutines(t *testing.T) {
+	bus := New(nil)
+	outer := NewTopic[string]("outer")
+	other := NewTopic[string]("other")
+	var got []string
+	Subscribe(bus, other, func(message string) {
+		got = append(got, "queued "+message)
+	}, &Options{Queued: true})
+	Subscribe(bus, outer, func(message string) {
+		done := make(chan struct{})
+		go func() {
+			Publish(bus, other, message+"/other")
+			close(done)
+		}()
+		<-done
+		got = append(got, "sync "+message)
+	})
+	Publish(bus, outer, "a")
+	want := []string{"queued a/other", "sync a"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
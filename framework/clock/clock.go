@@ -0,0 +1,42 @@
+// Package clock abstracts the passing of time, so that scheduling logic
+// can run on real time, on the UI thread, or on a virtual clock in tests.
+package clock
+
+import "time"
+
+// Clock tells the time and schedules functions to run later.
+type Clock interface {
+	// Now returns the current time of the clock
+	Now() time.Time
+
+	// AfterFunc calls fn once the duration has elapsed.
+	// Where fn runs depends on the clock, e.g. on its own goroutine for Real.
+	// It returns nil if the call can not be scheduled.
+	AfterFunc(d time.Duration, fn func()) Timer
+}
+
+// Timer is a pending call scheduled by Clock.AfterFunc.
+type Timer interface {
+	// Stop prevents the call from happening.
+	// It returns false if the call has happened or the timer was stopped already.
+	Stop() bool
+}
+
+// Real is the clock of the time package.
+// Functions scheduled with it run on their own goroutines.
+var Real Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, fn func()) Timer {
+	return time.AfterFunc(d, fn)
+}
+
+// Since returns the time elapsed since t on the clock.
+func Since(clock Clock, t time.Time) time.Duration {
+	return clock.Now().Sub(t)
+}
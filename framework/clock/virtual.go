@@ -0,0 +1,116 @@
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Virtual is a clock whose time only moves when told to.
+// Scheduled functions run synchronously on the goroutine advancing the clock,
+// in the order of their due times, with the clock set to their due time.
+type Virtual struct {
+	mutex  sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+}
+
+type virtualTimer struct {
+	clock *Virtual
+	due   time.Time
+	fn    func()
+}
+
+// NewVirtual creates a virtual clock starting at the specified time.
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{now: start}
+}
+
+// Now implements Clock.Now.
+func (this *Virtual) Now() time.Time {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.now
+}
+
+// AfterFunc implements Clock.AfterFunc.
+func (this *Virtual) AfterFunc(d time.Duration, fn func()) Timer {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	timer := &virtualTimer{clock: this, due: this.now.Add(max(d, 0)), fn: fn}
+	this.timers = append(this.timers, timer)
+	sort.SliceStable(this.timers, func(i, j int) bool {
+		return this.timers[i].due.Before(this.timers[j].due)
+	})
+	return timer
+}
+
+func (this *virtualTimer) Stop() bool {
+	clock := this.clock
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	for n, timer := range clock.timers {
+		if timer == this {
+			clock.timers = append(clock.timers[:n], clock.timers[n+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Advance moves the clock forward by the duration,
+// running the functions that become due on the way.
+func (this *Virtual) Advance(d time.Duration) {
+	this.AdvanceTo(this.Now().Add(d))
+}
+
+// AdvanceTo moves the clock forward to the specified time,
+// running the functions that become due on the way.
+// Functions scheduled by them run too, if due by then.
+func (this *Virtual) AdvanceTo(t time.Time) {
+	for {
+		this.mutex.Lock()
+		if len(this.timers) == 0 || this.timers[0].due.After(t) {
+			if t.After(this.now) {
+				this.now = t
+			}
+			this.mutex.Unlock()
+			return
+		}
+		timer := this.timers[0]
+		this.timers = this.timers[1:]
+		if timer.due.After(this.now) {
+			this.now = timer.due
+		}
+		this.mutex.Unlock()
+		timer.fn()
+	}
+}
+
+// RunNext advances the clock to the earliest pending function and runs it.
+// It returns false if no function is pending.
+func (this *Virtual) RunNext() bool {
+	due, ok := this.NextDue()
+	if !ok {
+		return false
+	}
+	this.AdvanceTo(due)
+	return true
+}
+
+// NextDue returns the due time of the earliest pending function.
+func (this *Virtual) NextDue() (time.Time, bool) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if len(this.timers) == 0 {
+		return time.Time{}, false
+	}
+	return this.timers[0].due, true
+}
+
+// Pending returns the number of pending functions.
+func (this *Virtual) Pending() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.timers)
+}
@@ -0,0 +1,253 @@
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Scoring weights of the matcher.
+const (
+	scoreMatch       = 16
+	scoreWordStart   = 8
+	scoreCamelCase   = 7
+	scoreConsecutive = 8
+	scoreExactCase   = 1
+	penaltyGap       = 1
+	penaltyLeading   = 1
+	maxLeadingGap    = 8
+)
+
+// Match is the result of a successful fuzzy match.
+type Match struct {
+	Score     int
+	Positions []int //rune indices of the matched characters in the text
+}
+
+// MatchString matches the pattern against the text as a case-insensitive subsequence
+// and returns the best scoring alignment. Matches at word starts, camel case humps
+// and runs of consecutive characters score higher; gaps are penalized.
+// Spaces in the pattern are ignored.
+func MatchString(pattern string, text string) (Match, bool) {
+	pRunes := []rune(strings.ReplaceAll(pattern, " ", ""))
+	tRunes := []rune(text)
+	m, n := len(pRunes), len(tRunes)
+	if m == 0 {
+		return Match{}, true
+	}
+	if m > n {
+		return Match{}, false
+	}
+	pLower := make([]rune, m)
+	for i, r := range pRunes {
+		pLower[i] = unicode.ToLower(r)
+	}
+	tLower := make([]rune, n)
+	bonuses := make([]int, n)
+	for j, r := range tRunes {
+		tLower[j] = unicode.ToLower(r)
+		bonuses[j] = charBonus(tRunes, j)
+	}
+
+	const none = -1 << 30
+	//scores[i][j] is the best score with pattern[i] matched at text[j]
+	scores := make([][]int, m)
+	froms := make([][]int, m)
+	for i := 0; i < m; i++ {
+		scores[i] = make([]int, n)
+		froms[i] = make([]int, n)
+		for j := 0; j < n; j++ {
+			scores[i][j] = none
+			froms[i][j] = -1
+		}
+	}
+	for i := 0; i < m; i++ {
+		bestPrev, bestPrevAt := none, -1 //best of scores[i-1][k] - gap penalty, k < j-1
+		for j := i; j < n; j++ {
+			if i > 0 && j >= 2 && scores[i-1][j-2] != none {
+				if bestPrev != none {
+					bestPrev -= penaltyGap
+				}
+				if scores[i-1][j-2]-penaltyGap > bestPrev {
+					bestPrev, bestPrevAt = scores[i-1][j-2]-penaltyGap, j-2
+				}
+			} else if bestPrev != none {
+				bestPrev -= penaltyGap
+			}
+			if tLower[j] != pLower[i] {
+				continue
+			}
+			score := scoreMatch + bonuses[j]
+			if tRunes[j] == pRunes[i] {
+				score += scoreExactCase
+			}
+			if i == 0 {
+				scores[i][j] = score - min(j, maxLeadingGap)*penaltyLeading
+				continue
+			}
+			best, from := none, -1
+			if j >= 1 && scores[i-1][j-1] != none {
+				best, from = scores[i-1][j-1]+scoreConsecutive, j-1
+			}
+			if bestPrev != none && bestPrev > best {
+				best, from = bestPrev, bestPrevAt
+			}
+			if from == -1 {
+				continue
+			}
+			scores[i][j] = best + score
+			froms[i][j] = from
+		}
+	}
+
+	bestScore, bestAt := none, -1
+	for j := m - 1; j < n; j++ {
+		if scores[m-1][j] > bestScore {
+			bestScore, bestAt = scores[m-1][j], j
+		}
+	}
+	if bestAt == -1 {
+		return Match{}, false
+	}
+	positions := make([]int, m)
+	for i, j := m-1, bestAt; i >= 0; i-- {
+		positions[i] = j
+		j = froms[i][j]
+	}
+	return Match{Score: bestScore, Positions: positions}, true
+}
+
+func charBonus(runes []rune, j int) int {
+	if j == 0 {
+		return scoreWordStart
+	}
+	prev, cur := runes[j-1], runes[j]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		if unicode.IsLetter(cur) || unicode.IsDigit(cur) {
+			return scoreWordStart
+		}
+		return 0
+	}
+	if unicode.IsLower(prev) && unicode.IsUpper(cur) {
+		return scoreCamelCase
+	}
+	if !unicode.IsDigit(prev) && unicode.IsDigit(cur) {
+		return scoreCamelCase
+	}
+	return 0
+}
+
+// Candidate is an item to be ranked.
+type Candidate struct {
+	Key    string   //identifies the candidate in the recent list
+	Text   string   //the primary text, whose match positions are reported
+	Extras []string //secondary texts such as a category or description, scored at half weight
+}
+
+// Result is a ranked candidate.
+type Result struct {
+	Index int //index into the candidates
+	Match
+}
+
+// Rank returns the candidates matching the pattern, best first.
+// Ties are broken by recent use and then by the original order.
+// With an empty pattern all candidates are returned, recently used ones first.
+func Rank(pattern string, candidates []Candidate, recent *RecentList) []Result {
+	pattern = strings.TrimSpace(pattern)
+	var results []Result
+	for n, candidate := range candidates {
+		match, ok := MatchString(pattern, candidate.Text)
+		if !ok {
+			match = Match{Score: -1 << 30}
+		}
+		for _, extra := range candidate.Extras {
+			extraMatch, extraOk := MatchString(pattern, extra)
+			if extraOk && extraMatch.Score/2 > match.Score {
+				match = Match{Score: extraMatch.Score / 2}
+				ok = true
+			}
+		}
+		if ok {
+			results = append(results, Result{Index: n, Match: match})
+		}
+	}
+	recentRank := func(index int) int {
+		if recent == nil {
+			return -1
+		}
+		return recent.Rank(candidates[index].Key)
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		r1, r2 := results[i], results[j]
+		if r1.Score != r2.Score {
+			return r1.Score > r2.Score
+		}
+		rank1, rank2 := recentRank(r1.Index), recentRank(r2.Index)
+		if rank1 != rank2 {
+			if rank1 == -1 {
+				return false
+			}
+			if rank2 == -1 {
+				return true
+			}
+			return rank1 < rank2
+		}
+		return r1.Index < r2.Index
+	})
+	return results
+}
+
+// RecentList keeps the most recently used keys, most recent first.
+type RecentList struct {
+	Max int //maximum number of keys kept, 0 means 20
+
+	keys []string
+}
+
+func NewRecentList(max int) *RecentList {
+	return &RecentList{Max: max}
+}
+
+// Add moves the key to the front of the list.
+func (this *RecentList) Add(key string) {
+	if key == "" {
+		return
+	}
+	keys := []string{key}
+	for _, k := range this.keys {
+		if k != key {
+			keys = append(keys, k)
+		}
+	}
+	max := this.Max
+	if max <= 0 {
+		max = 20
+	}
+	if len(keys) > max {
+		keys = keys[:max]
+	}
+	this.keys = keys
+}
+
+// Rank returns the position of the key, 0 being the most recent, or -1 if absent.
+func (this *RecentList) Rank(key string) int {
+	for n, k := range this.keys {
+		if k == key {
+			return n
+		}
+	}
+	return -1
+}
+
+func (this *RecentList) Keys() []string {
+	return append([]string(nil), this.keys...)
+}
+
+// SetKeys replaces the list, e.g. with keys restored from settings.
+func (this *RecentList) SetKeys(keys []string) {
+	this.keys = nil
+	for n := len(keys) - 1; n >= 0; n-- {
+		this.Add(keys[n])
+	}
+}
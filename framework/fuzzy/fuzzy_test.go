@@ -0,0 +1,128 @@
+package fuzzy
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchString(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		text      string
+		ok        bool
+		score     int
+		positions []int
+	}{
+		{"", "Open", true, 0, nil},
+		{"ab", "ab", true, 50, []int{0, 1}},
+		{"AB", "ab", true, 48, []int{0, 1}},
+		{"ab", "xab", true, 41, []int{1, 2}},
+		{"of", "Open File", true, 44, []int{0, 5}},
+		{"o f", "Open File", true, 44, []int{0, 5}},
+		{"fb", "FooBar", true, 45, []int{0, 3}},
+		{"f2", "File2", true, 45, []int{0, 4}},
+		//word starts outweigh a consecutive run
+		{"ab", "a_b ab", true, 49, []int{0, 2}},
+		{"ba", "ab", false, 0, nil},
+		{"abc", "ab", false, 0, nil},
+		{"x", "Open", false, 0, nil},
+	}
+	for _, test := range tests {
+		match, ok := MatchString(test.pattern, test.text)
+		if ok != test.ok || match.Score != test.score || !reflect.DeepEqual(match.Positions, test.positions) {
+			t.Errorf("MatchString(%q, %q) = %+v, %v, want {%d %v}, %v", test.pattern, test.text,
+				match, ok, test.score, test.positions, test.ok)
+		}
+	}
+}
+
+func TestMatchStringOrder(t *testing.T) {
+	score := func(pattern, text string) int {
+		match, _ := MatchString(pattern, text)
+		return match.Score
+	}
+	better := [][3]string{
+		//pattern, better text, worse text
+		{"sa", "Save As", "Disable"},
+		{"op", "Open Project", "Stop"},
+		{"ff", "Find File", "Diff"},
+		{"fs", "FileSave", "Files"},
+		{"Save", "Save", "save"},
+	}
+	for _, b := range better {
+		if score(b[0], b[1]) <= score(b[0], b[2]) {
+			t.Errorf("%q scores %d in %q, not above %d in %q", b[0],
+				score(b[0], b[1]), b[1], score(b[0], b[2]), b[2])
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	candidates := []Candidate{
+		{Key: "open", Text: "Open"},
+		{Key: "close", Text: "Close"},
+		{Key: "saveAs", Text: "Save As"},
+		{Key: "save", Text: "Save"},
+		{Key: "saveAll", Text: "Save All"},
+		{Key: "export", Text: "Export", Extras: []string{"File"}},
+		{Key: "openFile", Text: "Open File"},
+	}
+	recent := NewRecentList(5)
+	indices := func(results []Result) []int {
+		var indices []int
+		for _, result := range results {
+			indices = append(indices, result.Index)
+		}
+		return indices
+	}
+	tests := []struct {
+		name    string
+		pattern string
+		recent  *RecentList
+		want    []int
+	}{
+		{"all in order", "", nil, []int{0, 1, 2, 3, 4, 5, 6}},
+		{"best first", "o", nil, []int{0, 6, 1, 5}},
+		//no penalty for the unmatched rest of the text
+		{"ties in order", "save", nil, []int{2, 3, 4}},
+		{"extras at half weight", "file", nil, []int{6, 5}},
+		{"no match", "xyz", nil, nil},
+		{"ties by recent use", "save", recent, []int{4, 2, 3}},
+		{"recent first", " ", recent, []int{4, 1, 0, 2, 3, 5, 6}},
+	}
+	recent.Add("close")
+	recent.Add("saveAll")
+	for _, test := range tests {
+		results := Rank(test.pattern, candidates, test.recent)
+		if got := indices(results); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: ranked %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	results := Rank("of", candidates, nil)
+	if len(results) != 1 || results[0].Index != 6 || !reflect.DeepEqual(results[0].Positions, []int{0, 5}) {
+		t.Errorf("ranked %+v, want Open File matched at 0 and 5", results)
+	}
+	//a match in an extra text has no positions in the primary text
+	results = Rank("file", candidates[5:6], nil)
+	if len(results) != 1 || results[0].Positions != nil || results[0].Score <= 0 {
+		t.Errorf("ranked %+v, want Export matched by its extra", results)
+	}
+}
+
+func TestRecentList(t *testing.T) {
+	recent := NewRecentList(3)
+	for _, key := range []string{"a", "b", "", "c", "a", "d"} {
+		recent.Add(key)
+	}
+	if got := recent.Keys(); !reflect.DeepEqual(got, []string{"d", "a", "c"}) {
+		t.Errorf("keys %v, want [d a c]", got)
+	}
+	if recent.Rank("a") != 1 || recent.Rank("b") != -1 {
+		t.Errorf("ranks %d, %d, want 1, -1", recent.Rank("a"), recent.Rank("b"))
+	}
+	recent.SetKeys([]string{"x", "y", "x", "z", "w"})
+	if got := recent.Keys(); !reflect.DeepEqual(got, []string{"x", "y", "z"}) {
+		t.Errorf("keys %v after SetKeys, want [x y z]", got)
+	}
+}
@@ -0,0 +1,22 @@
+// Package goroutine identifies goroutines, for the framework packages
+// keeping state per goroutine.
+package goroutine
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ID parses the id of the current goroutine from its stack trace header,
+// "goroutine 1 [running]:".
+func ID() uint64 {
+	var buf [64]byte
+	n := runtime.Stack(buf[:], false)
+	fields := strings.Fields(string(buf[:n]))
+	if len(fields) < 2 {
+		return 0
+	}
+	id, _ := strconv.ParseUint(fields[1], 10, 64)
+	return id
+}
@@ -0,0 +1,182 @@
+package keys
+
+import (
+	"strings"
+)
+
+// KeyChord is a sequence of key strokes pressed one after another,
+// such as "Ctrl+K, Ctrl+C". A single key stroke is a chord of length 1.
+type KeyChord []KeyStroke
+
+// ParseKeyChord parses a comma separated sequence of key strokes.
+func ParseKeyChord(s string) (KeyChord, error) {
+	var chord KeyChord
+	for _, part := range splitChord(s) {
+		ks, err := ParseKeyStroke(part)
+		if err != nil {
+			return nil, err
+		}
+		chord = append(chord, ks)
+	}
+	if chord == nil {
+		_, err := ParseKeyStroke(s)
+		return nil, err
+	}
+	return chord, nil
+}
+
+// splitChord splits at commas, except for the comma key itself, as in "Ctrl+,".
+func splitChord(s string) []string {
+	var parts []string
+	start := 0
+	for n := 0; n < len(s); n++ {
+		if s[n] != ',' {
+			continue
+		}
+		prefix := strings.TrimSpace(s[start:n])
+		if prefix == "" || strings.HasSuffix(prefix, "+") {
+			continue //the comma key
+		}
+		parts = append(parts, prefix)
+		start = n + 1
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// String returns the chord formatted for display, as DisplayString.
+func (me KeyChord) String() string {
+	return me.DisplayString()
+}
+
+// Canonical returns the canonical form of the chord, such as "Ctrl+K, Ctrl+C",
+// which can be parsed back by ParseKeyChord.
+func (me KeyChord) Canonical() string {
+	strs := make([]string, len(me))
+	for n, ks := range me {
+		strs[n] = ks.Canonical()
+	}
+	return strings.Join(strs, ", ")
+}
+
+func (me KeyChord) DisplayString() string {
+	strs := make([]string, len(me))
+	for n, ks := range me {
+		strs[n] = ks.DisplayString()
+	}
+	return strings.Join(strs, ", ")
+}
+
+func (me KeyChord) Equals(other KeyChord) bool {
+	if len(me) != len(other) {
+		return false
+	}
+	for n := range me {
+		if me[n] != other[n] {
+			return false
+		}
+	}
+	return true
+}
+
+// HasPrefix reports whether the chord starts with the prefix.
+func (me KeyChord) HasPrefix(prefix KeyChord) bool {
+	return len(prefix) <= len(me) && me[:len(prefix)].Equals(prefix)
+}
+
+// ChordResult is the outcome of feeding a key stroke into a ChordMatcher.
+type ChordResult int
+
+const (
+	ChordNone     ChordResult = iota // the stroke is not bound
+	ChordPending                     // the stroke is a prefix of one or more chords
+	ChordMatched                     // the stroke completed a chord
+	ChordCanceled                    // the stroke broke a pending chord
+)
+
+type chordBinding[T any] struct {
+	chord  KeyChord
+	target T
+}
+
+// ChordMatcher is a state machine that matches key strokes against bound chords.
+// A stroke that is a prefix of some chord leaves the matcher pending;
+// when a stroke is both a complete chord and a prefix, the prefix wins.
+type ChordMatcher[T any] struct {
+	bindings []chordBinding[T]
+	pending  KeyChord
+}
+
+func (this *ChordMatcher[T]) Bind(chord KeyChord, target T) {
+	if len(chord) == 0 {
+		return
+	}
+	this.bindings = append(this.bindings, chordBinding[T]{chord, target})
+}
+
+// ClearBindings removes all bindings. The pending state is kept.
+func (this *ChordMatcher[T]) ClearBindings() {
+	this.bindings = nil
+}
+
+// HasBindings reports whether any chord is bound.
+func (this *ChordMatcher[T]) HasBindings() bool {
+	return len(this.bindings) > 0
+}
+
+// IsPrefix reports whether the chord is a proper prefix of a bound chord.
+func (this *ChordMatcher[T]) IsPrefix(chord KeyChord) bool {
+	for _, binding := range this.bindings {
+		if len(binding.chord) > len(chord) && binding.chord.HasPrefix(chord) {
+			return true
+		}
+	}
+	return false
+}
+
+// Pending returns the strokes of the chord being entered.
+func (this *ChordMatcher[T]) Pending() KeyChord {
+	return this.pending
+}
+
+func (this *ChordMatcher[T]) Reset() {
+	this.pending = nil
+}
+
+// Feed advances the state machine with a key stroke.
+// The target is valid only when the result is ChordMatched.
+func (this *ChordMatcher[T]) Feed(ks KeyStroke) (ChordResult, T) {
+	var target T
+	wasPending := len(this.pending) > 0
+	chord := append(append(KeyChord(nil), this.pending...), ks)
+
+	var matched *chordBinding[T]
+	isPrefix := false
+	for n := range this.bindings {
+		binding := &this.bindings[n]
+		if !binding.chord.HasPrefix(chord) {
+			continue
+		}
+		if len(binding.chord) == len(chord) {
+			if matched == nil {
+				matched = binding
+			}
+		} else {
+			isPrefix = true
+		}
+	}
+	if isPrefix {
+		this.pending = chord
+		return ChordPending, target
+	}
+	this.pending = nil
+	if matched != nil {
+		return ChordMatched, matched.target
+	}
+	if wasPending {
+		return ChordCanceled, target
+	}
+	return ChordNone, target
+}
@@ -0,0 +1,87 @@
+package keys
+
+import "testing"
+
+func TestParseKeyChord(t *testing.T) {
+	ctrl := func(key byte) KeyStroke {
+		return KeyStroke{Ctrl: true, Key: key}
+	}
+	tests := []struct {
+		in   string
+		want KeyChord
+	}{
+		{"Ctrl+K, Ctrl+C", KeyChord{ctrl('K'), ctrl('C')}},
+		{"Ctrl+K,Ctrl+C", KeyChord{ctrl('K'), ctrl('C')}},
+		{"Ctrl+,", KeyChord{ctrl(VK_OEM_COMMA)}},
+		{"Ctrl+,, Ctrl+K", KeyChord{ctrl(VK_OEM_COMMA), ctrl('K')}},
+		{"Ctrl+K, Ctrl+,", KeyChord{ctrl('K'), ctrl(VK_OEM_COMMA)}},
+		{"Ctrl+K, Ctrl++", KeyChord{ctrl('K'), ctrl(VK_OEM_PLUS)}},
+		{"Ctrl+K, G", KeyChord{ctrl('K'), {Key: 'G'}}},
+	}
+	for _, test := range tests {
+		got, err := ParseKeyChord(test.in)
+		if err != nil || !got.Equals(test.want) {
+			t.Errorf("ParseKeyChord(%q) = %v, %v, want %v", test.in, got, err, test.want)
+			continue
+		}
+		back, err := ParseKeyChord(got.Canonical())
+		if err != nil || !back.Equals(got) {
+			t.Errorf("ParseKeyChord(%q) = %v, %v, want %v", got.Canonical(), back, err, got)
+		}
+	}
+	for _, in := range []string{"", " ", "Ctrl+K, Foo", "Foo, Ctrl+K"} {
+		if chord, err := ParseKeyChord(in); err == nil {
+			t.Errorf("ParseKeyChord(%q) = %v, want an error", in, chord)
+		}
+	}
+}
+
+func TestChordMatcher(t *testing.T) {
+	var m ChordMatcher[string]
+	m.Bind(KeyChord{{Ctrl: true, Key: 'K'}, {Ctrl: true, Key: 'C'}}, "comment")
+	m.Bind(KeyChord{{Ctrl: true, Key: 'K'}, {Ctrl: true, Key: 'U'}}, "uncomment")
+	m.Bind(KeyChord{{Ctrl: true, Key: 'K'}}, "shadowed")
+	m.Bind(KeyChord{{Ctrl: true, Key: 'S'}}, "save")
+	m.Bind(KeyChord{{Ctrl: true, Key: 'S'}}, "second save")
+
+	steps := []struct {
+		key    byte
+		result ChordResult
+		target string
+	}{
+		{'S', ChordMatched, "save"},
+		{'K', ChordPending, ""},
+		{'C', ChordMatched, "comment"},
+		{'K', ChordPending, ""},
+		{'U', ChordMatched, "uncomment"},
+		{'K', ChordPending, ""},
+		{'X', ChordCanceled, ""},
+		{'X', ChordNone, ""},
+		{'K', ChordPending, ""},
+		//a bound stroke doesn't match while it breaks a pending chord
+		{'S', ChordCanceled, ""},
+	}
+	for n, step := range steps {
+		result, target := m.Feed(KeyStroke{Ctrl: true, Key: step.key})
+		if result != step.result || target != step.target {
+			t.Fatalf("step %d, Ctrl+%c: %v, %q, want %v, %q",
+				n, step.key, result, target, step.result, step.target)
+		}
+		if pending := len(m.Pending()) > 0; pending != (result == ChordPending) {
+			t.Fatalf("step %d: pending chord %v after %v", n, m.Pending(), result)
+		}
+	}
+
+	if !m.IsPrefix(KeyChord{{Ctrl: true, Key: 'K'}}) || m.IsPrefix(KeyChord{{Ctrl: true, Key: 'S'}}) {
+		t.Error("IsPrefix")
+	}
+	m.Feed(KeyStroke{Ctrl: true, Key: 'K'})
+	m.ClearBindings()
+	if m.HasBindings() || len(m.Pending()) != 1 {
+		t.Error("ClearBindings should remove the bindings and keep the pending chord")
+	}
+	m.Reset()
+	if len(m.Pending()) != 0 {
+		t.Error("Reset kept the pending chord")
+	}
+}
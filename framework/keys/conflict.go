@@ -0,0 +1,255 @@
+package keys
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ReservedKeyStrokes are key strokes handled by the system,
+// which should not be bound to commands.
+var ReservedKeyStrokes = []KeyStroke{
+	{Alt: true, Key: VK_F1 + 3}, //Alt+F4
+	{Alt: true, Key: VK_TAB},    //Alt+Tab
+	{Alt: true, Shift: true, Key: VK_TAB},
+	{Alt: true, Key: VK_ESCAPE},  //Alt+Esc
+	{Alt: true, Key: VK_SPACE},   //Alt+Space, the system menu
+	{Ctrl: true, Key: VK_ESCAPE}, //Ctrl+Esc, the start menu
+	{Ctrl: true, Shift: true, Key: VK_ESCAPE},
+	{Ctrl: true, Alt: true, Key: VK_DELETE},
+	{Key: VK_F1 + 9}, //F10, menu bar activation
+	{Key: VK_SNAPSHOT},
+}
+
+type ConflictKind int
+
+const (
+	ConflictDuplicate ConflictKind = iota + 1 // the same chord is bound to several commands
+	ConflictPrefix                            // a chord is a prefix of another, so it never fires
+	ConflictMnemonic                          // an Alt+letter shortcut shadows a menu mnemonic
+	ConflictReserved                          // the chord starts with a reserved system key
+)
+
+func (me ConflictKind) String() string {
+	switch me {
+	case ConflictDuplicate:
+		return "duplicate"
+	case ConflictPrefix:
+		return "prefix"
+	case ConflictMnemonic:
+		return "mnemonic"
+	case ConflictReserved:
+		return "reserved"
+	}
+	return "unknown"
+}
+
+// Conflict describes a problem with the shortcuts of a keymap.
+type Conflict struct {
+	Kind     ConflictKind
+	Chord    KeyChord
+	Commands []string //the command names involved, sorted
+}
+
+func (me Conflict) String() string {
+	return me.Kind.String() + " " + me.Chord.String() +
+		": " + strings.Join(me.Commands, ", ")
+}
+
+// ConflictOptions provides context for FindConflicts.
+type ConflictOptions struct {
+	// Mnemonics maps menu mnemonic keys to the names of the commands owning them
+	Mnemonics map[byte][]string
+
+	// Reserved overrides ReservedKeyStrokes if not nil
+	Reserved []KeyStroke
+}
+
+func (me *ConflictOptions) reserved() []KeyStroke {
+	if me.Reserved != nil {
+		return me.Reserved
+	}
+	return ReservedKeyStrokes
+}
+
+func (me *ConflictOptions) isReserved(ks KeyStroke) bool {
+	for _, reserved := range me.reserved() {
+		if reserved == ks {
+			return true
+		}
+	}
+	return false
+}
+
+// Mnemonic returns the upper-cased mnemonic key of a text with an ampersand prefix,
+// such as 'S' for "&Save", or 0 if there is none. "&&" denotes a literal ampersand.
+func Mnemonic(text string) byte {
+	runes := []rune(text)
+	for n := 0; n < len(runes)-1; n++ {
+		if runes[n] != '&' {
+			continue
+		}
+		c := runes[n+1]
+		if c == '&' {
+			n += 1
+			continue
+		}
+		c = unicode.ToUpper(c)
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			return byte(c)
+		}
+		return 0
+	}
+	return 0
+}
+
+// FindConflicts reports duplicate bindings, unreachable prefix bindings,
+// collisions with menu mnemonics and use of reserved system keys.
+func FindConflicts(keymap Keymap, options ConflictOptions) []Conflict {
+	var conflicts []Conflict
+
+	chordCommands := make(map[string][]string)
+	chords := make(map[string]KeyChord)
+	for _, name := range keymap.Names() {
+		for _, chord := range keymap[name] {
+			if len(chord) == 0 {
+				continue
+			}
+			key := chord.Canonical()
+			if !containsString(chordCommands[key], name) {
+				chordCommands[key] = append(chordCommands[key], name)
+			}
+			chords[key] = chord
+		}
+	}
+	chordKeys := make([]string, 0, len(chords))
+	for key := range chords {
+		chordKeys = append(chordKeys, key)
+	}
+	sort.Strings(chordKeys)
+
+	for _, key := range chordKeys {
+		chord := chords[key]
+		names := chordCommands[key]
+		if len(names) > 1 {
+			conflicts = append(conflicts, Conflict{ConflictDuplicate, chord, names})
+		}
+		for _, otherKey := range chordKeys {
+			other := chords[otherKey]
+			if len(other) > len(chord) && other.HasPrefix(chord) {
+				conflicts = append(conflicts, Conflict{ConflictPrefix, chord,
+					mergeNames(names, chordCommands[otherKey])})
+			}
+		}
+		first := chord[0]
+		if options.isReserved(first) {
+			conflicts = append(conflicts, Conflict{ConflictReserved, chord, names})
+		}
+		if first.Alt && !first.Ctrl && !first.Shift {
+			if owners := options.Mnemonics[first.Key]; len(owners) > 0 {
+				conflicts = append(conflicts, Conflict{ConflictMnemonic, chord,
+					mergeNames(names, owners)})
+			}
+		}
+	}
+	return conflicts
+}
+
+// IsFree reports whether the key stroke can be bound without conflicts.
+func IsFree(ks KeyStroke, keymap Keymap, options ConflictOptions) bool {
+	if ks.Key == 0 || IsModifierKey(ks.Key) || options.isReserved(ks) {
+		return false
+	}
+	if ks.Alt && !ks.Ctrl && !ks.Shift && len(options.Mnemonics[ks.Key]) > 0 {
+		return false
+	}
+	for _, chords := range keymap {
+		for _, chord := range chords {
+			if len(chord) > 0 && chord[0] == ks {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// SuggestKeyStrokes returns up to max free key strokes.
+// Letters and digits of the hint, such as the command text, are tried first,
+// combined with Ctrl, Ctrl+Shift, Ctrl+Alt and Alt+Shift, followed by function keys.
+func SuggestKeyStrokes(hint string, keymap Keymap, options ConflictOptions, max int) []KeyStroke {
+	var candidateKeys []byte
+	seen := make(map[byte]bool)
+	addKey := func(key byte) {
+		if !seen[key] {
+			seen[key] = true
+			candidateKeys = append(candidateKeys, key)
+		}
+	}
+	for _, c := range strings.ToUpper(hint) {
+		if (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			addKey(byte(c))
+		}
+	}
+	modifierSets := []KeyStroke{
+		{Ctrl: true},
+		{Ctrl: true, Shift: true},
+		{Ctrl: true, Alt: true},
+		{Alt: true, Shift: true},
+	}
+	var result []KeyStroke
+	tryAdd := func(ks KeyStroke) bool {
+		if IsFree(ks, keymap, options) {
+			result = append(result, ks)
+		}
+		return len(result) >= max
+	}
+	for _, modifiers := range modifierSets {
+		for _, key := range candidateKeys {
+			ks := modifiers
+			ks.Key = key
+			if tryAdd(ks) {
+				return result
+			}
+		}
+	}
+	fnModifierSets := append([]KeyStroke{{}, {Shift: true}}, modifierSets...)
+	for _, modifiers := range fnModifierSets {
+		for key := VK_F1; key < VK_F1+12; key++ {
+			ks := modifiers
+			ks.Key = key
+			if tryAdd(ks) {
+				return result
+			}
+		}
+	}
+	for _, modifiers := range modifierSets {
+		for key := byte('A'); key <= 'Z'; key++ {
+			ks := modifiers
+			ks.Key = key
+			if tryAdd(ks) {
+				return result
+			}
+		}
+	}
+	return result
+}
+
+func containsString(strs []string, s string) bool {
+	for _, str := range strs {
+		if str == s {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeNames(names1 []string, names2 []string) []string {
+	var result []string
+	for _, name := range append(append([]string{}, names1...), names2...) {
+		if !containsString(result, name) {
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,99 @@
+package keys
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMnemonic(t *testing.T) {
+	tests := []struct {
+		text string
+		want byte
+	}{
+		{"&Save", 'S'},
+		{"Save &as", 'A'},
+		{"&&Save &1", '1'},
+		{"Tom && Jerry", 0},
+		{"Save", 0},
+		{"Save&", 0},
+		{"&?", 0},
+	}
+	for _, test := range tests {
+		if got := Mnemonic(test.text); got != test.want {
+			t.Errorf("Mnemonic(%q) = %q, want %q", test.text, got, test.want)
+		}
+	}
+}
+
+func TestFindConflicts(t *testing.T) {
+	keymap := mustKeymap(t, map[string][]string{
+		"Save":    {"Ctrl+S"},
+		"SaveAll": {"Ctrl+S"},
+		"Kill":    {"Ctrl+K"},
+		"Comment": {"Ctrl+K, Ctrl+C"},
+		"Close":   {"Alt+F4"},
+		"Find":    {"Alt+F"},
+		"Open":    {"Ctrl+O"},
+	})
+	options := ConflictOptions{Mnemonics: map[byte][]string{'F': {"FileMenu"}}}
+	//sorted by chord
+	got := FindConflicts(keymap, options)
+	want := []Conflict{
+		{ConflictMnemonic, KeyChord{{Alt: true, Key: 'F'}}, []string{"FileMenu", "Find"}},
+		{ConflictReserved, KeyChord{{Alt: true, Key: VK_F1 + 3}}, []string{"Close"}},
+		{ConflictPrefix, KeyChord{{Ctrl: true, Key: 'K'}}, []string{"Comment", "Kill"}},
+		{ConflictDuplicate, KeyChord{{Ctrl: true, Key: 'S'}}, []string{"Save", "SaveAll"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts\n%v\nwant\n%v", got, want)
+	}
+
+	//the reserved keys can be replaced
+	options.Reserved = []KeyStroke{{Ctrl: true, Key: 'O'}}
+	options.Mnemonics = nil
+	got = FindConflicts(mustKeymap(t, map[string][]string{"Open": {"Ctrl+O"}, "Close": {"Alt+F4"}}), options)
+	want = []Conflict{{ConflictReserved, KeyChord{{Ctrl: true, Key: 'O'}}, []string{"Open"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("conflicts %v, want %v", got, want)
+	}
+}
+
+func TestIsFreeAndSuggest(t *testing.T) {
+	keymap := mustKeymap(t, map[string][]string{
+		"Save":    {"Ctrl+S"},
+		"Comment": {"Ctrl+K, Ctrl+C"},
+	})
+	options := ConflictOptions{Mnemonics: map[byte][]string{'E': {"EditMenu"}}}
+	tests := []struct {
+		ks   KeyStroke
+		want bool
+	}{
+		{KeyStroke{Ctrl: true, Key: 'S'}, false},
+		{KeyStroke{Ctrl: true, Key: 'K'}, false}, //the prefix of a chord
+		{KeyStroke{Ctrl: true, Key: 'C'}, true},
+		{KeyStroke{Alt: true, Key: 'E'}, false},
+		{KeyStroke{Alt: true, Shift: true, Key: 'E'}, true},
+		{KeyStroke{Alt: true, Key: VK_F1 + 3}, false},
+		{KeyStroke{Ctrl: true, Key: VK_SHIFT}, false},
+		{KeyStroke{}, false},
+	}
+	for _, test := range tests {
+		if got := IsFree(test.ks, keymap, options); got != test.want {
+			t.Errorf("IsFree(%v) = %v, want %v", test.ks.Canonical(), got, test.want)
+		}
+	}
+
+	ctrl := func(key byte) KeyStroke {
+		return KeyStroke{Ctrl: true, Key: key}
+	}
+	got := SuggestKeyStrokes("Save as", keymap, options, 3)
+	want := []KeyStroke{ctrl('A'), ctrl('V'), ctrl('E')}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions %v, want %v", got, want)
+	}
+	got = SuggestKeyStrokes("", keymap, options, 2)
+	want = []KeyStroke{{Key: VK_F1}, {Key: VK_F1 + 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("suggestions without a hint %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,168 @@
+package keys
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/zzl/goforms/framework/settings"
+)
+
+// MarshalText implements encoding.TextMarshaler.
+func (me KeyStroke) MarshalText() ([]byte, error) {
+	return []byte(me.Canonical()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (this *KeyStroke) UnmarshalText(text []byte) error {
+	ks, err := ParseKeyStroke(string(text))
+	if err != nil {
+		return err
+	}
+	*this = ks
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (me KeyChord) MarshalText() ([]byte, error) {
+	return []byte(me.Canonical()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (this *KeyChord) UnmarshalText(text []byte) error {
+	chord, err := ParseKeyChord(string(text))
+	if err != nil {
+		return err
+	}
+	*this = chord
+	return nil
+}
+
+// Keymap maps command names to their shortcuts.
+// A name mapped to an empty list explicitly removes the shortcuts of the command.
+// In JSON it is an object of string arrays, as in {"Save": ["Ctrl+S"]}.
+type Keymap map[string][]KeyChord
+
+// LoadKeymap decodes a keymap from JSON.
+func LoadKeymap(r io.Reader) (Keymap, error) {
+	var keymap Keymap
+	err := json.NewDecoder(r).Decode(&keymap)
+	if err != nil {
+		return nil, err
+	}
+	for name, chords := range keymap {
+		if chords == nil {
+			keymap[name] = []KeyChord{}
+		}
+	}
+	return keymap, nil
+}
+
+// LoadKeymapFile reads a keymap from a JSON file.
+// A missing file yields an empty keymap.
+func LoadKeymapFile(path string) (Keymap, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return Keymap{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadKeymap(f)
+}
+
+// Save encodes the keymap as JSON, with command names sorted.
+func (me Keymap) Save(w io.Writer) error {
+	data, err := json.MarshalIndent(me.normalized(), "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = w.Write(data)
+	return err
+}
+
+// SaveFile writes the keymap to a JSON file,
+// replacing it atomically as settings.WriteFileAtomic.
+func (me Keymap) SaveFile(path string) error {
+	var buf bytes.Buffer
+	err := me.Save(&buf)
+	if err != nil {
+		return err
+	}
+	return settings.WriteFileAtomic(path, buf.Bytes())
+}
+
+func (me Keymap) normalized() Keymap {
+	keymap := make(Keymap, len(me))
+	for name, chords := range me {
+		if chords == nil {
+			chords = []KeyChord{}
+		}
+		keymap[name] = chords
+	}
+	return keymap
+}
+
+// Names returns the command names in sorted order.
+func (me Keymap) Names() []string {
+	names := make([]string, 0, len(me))
+	for name := range me {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Clone returns a deep copy of the keymap.
+func (me Keymap) Clone() Keymap {
+	keymap := make(Keymap, len(me))
+	for name, chords := range me {
+		keymap[name] = append([]KeyChord{}, chords...)
+	}
+	return keymap
+}
+
+// MergeKeymaps layers keymaps on top of each other, such as defaults and user overrides.
+// For each command the last keymap that mentions it wins.
+func MergeKeymaps(layers ...Keymap) Keymap {
+	result := make(Keymap)
+	for _, layer := range layers {
+		for name, chords := range layer {
+			result[name] = append([]KeyChord{}, chords...)
+		}
+	}
+	return result
+}
+
+// DiffKeymaps returns the entries of keymap that differ from base,
+// which is what needs to be saved as user overrides.
+func DiffKeymaps(base Keymap, keymap Keymap) Keymap {
+	result := make(Keymap)
+	for name, chords := range keymap {
+		if !chordsEqual(base[name], chords) {
+			result[name] = append([]KeyChord{}, chords...)
+		}
+	}
+	for name, chords := range base {
+		if _, ok := keymap[name]; !ok && len(chords) > 0 {
+			result[name] = []KeyChord{}
+		}
+	}
+	return result
+}
+
+func chordsEqual(chords1 []KeyChord, chords2 []KeyChord) bool {
+	if len(chords1) != len(chords2) {
+		return false
+	}
+	for n := range chords1 {
+		if !chords1[n].Equals(chords2[n]) {
+			return false
+		}
+	}
+	return true
+}
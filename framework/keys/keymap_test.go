@@ -0,0 +1,152 @@
+package keys
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustKeymap(t *testing.T, entries map[string][]string) Keymap {
+	t.Helper()
+	keymap := make(Keymap)
+	for name, strs := range entries {
+		chords := []KeyChord{}
+		for _, s := range strs {
+			chord, err := ParseKeyChord(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			chords = append(chords, chord)
+		}
+		keymap[name] = chords
+	}
+	return keymap
+}
+
+func TestKeymapJSON(t *testing.T) {
+	keymap := mustKeymap(t, map[string][]string{
+		"Save":    {"Ctrl+S"},
+		"Comment": {"Ctrl+K, Ctrl+C", "Ctrl+/"},
+		"ZoomIn":  {"Ctrl++"},
+		"Print":   nil,
+	})
+	var buf bytes.Buffer
+	if err := keymap.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "Comment": [
+    "Ctrl+K, Ctrl+C",
+    "Ctrl+/"
+  ],
+  "Print": [],
+  "Save": [
+    "Ctrl+S"
+  ],
+  "ZoomIn": [
+    "Ctrl++"
+  ]
+}
+`
+	if buf.String() != want {
+		t.Errorf("saved\n%s\nwant\n%s", buf.String(), want)
+	}
+	loaded, err := LoadKeymap(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, keymap) {
+		t.Errorf("loaded %v, want %v", loaded, keymap)
+	}
+
+	//null removes the shortcuts as an empty list does
+	loaded, err = LoadKeymap(strings.NewReader(`{"Print": null}`))
+	if err != nil || loaded["Print"] == nil || len(loaded["Print"]) != 0 {
+		t.Errorf("loaded %v, %v, want an empty list for Print", loaded, err)
+	}
+	for _, bad := range []string{`{"Save": ["Ctrl+Foo"]}`, `{"Save": "Ctrl+S"}`, `[`} {
+		if _, err := LoadKeymap(strings.NewReader(bad)); err == nil {
+			t.Errorf("LoadKeymap(%s) succeeded", bad)
+		}
+	}
+}
+
+func TestKeymapFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "keymap.json")
+	keymap, err := LoadKeymapFile(path)
+	if err != nil || keymap == nil || len(keymap) != 0 {
+		t.Fatalf("loading a missing file = %v, %v, want an empty keymap", keymap, err)
+	}
+	keymap = mustKeymap(t, map[string][]string{"Save": {"Ctrl+S"}})
+	if err := keymap.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadKeymapFile(path)
+	if err != nil || !reflect.DeepEqual(loaded, keymap) {
+		t.Errorf("loaded %v, %v, want %v", loaded, err, keymap)
+	}
+	//no temporary file is left behind
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("%d files in the directory, want 1", len(entries))
+	}
+}
+
+func TestMergeAndDiffKeymaps(t *testing.T) {
+	defaults := mustKeymap(t, map[string][]string{
+		"Save":    {"Ctrl+S"},
+		"Open":    {"Ctrl+O"},
+		"Print":   {"Ctrl+P"},
+		"Comment": {"Ctrl+K, Ctrl+C"},
+	})
+	user := mustKeymap(t, map[string][]string{
+		"Save":  {"Ctrl+Shift+S", "F2"},
+		"Print": nil,
+		"New":   {"Ctrl+N"},
+	})
+	merged := MergeKeymaps(defaults, user)
+	want := mustKeymap(t, map[string][]string{
+		"Save":    {"Ctrl+Shift+S", "F2"},
+		"Open":    {"Ctrl+O"},
+		"Print":   nil,
+		"Comment": {"Ctrl+K, Ctrl+C"},
+		"New":     {"Ctrl+N"},
+	})
+	if !reflect.DeepEqual(merged, want) {
+		t.Errorf("merged %v, want %v", merged, want)
+	}
+	//the layers aren't aliased
+	merged["Open"][0] = KeyChord{{Key: VK_F1}}
+	if defaults["Open"][0][0].Key != 'O' {
+		t.Error("MergeKeymaps aliased the chords of a layer")
+	}
+
+	if diff := DiffKeymaps(defaults, MergeKeymaps(defaults, user)); !reflect.DeepEqual(diff, user) {
+		t.Errorf("diff %v, want the user keymap %v", diff, user)
+	}
+	if diff := DiffKeymaps(defaults, defaults.Clone()); len(diff) != 0 {
+		t.Errorf("diff of the same keymaps %v, want none", diff)
+	}
+	//a command missing from the keymap lost its default shortcuts
+	current := defaults.Clone()
+	delete(current, "Open")
+	if diff := DiffKeymaps(defaults, current); len(diff) != 1 || diff["Open"] == nil || len(diff["Open"]) != 0 {
+		t.Errorf("diff %v, want Open removed", diff)
+	}
+}
+
+func TestClone(t *testing.T) {
+	keymap := mustKeymap(t, map[string][]string{"Save": {"Ctrl+S"}})
+	clone := keymap.Clone()
+	clone["Save"][0] = KeyChord{{Key: VK_F1}}
+	clone["Open"] = nil
+	if keymap["Save"][0][0].Key != 'S' || len(keymap) != 1 {
+		t.Errorf("changing the clone changed the keymap to %v", keymap)
+	}
+	if clone := Keymap(nil).Clone(); clone == nil {
+		t.Error("the clone of a nil keymap is nil")
+	}
+}
@@ -0,0 +1,160 @@
+package keys
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeyStroke is a virtual key combined with modifier keys.
+type KeyStroke struct {
+	Ctrl  bool
+	Alt   bool
+	Shift bool
+	Key   byte
+}
+
+// ParseKeyStroke parses a key stroke such as "Ctrl+Shift+S".
+// Modifier and key names are matched case-insensitively.
+func ParseKeyStroke(s string) (KeyStroke, error) {
+	var ks KeyStroke
+	str := strings.TrimSpace(s)
+	if str == "" {
+		return ks, errors.New("empty key stroke")
+	}
+	var parts []string
+	if strings.HasSuffix(str, "++") {
+		parts = strings.Split(str[:len(str)-2], "+")
+		parts = append(parts, "+")
+	} else if str == "+" {
+		parts = []string{"+"}
+	} else {
+		parts = strings.Split(str, "+")
+	}
+	count := len(parts)
+	for n, part := range parts {
+		part = strings.TrimSpace(part)
+		if n < count-1 {
+			switch strings.ToLower(part) {
+			case "ctrl", "control":
+				ks.Ctrl = true
+			case "shift":
+				ks.Shift = true
+			case "alt":
+				ks.Alt = true
+			default:
+				return KeyStroke{}, errors.New("unknown modifier " + part + " in " + s)
+			}
+			continue
+		}
+		key, ok := KeyCode(part)
+		if !ok {
+			return KeyStroke{}, errors.New("unknown key " + part + " in " + s)
+		}
+		ks.Key = key
+	}
+	return ks, nil
+}
+
+// MustParseKeyStroke is like ParseKeyStroke but panics on error.
+func MustParseKeyStroke(s string) KeyStroke {
+	ks, err := ParseKeyStroke(s)
+	if err != nil {
+		panic(err)
+	}
+	return ks
+}
+
+func (me KeyStroke) IsExtended() bool {
+	return IsExtendedKey(me.Key)
+}
+
+// IsEmpty reports whether no key is specified.
+func (me KeyStroke) IsEmpty() bool {
+	return me.Key == 0
+}
+
+// String returns the key stroke formatted for display, as DisplayString.
+func (me KeyStroke) String() string {
+	return me.DisplayString()
+}
+
+// DisplayString returns the key stroke formatted for display,
+// using the locale set by SetLocale.
+func (me KeyStroke) DisplayString() string {
+	return me.format(displayLocale)
+}
+
+// Canonical returns the canonical form of the key stroke, such as "Ctrl+Shift+S",
+// which can be parsed back by ParseKeyStroke.
+func (me KeyStroke) Canonical() string {
+	return me.format(&defaultLocale)
+}
+
+func (me KeyStroke) format(locale *Locale) string {
+	var sb strings.Builder
+	if me.Ctrl {
+		sb.WriteString(locale.Ctrl)
+		sb.WriteByte('+')
+	}
+	if me.Shift {
+		sb.WriteString(locale.Shift)
+		sb.WriteByte('+')
+	}
+	if me.Alt {
+		sb.WriteString(locale.Alt)
+		sb.WriteByte('+')
+	}
+	var name string
+	if locale.KeyName != nil {
+		name = locale.KeyName(me.Key)
+	}
+	if name == "" {
+		name = KeyName(me.Key)
+	}
+	if name == "" {
+		name = fmt.Sprintf("0x%02X", me.Key)
+	}
+	sb.WriteString(name)
+	return sb.String()
+}
+
+// Locale provides the display names used by DisplayString.
+type Locale struct {
+	Ctrl  string
+	Shift string
+	Alt   string
+
+	// KeyName returns the display name of a virtual key,
+	// or "" to fall back to the canonical name.
+	KeyName func(key byte) string
+}
+
+var defaultLocale = Locale{Ctrl: "Ctrl", Shift: "Shift", Alt: "Alt"}
+
+var displayLocale = &defaultLocale
+
+// SetLocale sets the locale used to display key strokes.
+// Empty modifier names fall back to the canonical ones. A nil locale resets the default.
+func SetLocale(locale *Locale) {
+	if locale == nil {
+		displayLocale = &defaultLocale
+		return
+	}
+	l := *locale
+	if l.Ctrl == "" {
+		l.Ctrl = defaultLocale.Ctrl
+	}
+	if l.Shift == "" {
+		l.Shift = defaultLocale.Shift
+	}
+	if l.Alt == "" {
+		l.Alt = defaultLocale.Alt
+	}
+	displayLocale = &l
+}
+
+// GetLocale returns the locale used to display key strokes.
+func GetLocale() Locale {
+	return *displayLocale
+}
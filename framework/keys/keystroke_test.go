@@ -0,0 +1,108 @@
+package keys
+
+import "testing"
+
+func TestParseKeyStroke(t *testing.T) {
+	tests := []struct {
+		in   string
+		want KeyStroke
+	}{
+		{"Ctrl+Shift+S", KeyStroke{Ctrl: true, Shift: true, Key: 'S'}},
+		{" ctrl + alt + delete ", KeyStroke{Ctrl: true, Alt: true, Key: VK_DELETE}},
+		{"Control+F5", KeyStroke{Ctrl: true, Key: VK_F1 + 4}},
+		{"Alt+F24", KeyStroke{Alt: true, Key: VK_F24}},
+		{"Shift+Num5", KeyStroke{Shift: true, Key: VK_NUMPAD0 + 5}},
+		{"Esc", KeyStroke{Key: VK_ESCAPE}},
+		{"escape", KeyStroke{Key: VK_ESCAPE}},
+		{"Ctrl+,", KeyStroke{Ctrl: true, Key: VK_OEM_COMMA}},
+		{"Ctrl++", KeyStroke{Ctrl: true, Key: VK_OEM_PLUS}},
+		{"Ctrl+Shift++", KeyStroke{Ctrl: true, Shift: true, Key: VK_OEM_PLUS}},
+		{"+", KeyStroke{Key: VK_OEM_PLUS}},
+		{"Ctrl+=", KeyStroke{Ctrl: true, Key: VK_OEM_PLUS}},
+		{"Ctrl+Plus", KeyStroke{Ctrl: true, Key: VK_OEM_PLUS}},
+		{"Ctrl+-", KeyStroke{Ctrl: true, Key: VK_OEM_MINUS}},
+		{"Ctrl+NumAdd", KeyStroke{Ctrl: true, Key: VK_ADD}},
+		{"Ctrl+0x5B", KeyStroke{Ctrl: true, Key: 0x5B}},
+	}
+	for _, test := range tests {
+		got, err := ParseKeyStroke(test.in)
+		if err != nil || got != test.want {
+			t.Errorf("ParseKeyStroke(%q) = %+v, %v, want %+v", test.in, got, err, test.want)
+		}
+	}
+}
+
+func TestParseKeyStrokeInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", " ", "Ctrl+", "Foo+A", "Ctrl+Foo", "0x00", "0x100", "F0", "F25", "Num10", "Ctrl+Shift", "Win+A",
+	} {
+		if ks, err := ParseKeyStroke(in); err == nil {
+			t.Errorf("ParseKeyStroke(%q) = %+v, want an error", in, ks)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		ks   KeyStroke
+		want string
+	}{
+		{KeyStroke{Ctrl: true, Alt: true, Shift: true, Key: 'A'}, "Ctrl+Shift+Alt+A"},
+		{KeyStroke{Ctrl: true, Key: VK_OEM_PLUS}, "Ctrl++"},
+		{KeyStroke{Key: VK_OEM_PLUS}, "+"},
+		{KeyStroke{Ctrl: true, Key: VK_OEM_COMMA}, "Ctrl+,"},
+		{KeyStroke{Key: VK_NEXT}, "PageDown"},
+		{KeyStroke{Key: VK_F1 + 11}, "F12"},
+		{KeyStroke{Alt: true, Key: VK_NUMPAD0}, "Alt+Num0"},
+		{KeyStroke{Ctrl: true, Key: 0x5B}, "Ctrl+0x5B"},
+	}
+	for _, test := range tests {
+		if got := test.ks.Canonical(); got != test.want {
+			t.Errorf("%+v.Canonical() = %q, want %q", test.ks, got, test.want)
+		}
+	}
+}
+
+// TestCanonicalRoundTrip parses back the canonical form of every key with every modifier.
+func TestCanonicalRoundTrip(t *testing.T) {
+	for key := 1; key < 256; key++ {
+		for modifiers := 0; modifiers < 8; modifiers++ {
+			ks := KeyStroke{Ctrl: modifiers&1 != 0, Shift: modifiers&2 != 0,
+				Alt: modifiers&4 != 0, Key: byte(key)}
+			s := ks.Canonical()
+			got, err := ParseKeyStroke(s)
+			if err != nil || got != ks {
+				t.Fatalf("ParseKeyStroke(%q) = %+v, %v, want %+v", s, got, err, ks)
+			}
+		}
+	}
+}
+
+func TestDisplayString(t *testing.T) {
+	SetLocale(&Locale{Ctrl: "Strg", KeyName: func(key byte) string {
+		if key == VK_DELETE {
+			return "Entf"
+		}
+		return ""
+	}})
+	defer SetLocale(nil)
+
+	ks := KeyStroke{Ctrl: true, Shift: true, Key: VK_DELETE}
+	if got := ks.String(); got != "Strg+Shift+Entf" {
+		t.Errorf("String() = %q, want the display name Strg+Shift+Entf", got)
+	}
+	if got := ks.DisplayString(); got != "Strg+Shift+Entf" {
+		t.Errorf("DisplayString() = %q, want Strg+Shift+Entf", got)
+	}
+	if got := ks.Canonical(); got != "Ctrl+Shift+Delete" {
+		t.Errorf("Canonical() = %q, want Ctrl+Shift+Delete", got)
+	}
+	if got := (KeyStroke{Ctrl: true, Key: 'K'}).String(); got != "Strg+K" {
+		t.Errorf("String() = %q, want the canonical key name K", got)
+	}
+
+	SetLocale(nil)
+	if got := ks.String(); got != ks.Canonical() {
+		t.Errorf("String() = %q with the default locale, want %q", got, ks.Canonical())
+	}
+}
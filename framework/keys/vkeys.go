@@ -0,0 +1,219 @@
+package keys
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Virtual key codes, as defined by the Win32 API.
+// They are duplicated here so that key strokes can be
+// parsed and formatted without depending on the platform.
+const (
+	VK_BACK     byte = 0x08
+	VK_TAB      byte = 0x09
+	VK_CLEAR    byte = 0x0C
+	VK_RETURN   byte = 0x0D
+	VK_SHIFT    byte = 0x10
+	VK_CONTROL  byte = 0x11
+	VK_MENU     byte = 0x12
+	VK_PAUSE    byte = 0x13
+	VK_CAPITAL  byte = 0x14
+	VK_ESCAPE   byte = 0x1B
+	VK_SPACE    byte = 0x20
+	VK_PRIOR    byte = 0x21
+	VK_NEXT     byte = 0x22
+	VK_END      byte = 0x23
+	VK_HOME     byte = 0x24
+	VK_LEFT     byte = 0x25
+	VK_UP       byte = 0x26
+	VK_RIGHT    byte = 0x27
+	VK_DOWN     byte = 0x28
+	VK_SNAPSHOT byte = 0x2C
+	VK_INSERT   byte = 0x2D
+	VK_DELETE   byte = 0x2E
+	VK_HELP     byte = 0x2F
+	VK_LWIN     byte = 0x5B
+	VK_RWIN     byte = 0x5C
+	VK_APPS     byte = 0x5D
+	VK_NUMPAD0  byte = 0x60
+	VK_MULTIPLY byte = 0x6A
+	VK_ADD      byte = 0x6B
+	VK_SUBTRACT byte = 0x6D
+	VK_DECIMAL  byte = 0x6E
+	VK_DIVIDE   byte = 0x6F
+	VK_F1       byte = 0x70
+	VK_F24      byte = 0x87
+	VK_NUMLOCK  byte = 0x90
+	VK_SCROLL   byte = 0x91
+	VK_LSHIFT   byte = 0xA0
+	VK_RSHIFT   byte = 0xA1
+	VK_LCONTROL byte = 0xA2
+	VK_RCONTROL byte = 0xA3
+	VK_LMENU    byte = 0xA4
+	VK_RMENU    byte = 0xA5
+
+	VK_OEM_1      byte = 0xBA // ;
+	VK_OEM_PLUS   byte = 0xBB // + in any layout, = unshifted on US keyboards
+	VK_OEM_COMMA  byte = 0xBC // ,
+	VK_OEM_MINUS  byte = 0xBD // -
+	VK_OEM_PERIOD byte = 0xBE // .
+	VK_OEM_2      byte = 0xBF // /
+	VK_OEM_3      byte = 0xC0 // `
+	VK_OEM_4      byte = 0xDB // [
+	VK_OEM_5      byte = 0xDC // \
+	VK_OEM_6      byte = 0xDD // ]
+	VK_OEM_7      byte = 0xDE // '
+)
+
+// keyNames holds the canonical names of the named virtual keys.
+// Letters, digits, function keys and numpad digits are handled separately.
+var keyNames = map[byte]string{
+	VK_BACK:     "Backspace",
+	VK_TAB:      "Tab",
+	VK_CLEAR:    "Clear",
+	VK_RETURN:   "Enter",
+	VK_PAUSE:    "Pause",
+	VK_CAPITAL:  "CapsLock",
+	VK_ESCAPE:   "Esc",
+	VK_SPACE:    "Space",
+	VK_PRIOR:    "PageUp",
+	VK_NEXT:     "PageDown",
+	VK_END:      "End",
+	VK_HOME:     "Home",
+	VK_LEFT:     "Left",
+	VK_UP:       "Up",
+	VK_RIGHT:    "Right",
+	VK_DOWN:     "Down",
+	VK_SNAPSHOT: "PrintScreen",
+	VK_INSERT:   "Insert",
+	VK_DELETE:   "Delete",
+	VK_HELP:     "Help",
+	VK_APPS:     "Apps",
+	VK_MULTIPLY: "NumMultiply",
+	VK_ADD:      "NumAdd",
+	VK_SUBTRACT: "NumSubtract",
+	VK_DECIMAL:  "NumDecimal",
+	VK_DIVIDE:   "NumDivide",
+	VK_NUMLOCK:  "NumLock",
+	VK_SCROLL:   "ScrollLock",
+
+	VK_OEM_1:      ";",
+	VK_OEM_PLUS:   "+",
+	VK_OEM_COMMA:  ",",
+	VK_OEM_MINUS:  "-",
+	VK_OEM_PERIOD: ".",
+	VK_OEM_2:      "/",
+	VK_OEM_3:      "`",
+	VK_OEM_4:      "[",
+	VK_OEM_5:      "\\",
+	VK_OEM_6:      "]",
+	VK_OEM_7:      "'",
+}
+
+// keyAliases maps additional lower-cased names accepted by the parser.
+var keyAliases = map[string]byte{
+	"bksp":      VK_BACK,
+	"back":      VK_BACK,
+	"return":    VK_RETURN,
+	"escape":    VK_ESCAPE,
+	"pgup":      VK_PRIOR,
+	"prior":     VK_PRIOR,
+	"pgdn":      VK_NEXT,
+	"next":      VK_NEXT,
+	"ins":       VK_INSERT,
+	"del":       VK_DELETE,
+	"prtsc":     VK_SNAPSHOT,
+	"break":     VK_PAUSE,
+	"menu":      VK_APPS,
+	"plus":      VK_OEM_PLUS,
+	"=":         VK_OEM_PLUS,
+	"equals":    VK_OEM_PLUS,
+	"minus":     VK_OEM_MINUS,
+	"comma":     VK_OEM_COMMA,
+	"period":    VK_OEM_PERIOD,
+	"semicolon": VK_OEM_1,
+	"slash":     VK_OEM_2,
+	"backslash": VK_OEM_5,
+	"num*":      VK_MULTIPLY,
+	"num+":      VK_ADD,
+	"num-":      VK_SUBTRACT,
+	"num.":      VK_DECIMAL,
+	"num/":      VK_DIVIDE,
+}
+
+var keyCodes map[string]byte
+
+func init() {
+	keyCodes = make(map[string]byte, len(keyNames)+len(keyAliases))
+	for key, name := range keyNames {
+		keyCodes[strings.ToLower(name)] = key
+	}
+	for name, key := range keyAliases {
+		keyCodes[name] = key
+	}
+}
+
+// IsModifierKey reports whether the virtual key is a Ctrl, Shift, Alt or Win key.
+func IsModifierKey(key byte) bool {
+	switch key {
+	case VK_SHIFT, VK_CONTROL, VK_MENU, VK_LWIN, VK_RWIN,
+		VK_LSHIFT, VK_RSHIFT, VK_LCONTROL, VK_RCONTROL, VK_LMENU, VK_RMENU:
+		return true
+	}
+	return false
+}
+
+// IsExtendedKey reports whether the virtual key is an extended key,
+// i.e. one of the navigation keys outside the numeric keypad.
+func IsExtendedKey(key byte) bool {
+	switch key {
+	case VK_INSERT, VK_DELETE, VK_HOME, VK_END,
+		VK_NEXT, VK_PRIOR, VK_LEFT, VK_RIGHT, VK_UP, VK_DOWN:
+		return true
+	}
+	return false
+}
+
+// KeyName returns the canonical name of the virtual key, or "" if unknown.
+func KeyName(key byte) string {
+	switch {
+	case key >= 'A' && key <= 'Z', key >= '0' && key <= '9':
+		return string(rune(key))
+	case key >= VK_F1 && key <= VK_F24:
+		return "F" + strconv.Itoa(int(key-VK_F1)+1)
+	case key >= VK_NUMPAD0 && key <= VK_NUMPAD0+9:
+		return "Num" + string(rune('0'+key-VK_NUMPAD0))
+	}
+	return keyNames[key]
+}
+
+// KeyCode returns the virtual key of the name, which is matched case-insensitively.
+// Keys without a name are written in hex, as 0x5B.
+func KeyCode(name string) (byte, bool) {
+	lname := strings.ToLower(name)
+	if len(lname) == 1 {
+		c := lname[0]
+		if c >= 'a' && c <= 'z' {
+			return c - 'a' + 'A', true
+		}
+		if c >= '0' && c <= '9' {
+			return c, true
+		}
+	}
+	if len(lname) > 1 && lname[0] == 'f' {
+		if n, err := strconv.Atoi(lname[1:]); err == nil && n >= 1 && n <= 24 {
+			return VK_F1 + byte(n-1), true
+		}
+	}
+	if len(lname) == 4 && lname[:3] == "num" && lname[3] >= '0' && lname[3] <= '9' {
+		return VK_NUMPAD0 + lname[3] - '0', true
+	}
+	if hex, ok := strings.CutPrefix(lname, "0x"); ok {
+		if n, err := strconv.ParseUint(hex, 16, 8); err == nil && n != 0 {
+			return byte(n), true
+		}
+		return 0, false
+	}
+	key, ok := keyCodes[lname]
+	return key, ok
+}
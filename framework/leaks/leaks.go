@@ -0,0 +1,393 @@
+// Package leaks tracks live disposable objects in debug builds.
+//
+// Tracking is off by default and costs a single atomic load per call then.
+// When enabled, every tracked object records the stack trace of its creation,
+// so that objects never disposed can be reported along with where they came from.
+// Disposing an object twice, using an object after it was disposed and
+// objects collected by the garbage collector without being disposed are reported too.
+package leaks
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/zzl/goforms/framework/internal/goroutine"
+)
+
+// ProblemKind classifies a reported problem.
+type ProblemKind int
+
+const (
+	Leaked          ProblemKind = iota //never disposed
+	Collected                          //collected by the garbage collector without being disposed
+	DoubleDispose                      //disposed more than once
+	UseAfterDispose                    //used after being disposed
+)
+
+func (this ProblemKind) String() string {
+	switch this {
+	case Leaked:
+		return "leaked"
+	case Collected:
+		return "collected without dispose"
+	case DoubleDispose:
+		return "double dispose"
+	case UseAfterDispose:
+		return "use after dispose"
+	}
+	return "unknown"
+}
+
+// Record describes a tracked object.
+type Record struct {
+	Type  string //type name of the object, e.g. drawing.Pen
+	Seq   uint64 //creation sequence number, increasing
+	Stack string //stack trace of the creation
+
+	goroutine uint64 //id of the creating goroutine
+	owned     bool   //added to a scope or given to another owner
+	ignored   bool
+	reported  bool //reported as leaked by a scope already
+}
+
+// Problem is a reported misuse of a tracked object.
+type Problem struct {
+	Kind         ProblemKind
+	Context      string //where the problem was detected, e.g. "scope leave"
+	Record       Record
+	DisposeStack string //stack trace of the first dispose, if known
+	Stack        string //stack trace where the problem was detected
+}
+
+func (this *Problem) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s: %s", this.Kind, this.Record.Type)
+	if this.Context != "" {
+		fmt.Fprintf(&sb, " (%s)", this.Context)
+	}
+	if this.Record.Stack != "" {
+		sb.WriteString("\ncreated at:\n")
+		sb.WriteString(this.Record.Stack)
+	}
+	if this.DisposeStack != "" {
+		sb.WriteString("\ndisposed at:\n")
+		sb.WriteString(this.DisposeStack)
+	}
+	if this.Stack != "" {
+		sb.WriteString("\ndetected at:\n")
+		sb.WriteString(this.Stack)
+	}
+	return sb.String()
+}
+
+// Reporter receives the problems detected.
+// It may be called on any goroutine, e.g. from finalizers.
+type Reporter func(problem *Problem)
+
+// MaxDisposed is the number of disposed objects remembered
+// for detecting double dispose and use after dispose.
+var MaxDisposed = 4096
+
+// StackDepth is the maximum number of frames recorded per stack trace.
+var StackDepth = 16
+
+var enabled atomic.Bool
+
+var reporter atomic.Pointer[Reporter]
+
+var (
+	mutex         sync.Mutex
+	seq           uint64
+	live          = map[uintptr]*Record{}
+	disposed      = map[uintptr]string{}
+	disposedOrder []uintptr
+)
+
+// Enable turns tracking on or off.
+// Objects created while tracking is off are never reported.
+func Enable(on bool) {
+	enabled.Store(on)
+}
+
+// Enabled tells whether tracking is on.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// SetReporter sets the reporter of the problems and returns the previous one.
+// A nil reporter restores the default reporter, which logs the problems.
+func SetReporter(r Reporter) Reporter {
+	var p *Reporter
+	if r != nil {
+		p = &r
+	}
+	if ori := reporter.Swap(p); ori != nil {
+		return *ori
+	}
+	return logProblem
+}
+
+func logProblem(problem *Problem) {
+	log.Println(problem.String())
+}
+
+func report(problem *Problem) {
+	if r := reporter.Load(); r != nil {
+		(*r)(problem)
+	} else {
+		logProblem(problem)
+	}
+}
+
+func addressOf(obj any) uintptr {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return 0
+	}
+	return v.Pointer()
+}
+
+func typeName(obj any) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", obj), "*")
+}
+
+func captureStack(skip int) string {
+	pcs := make([]uintptr, StackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var sb strings.Builder
+	for {
+		frame, more := frames.Next()
+		if strings.HasPrefix(frame.Function, "runtime.") {
+			if !more {
+				break
+			}
+			continue
+		}
+		fmt.Fprintf(&sb, "\t%s\n\t\t%s:%d\n", frame.Function, frame.File, frame.Line)
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
+
+// Track starts tracking a newly created object, identified by its address.
+// The registry does not keep the object alive.
+func Track(obj any) {
+	if !enabled.Load() {
+		return
+	}
+	addr := addressOf(obj)
+	if addr == 0 {
+		return
+	}
+	stack := captureStack(1)
+	gid := goroutine.ID()
+	mutex.Lock()
+	defer mutex.Unlock()
+	seq += 1
+	live[addr] = &Record{Type: typeName(obj), Seq: seq, Stack: stack, goroutine: gid}
+	delete(disposed, addr) //address reused
+}
+
+// Disposed is called from the Dispose method of a tracked object.
+// It stops tracking the object and reports a double dispose
+// if the object had been disposed already.
+func Disposed(obj any) {
+	if !enabled.Load() {
+		return
+	}
+	addr := addressOf(obj)
+	if addr == 0 {
+		return
+	}
+	mutex.Lock()
+	if _, ok := live[addr]; ok {
+		delete(live, addr)
+		rememberDisposed(addr, captureStack(1))
+		mutex.Unlock()
+		return
+	}
+	disposeStack, wasDisposed := disposed[addr]
+	mutex.Unlock()
+	if wasDisposed {
+		report(&Problem{Kind: DoubleDispose, Record: Record{Type: typeName(obj)},
+			DisposeStack: disposeStack, Stack: captureStack(1)})
+	}
+}
+
+func rememberDisposed(addr uintptr, stack string) {
+	if MaxDisposed <= 0 {
+		return
+	}
+	if _, ok := disposed[addr]; !ok {
+		disposedOrder = append(disposedOrder, addr)
+	}
+	disposed[addr] = stack
+	for len(disposedOrder) > MaxDisposed {
+		delete(disposed, disposedOrder[0])
+		disposedOrder = disposedOrder[1:]
+	}
+}
+
+// Used is called when a disposed object is about to be used.
+func Used(obj any) {
+	if !enabled.Load() {
+		return
+	}
+	addr := addressOf(obj)
+	mutex.Lock()
+	disposeStack := disposed[addr]
+	mutex.Unlock()
+	report(&Problem{Kind: UseAfterDispose, Record: Record{Type: typeName(obj)},
+		DisposeStack: disposeStack, Stack: captureStack(1)})
+}
+
+// Finalized is called from the finalizer of a tracked object,
+// before it disposes itself.
+func Finalized(obj any) {
+	if !enabled.Load() {
+		return
+	}
+	addr := addressOf(obj)
+	mutex.Lock()
+	record, ok := live[addr]
+	if ok {
+		delete(live, addr)
+	}
+	mutex.Unlock()
+	if ok && !record.ignored {
+		report(&Problem{Kind: Collected, Record: *record})
+	}
+}
+
+// Owned marks an object as owned by a scope or another object that disposes it,
+// so it is not reported as leaked when a scope it was created in leaves.
+func Owned(obj any) {
+	if !enabled.Load() {
+		return
+	}
+	addr := addressOf(obj)
+	mutex.Lock()
+	if record, ok := live[addr]; ok {
+		record.owned = true
+	}
+	mutex.Unlock()
+}
+
+// Ignore excludes an intentionally long-lived object from leak reports.
+func Ignore(obj any) {
+	addr := addressOf(obj)
+	mutex.Lock()
+	if record, ok := live[addr]; ok {
+		record.ignored = true
+	}
+	mutex.Unlock()
+}
+
+// Mark returns the current creation sequence number,
+// to be passed to LiveSince or CheckScope later.
+func Mark() uint64 {
+	mutex.Lock()
+	defer mutex.Unlock()
+	return seq
+}
+
+// Live returns the records of the live objects, oldest first.
+func Live() []Record {
+	return LiveSince(0)
+}
+
+// LiveSince returns the records of the live objects created after the mark.
+func LiveSince(mark uint64) []Record {
+	mutex.Lock()
+	var records []Record
+	for _, record := range live {
+		if record.Seq > mark && !record.ignored {
+			records = append(records, *record)
+		}
+	}
+	mutex.Unlock()
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Seq < records[j].Seq
+	})
+	return records
+}
+
+// LiveCounts returns the number of live objects by type name.
+func LiveCounts() map[string]int {
+	counts := make(map[string]int)
+	for _, record := range Live() {
+		counts[record.Type] += 1
+	}
+	return counts
+}
+
+// CheckScope reports the objects created on the current goroutine after the mark
+// that are still alive, and neither owned nor reported by a nested scope already.
+// It is called when a scope leaves, with the mark taken when it was created.
+func CheckScope(mark uint64) {
+	if !enabled.Load() {
+		return
+	}
+	gid := goroutine.ID()
+	var records []Record
+	mutex.Lock()
+	for _, record := range live {
+		if record.Seq > mark && record.goroutine == gid &&
+			!record.owned && !record.ignored && !record.reported {
+			record.reported = true
+			records = append(records, *record)
+		}
+	}
+	mutex.Unlock()
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Seq < records[j].Seq
+	})
+	for _, record := range records {
+		report(&Problem{Kind: Leaked, Context: "created in scope but not disposed",
+			Record: record})
+	}
+}
+
+// ReportLive reports all live objects as leaked, and returns their number.
+func ReportLive(context string) int {
+	if !enabled.Load() {
+		return 0
+	}
+	records := Live()
+	for _, record := range records {
+		report(&Problem{Kind: Leaked, Context: context, Record: record})
+	}
+	return len(records)
+}
+
+// WriteSummary writes the live counts by type.
+func WriteSummary(w io.Writer) {
+	counts := LiveCounts()
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "%s: %d\n", name, counts[name])
+	}
+}
+
+// Reset forgets all tracked and disposed objects.
+func Reset() {
+	mutex.Lock()
+	live = map[uintptr]*Record{}
+	disposed = map[uintptr]string{}
+	disposedOrder = nil
+	mutex.Unlock()
+}
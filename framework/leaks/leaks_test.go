@@ -0,0 +1,250 @@
+package leaks
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// object is a tracked disposable, following the pattern of the drawing objects.
+type object struct {
+	p *int
+}
+
+func newObject() *object {
+	obj := &object{p: new(int)}
+	Track(obj)
+	return obj
+}
+
+func (this *object) Dispose() {
+	Disposed(this)
+	this.p = nil
+}
+
+func (this *object) handle() *int {
+	if this.p == nil {
+		Used(this)
+	}
+	return this.p
+}
+
+func (this *object) Value() int {
+	if p := this.handle(); p != nil {
+		return *p
+	}
+	return 0
+}
+
+// record turns tracking on for the test and collects the problems reported.
+func record(t *testing.T) func() []*Problem {
+	var mutex sync.Mutex
+	var problems []*Problem
+	oriReporter := SetReporter(func(problem *Problem) {
+		mutex.Lock()
+		problems = append(problems, problem)
+		mutex.Unlock()
+	})
+	Enable(true)
+	t.Cleanup(func() {
+		Enable(false)
+		SetReporter(oriReporter)
+		Reset()
+	})
+	return func() []*Problem {
+		mutex.Lock()
+		defer mutex.Unlock()
+		reported := problems
+		problems = nil
+		return reported
+	}
+}
+
+func TestCheckScope(t *testing.T) {
+	reported := record(t)
+	mark := Mark()
+	leaked := newObject()
+	owned := newObject()
+	Owned(owned)
+	ignored := newObject()
+	Ignore(ignored)
+	disposed := newObject()
+	disposed.Dispose()
+
+	innerMark := Mark()
+	innerLeaked := newObject()
+	CheckScope(innerMark)
+	problems := reported()
+	if len(problems) != 1 || problems[0].Kind != Leaked || problems[0].Record.Seq != innerMark+1 {
+		t.Fatalf("inner scope reported %v, want the object created in it", problems)
+	}
+
+	//an object created on another goroutine meanwhile may be owned there
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		newObject()
+	}()
+	wg.Wait()
+
+	CheckScope(mark)
+	problems = reported()
+	if len(problems) != 1 || problems[0].Record.Seq != mark+1 {
+		t.Fatalf("scope reported %v, want only the leaked object", problems)
+	}
+	problem := problems[0]
+	if problem.Record.Type != "leaks.object" || problem.Context == "" ||
+		!strings.Contains(problem.Record.Stack, "TestCheckScope") {
+		t.Errorf("problem %v, want the type and creation stack", problem)
+	}
+	if s := problem.String(); !strings.HasPrefix(s, "leaked: leaks.object (") ||
+		!strings.Contains(s, "created at:") {
+		t.Errorf("problem string %q", s)
+	}
+	//still reported at exit
+	if n := ReportLive("exit"); n != 4 || len(reported()) != 4 {
+		t.Errorf("%d live objects reported at exit, want 4", n)
+	}
+	leaked.Dispose()
+	innerLeaked.Dispose()
+	owned.Dispose()
+}
+
+func TestUseAfterDispose(t *testing.T) {
+	reported := record(t)
+	obj := newObject()
+	if obj.Value() != 0 || len(reported()) != 0 {
+		t.Fatal("use of a live object reported")
+	}
+	obj.Dispose()
+	obj.Value()
+	problems := reported()
+	if len(problems) != 1 || problems[0].Kind != UseAfterDispose {
+		t.Fatalf("reported %v, want use after dispose", problems)
+	}
+	if !strings.Contains(problems[0].DisposeStack, "TestUseAfterDispose") ||
+		!strings.Contains(problems[0].Stack, "(*object).Value") {
+		t.Errorf("problem %v, want the dispose and use stacks", problems[0])
+	}
+
+	obj.Dispose()
+	problems = reported()
+	if len(problems) != 1 || problems[0].Kind != DoubleDispose || problems[0].DisposeStack == "" {
+		t.Errorf("reported %v, want double dispose", problems)
+	}
+}
+
+func TestFinalized(t *testing.T) {
+	reported := record(t)
+	collected := newObject()
+	Finalized(collected)
+	ignored := newObject()
+	Ignore(ignored)
+	Finalized(ignored)
+	problems := reported()
+	if len(problems) != 1 || problems[0].Kind != Collected {
+		t.Errorf("reported %v, want one collected object", problems)
+	}
+	if len(Live()) != 0 {
+		t.Errorf("live %v after finalizing", Live())
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	reported := record(t)
+	Enable(false)
+	obj := newObject()
+	CheckScope(0)
+	obj.Dispose()
+	obj.Value()
+	obj.Dispose()
+	if len(reported()) != 0 || len(Live()) != 0 {
+		t.Error("problems reported while disabled")
+	}
+}
+
+func TestLiveCounts(t *testing.T) {
+	record(t)
+	a, b := newObject(), newObject()
+	Ignore(b)
+	mark := Mark()
+	c := newObject()
+	if counts := LiveCounts(); counts["leaks.object"] != 2 {
+		t.Errorf("live counts %v, want 2 objects", counts)
+	}
+	if records := LiveSince(mark); len(records) != 1 || records[0].Seq != mark+1 {
+		t.Errorf("live since the mark %v, want the last object", records)
+	}
+	var sb strings.Builder
+	WriteSummary(&sb)
+	if sb.String() != "leaks.object: 2\n" {
+		t.Errorf("summary %q", sb.String())
+	}
+	a.Dispose()
+	c.Dispose()
+}
+
+func TestSetReporter(t *testing.T) {
+	ori := SetReporter(nil)
+	defer SetReporter(ori)
+	if SetReporter(nil) == nil {
+		t.Error("no default reporter")
+	}
+	//reporters can be swapped while problems are reported elsewhere
+	Enable(true)
+	defer Enable(false)
+	defer Reset()
+	var wg sync.WaitGroup
+	for n := 0; n < 4; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			obj := newObject()
+			obj.Dispose()
+			for i := 0; i < 100; i++ {
+				Disposed(obj)
+			}
+		}()
+	}
+	for n := 0; n < 100; n++ {
+		SetReporter(func(problem *Problem) {})
+	}
+	wg.Wait()
+}
+
+// fakeTB records the errors of the test helpers.
+type fakeTB struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (this *fakeTB) Helper() {}
+
+func (this *fakeTB) Errorf(format string, args ...any) {
+	this.errors = append(this.errors, fmt.Sprintf(format, args...))
+}
+
+func (this *fakeTB) Cleanup(f func()) {
+	this.cleanups = append(this.cleanups, f)
+}
+
+func TestCheck(t *testing.T) {
+	defer Reset()
+	tb := &fakeTB{}
+	Check(tb)
+	leaked := newObject()
+	disposed := newObject()
+	disposed.Dispose()
+	disposed.Dispose()
+	tb.cleanups[0]()
+	if len(tb.errors) != 2 || !strings.HasPrefix(tb.errors[0], "leaked") ||
+		!strings.HasPrefix(tb.errors[1], "double dispose") {
+		t.Errorf("errors %q, want a leak and a double dispose", tb.errors)
+	}
+	if Enabled() {
+		t.Error("tracking left on")
+	}
+	_ = leaked
+}
@@ -0,0 +1,52 @@
+package leaks
+
+// TB is the subset of testing.TB used by the test helpers.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+// CleanupTB is a TB supporting Cleanup, as testing.TB does.
+type CleanupTB interface {
+	TB
+	Cleanup(func())
+}
+
+// AssertNoLeaks fails the test if any tracked object is still alive.
+func AssertNoLeaks(t TB) {
+	t.Helper()
+	for _, record := range Live() {
+		t.Errorf("%s", (&Problem{Kind: Leaked, Record: record}).String())
+	}
+}
+
+// Check turns tracking on for the rest of the test, and when the test ends
+// fails it for every object created meanwhile that was not disposed,
+// and for every problem reported meanwhile.
+// Check must not be used by parallel tests.
+func Check(t CleanupTB) {
+	t.Helper()
+	wasEnabled := Enabled()
+	var problems []*Problem
+	oriReporter := SetReporter(func(problem *Problem) {
+		mutex.Lock()
+		problems = append(problems, problem)
+		mutex.Unlock()
+	})
+	Enable(true)
+	mark := Mark()
+	t.Cleanup(func() {
+		t.Helper()
+		for _, record := range LiveSince(mark) {
+			t.Errorf("%s", (&Problem{Kind: Leaked, Context: "test end", Record: record}).String())
+		}
+		mutex.Lock()
+		reported := problems
+		mutex.Unlock()
+		for _, problem := range reported {
+			t.Errorf("%s", problem.String())
+		}
+		SetReporter(oriReporter)
+		Enable(wasEnabled)
+	})
+}
@@ -1,19 +1,30 @@
 package scope
 
-import "github.com/zzl/goforms/framework/types"
+import "github.com/zzl/goforms/framework/leaks"
 
-//
+// Disposable is the same as types.Disposable,
+// declared here so that the scope package does not depend on win32 through types.
+type Disposable interface {
+	Dispose()
+}
 
 type Scope struct {
-	disposables []types.Disposable
+	disposables []Disposable
+
+	leakMark uint64 //leak tracking sequence number when the scope was created
 }
 
 func NewScope() *Scope {
-	return &Scope{}
+	s := &Scope{}
+	if leaks.Enabled() {
+		s.leakMark = leaks.Mark() + 1
+	}
+	return s
 }
 
-func (this *Scope) Add(disposable types.Disposable) {
+func (this *Scope) Add(disposable Disposable) {
 	this.disposables = append(this.disposables, disposable)
+	leaks.Owned(disposable)
 }
 
 func (this *Scope) Leave() {
@@ -22,6 +33,10 @@ func (this *Scope) Leave() {
 	for n := len(disposables) - 1; n >= 0; n -= 1 {
 		disposables[n].Dispose()
 	}
+	if this.leakMark != 0 {
+		leaks.CheckScope(this.leakMark - 1)
+		this.leakMark = leaks.Mark() + 1
+	}
 }
 
 type ScopedFunc func(s *Scope)
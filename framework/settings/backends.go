@@ -0,0 +1,243 @@
+package settings
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Memory is a backend keeping the document in memory, mainly for tests.
+type Memory struct {
+	mutex sync.Mutex
+	doc   *Document
+}
+
+// NewMemory creates a memory backend, optionally with an initial document.
+func NewMemory(doc ...*Document) *Memory {
+	backend := &Memory{}
+	if len(doc) > 0 && doc[0] != nil {
+		backend.doc = doc[0].Clone()
+	}
+	return backend
+}
+
+func (this *Memory) Load() (*Document, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.doc == nil {
+		return nil, nil
+	}
+	return this.doc.Clone(), nil
+}
+
+func (this *Memory) Save(doc *Document) error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.doc = doc.Clone()
+	return nil
+}
+
+// versionKey is the key of the schema version in JSON files.
+const versionKey = "$version"
+
+// JSONFile is a backend storing the document as a JSON object,
+// with a member per section and the version in the "$version" member.
+type JSONFile struct {
+	Path string
+}
+
+// NewJSONFile creates a JSON file backend.
+func NewJSONFile(path string) *JSONFile {
+	return &JSONFile{Path: path}
+}
+
+// Load reads the file. A missing file yields a nil document,
+// and a missing "$version" member a document of version NoVersion.
+func (this *JSONFile) Load() (*Document, error) {
+	data, err := os.ReadFile(this.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err = json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	doc := NewDocument(NoVersion)
+	for name, raw := range members {
+		if name == versionKey {
+			err = json.Unmarshal(raw, &doc.Version)
+		} else {
+			var values map[string]any
+			err = json.Unmarshal(raw, &values)
+			doc.Sections[name] = values
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return doc, nil
+}
+
+// Save writes the file atomically.
+func (this *JSONFile) Save(doc *Document) error {
+	members := make(map[string]any, len(doc.Sections)+1)
+	for name, values := range doc.Sections {
+		members[name] = values
+	}
+	members[versionKey] = doc.Version
+	data, err := json.MarshalIndent(members, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	return WriteFileAtomic(this.Path, data)
+}
+
+// INIFile is a backend storing the document as an INI file,
+// with a [section] per section and the version in a leading version= line.
+// Strings are written as they are where unambiguous, and other values as JSON.
+type INIFile struct {
+	Path string
+}
+
+// NewINIFile creates an INI file backend.
+func NewINIFile(path string) *INIFile {
+	return &INIFile{Path: path}
+}
+
+// Load reads the file. A missing file yields a nil document,
+// and a missing version line a document of version NoVersion.
+// Lines starting with ';' or '#' are comments.
+func (this *INIFile) Load() (*Document, error) {
+	data, err := os.ReadFile(this.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	doc := NewDocument(NoVersion)
+	var values map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("line %d: bad section header", lineNo)
+			}
+			name := strings.TrimSpace(line[1 : len(line)-1])
+			values = doc.Sections[name]
+			if values == nil {
+				values = map[string]any{}
+				doc.Sections[name] = values
+			}
+			continue
+		}
+		key, text, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing '='", lineNo)
+		}
+		key, text = strings.TrimSpace(key), strings.TrimSpace(text)
+		if values == nil {
+			if key != "version" {
+				return nil, fmt.Errorf("line %d: key outside of a section", lineNo)
+			}
+			doc.Version, err = strconv.Atoi(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: bad version", lineNo)
+			}
+			continue
+		}
+		values[key] = parseINIValue(text)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// Save writes the file atomically, with the sections and keys sorted.
+func (this *INIFile) Save(doc *Document) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "version=%d\n", doc.Version)
+	for _, name := range doc.SectionNames() {
+		if strings.ContainsAny(name, "[]\r\n") {
+			return fmt.Errorf("bad section name %q", name)
+		}
+		fmt.Fprintf(&buf, "\n[%s]\n", name)
+		values := doc.Sections[name]
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if key == "" || strings.ContainsAny(key, "=[;#\r\n") {
+				return fmt.Errorf("bad key %q in section %s", key, name)
+			}
+			text, err := formatINIValue(values[key])
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", name, key, err)
+			}
+			fmt.Fprintf(&buf, "%s=%s\n", key, text)
+		}
+	}
+	return WriteFileAtomic(this.Path, buf.Bytes())
+}
+
+func parseINIValue(text string) any {
+	var value any
+	if json.Unmarshal([]byte(text), &value) == nil {
+		return value
+	}
+	return text
+}
+
+func formatINIValue(value any) (string, error) {
+	if s, ok := value.(string); ok {
+		var parsed any
+		if s == strings.TrimSpace(s) && !strings.ContainsAny(s, "\r\n") &&
+			json.Unmarshal([]byte(s), &parsed) != nil {
+			return s, nil
+		}
+	}
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// WriteFileAtomic writes data to a file via a temporary file
+// in the same directory, so that readers never see a partial file.
+func WriteFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+	}
+	return err
+}
@@ -0,0 +1,153 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testDocument() *Document {
+	doc := NewDocument(3)
+	doc.Sections["editor"] = map[string]any{
+		"fontSize": 14.0,
+		"theme":    "dark",
+		"wordWrap": true,
+		"font":     "Segoe UI",
+		"number":   "42", //a string that reads as a number
+		"padded":   " x ",
+		"recent":   []any{"a.txt", "b.txt"},
+	}
+	doc.Sections["window"] = map[string]any{"left": -5.0, "maximized": false}
+	return doc
+}
+
+func TestFileRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	for _, backend := range []Backend{
+		NewJSONFile(filepath.Join(dir, "settings.json")),
+		NewINIFile(filepath.Join(dir, "settings.ini")),
+	} {
+		doc, err := backend.Load()
+		if doc != nil || err != nil {
+			t.Fatalf("%T: %v, %v loading a missing file, want nil, nil", backend, doc, err)
+		}
+		want := testDocument()
+		if err = backend.Save(want); err != nil {
+			t.Fatalf("%T: %v", backend, err)
+		}
+		doc, err = backend.Load()
+		if err != nil || !reflect.DeepEqual(doc, want) {
+			t.Errorf("%T: loaded %+v, %v, want %+v", backend, doc, err, want)
+		}
+	}
+}
+
+func TestFileNoVersion(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		backend Backend
+		path    string
+		content string
+	}{
+		{NewJSONFile(filepath.Join(dir, "a.json")), "a.json", `{"editor": {"fontSize": 12}}`},
+		{NewINIFile(filepath.Join(dir, "a.ini")), "a.ini", "[editor]\nfontSize=12\n"},
+	}
+	for _, test := range tests {
+		if err := os.WriteFile(filepath.Join(dir, test.path), []byte(test.content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		doc, err := test.backend.Load()
+		if err != nil || doc.Version != NoVersion || doc.Sections["editor"]["fontSize"] != 12.0 {
+			t.Errorf("%s: loaded %+v, %v, want version NoVersion", test.path, doc, err)
+		}
+	}
+}
+
+func TestINIFormat(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.ini")
+	content := "; comment\nversion=2\n\n[editor]\n# comment\ntheme = dark \nfontSize=14\nrecent=[\"a\"]\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := NewINIFile(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"theme": "dark", "fontSize": 14.0, "recent": []any{"a"}}
+	if doc.Version != 2 || !reflect.DeepEqual(doc.Sections["editor"], want) {
+		t.Errorf("loaded %+v", doc)
+	}
+
+	for _, bad := range []string{"[editor\n", "[editor]\nnoequals\n", "key=1\n", "version=x\n"} {
+		if err := os.WriteFile(path, []byte(bad), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewINIFile(path).Load(); err == nil {
+			t.Errorf("no error loading %q", bad)
+		}
+	}
+	doc = NewDocument(1)
+	doc.Sections["editor"] = map[string]any{"a=b": 1.0}
+	if err := NewINIFile(path).Save(doc); err == nil {
+		t.Error("no error saving a key with '='")
+	}
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	if err := os.WriteFile(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Errorf("content %q, %v, want new", data, err)
+	}
+
+	//a failed write leaves the file and no temporary file behind
+	if err := os.Mkdir(filepath.Join(dir, "dir"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "dir", "child"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(filepath.Join(dir, "dir"), []byte("x")); err == nil {
+		t.Error("replacing a non-empty directory succeeded")
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Errorf("temporary file %s left behind", entry.Name())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "dir", "child")); err != nil {
+		t.Errorf("the target was damaged: %v", err)
+	}
+}
+
+func TestStoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	store := NewStore(NewJSONFile(path), 1)
+	section := NewSection(store, "editor", editorDefaults)
+	section.Update(func(value *editor) {
+		value.FontSize = 20
+	})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	store = NewStore(NewJSONFile(path), 1)
+	section = NewSection(store, "editor", editorDefaults)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if section.Get().FontSize != 20 || store.Document().Version != 1 {
+		t.Errorf("font size %d, version %d after reloading, want 20 and 1",
+			section.Get().FontSize, store.Document().Version)
+	}
+}
@@ -0,0 +1,377 @@
+// Package settings persists application settings in typed sections.
+//
+// A Store holds the settings document loaded from a Backend, such as a JSON or INI file.
+// Each Section maps a named part of the document to a Go struct, with defaults
+// for the values not present. Documents carry a schema version, and migrations
+// upgrade older documents on load.
+//
+// Values are converted through encoding/json, so the sections follow
+// the json struct tags of their types.
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/zzl/goforms/framework/events"
+)
+
+// Document is the raw content of a settings store.
+type Document struct {
+	Version  int //NoVersion if the stored document has none
+	Sections map[string]map[string]any
+}
+
+// NoVersion is the version of a document stored without one, e.g. written by hand.
+// Such a document is taken to be of the current version of the store, and not migrated.
+const NoVersion = -1
+
+// NewDocument creates an empty document.
+func NewDocument(version int) *Document {
+	return &Document{Version: version, Sections: map[string]map[string]any{}}
+}
+
+// SectionNames returns the names of the sections, sorted.
+func (this *Document) SectionNames() []string {
+	names := make([]string, 0, len(this.Sections))
+	for name := range this.Sections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rename moves a section to a new name, e.g. in a migration.
+func (this *Document) Rename(oldName, newName string) {
+	if section, ok := this.Sections[oldName]; ok {
+		delete(this.Sections, oldName)
+		this.Sections[newName] = section
+	}
+}
+
+// Clone returns a deep copy of the document.
+func (this *Document) Clone() *Document {
+	doc := NewDocument(this.Version)
+	for name, values := range this.Sections {
+		doc.Sections[name] = cloneValue(values).(map[string]any)
+	}
+	return doc
+}
+
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for key, item := range v {
+			m[key] = cloneValue(item)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for n, item := range v {
+			s[n] = cloneValue(item)
+		}
+		return s
+	}
+	return value
+}
+
+// Backend loads and saves settings documents.
+type Backend interface {
+	// Load returns the stored document, or nil if there is none yet.
+	Load() (*Document, error)
+	// Save stores the document, replacing the previous one atomically.
+	Save(doc *Document) error
+}
+
+// Migration upgrades a document from version From to version From+1.
+type Migration struct {
+	From    int
+	Migrate func(doc *Document) error
+}
+
+// ChangeEventInfo is the event info of Store.OnChange.
+type ChangeEventInfo struct {
+	events.SimpleEventInfo
+	Section string
+}
+
+// Store holds the settings of an application.
+type Store struct {
+	OnLoad   events.SimpleEvent //fired after the sections are loaded
+	OnSave   events.SimpleEvent //fired before the sections are saved, to update them
+	OnChange events.Event[*ChangeEventInfo]
+
+	mutex      sync.Mutex
+	backend    Backend
+	version    int
+	migrations map[int]Migration
+	doc        *Document
+	sections   map[string]sectionBinding
+}
+
+type sectionBinding interface {
+	load(values map[string]any) (bool, error)
+	save() (map[string]any, error)
+}
+
+// NewStore creates a store of the specified schema version.
+func NewStore(backend Backend, version int, migrations ...Migration) *Store {
+	store := &Store{
+		backend:    backend,
+		version:    version,
+		migrations: map[int]Migration{},
+		doc:        NewDocument(version),
+		sections:   map[string]sectionBinding{},
+	}
+	for _, migration := range migrations {
+		store.migrations[migration.From] = migration
+	}
+	return store
+}
+
+// Version returns the schema version of the store.
+func (this *Store) Version() int {
+	return this.version
+}
+
+// Document returns a copy of the current document.
+func (this *Store) Document() *Document {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.doc.Clone()
+}
+
+// Load loads the document from the backend, migrates it to the current version
+// and updates the sections. A missing document leaves the sections at their defaults.
+// Sections whose values can not be decoded are left at their defaults too,
+// and their errors are returned once the other sections are loaded.
+func (this *Store) Load() error {
+	doc, err := this.backend.Load()
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		doc = NewDocument(this.version)
+	}
+	if doc.Sections == nil {
+		doc.Sections = map[string]map[string]any{}
+	}
+	if doc.Version == NoVersion {
+		doc.Version = this.version
+	}
+	if doc.Version > this.version {
+		return fmt.Errorf("settings version %d is newer than %d", doc.Version, this.version)
+	}
+	for doc.Version < this.version {
+		migration, ok := this.migrations[doc.Version]
+		if !ok {
+			return fmt.Errorf("no settings migration from version %d", doc.Version)
+		}
+		if err = migration.Migrate(doc); err != nil {
+			return fmt.Errorf("settings migration from version %d: %w", doc.Version, err)
+		}
+		doc.Version += 1
+	}
+
+	this.mutex.Lock()
+	this.doc = doc
+	sections := this.sortedSections()
+	this.mutex.Unlock()
+
+	var errs []error
+	for _, entry := range sections {
+		changed, err := entry.binding.load(doc.Sections[entry.name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("settings section %s: %w", entry.name, err))
+		}
+		if changed {
+			this.fireChange(entry.name)
+		}
+	}
+	this.OnLoad.Fire(this, &events.SimpleEventInfo{})
+	return errors.Join(errs...)
+}
+
+// Save stores the sections to the backend.
+// Sections of the document not bound to a Section are kept as they are.
+func (this *Store) Save() error {
+	this.OnSave.Fire(this, &events.SimpleEventInfo{})
+
+	this.mutex.Lock()
+	sections := this.sortedSections()
+	this.mutex.Unlock()
+
+	values := make(map[string]map[string]any, len(sections))
+	for _, entry := range sections {
+		sectionValues, err := entry.binding.save()
+		if err != nil {
+			return fmt.Errorf("settings section %s: %w", entry.name, err)
+		}
+		values[entry.name] = sectionValues
+	}
+
+	this.mutex.Lock()
+	for name, sectionValues := range values {
+		this.doc.Sections[name] = sectionValues
+	}
+	this.doc.Version = this.version
+	doc := this.doc.Clone()
+	this.mutex.Unlock()
+	return this.backend.Save(doc)
+}
+
+type namedBinding struct {
+	name    string
+	binding sectionBinding
+}
+
+func (this *Store) sortedSections() []namedBinding {
+	entries := make([]namedBinding, 0, len(this.sections))
+	for name, binding := range this.sections {
+		entries = append(entries, namedBinding{name, binding})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].name < entries[j].name
+	})
+	return entries
+}
+
+func (this *Store) fireChange(section string) {
+	this.OnChange.Fire(this, &ChangeEventInfo{Section: section})
+}
+
+// Section is a typed section of a store.
+type Section[T any] struct {
+	OnChange events.SimpleEvent
+
+	store    *Store
+	name     string
+	defaults T
+
+	mutex sync.Mutex
+	value T
+	err   error
+}
+
+// NewSection binds a section of the store to type T.
+// The values missing in the document take those of the defaults.
+// If the values in the document can not be decoded, the section takes the defaults
+// and Err returns the error. Binding a name twice panics.
+func NewSection[T any](store *Store, name string, defaults T) *Section[T] {
+	section := &Section[T]{store: store, name: name, defaults: defaults}
+	store.mutex.Lock()
+	if _, ok := store.sections[name]; ok {
+		store.mutex.Unlock()
+		panic("settings section already bound: " + name)
+	}
+	store.sections[name] = section
+	values := store.doc.Sections[name]
+	store.mutex.Unlock()
+	_, _ = section.load(values)
+	return section
+}
+
+// Name returns the name of the section.
+func (this *Section[T]) Name() string {
+	return this.name
+}
+
+// Err returns the error decoding the section when it was last loaded, or nil.
+func (this *Section[T]) Err() error {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.err
+}
+
+// Get returns a copy of the section value.
+func (this *Section[T]) Get() T {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	var value T
+	_ = copyValue(this.value, &value)
+	return value
+}
+
+// Set replaces the section value, notifying the listeners if it changed.
+func (this *Section[T]) Set(value T) {
+	this.mutex.Lock()
+	changed := !equalValues(this.value, value)
+	if changed {
+		var copied T
+		_ = copyValue(value, &copied)
+		this.value = copied
+	}
+	this.mutex.Unlock()
+	if changed {
+		this.notifyChange()
+	}
+}
+
+// Update modifies the section value through a function.
+func (this *Section[T]) Update(fn func(value *T)) {
+	value := this.Get()
+	fn(&value)
+	this.Set(value)
+}
+
+// Reset restores the defaults.
+func (this *Section[T]) Reset() {
+	this.Set(this.defaults)
+}
+
+func (this *Section[T]) notifyChange() {
+	this.OnChange.Fire(this, &events.SimpleEventInfo{})
+	this.store.fireChange(this.name)
+}
+
+// load sets the value from the document values and reports whether it changed.
+// If the values can not be decoded, the value is set to the defaults and the error returned.
+func (this *Section[T]) load(values map[string]any) (bool, error) {
+	var value T
+	_ = copyValue(this.defaults, &value)
+	var err error
+	if values != nil {
+		if err = copyValue(values, &value); err != nil {
+			var defaults T //value may hold part of the values
+			_ = copyValue(this.defaults, &defaults)
+			value = defaults
+		}
+	}
+	this.mutex.Lock()
+	changed := !equalValues(this.value, value)
+	this.value = value
+	this.err = err
+	this.mutex.Unlock()
+	if changed {
+		this.OnChange.Fire(this, &events.SimpleEventInfo{})
+	}
+	return changed, err
+}
+
+func (this *Section[T]) save() (map[string]any, error) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	var values map[string]any
+	err := copyValue(this.value, &values)
+	return values, err
+}
+
+// copyValue converts a value to another type through JSON.
+func copyValue(from any, to any) error {
+	data, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, to)
+}
+
+func equalValues(a any, b any) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(dataA, dataB)
+}
@@ -0,0 +1,195 @@
+package settings
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zzl/goforms/framework/events"
+)
+
+type editor struct {
+	FontSize int    `json:"fontSize"`
+	Theme    string `json:"theme"`
+	WordWrap bool   `json:"wordWrap"`
+}
+
+var editorDefaults = editor{FontSize: 10, Theme: "light"}
+
+func TestLoadDefaults(t *testing.T) {
+	store := NewStore(NewMemory(), 1)
+	section := NewSection(store, "editor", editorDefaults)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if section.Get() != editorDefaults {
+		t.Errorf("value %+v without a document, want the defaults", section.Get())
+	}
+}
+
+func TestLoadSave(t *testing.T) {
+	doc := NewDocument(1)
+	doc.Sections["editor"] = map[string]any{"fontSize": 14.0}
+	doc.Sections["unbound"] = map[string]any{"kept": true}
+	backend := NewMemory(doc)
+	store := NewStore(backend, 1)
+	section := NewSection(store, "editor", editorDefaults)
+	var changed []string
+	store.OnChange.AddListener(func(ei *ChangeEventInfo) {
+		changed = append(changed, ei.Section)
+	})
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	want := editor{FontSize: 14, Theme: "light"}
+	if section.Get() != want || len(changed) != 1 {
+		t.Fatalf("value %+v, changes %v, want %+v, one change", section.Get(), changed, want)
+	}
+
+	store.OnSave.AddListener(func(ei *events.SimpleEventInfo) {
+		section.Update(func(value *editor) {
+			value.WordWrap = true
+		})
+	})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := backend.Load()
+	values := saved.Sections["editor"]
+	if values["fontSize"] != 14.0 || values["theme"] != "light" || values["wordWrap"] != true {
+		t.Errorf("saved %v", values)
+	}
+	if saved.Sections["unbound"]["kept"] != true {
+		t.Error("the unbound section was dropped")
+	}
+}
+
+func TestSetNotifies(t *testing.T) {
+	store := NewStore(NewMemory(), 1)
+	section := NewSection(store, "editor", editorDefaults)
+	changes := 0
+	section.OnChange.AddListener(func(ei *events.SimpleEventInfo) {
+		changes += 1
+	})
+	section.Set(editorDefaults)
+	if changes != 0 {
+		t.Errorf("%d changes setting an equal value", changes)
+	}
+	section.Update(func(value *editor) {
+		value.Theme = "dark"
+	})
+	section.Reset()
+	if changes != 2 || section.Get() != editorDefaults {
+		t.Errorf("%d changes, value %+v, want 2 and the defaults", changes, section.Get())
+	}
+}
+
+func TestDecodeError(t *testing.T) {
+	doc := NewDocument(1)
+	doc.Sections["editor"] = map[string]any{"fontSize": "large", "theme": "dark"}
+	doc.Sections["other"] = map[string]any{"fontSize": 12.0}
+	store := NewStore(NewMemory(doc), 1)
+	section := NewSection(store, "editor", editorDefaults)
+	other := NewSection(store, "other", editorDefaults)
+	err := store.Load()
+	if err == nil || !strings.Contains(err.Error(), "settings section editor") {
+		t.Fatalf("Load returned %v, want the error of the editor section", err)
+	}
+	if section.Get() != editorDefaults || section.Err() == nil {
+		t.Errorf("value %+v, err %v, want the defaults and an error", section.Get(), section.Err())
+	}
+	if other.Get().FontSize != 12 || other.Err() != nil {
+		t.Errorf("other section %+v, err %v, want it loaded", other.Get(), other.Err())
+	}
+
+	//bound after the load
+	late := NewSection(store, "editor2", editorDefaults)
+	if late.Err() != nil {
+		t.Errorf("err %v for a section missing from the document", late.Err())
+	}
+	store.doc.Sections["editor3"] = map[string]any{"wordWrap": "yes"}
+	if bad := NewSection(store, "editor3", editorDefaults); bad.Err() == nil || bad.Get() != editorDefaults {
+		t.Errorf("value %+v, err %v binding undecodable values", bad.Get(), bad.Err())
+	}
+}
+
+func TestMigration(t *testing.T) {
+	doc := NewDocument(0)
+	doc.Sections["view"] = map[string]any{"fontSize": 12.0}
+	var steps []int
+	store := NewStore(NewMemory(doc), 2,
+		Migration{From: 0, Migrate: func(doc *Document) error {
+			steps = append(steps, 0)
+			doc.Rename("view", "editor")
+			return nil
+		}},
+		Migration{From: 1, Migrate: func(doc *Document) error {
+			steps = append(steps, 1)
+			doc.Sections["editor"]["theme"] = "dark"
+			return nil
+		}},
+	)
+	section := NewSection(store, "editor", editorDefaults)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || steps[0] != 0 || steps[1] != 1 {
+		t.Errorf("migrations %v, want [0 1]", steps)
+	}
+	want := editor{FontSize: 12, Theme: "dark"}
+	if section.Get() != want || store.Document().Version != 2 {
+		t.Errorf("value %+v, version %d, want %+v at version 2", section.Get(), store.Document().Version, want)
+	}
+}
+
+func TestMigrationErrors(t *testing.T) {
+	failing := errors.New("failing")
+	tests := []struct {
+		name       string
+		version    int
+		migrations []Migration
+		want       string
+	}{
+		{"newer", 3, nil, "newer"},
+		{"missing", 0, nil, "no settings migration from version 0"},
+		{"failing", 0, []Migration{{From: 0, Migrate: func(doc *Document) error {
+			return failing
+		}}}, "settings migration from version 0: failing"},
+	}
+	for _, test := range tests {
+		store := NewStore(NewMemory(NewDocument(test.version)), 1, test.migrations...)
+		err := store.Load()
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: err %v, want %q", test.name, err, test.want)
+		}
+	}
+}
+
+func TestNoVersion(t *testing.T) {
+	doc := NewDocument(NoVersion)
+	doc.Sections["editor"] = map[string]any{"fontSize": 16.0}
+	migrated := false
+	store := NewStore(NewMemory(doc), 2, Migration{From: 0, Migrate: func(doc *Document) error {
+		migrated = true
+		return nil
+	}})
+	section := NewSection(store, "editor", editorDefaults)
+	if err := store.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if migrated || section.Get().FontSize != 16 || store.Document().Version != 2 {
+		t.Errorf("migrated %v, font size %d, version %d, want the document taken as version 2",
+			migrated, section.Get().FontSize, store.Document().Version)
+	}
+}
+
+func TestBindTwice(t *testing.T) {
+	store := NewStore(NewMemory(), 1)
+	NewSection(store, "editor", editorDefaults)
+	defer func() {
+		if recover() == nil {
+			t.Error("binding a section twice didn't panic")
+		}
+	}()
+	NewSection(store, "editor", editorDefaults)
+}
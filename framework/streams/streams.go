@@ -0,0 +1,268 @@
+// Package streams is a small reactive layer over events.Event.
+//
+// A Stream delivers values to its observers. Streams are built from events
+// or subjects and transformed with operators such as Map, Filter and Debounce.
+// Time-based operators schedule on a clock.Clock, so that they run on the UI thread
+// with forms.UIClock, and deterministically on a clock.Virtual in tests.
+package streams
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zzl/goforms/framework/clock"
+	"github.com/zzl/goforms/framework/events"
+	"github.com/zzl/goforms/framework/timers"
+)
+
+// Stream is a source of values. Subscribing to it starts the delivery
+// of values to the observer until the subscription is disposed.
+type Stream[T any] func(observer func(value T)) *Subscription
+
+// Subscribe subscribes the observer to the stream.
+func (this Stream[T]) Subscribe(observer func(value T)) *Subscription {
+	return this(observer)
+}
+
+// Subscription is returned by Stream.Subscribe.
+// It implements types.Disposable.
+type Subscription struct {
+	once    sync.Once
+	dispose func()
+}
+
+// NewSubscription creates a subscription calling dispose when disposed.
+func NewSubscription(dispose func()) *Subscription {
+	return &Subscription{dispose: dispose}
+}
+
+// Dispose ends the subscription. Disposing more than once is harmless.
+func (this *Subscription) Dispose() {
+	this.once.Do(func() {
+		if this.dispose != nil {
+			this.dispose()
+		}
+	})
+}
+
+// FromEvent creates a stream of the infos of an event.
+func FromEvent[T events.EventInfo](event *events.Event[T]) Stream[T] {
+	return func(observer func(value T)) *Subscription {
+		pListener := event.AddListener(func(info T) {
+			observer(info)
+		})
+		return NewSubscription(func() {
+			event.RemoveListener(pListener)
+		})
+	}
+}
+
+// Subject is a stream whose values are emitted explicitly.
+type Subject[T any] struct {
+	mutex     sync.Mutex
+	observers []*func(value T)
+}
+
+// NewSubject creates a subject.
+func NewSubject[T any]() *Subject[T] {
+	return &Subject[T]{}
+}
+
+// Emit delivers the value to the current observers.
+func (this *Subject[T]) Emit(value T) {
+	this.mutex.Lock()
+	observers := append([]*func(T){}, this.observers...)
+	this.mutex.Unlock()
+	for _, observer := range observers {
+		(*observer)(value)
+	}
+}
+
+// Stream returns the subject as a stream.
+func (this *Subject[T]) Stream() Stream[T] {
+	return func(observer func(value T)) *Subscription {
+		p := &observer
+		this.mutex.Lock()
+		this.observers = append(this.observers, p)
+		this.mutex.Unlock()
+		return NewSubscription(func() {
+			this.mutex.Lock()
+			defer this.mutex.Unlock()
+			for n, o := range this.observers {
+				if o == p {
+					this.observers = append(this.observers[:n], this.observers[n+1:]...)
+					return
+				}
+			}
+		})
+	}
+}
+
+// Map transforms the values of the stream.
+func Map[T, U any](source Stream[T], fn func(value T) U) Stream[U] {
+	return func(observer func(value U)) *Subscription {
+		return source(func(value T) {
+			observer(fn(value))
+		})
+	}
+}
+
+// Filter passes the values satisfying the predicate.
+func Filter[T any](source Stream[T], predicate func(value T) bool) Stream[T] {
+	return func(observer func(value T)) *Subscription {
+		return source(func(value T) {
+			if predicate(value) {
+				observer(value)
+			}
+		})
+	}
+}
+
+// DistinctUntilChanged drops the values equal to their predecessor.
+func DistinctUntilChanged[T comparable](source Stream[T]) Stream[T] {
+	return DistinctUntilChangedBy(source, func(value T) T {
+		return value
+	})
+}
+
+// DistinctUntilChangedBy drops the values whose key equals the key of their predecessor.
+func DistinctUntilChangedBy[T any, K comparable](source Stream[T], key func(value T) K) Stream[T] {
+	return func(observer func(value T)) *Subscription {
+		var mutex sync.Mutex
+		var last K
+		var hasLast bool
+		return source(func(value T) {
+			k := key(value)
+			mutex.Lock()
+			same := hasLast && k == last
+			last, hasLast = k, true
+			mutex.Unlock()
+			if !same {
+				observer(value)
+			}
+		})
+	}
+}
+
+// Debounce delivers a value once the stream has been quiet for the delay
+// since it, dropping the values superseded meanwhile.
+func Debounce[T any](source Stream[T], clk clock.Clock, delay time.Duration) Stream[T] {
+	return func(observer func(value T)) *Subscription {
+		var mutex sync.Mutex
+		var latest T
+		var disposed bool
+		debouncer := timers.NewDebouncer(clk, delay, func() {
+			mutex.Lock()
+			value, ok := latest, !disposed
+			mutex.Unlock()
+			if ok {
+				observer(value)
+			}
+		})
+		sub := source(func(value T) {
+			mutex.Lock()
+			latest = value
+			mutex.Unlock()
+			debouncer.Trigger()
+		})
+		return NewSubscription(func() {
+			sub.Dispose()
+			mutex.Lock()
+			disposed = true
+			mutex.Unlock()
+			debouncer.Cancel()
+		})
+	}
+}
+
+// Throttle delivers at most one value per interval. The first value is
+// delivered immediately, and the latest value received within the interval
+// that follows is delivered at its end.
+func Throttle[T any](source Stream[T], clk clock.Clock, interval time.Duration) Stream[T] {
+	return func(observer func(value T)) *Subscription {
+		var mutex sync.Mutex
+		var latest T
+		var disposed bool
+		throttler := timers.NewThrottler(clk, interval, func() {
+			mutex.Lock()
+			value, ok := latest, !disposed
+			mutex.Unlock()
+			if ok {
+				observer(value)
+			}
+		})
+		sub := source(func(value T) {
+			mutex.Lock()
+			latest = value
+			mutex.Unlock()
+			throttler.Trigger()
+		})
+		return NewSubscription(func() {
+			sub.Dispose()
+			mutex.Lock()
+			disposed = true
+			mutex.Unlock()
+			throttler.Cancel()
+		})
+	}
+}
+
+// Merge delivers the values of all the streams as they come.
+func Merge[T any](sources ...Stream[T]) Stream[T] {
+	return func(observer func(value T)) *Subscription {
+		subs := make([]*Subscription, len(sources))
+		for n, source := range sources {
+			subs[n] = source(observer)
+		}
+		return NewSubscription(func() {
+			for _, sub := range subs {
+				sub.Dispose()
+			}
+		})
+	}
+}
+
+// Pair is a value of Zip.
+type Pair[T, U any] struct {
+	First  T
+	Second U
+}
+
+// Zip pairs the values of two streams by their order,
+// buffering the values of the faster stream.
+func Zip[T, U any](first Stream[T], second Stream[U]) Stream[Pair[T, U]] {
+	return func(observer func(value Pair[T, U])) *Subscription {
+		var mutex sync.Mutex
+		var firsts []T
+		var seconds []U
+		emit := func() {
+			for {
+				mutex.Lock()
+				if len(firsts) == 0 || len(seconds) == 0 {
+					mutex.Unlock()
+					return
+				}
+				pair := Pair[T, U]{firsts[0], seconds[0]}
+				firsts, seconds = firsts[1:], seconds[1:]
+				mutex.Unlock()
+				observer(pair)
+			}
+		}
+		sub1 := first(func(value T) {
+			mutex.Lock()
+			firsts = append(firsts, value)
+			mutex.Unlock()
+			emit()
+		})
+		sub2 := second(func(value U) {
+			mutex.Lock()
+			seconds = append(seconds, value)
+			mutex.Unlock()
+			emit()
+		})
+		return NewSubscription(func() {
+			sub1.Dispose()
+			sub2.Dispose()
+		})
+	}
+}
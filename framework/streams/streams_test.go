@@ -0,0 +1,193 @@
+package streams
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/zzl/goforms/framework/clock"
+	"github.com/zzl/goforms/framework/events"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const ms = time.Millisecond
+
+// collector collects the values of a stream with their times, as offsets from start.
+type collector[T any] struct {
+	clock  *clock.Virtual
+	values []string
+}
+
+func (this *collector[T]) observe(value T) {
+	if this.clock != nil {
+		this.values = append(this.values, fmt.Sprintf("%v@%v", value, this.clock.Now().Sub(start)))
+	} else {
+		this.values = append(this.values, fmt.Sprint(value))
+	}
+}
+
+func (this *collector[T]) check(t *testing.T, name string, want ...string) {
+	t.Helper()
+	if fmt.Sprint(this.values) != fmt.Sprint(want) {
+		t.Errorf("%s: values %v, want %v", name, this.values, want)
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	virtual := clock.NewVirtual(start)
+	subject := NewSubject[string]()
+	c := &collector[string]{clock: virtual}
+	sub := Debounce(subject.Stream(), virtual, 100*ms).Subscribe(c.observe)
+	defer sub.Dispose()
+
+	//typing "abc" a character every 50ms
+	for _, s := range []string{"a", "ab", "abc"} {
+		subject.Emit(s)
+		virtual.Advance(50 * ms)
+	}
+	c.check(t, "typing")
+	virtual.Advance(50 * ms)
+	c.check(t, "quiet", "abc@200ms")
+
+	//a value after a quiet period waits a full delay
+	virtual.Advance(time.Second)
+	subject.Emit("x")
+	virtual.Advance(99 * ms)
+	c.check(t, "before the delay", "abc@200ms")
+	virtual.Advance(1 * ms)
+	c.check(t, "after the delay", "abc@200ms", "x@1.3s")
+}
+
+func TestDebounceDispose(t *testing.T) {
+	virtual := clock.NewVirtual(start)
+	subject := NewSubject[int]()
+	c := &collector[int]{clock: virtual}
+	sub := Debounce(subject.Stream(), virtual, 100*ms).Subscribe(c.observe)
+	subject.Emit(1)
+	sub.Dispose()
+	if virtual.Pending() != 0 {
+		t.Errorf("%d calls pending after Dispose", virtual.Pending())
+	}
+	subject.Emit(2)
+	virtual.Advance(time.Second)
+	c.check(t, "disposed")
+}
+
+func TestThrottle(t *testing.T) {
+	virtual := clock.NewVirtual(start)
+	subject := NewSubject[int]()
+	c := &collector[int]{clock: virtual}
+	sub := Throttle(subject.Stream(), virtual, 100*ms).Subscribe(c.observe)
+	defer sub.Dispose()
+
+	//a value every 30ms: the first at once, then the latest at the end of each interval
+	for i := 1; i <= 8; i++ {
+		subject.Emit(i)
+		virtual.Advance(30 * ms)
+	}
+	virtual.Advance(time.Second)
+	c.check(t, "throttled", "1@0s", "4@100ms", "7@200ms", "8@300ms")
+
+	//a value after a quiet interval is delivered at once
+	subject.Emit(9)
+	c.check(t, "after quiet", "1@0s", "4@100ms", "7@200ms", "8@300ms", "9@1.24s")
+}
+
+func TestThrottleDispose(t *testing.T) {
+	virtual := clock.NewVirtual(start)
+	subject := NewSubject[int]()
+	c := &collector[int]{clock: virtual}
+	sub := Throttle(subject.Stream(), virtual, 100*ms).Subscribe(c.observe)
+	subject.Emit(1)
+	subject.Emit(2)
+	sub.Dispose()
+	virtual.Advance(time.Second)
+	c.check(t, "disposed", "1@0s")
+}
+
+func TestOperators(t *testing.T) {
+	subject := NewSubject[int]()
+	c := &collector[string]{}
+	even := Filter(subject.Stream(), func(value int) bool {
+		return value%2 == 0
+	})
+	halves := DistinctUntilChanged(Map(even, func(value int) int {
+		return value / 2
+	}))
+	sub := Map(halves, func(value int) string {
+		return fmt.Sprint("h", value)
+	}).Subscribe(c.observe)
+	for _, value := range []int{1, 2, 3, 2, 4, 5, 4, 4, 6} {
+		subject.Emit(value)
+	}
+	c.check(t, "operators", "h1", "h2", "h3")
+	sub.Dispose()
+	subject.Emit(8)
+	c.check(t, "disposed", "h1", "h2", "h3")
+}
+
+func TestOrdering(t *testing.T) {
+	//observers receive the values in the order emitted, in the order subscribed
+	subject := NewSubject[int]()
+	var log []string
+	subs := make([]*Subscription, 3)
+	for n := range subs {
+		name := fmt.Sprint("o", n)
+		subs[n] = subject.Stream().Subscribe(func(value int) {
+			log = append(log, fmt.Sprint(name, ":", value))
+		})
+	}
+	subject.Emit(1)
+	subs[1].Dispose()
+	subject.Emit(2)
+	if fmt.Sprint(log) != "[o0:1 o1:1 o2:1 o0:2 o2:2]" {
+		t.Errorf("log %v", log)
+	}
+
+	//timed values are delivered in the order of their due times
+	virtual := clock.NewVirtual(start)
+	fast, slow := NewSubject[string](), NewSubject[string]()
+	c := &collector[string]{clock: virtual}
+	sub := Merge(
+		Debounce(slow.Stream(), virtual, 100*ms),
+		Debounce(fast.Stream(), virtual, 10*ms),
+	).Subscribe(c.observe)
+	defer sub.Dispose()
+	slow.Emit("slow")
+	fast.Emit("fast")
+	virtual.Advance(time.Second)
+	c.check(t, "merged", "fast@10ms", "slow@100ms")
+}
+
+func TestZip(t *testing.T) {
+	letters, numbers := NewSubject[string](), NewSubject[int]()
+	c := &collector[Pair[string, int]]{}
+	sub := Zip(letters.Stream(), numbers.Stream()).Subscribe(c.observe)
+	letters.Emit("a")
+	letters.Emit("b")
+	numbers.Emit(1)
+	letters.Emit("c")
+	numbers.Emit(2)
+	numbers.Emit(3)
+	numbers.Emit(4)
+	c.check(t, "zipped", "{a 1}", "{b 2}", "{c 3}")
+	sub.Dispose()
+	letters.Emit("d")
+	c.check(t, "disposed", "{a 1}", "{b 2}", "{c 3}")
+}
+
+func TestFromEvent(t *testing.T) {
+	var event events.SimpleEvent
+	var senders []any
+	sub := FromEvent(&event).Subscribe(func(info *events.SimpleEventInfo) {
+		senders = append(senders, info.Sender)
+	})
+	event.Fire("a", &events.SimpleEventInfo{})
+	sub.Dispose()
+	sub.Dispose()
+	event.Fire("b", &events.SimpleEventInfo{})
+	if len(senders) != 1 || senders[0] != "a" {
+		t.Errorf("senders %v, want [a]", senders)
+	}
+}
@@ -0,0 +1,89 @@
+package tasks
+
+import (
+	"sync"
+	"time"
+)
+
+// Dispatcher runs functions on the UI thread.
+// The functions must be run in the order they are posted.
+type Dispatcher interface {
+	// Post queues the function to run on the UI thread and returns immediately.
+	Post(fn func())
+}
+
+// DispatcherFunc adapts a function to a Dispatcher.
+type DispatcherFunc func(fn func())
+
+func (this DispatcherFunc) Post(fn func()) {
+	this(fn)
+}
+
+// QueueDispatcher is a Dispatcher that queues the posted functions
+// until they are run explicitly by the goroutine acting as the UI thread.
+// It stands in for the real dispatcher in tests.
+type QueueDispatcher struct {
+	mutex  sync.Mutex
+	queue  []func()
+	signal chan struct{}
+}
+
+// NewQueueDispatcher creates a QueueDispatcher.
+func NewQueueDispatcher() *QueueDispatcher {
+	return &QueueDispatcher{signal: make(chan struct{}, 1)}
+}
+
+// Post implements Dispatcher.Post.
+func (this *QueueDispatcher) Post(fn func()) {
+	this.mutex.Lock()
+	this.queue = append(this.queue, fn)
+	this.mutex.Unlock()
+	select {
+	case this.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Pending returns the number of queued functions.
+func (this *QueueDispatcher) Pending() int {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return len(this.queue)
+}
+
+// RunPending runs the queued functions, including those posted while running,
+// and returns the number of functions run.
+func (this *QueueDispatcher) RunPending() int {
+	count := 0
+	for {
+		this.mutex.Lock()
+		if len(this.queue) == 0 {
+			this.mutex.Unlock()
+			return count
+		}
+		fn := this.queue[0]
+		this.queue = this.queue[1:]
+		this.mutex.Unlock()
+		fn()
+		count += 1
+	}
+}
+
+// RunUntil runs the posted functions as they arrive until the condition is met,
+// or the timeout elapses. It returns whether the condition was met.
+func (this *QueueDispatcher) RunUntil(cond func() bool, timeout time.Duration) bool {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	for {
+		this.RunPending()
+		if cond() {
+			return true
+		}
+		select {
+		case <-this.signal:
+		case <-deadline.C:
+			this.RunPending()
+			return cond()
+		}
+	}
+}
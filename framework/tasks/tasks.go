@@ -0,0 +1,311 @@
+// Package tasks runs work in the background and delivers the results,
+// progress and completion notifications on the UI thread through a Dispatcher.
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// DefaultProgressInterval is the minimum interval between progress notifications
+// if Options.ProgressInterval is not specified.
+const DefaultProgressInterval = 100 * time.Millisecond
+
+// ErrNotDone is returned by Future.Result before the task completes.
+var ErrNotDone = errors.New("task not done")
+
+// PanicError is the error of a task whose work function panicked.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (this *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", this.Value)
+}
+
+// ProgressInfo describes the progress of a task.
+type ProgressInfo struct {
+	Fraction float64 //completed fraction in [0, 1], negative if unknown
+	Message  string
+}
+
+// Progress is passed to the work function to report progress.
+// Report can be called from any goroutine and as often as convenient;
+// the reports are coalesced so that the listeners are notified at most
+// once per progress interval, with the latest report.
+type Progress struct {
+	task *Task
+}
+
+// Report reports the completed fraction and a message.
+func (this *Progress) Report(fraction float64, message string) {
+	this.task.report(ProgressInfo{Fraction: fraction, Message: message})
+}
+
+// Work is the function run in the background by a task.
+// It should return early with ctx.Err() when the context is canceled.
+type Work[T any] func(ctx context.Context, progress *Progress) (T, error)
+
+// BusyHook is called on the UI thread when a task starts,
+// e.g. to show a wait cursor or a progress dialog.
+// The returned function, if not nil, is called on the UI thread when the task completes,
+// before the continuations of the task.
+type BusyHook func(task *Task) (end func())
+
+// Options are the options of a task.
+type Options struct {
+	ProgressInterval time.Duration //minimum interval between progress notifications
+	Busy             BusyHook
+}
+
+// Task is the part of a Future independent of the result type.
+type Task struct {
+	dispatcher Dispatcher
+	cancel     context.CancelFunc
+	interval   time.Duration
+
+	mutex             sync.Mutex
+	done              bool
+	doneCh            chan struct{}
+	latestProgress    ProgressInfo
+	progressPending   bool
+	lastProgressTime  time.Time
+	progressListeners []func(info ProgressInfo)
+	completeListeners []func()
+}
+
+func (this *Task) init(dispatcher Dispatcher, cancel context.CancelFunc, options *Options) {
+	this.dispatcher = dispatcher
+	this.cancel = cancel
+	this.interval = options.ProgressInterval
+	if this.interval <= 0 {
+		this.interval = DefaultProgressInterval
+	}
+	this.doneCh = make(chan struct{})
+}
+
+// Cancel cancels the context passed to the work function.
+// The task completes with the context error unless it is already done.
+func (this *Task) Cancel() {
+	this.cancel()
+}
+
+// Done returns a channel closed when the task completes,
+// after its result is set and before its continuations run.
+func (this *Task) Done() <-chan struct{} {
+	return this.doneCh
+}
+
+// IsDone tells whether the task has completed.
+func (this *Task) IsDone() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.done
+}
+
+// LatestProgress returns the latest progress reported.
+func (this *Task) LatestProgress() ProgressInfo {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.latestProgress
+}
+
+// AddProgressListener adds a listener notified of progress on the UI thread.
+func (this *Task) AddProgressListener(listener func(info ProgressInfo)) {
+	this.mutex.Lock()
+	this.progressListeners = append(this.progressListeners, listener)
+	this.mutex.Unlock()
+}
+
+// AddCompleteListener adds a listener called on the UI thread when the task completes.
+// If the task has completed already, the listener is posted to the UI thread.
+func (this *Task) AddCompleteListener(listener func()) {
+	this.mutex.Lock()
+	if !this.done {
+		this.completeListeners = append(this.completeListeners, listener)
+		this.mutex.Unlock()
+		return
+	}
+	this.mutex.Unlock()
+	this.dispatcher.Post(listener)
+}
+
+func (this *Task) report(info ProgressInfo) {
+	this.mutex.Lock()
+	if this.done {
+		this.mutex.Unlock()
+		return
+	}
+	this.latestProgress = info
+	if this.progressPending {
+		this.mutex.Unlock()
+		return
+	}
+	this.progressPending = true
+	delay := this.interval - time.Since(this.lastProgressTime)
+	this.mutex.Unlock()
+
+	if delay <= 0 {
+		this.dispatcher.Post(this.notifyProgress)
+	} else {
+		time.AfterFunc(delay, func() {
+			this.dispatcher.Post(this.notifyProgress)
+		})
+	}
+}
+
+// notifyProgress runs on the UI thread.
+func (this *Task) notifyProgress() {
+	this.mutex.Lock()
+	if this.done || !this.progressPending {
+		this.mutex.Unlock()
+		return
+	}
+	this.progressPending = false
+	this.lastProgressTime = time.Now()
+	info := this.latestProgress
+	listeners := append([]func(ProgressInfo){}, this.progressListeners...)
+	this.mutex.Unlock()
+
+	for _, listener := range listeners {
+		listener(info)
+	}
+}
+
+// complete runs on the UI thread.
+func (this *Task) complete(setResult func()) {
+	this.notifyProgress() //flush the pending progress
+	setResult()
+	this.mutex.Lock()
+	this.done = true
+	listeners := this.completeListeners
+	this.completeListeners = nil
+	this.mutex.Unlock()
+
+	close(this.doneCh)
+	for _, listener := range listeners {
+		listener()
+	}
+}
+
+// Future is a task producing a result of type T.
+// Its continuations run on the UI thread.
+type Future[T any] struct {
+	Task
+
+	result T
+	err    error
+}
+
+// Run runs the work on a new goroutine and returns its future.
+// Canceling ctx or calling Future.Cancel cancels the context passed to the work,
+// and a task whose context is canceled completes with the context error
+// even if the work returns successfully.
+func Run[T any](dispatcher Dispatcher, ctx context.Context,
+	work Work[T], opts ...*Options) *Future[T] {
+	options := &Options{}
+	if len(opts) > 0 && opts[0] != nil {
+		options = opts[0]
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	future := &Future[T]{}
+	future.init(dispatcher, cancel, options)
+
+	if options.Busy != nil {
+		var end func()
+		dispatcher.Post(func() {
+			end = options.Busy(&future.Task)
+		})
+		future.AddCompleteListener(func() {
+			if end != nil {
+				end()
+			}
+		})
+	}
+
+	go func() {
+		result, err := runWork(ctx, work, &Progress{task: &future.Task})
+		if err == nil && ctx.Err() != nil {
+			var zero T
+			result, err = zero, ctx.Err()
+		}
+		cancel()
+		dispatcher.Post(func() {
+			future.complete(func() {
+				future.result = result
+				future.err = err
+			})
+		})
+	}()
+	return future
+}
+
+func runWork[T any](ctx context.Context, work Work[T], progress *Progress) (result T, err error) {
+	defer func() {
+		if value := recover(); value != nil {
+			err = &PanicError{Value: value, Stack: debug.Stack()}
+		}
+	}()
+	return work(ctx, progress)
+}
+
+// FromResult returns a completed future.
+func FromResult[T any](dispatcher Dispatcher, result T, err error) *Future[T] {
+	future := &Future[T]{result: result, err: err}
+	future.init(dispatcher, func() {}, &Options{})
+	future.done = true
+	close(future.doneCh)
+	return future
+}
+
+// Result returns the result of the task, or ErrNotDone if it has not completed.
+func (this *Future[T]) Result() (T, error) {
+	if !this.IsDone() {
+		var zero T
+		return zero, ErrNotDone
+	}
+	return this.result, this.err
+}
+
+// OnComplete adds a continuation called with the result of the task.
+func (this *Future[T]) OnComplete(fn func(result T, err error)) *Future[T] {
+	this.AddCompleteListener(func() {
+		fn(this.result, this.err)
+	})
+	return this
+}
+
+// Then adds a continuation called if the task succeeds.
+func (this *Future[T]) Then(fn func(result T)) *Future[T] {
+	return this.OnComplete(func(result T, err error) {
+		if err == nil {
+			fn(result)
+		}
+	})
+}
+
+// Catch adds a continuation called if the task fails or is canceled.
+func (this *Future[T]) Catch(fn func(err error)) *Future[T] {
+	return this.OnComplete(func(result T, err error) {
+		if err != nil {
+			fn(err)
+		}
+	})
+}
+
+// Finally adds a continuation called when the task completes.
+func (this *Future[T]) Finally(fn func()) *Future[T] {
+	this.AddCompleteListener(fn)
+	return this
+}
+
+// OnProgress adds a listener notified of progress on the UI thread.
+func (this *Future[T]) OnProgress(fn func(info ProgressInfo)) *Future[T] {
+	this.AddProgressListener(fn)
+	return this
+}
@@ -0,0 +1,211 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// runDone runs the posted functions until the task completes.
+func runDone(t *testing.T, d *QueueDispatcher, task *Task) {
+	t.Helper()
+	if !d.RunUntil(task.IsDone, 5*time.Second) {
+		t.Fatal("task not done")
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	d := NewQueueDispatcher()
+	proceed := make(chan struct{})
+	future := Run(d, context.Background(), func(ctx context.Context, progress *Progress) (int, error) {
+		<-proceed
+		return 42, nil
+	})
+	if _, err := future.Result(); err != ErrNotDone {
+		t.Errorf("result before completion: %v, want ErrNotDone", err)
+	}
+	var results []int
+	future.Then(func(result int) {
+		results = append(results, result)
+	}).Catch(func(err error) {
+		t.Errorf("Catch called with %v", err)
+	})
+	close(proceed)
+	<-time.After(10 * time.Millisecond)
+	//the completion waits for the UI thread
+	if future.IsDone() || len(results) != 0 {
+		t.Fatal("completed off the UI thread")
+	}
+	runDone(t, d, &future.Task)
+	result, err := future.Result()
+	if result != 42 || err != nil || !reflect.DeepEqual(results, []int{42}) {
+		t.Errorf("result %d, %v, continuations got %v", result, err, results)
+	}
+	select {
+	case <-future.Done():
+	default:
+		t.Error("Done channel not closed")
+	}
+
+	failing := Run(d, context.Background(), func(ctx context.Context, progress *Progress) (int, error) {
+		return 1, errors.New("failed")
+	})
+	var caught error
+	failing.Then(func(result int) {
+		t.Error("Then called for a failed task")
+	}).Catch(func(err error) {
+		caught = err
+	})
+	runDone(t, d, &failing.Task)
+	d.RunPending()
+	if caught == nil || caught.Error() != "failed" {
+		t.Errorf("caught %v", caught)
+	}
+
+	panicking := Run(d, context.Background(), func(ctx context.Context, progress *Progress) (int, error) {
+		panic("oops")
+	})
+	runDone(t, d, &panicking.Task)
+	var panicErr *PanicError
+	if _, err := panicking.Result(); !errors.As(err, &panicErr) || panicErr.Value != "oops" {
+		t.Errorf("panicking task error %v, want a PanicError", err)
+	}
+}
+
+func TestCancellation(t *testing.T) {
+	d := NewQueueDispatcher()
+	started := make(chan struct{})
+	future := Run(d, context.Background(), func(ctx context.Context, progress *Progress) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	<-started
+	future.Cancel()
+	runDone(t, d, &future.Task)
+	if _, err := future.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled task error %v, want context.Canceled", err)
+	}
+
+	//the work succeeding after the cancellation doesn't count
+	ctx, cancel := context.WithCancel(context.Background())
+	proceed := make(chan struct{})
+	ignoring := Run(d, ctx, func(ctx context.Context, progress *Progress) (string, error) {
+		<-proceed
+		return "done", nil
+	})
+	cancel()
+	close(proceed)
+	runDone(t, d, &ignoring.Task)
+	if result, err := ignoring.Result(); result != "" || !errors.Is(err, context.Canceled) {
+		t.Errorf("result %q, %v, want a canceled task", result, err)
+	}
+
+	//canceling a completed task changes nothing
+	ignoring.Cancel()
+	if _, err := ignoring.Result(); !errors.Is(err, context.Canceled) {
+		t.Errorf("error %v changed", err)
+	}
+	done := FromResult(d, "ok", nil)
+	done.Cancel()
+	if result, err := done.Result(); result != "ok" || err != nil {
+		t.Errorf("result %q, %v of a completed future changed by Cancel", result, err)
+	}
+}
+
+func TestContinuationOrder(t *testing.T) {
+	d := NewQueueDispatcher()
+	var order []string
+	proceed := make(chan struct{})
+	future := Run(d, context.Background(), func(ctx context.Context, progress *Progress) (int, error) {
+		<-proceed
+		return 1, nil
+	}, &Options{Busy: func(task *Task) func() {
+		order = append(order, "busy")
+		return func() {
+			order = append(order, "end busy")
+		}
+	}})
+	future.OnComplete(func(result int, err error) {
+		order = append(order, "complete")
+	}).Then(func(result int) {
+		order = append(order, "then")
+	}).Finally(func() {
+		order = append(order, "finally")
+	})
+	d.RunPending()
+	close(proceed)
+	runDone(t, d, &future.Task)
+	//added after completion, run in order on the UI thread
+	future.Then(func(result int) {
+		order = append(order, "late then")
+	}).Finally(func() {
+		order = append(order, "late finally")
+	})
+	if len(order) != 5 {
+		t.Errorf("late continuations ran before the UI thread did: %v", order)
+	}
+	d.RunPending()
+	want := []string{"busy", "end busy", "complete", "then", "finally", "late then", "late finally"}
+	if !reflect.DeepEqual(order, want) {
+		t.Errorf("order %v, want %v", order, want)
+	}
+}
+
+func TestProgress(t *testing.T) {
+	d := NewQueueDispatcher()
+	reported := make(chan struct{})
+	proceed := make(chan struct{})
+	future := Run(d, context.Background(), func(ctx context.Context, progress *Progress) (int, error) {
+		progress.Report(0.1, "first")
+		progress.Report(0.2, "second")
+		close(reported)
+		<-proceed
+		progress.Report(0.5, "third")
+		progress.Report(0.9, "last")
+		return 0, nil
+	}, &Options{ProgressInterval: time.Hour})
+	var infos []ProgressInfo
+	future.OnProgress(func(info ProgressInfo) {
+		infos = append(infos, info)
+	})
+	<-reported
+	d.RunPending()
+	close(proceed)
+	runDone(t, d, &future.Task)
+	//coalesced to the latest, and flushed on completion
+	want := []ProgressInfo{{0.2, "second"}, {0.9, "last"}}
+	if !reflect.DeepEqual(infos, want) {
+		t.Errorf("progress %v, want %v", infos, want)
+	}
+	if future.LatestProgress() != (ProgressInfo{0.9, "last"}) {
+		t.Errorf("latest progress %v", future.LatestProgress())
+	}
+}
+
+func TestQueueDispatcher(t *testing.T) {
+	d := NewQueueDispatcher()
+	var order []int
+	d.Post(func() {
+		order = append(order, 1)
+		d.Post(func() { order = append(order, 3) })
+	})
+	d.Post(func() { order = append(order, 2) })
+	if d.Pending() != 2 {
+		t.Errorf("%d pending, want 2", d.Pending())
+	}
+	if n := d.RunPending(); n != 3 || !reflect.DeepEqual(order, []int{1, 2, 3}) {
+		t.Errorf("ran %d: %v, want 3 in order", n, order)
+	}
+	if d.RunUntil(func() bool { return false }, 10*time.Millisecond) {
+		t.Error("RunUntil met a false condition")
+	}
+	var posted DispatcherFunc = func(fn func()) { fn() }
+	ran := false
+	posted.Post(func() { ran = true })
+	if !ran {
+		t.Error("DispatcherFunc didn't post")
+	}
+}
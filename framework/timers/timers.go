@@ -0,0 +1,226 @@
+// Package timers provides one-shot and repeating timers, debouncers and throttlers
+// scheduled on a clock.Clock.
+//
+// The callbacks run where the clock runs them, e.g. on the UI thread for a UI clock,
+// so the types here are usually used from a single goroutine;
+// they are safe for concurrent use nevertheless.
+package timers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/zzl/goforms/framework/clock"
+)
+
+// Timer calls a function after an interval, once or repeatedly.
+type Timer struct {
+	mutex    sync.Mutex
+	clock    clock.Clock
+	interval time.Duration
+	repeat   bool
+	fn       func()
+
+	pending clock.Timer
+	due     time.Time
+	gen     int //incremented on every (re)scheduling, to ignore stale calls
+}
+
+// NewTimer creates a stopped timer calling fn after the interval,
+// repeatedly if repeat is true.
+func NewTimer(clk clock.Clock, interval time.Duration, repeat bool, fn func()) *Timer {
+	return &Timer{clock: clk, interval: interval, repeat: repeat, fn: fn}
+}
+
+// AfterFunc creates and starts a one-shot timer.
+func AfterFunc(clk clock.Clock, delay time.Duration, fn func()) *Timer {
+	timer := NewTimer(clk, delay, false, fn)
+	timer.Start()
+	return timer
+}
+
+// Start starts the timer if it is not running.
+// It returns false if the clock could not schedule the call, the timer staying stopped.
+func (this *Timer) Start() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	if this.pending == nil {
+		this.schedule(this.clock.Now().Add(this.interval))
+	}
+	return this.pending != nil
+}
+
+// Stop stops the timer. It returns false if the timer was not running.
+func (this *Timer) Stop() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.stop()
+}
+
+// Restart stops the timer if it is running and starts it again,
+// so that the next call happens a full interval from now.
+func (this *Timer) Restart() {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.stop()
+	this.schedule(this.clock.Now().Add(this.interval))
+}
+
+// IsRunning tells whether a call is pending.
+func (this *Timer) IsRunning() bool {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.pending != nil
+}
+
+// Interval returns the interval of the timer.
+func (this *Timer) Interval() time.Duration {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	return this.interval
+}
+
+// SetInterval changes the interval of the timer,
+// restarting it with the new interval if it is running.
+func (this *Timer) SetInterval(interval time.Duration) {
+	this.mutex.Lock()
+	defer this.mutex.Unlock()
+	this.interval = interval
+	if this.stop() {
+		this.schedule(this.clock.Now().Add(interval))
+	}
+}
+
+func (this *Timer) stop() bool {
+	if this.pending == nil {
+		return false
+	}
+	this.pending.Stop()
+	this.pending = nil
+	this.gen += 1
+	return true
+}
+
+// schedule schedules the next call, leaving the timer stopped if the clock fails to.
+func (this *Timer) schedule(due time.Time) {
+	this.gen += 1
+	gen := this.gen
+	this.due = due
+	this.pending = this.clock.AfterFunc(due.Sub(this.clock.Now()), func() {
+		this.fire(gen)
+	})
+}
+
+func (this *Timer) fire(gen int) {
+	this.mutex.Lock()
+	if gen != this.gen || this.pending == nil {
+		this.mutex.Unlock()
+		return
+	}
+	this.pending = nil
+	if this.repeat && this.interval > 0 {
+		//schedule relative to the due time so that ticks do not drift
+		due := this.due.Add(this.interval)
+		if now := this.clock.Now(); due.Before(now) {
+			due = now
+		}
+		this.schedule(due)
+	}
+	fn := this.fn
+	this.mutex.Unlock()
+	fn()
+}
+
+// Debouncer calls a function once calls to Trigger have stopped for a delay,
+// e.g. to search as the user types.
+type Debouncer struct {
+	timer *Timer
+}
+
+// NewDebouncer creates a debouncer calling fn after the delay.
+func NewDebouncer(clk clock.Clock, delay time.Duration, fn func()) *Debouncer {
+	return &Debouncer{timer: NewTimer(clk, delay, false, fn)}
+}
+
+// Trigger schedules the call a full delay from now,
+// replacing the call scheduled by a previous Trigger.
+func (this *Debouncer) Trigger() {
+	this.timer.Restart()
+}
+
+// Cancel cancels the scheduled call. It returns false if no call was pending.
+func (this *Debouncer) Cancel() bool {
+	return this.timer.Stop()
+}
+
+// Flush makes the scheduled call now, if any.
+func (this *Debouncer) Flush() {
+	if this.timer.Stop() {
+		this.timer.fn()
+	}
+}
+
+// IsPending tells whether a call is scheduled.
+func (this *Debouncer) IsPending() bool {
+	return this.timer.IsRunning()
+}
+
+// Throttler calls a function at most once per interval, e.g. for resize handlers.
+// The first Trigger calls the function immediately; the Triggers within the
+// interval that follows are coalesced into one call at the end of the interval.
+type Throttler struct {
+	mutex    sync.Mutex
+	clock    clock.Clock
+	interval time.Duration
+	fn       func()
+
+	last     time.Time
+	called   bool //whether fn has been called, so that last is valid
+	trailing *Timer
+}
+
+// NewThrottler creates a throttler calling fn at most once per interval.
+func NewThrottler(clk clock.Clock, interval time.Duration, fn func()) *Throttler {
+	throttler := &Throttler{clock: clk, interval: interval, fn: fn}
+	throttler.trailing = NewTimer(clk, interval, false, throttler.fireTrailing)
+	return throttler
+}
+
+// Trigger calls the function now if the interval has elapsed since the last call,
+// otherwise it schedules a call at the end of the interval.
+func (this *Throttler) Trigger() {
+	this.mutex.Lock()
+	now := this.clock.Now()
+	elapsed := now.Sub(this.last)
+	if !this.called || elapsed >= this.interval {
+		this.called = true
+		this.last = now
+		this.mutex.Unlock()
+		this.trailing.Stop()
+		this.fn()
+		return
+	}
+	this.mutex.Unlock()
+	if !this.trailing.IsRunning() {
+		this.trailing.SetInterval(this.interval - elapsed)
+		this.trailing.Start()
+	}
+}
+
+// Cancel cancels the call scheduled at the end of the interval, if any.
+func (this *Throttler) Cancel() bool {
+	return this.trailing.Stop()
+}
+
+// IsPending tells whether a call is scheduled at the end of the interval.
+func (this *Throttler) IsPending() bool {
+	return this.trailing.IsRunning()
+}
+
+func (this *Throttler) fireTrailing() {
+	this.mutex.Lock()
+	this.called = true
+	this.last = this.clock.Now()
+	this.mutex.Unlock()
+	this.fn()
+}
@@ -0,0 +1,204 @@
+package timers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zzl/goforms/framework/clock"
+)
+
+var start = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// recorder records the times of its calls, as offsets from start.
+type recorder struct {
+	clock *clock.Virtual
+	calls []time.Duration
+}
+
+func newRecorder() *recorder {
+	return &recorder{clock: clock.NewVirtual(start)}
+}
+
+func (this *recorder) call() {
+	this.calls = append(this.calls, this.clock.Now().Sub(start))
+}
+
+func (this *recorder) check(t *testing.T, name string, want ...time.Duration) {
+	t.Helper()
+	if len(this.calls) != len(want) {
+		t.Fatalf("%s: calls at %v, want %v", name, this.calls, want)
+	}
+	for i := range want {
+		if this.calls[i] != want[i] {
+			t.Fatalf("%s: calls at %v, want %v", name, this.calls, want)
+		}
+	}
+}
+
+const ms = time.Millisecond
+
+func TestOneShot(t *testing.T) {
+	r := newRecorder()
+	timer := NewTimer(r.clock, 100*ms, false, r.call)
+	if timer.IsRunning() || r.clock.Pending() != 0 {
+		t.Fatal("a new timer is running")
+	}
+	if !timer.Start() || !timer.IsRunning() {
+		t.Fatal("Start failed")
+	}
+	timer.Start() //already running, no second call
+	r.clock.Advance(99 * ms)
+	r.check(t, "before due")
+	r.clock.Advance(1 * ms)
+	r.check(t, "due", 100*ms)
+	if timer.IsRunning() {
+		t.Error("a one-shot timer is running after firing")
+	}
+	r.clock.Advance(time.Second)
+	r.check(t, "after firing", 100*ms)
+}
+
+func TestRepeat(t *testing.T) {
+	r := newRecorder()
+	timer := NewTimer(r.clock, 100*ms, true, r.call)
+	timer.Start()
+	r.clock.Advance(350 * ms)
+	r.check(t, "repeating", 100*ms, 200*ms, 300*ms)
+	if !timer.IsRunning() || r.clock.Pending() != 1 {
+		t.Fatalf("running %v with %d pending, want one pending tick", timer.IsRunning(), r.clock.Pending())
+	}
+}
+
+func TestStop(t *testing.T) {
+	r := newRecorder()
+	timer := NewTimer(r.clock, 100*ms, true, r.call)
+	if timer.Stop() {
+		t.Error("Stop of a stopped timer returned true")
+	}
+	timer.Start()
+	r.clock.Advance(150 * ms)
+	if !timer.Stop() || timer.IsRunning() || r.clock.Pending() != 0 {
+		t.Fatalf("running %v with %d pending after Stop", timer.IsRunning(), r.clock.Pending())
+	}
+	r.clock.Advance(time.Second)
+	r.check(t, "stopped", 100*ms)
+
+	//stopped from its own function
+	r = newRecorder()
+	var self *Timer
+	self = NewTimer(r.clock, 100*ms, true, func() {
+		r.call()
+		self.Stop()
+	})
+	self.Start()
+	r.clock.Advance(time.Second)
+	r.check(t, "stopped by itself", 100*ms)
+}
+
+func TestRearm(t *testing.T) {
+	r := newRecorder()
+	timer := NewTimer(r.clock, 100*ms, false, r.call)
+	timer.Start()
+	r.clock.Advance(100 * ms)
+	//a one-shot timer can be started again after firing
+	timer.Start()
+	r.clock.Advance(100 * ms)
+	r.check(t, "started again", 100*ms, 200*ms)
+
+	//Restart moves the call a full interval from now
+	timer.Start()
+	r.clock.Advance(60 * ms)
+	timer.Restart()
+	r.clock.Advance(60 * ms)
+	r.check(t, "restarted", 100*ms, 200*ms)
+	r.clock.Advance(40 * ms)
+	r.check(t, "restarted", 100*ms, 200*ms, 360*ms)
+
+	//Restart starts a stopped timer
+	timer.Restart()
+	r.clock.Advance(100 * ms)
+	r.check(t, "restarted when stopped", 100*ms, 200*ms, 360*ms, 460*ms)
+
+	//SetInterval reschedules a running timer, and only a running one
+	timer.Start()
+	timer.SetInterval(30 * ms)
+	r.clock.Advance(30 * ms)
+	r.check(t, "new interval", 100*ms, 200*ms, 360*ms, 460*ms, 490*ms)
+	timer.SetInterval(50 * ms)
+	if timer.IsRunning() || timer.Interval() != 50*ms {
+		t.Errorf("SetInterval started a stopped timer, or interval %v", timer.Interval())
+	}
+}
+
+func TestAfterFunc(t *testing.T) {
+	r := newRecorder()
+	timer := AfterFunc(r.clock, 20*ms, r.call)
+	if !timer.IsRunning() {
+		t.Fatal("AfterFunc didn't start the timer")
+	}
+	r.clock.Advance(time.Second)
+	r.check(t, "after func", 20*ms)
+}
+
+// failingClock is a clock that can't schedule calls.
+type failingClock struct {
+	*clock.Virtual
+}
+
+func (this failingClock) AfterFunc(d time.Duration, fn func()) clock.Timer {
+	return nil
+}
+
+func TestStartFailure(t *testing.T) {
+	timer := NewTimer(failingClock{clock.NewVirtual(start)}, 100*ms, true, func() {})
+	if timer.Start() || timer.IsRunning() {
+		t.Error("Start succeeded on a clock that can't schedule calls")
+	}
+}
+
+func TestDebouncer(t *testing.T) {
+	r := newRecorder()
+	debouncer := NewDebouncer(r.clock, 100*ms, r.call)
+	for i := 0; i < 5; i++ {
+		debouncer.Trigger()
+		r.clock.Advance(50 * ms)
+	}
+	r.check(t, "triggering")
+	r.clock.Advance(50 * ms)
+	r.check(t, "settled", 300*ms)
+
+	debouncer.Trigger()
+	if !debouncer.Cancel() || debouncer.IsPending() {
+		t.Error("Cancel failed")
+	}
+	debouncer.Trigger()
+	r.clock.Advance(10 * ms)
+	debouncer.Flush()
+	r.clock.Advance(time.Second)
+	r.check(t, "flushed", 300*ms, 310*ms)
+}
+
+func TestThrottler(t *testing.T) {
+	r := newRecorder()
+	throttler := NewThrottler(r.clock, 100*ms, r.call)
+	throttler.Trigger()
+	r.check(t, "leading", 0)
+	for i := 0; i < 4; i++ {
+		r.clock.Advance(20 * ms)
+		throttler.Trigger()
+	}
+	if !throttler.IsPending() {
+		t.Fatal("no trailing call pending")
+	}
+	r.clock.Advance(20 * ms)
+	r.check(t, "trailing", 0, 100*ms)
+	//the trailing call starts a new interval
+	r.clock.Advance(50 * ms)
+	throttler.Trigger()
+	r.check(t, "within the new interval", 0, 100*ms)
+	r.clock.Advance(50 * ms)
+	r.check(t, "second trailing", 0, 100*ms, 200*ms)
+	r.clock.Advance(time.Second)
+	throttler.Trigger()
+	r.check(t, "after the interval", 0, 100*ms, 200*ms, 1200*ms)
+}
@@ -0,0 +1,327 @@
+package undo
+
+import "github.com/zzl/goforms/framework/events"
+
+// Action is a reversible operation recorded by a Manager.
+type Action interface {
+	// Name returns the display name of the action, such as "Rename"
+	Name() string
+	// Undo reverts the effect of the action
+	Undo()
+	// Redo performs the action again after it has been undone
+	Redo()
+}
+
+// Merger is implemented by actions that can absorb a subsequent action,
+// e.g. consecutive keystrokes in a text editor.
+type Merger interface {
+	// Merge tries to fold next into this action.
+	// It returns false if the two actions can not be merged.
+	Merge(next Action) bool
+}
+
+// FuncAction is an Action implemented by functions.
+type FuncAction struct {
+	Text      string
+	UndoFunc  func()
+	RedoFunc  func()
+	MergeFunc func(next Action) bool
+}
+
+func (this *FuncAction) Name() string {
+	return this.Text
+}
+
+func (this *FuncAction) Undo() {
+	if this.UndoFunc != nil {
+		this.UndoFunc()
+	}
+}
+
+func (this *FuncAction) Redo() {
+	if this.RedoFunc != nil {
+		this.RedoFunc()
+	}
+}
+
+func (this *FuncAction) Merge(next Action) bool {
+	if this.MergeFunc == nil {
+		return false
+	}
+	return this.MergeFunc(next)
+}
+
+// Group is a composite action that is undone and redone as a whole.
+type Group struct {
+	Text    string
+	Actions []Action
+}
+
+func (this *Group) Name() string {
+	return this.Text
+}
+
+func (this *Group) Undo() {
+	for n := len(this.Actions) - 1; n >= 0; n-- {
+		this.Actions[n].Undo()
+	}
+}
+
+func (this *Group) Redo() {
+	for _, action := range this.Actions {
+		action.Redo()
+	}
+}
+
+func (this *Group) add(action Action) {
+	count := len(this.Actions)
+	if count > 0 {
+		if merger, ok := this.Actions[count-1].(Merger); ok && merger.Merge(action) {
+			return
+		}
+	}
+	this.Actions = append(this.Actions, action)
+}
+
+// Manager maintains the undo and redo stacks of a document.
+type Manager struct {
+	// Limit is the maximum number of undoable actions, 0 means unlimited
+	Limit int
+
+	// OnChange fires whenever the stacks or the modified state change
+	OnChange events.SimpleEvent
+
+	actions  []Action //actions[:pos] are undoable, actions[pos:] are redoable
+	pos      int
+	savedPos int //-1 if the saved state is no longer reachable
+
+	groups     []*Group
+	noMerge    bool
+	performing bool
+}
+
+func NewManager() *Manager {
+	return &Manager{}
+}
+
+// Execute performs the action by calling its Redo method and records it.
+func (this *Manager) Execute(action Action) {
+	this.performing = true
+	func() {
+		defer func() {
+			this.performing = false
+		}()
+		action.Redo()
+	}()
+	this.Add(action)
+}
+
+// Add records an action that has already been performed.
+// Actions added while an undo or redo is in progress are ignored.
+func (this *Manager) Add(action Action) {
+	if this.performing {
+		return
+	}
+	if count := len(this.groups); count > 0 {
+		this.groups[count-1].add(action)
+		return
+	}
+	if this.pos < len(this.actions) {
+		if this.savedPos > this.pos {
+			this.savedPos = -1
+		}
+		this.actions = this.actions[:this.pos]
+	}
+	if !this.noMerge && this.pos > 0 && this.savedPos != this.pos {
+		merger, ok := this.actions[this.pos-1].(Merger)
+		if ok && merger.Merge(action) {
+			this.fireChange()
+			return
+		}
+	}
+	this.actions = append(this.actions, action)
+	this.pos += 1
+	this.noMerge = false
+	this.applyLimit()
+	this.fireChange()
+}
+
+func (this *Manager) applyLimit() {
+	if this.Limit <= 0 || len(this.actions) <= this.Limit {
+		return
+	}
+	drop := len(this.actions) - this.Limit
+	this.actions = append([]Action(nil), this.actions[drop:]...)
+	this.pos -= drop
+	if this.savedPos >= 0 {
+		this.savedPos -= drop
+		if this.savedPos < 0 {
+			this.savedPos = -1
+		}
+	}
+}
+
+// BreakMerge prevents the next added action from being
+// merged into the current top action.
+func (this *Manager) BreakMerge() {
+	this.noMerge = true
+}
+
+// BeginGroup starts collecting subsequently added actions into a named group.
+// Groups can be nested; only the outermost one appears on the undo stack.
+func (this *Manager) BeginGroup(name string) {
+	this.groups = append(this.groups, &Group{Text: name})
+}
+
+// EndGroup closes the innermost group and records it if it is not empty.
+func (this *Manager) EndGroup() {
+	count := len(this.groups)
+	if count == 0 {
+		return
+	}
+	group := this.groups[count-1]
+	this.groups = this.groups[:count-1]
+	if len(group.Actions) == 0 {
+		return
+	}
+	var action Action = group
+	if len(group.Actions) == 1 && group.Text == "" {
+		action = group.Actions[0]
+	}
+	if count > 1 {
+		this.groups[count-2].add(action)
+	} else {
+		this.noMerge = true
+		this.Add(action)
+		this.noMerge = true
+	}
+}
+
+// CancelGroup closes the innermost group, undoing the actions collected so far.
+func (this *Manager) CancelGroup() {
+	count := len(this.groups)
+	if count == 0 {
+		return
+	}
+	group := this.groups[count-1]
+	this.groups = this.groups[:count-1]
+	this.perform(group.Undo)
+}
+
+// InGroup reports whether a group is open.
+func (this *Manager) InGroup() bool {
+	return len(this.groups) > 0
+}
+
+// Transaction runs fn within a named group.
+// The group is canceled if fn returns an error or panics.
+func (this *Manager) Transaction(name string, fn func() error) (err error) {
+	this.BeginGroup(name)
+	committed := false
+	defer func() {
+		if !committed {
+			this.CancelGroup()
+		}
+	}()
+	err = fn()
+	if err == nil {
+		committed = true
+		this.EndGroup()
+	}
+	return err
+}
+
+func (this *Manager) perform(fn func()) {
+	this.performing = true
+	defer func() {
+		this.performing = false
+	}()
+	fn()
+}
+
+func (this *Manager) CanUndo() bool {
+	return this.pos > 0 && len(this.groups) == 0
+}
+
+func (this *Manager) CanRedo() bool {
+	return this.pos < len(this.actions) && len(this.groups) == 0
+}
+
+// UndoName returns the name of the action to be undone, or "" if none.
+func (this *Manager) UndoName() string {
+	if !this.CanUndo() {
+		return ""
+	}
+	return this.actions[this.pos-1].Name()
+}
+
+// RedoName returns the name of the action to be redone, or "" if none.
+func (this *Manager) RedoName() string {
+	if !this.CanRedo() {
+		return ""
+	}
+	return this.actions[this.pos].Name()
+}
+
+func (this *Manager) UndoCount() int {
+	return this.pos
+}
+
+func (this *Manager) RedoCount() int {
+	return len(this.actions) - this.pos
+}
+
+func (this *Manager) Undo() bool {
+	if !this.CanUndo() {
+		return false
+	}
+	this.pos -= 1
+	this.perform(this.actions[this.pos].Undo)
+	this.noMerge = true
+	this.fireChange()
+	return true
+}
+
+func (this *Manager) Redo() bool {
+	if !this.CanRedo() {
+		return false
+	}
+	this.perform(this.actions[this.pos].Redo)
+	this.pos += 1
+	this.noMerge = true
+	this.fireChange()
+	return true
+}
+
+// MarkSaved records the current position as the saved state.
+func (this *Manager) MarkSaved() {
+	if this.savedPos == this.pos {
+		return
+	}
+	this.savedPos = this.pos
+	this.fireChange()
+}
+
+// IsModified reports whether the document differs from the saved state.
+func (this *Manager) IsModified() bool {
+	return this.savedPos != this.pos
+}
+
+// Clear removes all actions. The modified state is preserved.
+func (this *Manager) Clear() {
+	modified := this.IsModified()
+	this.actions = nil
+	this.pos = 0
+	this.groups = nil
+	this.noMerge = false
+	if modified {
+		this.savedPos = -1
+	} else {
+		this.savedPos = 0
+	}
+	this.fireChange()
+}
+
+func (this *Manager) fireChange() {
+	this.OnChange.Fire(this, &events.SimpleEventInfo{})
+}
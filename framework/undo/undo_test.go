@@ -0,0 +1,261 @@
+package undo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/zzl/goforms/framework/events"
+)
+
+// doc is a document of text, edited by typing actions.
+type doc struct {
+	text string
+}
+
+// typing appends text to a doc. Consecutive typing merges.
+type typing struct {
+	doc  *doc
+	text string
+}
+
+func (this *typing) Name() string {
+	return "Typing"
+}
+
+func (this *typing) Undo() {
+	this.doc.text = this.doc.text[:len(this.doc.text)-len(this.text)]
+}
+
+func (this *typing) Redo() {
+	this.doc.text += this.text
+}
+
+func (this *typing) Merge(next Action) bool {
+	other, ok := next.(*typing)
+	if !ok {
+		return false
+	}
+	this.text += other.text
+	return true
+}
+
+// set replaces the text of a doc.
+func set(d *doc, text string) Action {
+	old := d.text
+	return &FuncAction{
+		Text:     "Set " + text,
+		UndoFunc: func() { d.text = old },
+		RedoFunc: func() { d.text = text },
+	}
+}
+
+func TestUndoRedo(t *testing.T) {
+	d := &doc{}
+	m := NewManager()
+	m.Execute(set(d, "a"))
+	m.Execute(set(d, "b"))
+	if d.text != "b" || m.UndoName() != "Set b" || m.CanRedo() {
+		t.Fatalf("text %q, undo name %q, can redo %v", d.text, m.UndoName(), m.CanRedo())
+	}
+	m.Undo()
+	if d.text != "a" || m.RedoName() != "Set b" || m.UndoCount() != 1 || m.RedoCount() != 1 {
+		t.Errorf("after undo: text %q, redo name %q, counts %d/%d", d.text, m.RedoName(), m.UndoCount(), m.RedoCount())
+	}
+	m.Redo()
+	if d.text != "b" {
+		t.Errorf("after redo: text %q, want b", d.text)
+	}
+	m.Undo()
+	m.Undo()
+	if d.text != "" || m.Undo() {
+		t.Errorf("after undoing all: text %q, or undo succeeded", d.text)
+	}
+	//a new action drops the redo stack
+	m.Execute(set(d, "c"))
+	if m.CanRedo() || m.UndoCount() != 1 {
+		t.Errorf("redo stack kept: redo count %d, undo count %d", m.RedoCount(), m.UndoCount())
+	}
+}
+
+func TestIsModified(t *testing.T) {
+	d := &doc{}
+	m := NewManager()
+	changes := 0
+	m.OnChange.AddListener(func(ei *events.SimpleEventInfo) {
+		changes += 1
+	})
+	if m.IsModified() {
+		t.Fatal("a new manager is modified")
+	}
+	m.Execute(set(d, "a"))
+	m.MarkSaved()
+	steps := []struct {
+		name     string
+		step     func()
+		modified bool
+	}{
+		{"saved", func() {}, false},
+		{"executed", func() { m.Execute(set(d, "b")) }, true},
+		{"undone to saved", func() { m.Undo() }, false},
+		{"undone past saved", func() { m.Undo() }, true},
+		{"redone to saved", func() { m.Redo() }, false},
+		{"redone past saved", func() { m.Redo() }, true},
+		{"undone to saved again", func() { m.Undo() }, false},
+		//the saved state is dropped from the redo stack
+		{"undone and executed", func() { m.Undo(); m.Execute(set(d, "c")) }, true},
+		{"undone to empty", func() { m.Undo() }, true},
+		{"redone", func() { m.Redo() }, true},
+		{"saved again", func() { m.MarkSaved() }, false},
+		{"cleared unmodified", func() { m.Clear() }, false},
+		{"executed after clear", func() { m.Execute(set(d, "d")) }, true},
+		{"cleared modified", func() { m.Clear() }, true},
+	}
+	for _, step := range steps {
+		step.step()
+		if m.IsModified() != step.modified {
+			t.Errorf("%s: modified %v, want %v", step.name, !step.modified, step.modified)
+		}
+	}
+	if changes == 0 {
+		t.Error("OnChange didn't fire")
+	}
+}
+
+func TestLimit(t *testing.T) {
+	d := &doc{}
+	m := NewManager()
+	m.Limit = 3
+	m.Execute(set(d, "a"))
+	m.MarkSaved()
+	for _, text := range []string{"b", "c", "d", "e"} {
+		m.Execute(set(d, text))
+	}
+	if m.UndoCount() != 3 {
+		t.Fatalf("undo count %d, want 3", m.UndoCount())
+	}
+	//the saved state was trimmed, so no undo reaches it
+	for m.Undo() {
+		if !m.IsModified() {
+			t.Fatalf("unmodified at %q after the saved state was trimmed", d.text)
+		}
+	}
+	if d.text != "b" {
+		t.Errorf("text %q after undoing all, want b", d.text)
+	}
+
+	//a saved state still within the limit is kept
+	m = NewManager()
+	m.Limit = 2
+	m.Execute(set(d, "x"))
+	m.Execute(set(d, "y"))
+	m.MarkSaved()
+	m.Execute(set(d, "z"))
+	m.Undo()
+	if m.IsModified() || d.text != "y" {
+		t.Errorf("modified %v at %q, want the saved state y", m.IsModified(), d.text)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	d := &doc{}
+	m := NewManager()
+	m.Execute(&typing{d, "a"})
+	m.Execute(&typing{d, "b"})
+	if m.UndoCount() != 1 || d.text != "ab" {
+		t.Fatalf("undo count %d, text %q, want merged typing", m.UndoCount(), d.text)
+	}
+	m.BreakMerge()
+	m.Execute(&typing{d, "c"})
+	if m.UndoCount() != 2 {
+		t.Errorf("undo count %d after BreakMerge, want 2", m.UndoCount())
+	}
+	//the saved state isn't merged into
+	m.MarkSaved()
+	m.Execute(&typing{d, "d"})
+	m.Undo()
+	if d.text != "abc" || m.IsModified() {
+		t.Errorf("text %q, modified %v, want the saved abc", d.text, m.IsModified())
+	}
+	//nor is an action after an undo
+	m.Execute(&typing{d, "e"})
+	m.Execute(&typing{d, "f"})
+	m.Undo()
+	if d.text != "abc" {
+		t.Errorf("text %q, want abc", d.text)
+	}
+	m.Undo()
+	if d.text != "ab" {
+		t.Errorf("text %q, want ab", d.text)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	d := &doc{}
+	m := NewManager()
+	m.BeginGroup("Edit")
+	m.Execute(set(d, "a"))
+	m.BeginGroup("")
+	m.Execute(&typing{d, "b"})
+	m.Execute(&typing{d, "c"})
+	m.EndGroup()
+	if m.CanUndo() || !m.InGroup() {
+		t.Fatal("undo available within a group")
+	}
+	m.EndGroup()
+	if m.UndoCount() != 1 || m.UndoName() != "Edit" || d.text != "abc" {
+		t.Fatalf("undo count %d, name %q, text %q", m.UndoCount(), m.UndoName(), d.text)
+	}
+	m.Undo()
+	if d.text != "" {
+		t.Errorf("text %q after undoing the group, want empty", d.text)
+	}
+	m.Redo()
+	if d.text != "abc" {
+		t.Errorf("text %q after redoing the group, want abc", d.text)
+	}
+	//the next typing doesn't merge into the group's last action
+	m.Execute(&typing{d, "d"})
+	m.Undo()
+	if d.text != "abc" {
+		t.Errorf("text %q, want abc", d.text)
+	}
+
+	//empty groups aren't recorded
+	count := m.UndoCount()
+	m.BeginGroup("Empty")
+	m.EndGroup()
+	if m.UndoCount() != count {
+		t.Error("an empty group was recorded")
+	}
+}
+
+func TestTransaction(t *testing.T) {
+	d := &doc{text: "start"}
+	m := NewManager()
+	err := m.Transaction("Failing", func() error {
+		m.Execute(set(d, "a"))
+		m.Execute(&typing{d, "b"})
+		return errors.New("failed")
+	})
+	if err == nil || d.text != "start" || m.CanUndo() || m.InGroup() {
+		t.Errorf("failed transaction: err %v, text %q, can undo %v", err, d.text, m.CanUndo())
+	}
+	func() {
+		defer func() {
+			recover()
+		}()
+		_ = m.Transaction("Panicking", func() error {
+			m.Execute(set(d, "p"))
+			panic("panicking")
+		})
+	}()
+	if d.text != "start" || m.InGroup() {
+		t.Errorf("panicking transaction: text %q, in group %v", d.text, m.InGroup())
+	}
+	if err := m.Transaction("Ok", func() error {
+		m.Execute(set(d, "ok"))
+		return nil
+	}); err != nil || m.UndoName() != "Ok" {
+		t.Errorf("transaction: err %v, undo name %q", err, m.UndoName())
+	}
+}
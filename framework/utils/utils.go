@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/zzl/goforms/framework/consts"
 	"github.com/zzl/goforms/framework/types"
-	"log"
 	"math"
 	"reflect"
 	"regexp"
@@ -171,10 +170,3 @@ func OptionalArg[T any](args []*T) *T {
 	}
 	return p
 }
-
-// AssertNoErr panics if an error is not nil.
-func AssertNoErr(err error) {
-	if err != nil {
-		log.Panic(err)
-	}
-}
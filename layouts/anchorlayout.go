@@ -3,7 +3,6 @@ package layouts
 import (
 	"github.com/zzl/goforms/framework/consts"
 	"github.com/zzl/goforms/framework/utils"
-	"log"
 	"math"
 )
 
@@ -375,7 +374,7 @@ func (this *AnchorLayout) SetItemDefaults(itemDefaults LayoutItem) {
 	this.ItemDefaults = itemDefaults.(*AnchorItem)
 }
 
-func (this *AnchorLayout) SetContainer(container Container) {
+func (this *AnchorLayout) SetContainer(container Container) error {
 	items := this._getItems()
 	for n, _ := range items {
 		item := items[n]
@@ -395,25 +394,26 @@ func (this *AnchorLayout) SetContainer(container Container) {
 		}
 
 		if item.Name != "" {
-			if item.Control != nil {
-				log.Fatal("??")
-			}
-			item.Control = container.GetControlByName(item.Name)
-			if item.Control == nil {
-				log.Fatal("??")
+			control, err := resolveItemControl(container, item.Name, item.Control)
+			if err != nil {
+				return err
 			}
+			item.Control = control
 			la, ok := item.Control.(LayoutAware)
 			if ok {
 				la.SetLayout(item.Layout)
 			}
 		} else if item.Layout != nil {
-			item.Layout.SetContainer(container)
+			if err := item.Layout.SetContainer(container); err != nil {
+				return err
+			}
 		}
 
 		if item.Control != nil {
 			item.Control.SetData(Data_Layout, this)
 		}
 	}
+	return nil
 }
 
 func (this *AnchorLayout) AddItems(items []LayoutItem, prepend bool) {
@@ -1,6 +1,7 @@
 package layouts
 
 import (
+	"errors"
 	"github.com/zzl/goforms/framework/events"
 	"github.com/zzl/goforms/layouts/aligns"
 )
@@ -12,6 +13,10 @@ const (
 	DefaultContentAlign = aligns.Stretch
 )
 
+// ErrControlNotFound is returned by SetContainer if the container
+// has no control named as a layout item.
+var ErrControlNotFound = errors.New("layout item control not found")
+
 type LayoutAware interface {
 	SetLayout(layout Layout)
 }
@@ -59,7 +64,7 @@ type Layout interface {
 	BoundsAware
 	LayoutEventSource
 
-	SetContainer(container Container)
+	SetContainer(container Container) error
 	SetItemDefaults(itemDefaults LayoutItem)
 	AddItems(items []LayoutItem, prepend bool)
 	Clone() Layout
@@ -1,8 +1,6 @@
 package layouts
 
 import (
-	"log"
-
 	"github.com/zzl/goforms/framework/utils"
 	"github.com/zzl/goforms/layouts/aligns"
 )
@@ -153,7 +151,7 @@ func (this *LinearLayout) AddItems(items []LayoutItem, prepend bool) {
 	}
 }
 
-func (this *LinearLayout) SetContainer(container Container) {
+func (this *LinearLayout) SetContainer(container Container) error {
 	items := this._getItems() //?
 	for n, _ := range items {
 		item := items[n]
@@ -181,19 +179,19 @@ func (this *LinearLayout) SetContainer(container Container) {
 		}
 
 		if item.Name != "" {
-			if item.Control != nil {
-				log.Fatal("??")
-			}
-			item.Control = container.GetControlByName(item.Name)
-			if item.Control == nil {
-				log.Fatal("layout item control not found: ", item.Name)
+			control, err := resolveItemControl(container, item.Name, item.Control)
+			if err != nil {
+				return err
 			}
+			item.Control = control
 			la, ok := item.Control.(LayoutAware)
 			if ok {
 				la.SetLayout(item.Layout)
 			}
 		} else if item.Layout != nil {
-			item.Layout.SetContainer(container)
+			if err := item.Layout.SetContainer(container); err != nil {
+				return err
+			}
 		}
 
 		if item.Control != nil {
@@ -202,6 +200,7 @@ func (this *LinearLayout) SetContainer(container Container) {
 	}
 	this._sizeGroupMap = make(map[string]int)
 	this.collectSizeGroupMap()
+	return nil
 }
 
 func (this *LinearLayout) SetSizeGroup(sg map[string]int) {
@@ -1,5 +1,7 @@
 package layouts
 
+import "fmt"
+
 func Items[T LayoutItem](items []T) []LayoutItem {
 	if items == nil {
 		return nil
@@ -10,3 +12,15 @@ func Items[T LayoutItem](items []T) []LayoutItem {
 	}
 	return result
 }
+
+// resolveItemControl finds the control of a named layout item in the container.
+func resolveItemControl(container Container, name string, control Control) (Control, error) {
+	if control != nil {
+		return nil, fmt.Errorf("layout item %q: both name and control specified", name)
+	}
+	control = container.GetControlByName(name)
+	if control == nil {
+		return nil, fmt.Errorf("layout item %q: %w", name, ErrControlNotFound)
+	}
+	return control, nil
+}